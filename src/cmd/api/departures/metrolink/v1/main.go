@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
 	"github.com/Marchie/tf-experiment/lambda/internal/core/departures/metrolink/api"
+	api2 "github.com/Marchie/tf-experiment/lambda/internal/core/stops/api"
 	"github.com/Marchie/tf-experiment/lambda/internal/logger"
 	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/departures/metrolink/v1"
+	v13 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/messages/metrolink/v1"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
 	v12 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/system/status/v1"
 	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
@@ -19,15 +22,34 @@ import (
 )
 
 type Config struct {
+	BatchDeparturesApiGatewayResource                  string        `envvar:"BATCH_DEPARTURES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v2/batch"`
+	DeparturesApiGatewayResource                       string        `envvar:"DEPARTURES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}"`
+	DeparturesV2ApiGatewayResource                     string        `envvar:"DEPARTURES_V2_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v2/{stopAreaCodeOrAtcoCode}"`
+	LineApiGatewayPathParameter                        string        `envvar:"LINE_API_GATEWAY_PATH_PARAMETER" default:"line"`
+	LineMessagesApiGatewayResource                     string        `envvar:"LINE_MESSAGES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/lines/{line}/messages"`
+	LocationsApiGatewayQueryParameter                  string        `envvar:"LOCATIONS_API_GATEWAY_QUERY_PARAMETER" default:"locations"`
 	LogLevel                                           int8          `envvar:"LOG_LEVEL" default:"0"`
+	MessagesApiGatewayResource                         string        `envvar:"MESSAGES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}/messages"`
+	MetrolinkDeparturesAgeAdjustedWaits                bool          `envvar:"METROLINK_DEPARTURES_AGE_ADJUSTED_WAITS" default:"false"`
+	MetrolinkDeparturesDegradedDataThreshold           time.Duration `envvar:"METROLINK_DEPARTURES_DEGRADED_DATA_THRESHOLD" default:"0s"`
 	MetrolinkDeparturesStaleDataThreshold              time.Duration `envvar:"METROLINK_DEPARTURES_STALE_DATA_THRESHOLD" default:"30s"`
-	RedisMetrolinkDeparturesServerAddress              string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS"`
+	NearbyStopsApiGatewayResource                      string        `envvar:"NEARBY_STOPS_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1/nearby"`
+	RedisAtcoCodeStopAreasKeyPrefix                    string        `envvar:"REDIS_ATCO_CODE_STOP_AREAS_KEY_PREFIX" default:"atco_code_stop_areas"`
+	RedisMetrolinkDeparturesServerAddress              string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS" default:""`
 	RedisMetrolinkDeparturesKeyPrefix                  string        `envvar:"REDIS_METROLINK_DEPARTURES_KEY_PREFIX" default:"metrolink_departures"`
-	RedisMetrolinkDeparturesServiceStatusServerAddress string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS"`
+	RedisMetrolinkMessagesKeyPrefix                    string        `envvar:"REDIS_METROLINK_MESSAGES_KEY_PREFIX" default:"metrolink_messages"`
+	RedisMetrolinkTlarefsKeyPrefix                     string        `envvar:"REDIS_METROLINK_TLAREFS_KEY_PREFIX" default:"metrolink_tlarefs"`
+	RedisMetrolinkDeparturesServiceStatusServerAddress string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS" default:""`
 	RedisMetrolinkDeparturesServiceStatusKey           string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_KEY" default:"metrolink_departures_service_status"`
-	RedisStopsInAreaServerAddress                      string        `envvar:"REDIS_STOPS_IN_AREA_SERVER_ADDRESS"`
+	RedisNaptanStopAreasKey                            string        `envvar:"REDIS_NAPTAN_STOP_AREAS_KEY" default:"naptan_stop_areas"`
+	RedisNaptanStopsKey                                string        `envvar:"REDIS_NAPTAN_STOPS_KEY" default:"naptan_stops"`
+	RedisStopDirectoryKey                              string        `envvar:"REDIS_STOP_DIRECTORY_KEY" default:"metrolink_stop_directory"`
+	RedisStopsInAreaServerAddress                      string        `envvar:"REDIS_STOPS_IN_AREA_SERVER_ADDRESS" default:""`
 	RedisStopsInAreaKeyPrefix                          string        `envvar:"REDIS_STOPS_IN_AREA_KEY_PREFIX" default:"stops_in_area"`
+	StatusApiGatewayResource                           string        `envvar:"STATUS_API_GATEWAY_RESOURCE" default:"/status"`
 	StopAreaCodeOrAtcoCodeApiGatewayPathParameter      string        `envvar:"STOP_AREA_CODE_OR_ATCO_CODE_API_GATEWAY_PATH_PARAMETER"`
+	StopSearchApiGatewayResource                       string        `envvar:"STOP_SEARCH_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1/search"`
+	StopsApiGatewayResource                            string        `envvar:"STOPS_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1"`
 	TimeLocation                                       string        `envvar:"TIME_LOCATION" default:"Europe/London"`
 }
 
@@ -65,19 +87,47 @@ func main() {
 		panic(errors.Wrap(err, "error loading time location"))
 	}
 
+	if cfg.RedisMetrolinkDeparturesServerAddress == "" || cfg.RedisMetrolinkDeparturesServiceStatusServerAddress == "" || cfg.RedisStopsInAreaServerAddress == "" {
+		panic(errors.New("every Redis server address is required"))
+	}
+
 	lambda.Start(func(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
 		lc, _ := lambdacontext.FromContext(ctx)
 
 		childLogger := baseLogger.With(zap.String("awsRequestId", lc.AwsRequestID))
 
-		stopsInAreaGetter := naptan.NewNaptanRedis(childLogger, stopsInAreaPool, cfg.RedisStopsInAreaKeyPrefix, 0)
+		stopsInAreaGetter := naptan.NewNaptanRedis(childLogger, stopsInAreaPool, cfg.RedisStopsInAreaKeyPrefix, cfg.RedisAtcoCodeStopAreasKeyPrefix, 0)
+
+		stopDirectoryGetter := naptan.NewMetrolinkStopDirectoryRedis(childLogger, stopsInAreaPool, cfg.RedisStopDirectoryKey, 0)
+
+		naptanStopsGetter := naptan.NewNaptanStopsRedis(childLogger, stopsInAreaPool, cfg.RedisNaptanStopsKey, cfg.RedisNaptanStopAreasKey, 0)
 
 		metrolinkDeparturesGetter := v1.NewMetrolinkDeparturesRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkDeparturesKeyPrefix, 0)
 
+		metrolinkMessagesGetter := v13.NewMetrolinkMessagesRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkMessagesKeyPrefix, 0)
+
+		tlarefGetter := v1.NewMetrolinkTlarefsRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkTlarefsKeyPrefix, 0)
+
 		systemStatusGetter := v12.NewMetrolinkDeparturesSystemStatusRepository(childLogger, metrolinkDeparturesSystemStatusPool, cfg.RedisMetrolinkDeparturesServiceStatusKey)
 
-		metrolinkDeparturesApi := api.NewApi(childLogger, stopsInAreaGetter, metrolinkDeparturesGetter, systemStatusGetter, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold, timeLocation)
+		metrolinkDeparturesApi := api.NewApi(childLogger, stopsInAreaGetter, stopsInAreaGetter, tlarefGetter, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkMessagesGetter, systemStatusGetter, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, timeLocation, cfg.MetrolinkDeparturesAgeAdjustedWaits)
+
+		stopsApi := api2.NewApi(childLogger, stopDirectoryGetter, naptanStopsGetter, naptanStopsGetter, naptanStopsGetter, metrolinkDeparturesGetter, systemStatusGetter, metrolinkDeparturesApi)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(childLogger, metrolinkDeparturesApi, metrolinkDeparturesApi, cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter, cfg.LineApiGatewayPathParameter)
+
+		router := apigw.NewRouter(childLogger, map[string]apigw.AwsApiGatewayHandler{
+			cfg.DeparturesApiGatewayResource:      apigw.NewMetrolinkDeparturesAwsApiGateway(childLogger, metrolinkDeparturesApi, cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter),
+			cfg.DeparturesV2ApiGatewayResource:    apigw.NewMetrolinkDeparturesAwsApiGateway(childLogger, core.StopAreaDeparturesJsonerFunc(metrolinkDeparturesApi.JsonV2), cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter),
+			cfg.BatchDeparturesApiGatewayResource: apigw.NewMetrolinkDeparturesBatchAwsApiGateway(childLogger, metrolinkDeparturesApi, cfg.LocationsApiGatewayQueryParameter),
+			cfg.MessagesApiGatewayResource:        metrolinkMessagesAwsApiGateway,
+			cfg.LineMessagesApiGatewayResource:    metrolinkMessagesAwsApiGateway,
+			cfg.StatusApiGatewayResource:          apigw.NewMetrolinkSystemStatusAwsApiGateway(childLogger, metrolinkDeparturesApi),
+			cfg.StopsApiGatewayResource:           apigw.NewMetrolinkStopsAwsApiGateway(childLogger, stopsApi),
+			cfg.NearbyStopsApiGatewayResource:     apigw.NewMetrolinkNearbyStopsAwsApiGateway(childLogger, stopsApi),
+			cfg.StopSearchApiGatewayResource:      apigw.NewMetrolinkStopSearchAwsApiGateway(childLogger, stopsApi),
+		})
 
-		return apigw.NewMetrolinkDeparturesAwsApiGateway(childLogger, metrolinkDeparturesApi, cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter).Handler(ctx, event)
+		return router.Handler(ctx, event)
 	})
 }
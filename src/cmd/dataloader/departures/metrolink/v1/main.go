@@ -4,9 +4,11 @@ import (
 	"context"
 	loader2 "github.com/Marchie/tf-experiment/lambda/internal/core/departures/metrolink/loader"
 	"github.com/Marchie/tf-experiment/lambda/internal/logger"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/tfgm/developer"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/filesystem"
 	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/departures/metrolink/v1"
+	v13 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/messages/metrolink/v1"
 	v12 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/system/status/v1"
 	"github.com/Marchie/tf-experiment/lambda/internal/transport/sqs"
 	"github.com/aws/aws-lambda-go/lambda"
@@ -19,14 +21,30 @@ import (
 	"time"
 )
 
+const (
+	metrolinkDeparturesSourceReplay = "replay"
+	metrolinkDeparturesSourceTfgm   = "tfgm"
+)
+
 type Config struct {
 	HttpClientTimeout                                  time.Duration `envvar:"HTTP_CLIENT_TIMEOUT" default:"1500ms"`
 	LogLevel                                           int8          `envvar:"LOG_LEVEL" default:"0"`
+	MetrolinkDeparturesDegradedDataThreshold           time.Duration `envvar:"METROLINK_DEPARTURES_DEGRADED_DATA_THRESHOLD" default:"0s"`
+	MetrolinkDeparturesLoaderInterval                  time.Duration `envvar:"METROLINK_DEPARTURES_LOADER_INTERVAL" default:"10s"`
+	MetrolinkDeparturesRecordingDirectory              string        `envvar:"METROLINK_DEPARTURES_RECORDING_DIRECTORY" default:""`
+	MetrolinkDeparturesReplayDirectory                 string        `envvar:"METROLINK_DEPARTURES_REPLAY_DIRECTORY" default:""`
+	MetrolinkDeparturesReplayRebase                    bool          `envvar:"METROLINK_DEPARTURES_REPLAY_REBASE" default:"true"`
+	MetrolinkDeparturesReplaySpeed                     float64       `envvar:"METROLINK_DEPARTURES_REPLAY_SPEED" default:"0"`
+	MetrolinkDeparturesSource                          string        `envvar:"METROLINK_DEPARTURES_SOURCE" default:"tfgm"`
 	MetrolinkDeparturesStaleDataThreshold              time.Duration `envvar:"METROLINK_DEPARTURES_STALE_DATA_THRESHOLD" default:"30s"`
-	RedisMetrolinkDeparturesServerAddress              string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS"`
+	RedisMetrolinkDeparturesServerAddress              string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS" default:""`
 	RedisMetrolinkDeparturesKeyPrefix                  string        `envvar:"REDIS_METROLINK_DEPARTURES_KEY_PREFIX" default:"metrolink_departures"`
-	RedisMetrolinkDeparturesTimeToLive                 time.Duration `envvar:"REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE" default:"15s"`
-	RedisMetrolinkDeparturesServiceStatusServerAddress string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS"`
+	RedisMetrolinkDeparturesTimeToLive                 time.Duration `envvar:"REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE" default:"1m"`
+	RedisMetrolinkMessagesKeyPrefix                    string        `envvar:"REDIS_METROLINK_MESSAGES_KEY_PREFIX" default:"metrolink_messages"`
+	RedisMetrolinkMessagesTimeToLive                   time.Duration `envvar:"REDIS_METROLINK_MESSAGES_TIME_TO_LIVE" default:"1m"`
+	RedisMetrolinkTlarefsKeyPrefix                     string        `envvar:"REDIS_METROLINK_TLAREFS_KEY_PREFIX" default:"metrolink_tlarefs"`
+	RedisMetrolinkTlarefsTimeToLive                    time.Duration `envvar:"REDIS_METROLINK_TLAREFS_TIME_TO_LIVE" default:"24h"`
+	RedisMetrolinkDeparturesServiceStatusServerAddress string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS" default:""`
 	RedisMetrolinkDeparturesServiceStatusKey           string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_KEY" default:"metrolink_departures_service_status"`
 	TfgmMetrolinksApiKey                               string        `envvar:"TFGM_METROLINKS_API_KEY"`
 	TfgmMetrolinksApiUrl                               string        `envvar:"TFGM_METROLINKS_API_URL"`
@@ -43,6 +61,14 @@ func main() {
 		panic(errors.Wrap(err, "error creating new Logger"))
 	}
 
+	if err := loader2.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", cfg.RedisMetrolinkDeparturesTimeToLive, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, cfg.MetrolinkDeparturesLoaderInterval); err != nil {
+		panic(err)
+	}
+
+	if err := loader2.ValidateGenerationTimeToLive("REDIS_METROLINK_MESSAGES_TIME_TO_LIVE", cfg.RedisMetrolinkMessagesTimeToLive, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, cfg.MetrolinkDeparturesLoaderInterval); err != nil {
+		panic(err)
+	}
+
 	httpClient := &http.Client{
 		Timeout: cfg.HttpClientTimeout,
 	}
@@ -59,20 +85,51 @@ func main() {
 		},
 	}
 
+	var replayDataSource *developer.TfgmDeveloperMetrolinkReplayDataSource
+
+	switch cfg.MetrolinkDeparturesSource {
+	case metrolinkDeparturesSourceTfgm:
+	case metrolinkDeparturesSourceReplay:
+		// The replay is created once so that its position is kept between invocations
+		replayDataSource = developer.NewTfgmDeveloperMetrolinkReplayDataSource(baseLogger, developer.NewTfgmDeveloperMetrolinkDataSource(baseLogger, httpClient, cfg.TfgmMetrolinksApiUrl, cfg.TfgmMetrolinksApiKey), cfg.MetrolinkDeparturesReplayDirectory, cfg.MetrolinkDeparturesReplaySpeed, cfg.MetrolinkDeparturesReplayRebase, time.Now)
+	default:
+		panic(errors.Errorf("unknown Metrolink departures source %s", cfg.MetrolinkDeparturesSource))
+	}
+
+	if cfg.RedisMetrolinkDeparturesServerAddress == "" || cfg.RedisMetrolinkDeparturesServiceStatusServerAddress == "" {
+		panic(errors.New("every Redis server address is required"))
+	}
+
 	lambda.Start(func(ctx context.Context) error {
 		lc, _ := lambdacontext.FromContext(ctx)
 
 		childLogger := baseLogger.With(zap.String("awsRequestId", lc.AwsRequestID))
 
-		metrolinkDataSource := developer.NewTfgmDeveloperMetrolinkDataSource(childLogger, httpClient, cfg.TfgmMetrolinksApiUrl, cfg.TfgmMetrolinksApiKey)
+		var metrolinkDataSource repository.MetrolinkDeparturesFetcher
+
+		if replayDataSource != nil {
+			metrolinkDataSource = replayDataSource
+		} else {
+			tfgmDataSource := developer.NewTfgmDeveloperMetrolinkDataSource(childLogger, httpClient, cfg.TfgmMetrolinksApiUrl, cfg.TfgmMetrolinksApiKey)
+
+			metrolinkDataSource = tfgmDataSource
+
+			if cfg.MetrolinkDeparturesRecordingDirectory != "" {
+				metrolinkDataSource = developer.NewTfgmDeveloperMetrolinkRecorder(childLogger, tfgmDataSource, cfg.MetrolinkDeparturesRecordingDirectory, time.Now)
+			}
+		}
 
 		platformNamer := filesystem.NewPlatformNamer(childLogger)
 
-		redisMetrolinkDeparturesStorer := v1.NewMetrolinkDeparturesRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkDeparturesKeyPrefix, cfg.RedisMetrolinkDeparturesTimeToLive)
+		metrolinkDeparturesStorer := v1.NewMetrolinkDeparturesRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkDeparturesKeyPrefix, cfg.RedisMetrolinkDeparturesTimeToLive)
+
+		metrolinkMessagesStorer := v13.NewMetrolinkMessagesRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkMessagesKeyPrefix, cfg.RedisMetrolinkMessagesTimeToLive)
+
+		metrolinkTlarefsStorer := v1.NewMetrolinkTlarefsRepository(childLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkTlarefsKeyPrefix, cfg.RedisMetrolinkTlarefsTimeToLive)
 
-		redisMetrolinkDeparturesSystemStatusStorer := v12.NewMetrolinkDeparturesSystemStatusRepository(childLogger, metrolinkDeparturesSystemStatusPool, cfg.RedisMetrolinkDeparturesServiceStatusKey)
+		metrolinkDeparturesSystemStatusStorer := v12.NewMetrolinkDeparturesSystemStatusRepository(childLogger, metrolinkDeparturesSystemStatusPool, cfg.RedisMetrolinkDeparturesServiceStatusKey)
 
-		metrolinkDeparturesLoader := loader2.NewMetrolinkDeparturesLoader(childLogger, metrolinkDataSource, platformNamer, redisMetrolinkDeparturesStorer, redisMetrolinkDeparturesSystemStatusStorer, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold)
+		metrolinkDeparturesLoader := loader2.NewMetrolinkDeparturesLoader(childLogger, metrolinkDataSource, platformNamer, metrolinkDeparturesStorer, metrolinkMessagesStorer, metrolinkTlarefsStorer, metrolinkDeparturesSystemStatusStorer, metrolinkDeparturesSystemStatusStorer, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold)
 
 		metrolinkDeparturesDataLoader := sqs.NewMetrolinkDeparturesDataLoader(childLogger, metrolinkDeparturesLoader)
 
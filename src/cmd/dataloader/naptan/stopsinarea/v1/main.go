@@ -4,30 +4,58 @@ import (
 	"context"
 	"github.com/Marchie/tf-experiment/lambda/internal/core/naptan/loader"
 	"github.com/Marchie/tf-experiment/lambda/internal/logger"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository"
 	naptan2 "github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/dft/naptan"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/filesystem"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
+	sqs2 "github.com/Marchie/tf-experiment/lambda/internal/repository/sqs"
 	"github.com/Marchie/tf-experiment/lambda/internal/transport/cloudwatch"
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
 	"github.com/plaid/go-envvar/envvar"
 	"go.uber.org/zap"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const (
+	naptanFormatCsv = "csv"
+	naptanFormatXml = "xml"
+)
+
 type Config struct {
-	HttpClientTimeout                        time.Duration `envvar:"HTTP_CLIENT_TIMEOUT" default:"15s"`
-	LogLevel                                 int8          `envvar:"LOG_LEVEL" default:"0"`
-	NaptanCsvUrl                             string        `envvar:"NAPTAN_CSV_URL"`
-	NaptanStopsInAreaFilename                string        `envvar:"NAPTAN_STOPS_IN_AREA_FILENAME" default:"StopsInArea.csv"`
-	NaptanStopsInAreaStopAreaCodeColumnIndex int           `envvar:"NAPTAN_STOPS_IN_AREA_STOP_AREA_CODE_COLUMN_INDEX" default:"0"`
-	NaptanStopsInAreaAtcoCodeColumnIndex     int           `envvar:"NAPTAN_STOPS_IN_AREA_ATCO_CODE_COLUMN_INDEX" default:"1"`
-	RedisServerAddress                       string        `envvar:"REDIS_SERVER_ADDRESS"`
-	RedisStopsInAreaKeyPrefix                string        `envvar:"REDIS_STOPS_IN_AREA_KEY_PREFIX" default:"stops_in_area"`
-	RedisStopsInAreaTimeToLive               time.Duration `envvar:"REDIS_STOPS_IN_AREA_TIME_TO_LIVE" default:"25h"`
+	HttpClientTimeout               time.Duration `envvar:"HTTP_CLIENT_TIMEOUT" default:"15s"`
+	LogLevel                        int8          `envvar:"LOG_LEVEL" default:"0"`
+	NaptanAtcoCodeColumn            string        `envvar:"NAPTAN_ATCO_CODE_COLUMN" default:"AtcoCode"`
+	NaptanChangeReportQueueUrl      string        `envvar:"NAPTAN_CHANGE_REPORT_QUEUE_URL" default:""`
+	NaptanCsvLayout                 string        `envvar:"NAPTAN_CSV_LAYOUT" default:"legacy"`
+	NaptanCsvUrl                    string        `envvar:"NAPTAN_CSV_URL"`
+	NaptanFormat                    string        `envvar:"NAPTAN_FORMAT" default:"csv"`
+	NaptanMaximumShrinkPercentage   float64       `envvar:"NAPTAN_MAXIMUM_SHRINK_PERCENTAGE" default:"10"`
+	NaptanModificationColumn        string        `envvar:"NAPTAN_MODIFICATION_COLUMN" default:"Modification"`
+	NaptanRevisionNumberColumn      string        `envvar:"NAPTAN_REVISION_NUMBER_COLUMN" default:"RevisionNumber"`
+	NaptanStatusColumn              string        `envvar:"NAPTAN_STATUS_COLUMN" default:"Status"`
+	NaptanStopAreaCodeColumn        string        `envvar:"NAPTAN_STOP_AREA_CODE_COLUMN" default:"StopAreaCode"`
+	NaptanStopAreaCodePrefixes      string        `envvar:"NAPTAN_STOP_AREA_CODE_PREFIXES" default:"940GZZMA"`
+	NaptanStopAreasFilename         string        `envvar:"NAPTAN_STOP_AREAS_FILENAME" default:"StopAreas.csv"`
+	NaptanStopsFilename             string        `envvar:"NAPTAN_STOPS_FILENAME" default:"Stops.csv"`
+	NaptanStopsCsvUrl               string        `envvar:"NAPTAN_STOPS_CSV_URL" default:""`
+	NaptanStopsInAreaFilename       string        `envvar:"NAPTAN_STOPS_IN_AREA_FILENAME" default:"StopsInArea.csv"`
+	NaptanXmlFilename               string        `envvar:"NAPTAN_XML_FILENAME" default:"NaPTAN.xml"`
+	NaptanXmlUrl                    string        `envvar:"NAPTAN_XML_URL" default:""`
+	RedisAtcoCodeStopAreasKeyPrefix string        `envvar:"REDIS_ATCO_CODE_STOP_AREAS_KEY_PREFIX" default:"atco_code_stop_areas"`
+	RedisNaptanStopAreasKey         string        `envvar:"REDIS_NAPTAN_STOP_AREAS_KEY" default:"naptan_stop_areas"`
+	RedisNaptanStopsKey             string        `envvar:"REDIS_NAPTAN_STOPS_KEY" default:"naptan_stops"`
+	RedisServerAddress              string        `envvar:"REDIS_SERVER_ADDRESS" default:""`
+	RedisStopDirectoryKey           string        `envvar:"REDIS_STOP_DIRECTORY_KEY" default:"metrolink_stop_directory"`
+	RedisStopsInAreaKeyPrefix       string        `envvar:"REDIS_STOPS_IN_AREA_KEY_PREFIX" default:"stops_in_area"`
+	RedisStopsInAreaTimeToLive      time.Duration `envvar:"REDIS_STOPS_IN_AREA_TIME_TO_LIVE" default:"25h"`
 }
 
 func main() {
@@ -51,6 +79,39 @@ func main() {
 		},
 	}
 
+	if cfg.RedisServerAddress == "" {
+		panic(errors.New("a Redis server address is required"))
+	}
+
+	if cfg.NaptanCsvLayout != naptan2.CSVLayoutLegacy && cfg.NaptanCsvLayout != naptan2.CSVLayoutExport {
+		panic(errors.Errorf("unknown NaPTAN CSV layout %s", cfg.NaptanCsvLayout))
+	}
+
+	if cfg.NaptanCsvLayout == naptan2.CSVLayoutExport && cfg.NaptanStopsCsvUrl == "" {
+		panic(errors.New("a NaPTAN stops CSV URL is required for the export NaPTAN CSV layout, which has no stops or stop areas files"))
+	}
+
+	switch cfg.NaptanFormat {
+	case naptanFormatCsv:
+	case naptanFormatXml:
+		if cfg.NaptanXmlUrl == "" {
+			panic(errors.New("a NaPTAN XML URL is required for the xml NaPTAN format"))
+		}
+	default:
+		panic(errors.Errorf("unknown NaPTAN format %s", cfg.NaptanFormat))
+	}
+
+	var changeReportPublisher repository.StopsInAreaChangeReportPublisher
+
+	if cfg.NaptanChangeReportQueueUrl != "" {
+		sess, err := session.NewSession()
+		if err != nil {
+			panic(errors.Wrap(err, "error creating AWS Session"))
+		}
+
+		changeReportPublisher = sqs2.NewStopsInAreaChangeReportPublisher(baseLogger, sqs.New(sess), cfg.NaptanChangeReportQueueUrl)
+	}
+
 	lambda.Start(func(ctx context.Context) error {
 		lc, _ := lambdacontext.FromContext(ctx)
 
@@ -58,16 +119,59 @@ func main() {
 
 		httpZipFileFetcher := naptan2.NewRepository(childLogger, httpClient, cfg.NaptanCsvUrl)
 
-		zipFileExtractor := compression.NewZipFileExtractor(childLogger)
+		extractor := compression.NewDetectingExtractor(childLogger)
 
-		httpStopsInAreaFetcher := naptan2.NewCSV(childLogger, httpZipFileFetcher, zipFileExtractor, cfg.NaptanStopsInAreaFilename, cfg.NaptanStopsInAreaStopAreaCodeColumnIndex, cfg.NaptanStopsInAreaAtcoCodeColumnIndex)
+		var httpStopsInAreaFetcher repository.StopsInAreaFetcher
 
-		redisStopsInAreaStorer := naptan.NewNaptanRedis(childLogger, pool, cfg.RedisStopsInAreaKeyPrefix, cfg.RedisStopsInAreaTimeToLive)
+		if cfg.NaptanFormat == naptanFormatXml {
+			httpXmlFileFetcher := naptan2.NewRepository(childLogger, httpClient, cfg.NaptanXmlUrl)
 
-		stopsInAreaLoader := loader.NewStopsInAreaLoader(childLogger, httpStopsInAreaFetcher, redisStopsInAreaStorer)
+			httpStopsInAreaFetcher = naptan2.NewXML(childLogger, httpXmlFileFetcher, extractor, cfg.NaptanXmlFilename, splitList(cfg.NaptanStopAreaCodePrefixes))
+		} else {
+			httpStopsInAreaFetcher = naptan2.NewCSV(childLogger, httpZipFileFetcher, extractor, cfg.NaptanCsvLayout, cfg.NaptanStopsInAreaFilename, cfg.NaptanStopsFilename, naptanCSVColumns(cfg), splitList(cfg.NaptanStopAreaCodePrefixes))
+		}
+
+		// The export layout has no stops or stop areas files, so they are read from a separate download of the archive
+		naptanStopsCsvUrl := cfg.NaptanCsvUrl
+		if cfg.NaptanStopsCsvUrl != "" {
+			naptanStopsCsvUrl = cfg.NaptanStopsCsvUrl
+		}
+
+		httpNaptanStopsFetcher := naptan2.NewStopsCSV(childLogger, naptan2.NewRepository(childLogger, httpClient, naptanStopsCsvUrl), extractor, cfg.NaptanStopsFilename, cfg.NaptanStopAreasFilename)
+
+		stopsInAreaStorer := naptan.NewNaptanRedis(childLogger, pool, cfg.RedisStopsInAreaKeyPrefix, cfg.RedisAtcoCodeStopAreasKeyPrefix, cfg.RedisStopsInAreaTimeToLive)
+		stopDirectoryStorer := naptan.NewMetrolinkStopDirectoryRedis(childLogger, pool, cfg.RedisStopDirectoryKey, cfg.RedisStopsInAreaTimeToLive)
+		naptanStopsStorer := naptan.NewNaptanStopsRedis(childLogger, pool, cfg.RedisNaptanStopsKey, cfg.RedisNaptanStopAreasKey, cfg.RedisStopsInAreaTimeToLive)
+
+		platformNamer := filesystem.NewPlatformNamer(childLogger)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(childLogger, httpStopsInAreaFetcher, stopsInAreaStorer, stopsInAreaStorer, platformNamer, stopDirectoryStorer, httpNaptanStopsFetcher, naptanStopsStorer, naptanStopsStorer, changeReportPublisher, cfg.NaptanMaximumShrinkPercentage)
 
 		naptanDataLoader := cloudwatch.NewNaptanDataLoader(childLogger, stopsInAreaLoader)
 
 		return naptanDataLoader.Handler(ctx)
 	})
 }
+
+// splitList splits a comma-separated config value into its non-empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func naptanCSVColumns(cfg Config) naptan2.CSVColumns {
+	return naptan2.CSVColumns{
+		StopAreaCode:   cfg.NaptanStopAreaCodeColumn,
+		AtcoCode:       cfg.NaptanAtcoCodeColumn,
+		RevisionNumber: cfg.NaptanRevisionNumberColumn,
+		Modification:   cfg.NaptanModificationColumn,
+		Status:         cfg.NaptanStatusColumn,
+	}
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/departures/metrolink/api"
+	loader2 "github.com/Marchie/tf-experiment/lambda/internal/core/departures/metrolink/loader"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/naptan/loader"
+	api2 "github.com/Marchie/tf-experiment/lambda/internal/core/stops/api"
+	"github.com/Marchie/tf-experiment/lambda/internal/logger"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository"
+	naptan2 "github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/dft/naptan"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/tfgm/developer"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/filesystem"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/departures/metrolink/v1"
+	v13 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/messages/metrolink/v1"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
+	v12 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/system/status/v1"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/httpserver"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/ticker"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/plaid/go-envvar/envvar"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	metrolinkDeparturesSourceReplay = "replay"
+	metrolinkDeparturesSourceTfgm   = "tfgm"
+
+	storageBackendMemory = "memory"
+	storageBackendRedis  = "redis"
+)
+
+type metrolinkDeparturesRepository interface {
+	repository.MetrolinkDeparturesMultiGetter
+	repository.MetrolinkDeparturesStorer
+}
+
+type metrolinkMessagesRepository interface {
+	repository.MetrolinkMessagesGetter
+	repository.MetrolinkLineMessagesGetter
+	repository.MetrolinkMessagesStorer
+}
+
+type metrolinkTlarefsRepository interface {
+	repository.MetrolinkTlarefGetter
+	repository.MetrolinkTlarefsStorer
+}
+
+type systemStatusRepository interface {
+	repository.SystemStatusGetter
+	repository.SystemStatusSetter
+}
+
+type stopsInAreaRepository interface {
+	repository.StopsInAreaGetter
+	repository.StopsInAreaLister
+	repository.StopsInAreaStorer
+	repository.AtcoCodeLister
+}
+
+type stopDirectoryRepository interface {
+	repository.MetrolinkStopDirectoryGetter
+	repository.MetrolinkStopDirectoryStorer
+}
+
+type naptanStopsRepository interface {
+	repository.NaptanStopsGetter
+	repository.NaptanStopsStorer
+	repository.NaptanStopAreasGetter
+	repository.NaptanStopAreasStorer
+	repository.NaptanStopAreasNearbyGetter
+}
+
+type Config struct {
+	BatchDeparturesApiGatewayResource                  string        `envvar:"BATCH_DEPARTURES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v2/batch"`
+	DeparturesApiGatewayResource                       string        `envvar:"DEPARTURES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}"`
+	DeparturesV2ApiGatewayResource                     string        `envvar:"DEPARTURES_V2_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v2/{stopAreaCodeOrAtcoCode}"`
+	HttpClientTimeout                                  time.Duration `envvar:"HTTP_CLIENT_TIMEOUT" default:"1500ms"`
+	HttpServerAddress                                  string        `envvar:"HTTP_SERVER_ADDRESS" default:":8080"`
+	HttpServerShutdownTimeout                          time.Duration `envvar:"HTTP_SERVER_SHUTDOWN_TIMEOUT" default:"10s"`
+	LineApiGatewayPathParameter                        string        `envvar:"LINE_API_GATEWAY_PATH_PARAMETER" default:"line"`
+	LineMessagesApiGatewayResource                     string        `envvar:"LINE_MESSAGES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/lines/{line}/messages"`
+	LocationsApiGatewayQueryParameter                  string        `envvar:"LOCATIONS_API_GATEWAY_QUERY_PARAMETER" default:"locations"`
+	LogLevel                                           int8          `envvar:"LOG_LEVEL" default:"0"`
+	MessagesApiGatewayResource                         string        `envvar:"MESSAGES_API_GATEWAY_RESOURCE" default:"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}/messages"`
+	MetrolinkDeparturesAgeAdjustedWaits                bool          `envvar:"METROLINK_DEPARTURES_AGE_ADJUSTED_WAITS" default:"false"`
+	MetrolinkDeparturesDegradedDataThreshold           time.Duration `envvar:"METROLINK_DEPARTURES_DEGRADED_DATA_THRESHOLD" default:"0s"`
+	MetrolinkDeparturesLoaderEnabled                   bool          `envvar:"METROLINK_DEPARTURES_LOADER_ENABLED" default:"false"`
+	MetrolinkDeparturesLoaderInterval                  time.Duration `envvar:"METROLINK_DEPARTURES_LOADER_INTERVAL" default:"10s"`
+	MetrolinkDeparturesRecordingDirectory              string        `envvar:"METROLINK_DEPARTURES_RECORDING_DIRECTORY" default:""`
+	MetrolinkDeparturesReplayDirectory                 string        `envvar:"METROLINK_DEPARTURES_REPLAY_DIRECTORY" default:""`
+	MetrolinkDeparturesReplayRebase                    bool          `envvar:"METROLINK_DEPARTURES_REPLAY_REBASE" default:"true"`
+	MetrolinkDeparturesReplaySpeed                     float64       `envvar:"METROLINK_DEPARTURES_REPLAY_SPEED" default:"0"`
+	MetrolinkDeparturesSource                          string        `envvar:"METROLINK_DEPARTURES_SOURCE" default:"tfgm"`
+	MetrolinkDeparturesStaleDataThreshold              time.Duration `envvar:"METROLINK_DEPARTURES_STALE_DATA_THRESHOLD" default:"30s"`
+	NaptanAtcoCodeColumn                               string        `envvar:"NAPTAN_ATCO_CODE_COLUMN" default:"AtcoCode"`
+	NaptanCsvLayout                                    string        `envvar:"NAPTAN_CSV_LAYOUT" default:"legacy"`
+	NaptanCsvUrl                                       string        `envvar:"NAPTAN_CSV_URL" default:""`
+	NaptanHttpClientTimeout                            time.Duration `envvar:"NAPTAN_HTTP_CLIENT_TIMEOUT" default:"15s"`
+	NaptanLoaderEnabled                                bool          `envvar:"NAPTAN_LOADER_ENABLED" default:"false"`
+	NaptanLoaderInterval                               time.Duration `envvar:"NAPTAN_LOADER_INTERVAL" default:"24h"`
+	NaptanMaximumShrinkPercentage                      float64       `envvar:"NAPTAN_MAXIMUM_SHRINK_PERCENTAGE" default:"10"`
+	NaptanModificationColumn                           string        `envvar:"NAPTAN_MODIFICATION_COLUMN" default:"Modification"`
+	NaptanRevisionNumberColumn                         string        `envvar:"NAPTAN_REVISION_NUMBER_COLUMN" default:"RevisionNumber"`
+	NaptanStatusColumn                                 string        `envvar:"NAPTAN_STATUS_COLUMN" default:"Status"`
+	NaptanStopAreaCodeColumn                           string        `envvar:"NAPTAN_STOP_AREA_CODE_COLUMN" default:"StopAreaCode"`
+	NaptanStopAreaCodePrefixes                         string        `envvar:"NAPTAN_STOP_AREA_CODE_PREFIXES" default:"940GZZMA"`
+	NaptanStopAreasFilename                            string        `envvar:"NAPTAN_STOP_AREAS_FILENAME" default:"StopAreas.csv"`
+	NaptanStopsFilename                                string        `envvar:"NAPTAN_STOPS_FILENAME" default:"Stops.csv"`
+	NaptanStopsCsvUrl                                  string        `envvar:"NAPTAN_STOPS_CSV_URL" default:""`
+	NaptanStopsInAreaFilename                          string        `envvar:"NAPTAN_STOPS_IN_AREA_FILENAME" default:"StopsInArea.csv"`
+	NearbyStopsApiGatewayResource                      string        `envvar:"NEARBY_STOPS_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1/nearby"`
+	RedisAtcoCodeStopAreasKeyPrefix                    string        `envvar:"REDIS_ATCO_CODE_STOP_AREAS_KEY_PREFIX" default:"atco_code_stop_areas"`
+	RedisMetrolinkDeparturesServerAddress              string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS" default:""`
+	RedisMetrolinkDeparturesKeyPrefix                  string        `envvar:"REDIS_METROLINK_DEPARTURES_KEY_PREFIX" default:"metrolink_departures"`
+	RedisMetrolinkDeparturesTimeToLive                 time.Duration `envvar:"REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE" default:"1m"`
+	RedisMetrolinkMessagesKeyPrefix                    string        `envvar:"REDIS_METROLINK_MESSAGES_KEY_PREFIX" default:"metrolink_messages"`
+	RedisMetrolinkMessagesTimeToLive                   time.Duration `envvar:"REDIS_METROLINK_MESSAGES_TIME_TO_LIVE" default:"1m"`
+	RedisMetrolinkTlarefsKeyPrefix                     string        `envvar:"REDIS_METROLINK_TLAREFS_KEY_PREFIX" default:"metrolink_tlarefs"`
+	RedisMetrolinkTlarefsTimeToLive                    time.Duration `envvar:"REDIS_METROLINK_TLAREFS_TIME_TO_LIVE" default:"24h"`
+	RedisMetrolinkDeparturesServiceStatusServerAddress string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS" default:""`
+	RedisMetrolinkDeparturesServiceStatusKey           string        `envvar:"REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_KEY" default:"metrolink_departures_service_status"`
+	RedisNaptanStopAreasKey                            string        `envvar:"REDIS_NAPTAN_STOP_AREAS_KEY" default:"naptan_stop_areas"`
+	RedisNaptanStopsKey                                string        `envvar:"REDIS_NAPTAN_STOPS_KEY" default:"naptan_stops"`
+	RedisStopDirectoryKey                              string        `envvar:"REDIS_STOP_DIRECTORY_KEY" default:"metrolink_stop_directory"`
+	RedisStopsInAreaServerAddress                      string        `envvar:"REDIS_STOPS_IN_AREA_SERVER_ADDRESS" default:""`
+	RedisStopsInAreaKeyPrefix                          string        `envvar:"REDIS_STOPS_IN_AREA_KEY_PREFIX" default:"stops_in_area"`
+	RedisStopsInAreaTimeToLive                         time.Duration `envvar:"REDIS_STOPS_IN_AREA_TIME_TO_LIVE" default:"25h"`
+	StatusApiGatewayResource                           string        `envvar:"STATUS_API_GATEWAY_RESOURCE" default:"/status"`
+	StorageBackend                                     string        `envvar:"STORAGE_BACKEND" default:"redis"`
+	StopAreaCodeOrAtcoCodeApiGatewayPathParameter      string        `envvar:"STOP_AREA_CODE_OR_ATCO_CODE_API_GATEWAY_PATH_PARAMETER" default:"stopAreaCodeOrAtcoCode"`
+	StopSearchApiGatewayResource                       string        `envvar:"STOP_SEARCH_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1/search"`
+	StopsApiGatewayResource                            string        `envvar:"STOPS_API_GATEWAY_RESOURCE" default:"/stops/metrolink/v1"`
+	TfgmMetrolinksApiKey                               string        `envvar:"TFGM_METROLINKS_API_KEY" default:""`
+	TfgmMetrolinksApiUrl                               string        `envvar:"TFGM_METROLINKS_API_URL" default:""`
+	TimeLocation                                       string        `envvar:"TIME_LOCATION" default:"Europe/London"`
+}
+
+func main() {
+	var cfg Config
+	if err := envvar.Parse(&cfg); err != nil {
+		panic(errors.Wrap(err, "error parsing config"))
+	}
+
+	baseLogger, err := logger.NewLogger(cfg.LogLevel)
+	if err != nil {
+		panic(errors.Wrap(err, "error creating new Logger"))
+	}
+
+	metrolinkDeparturesPool := &redis.Pool{
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", cfg.RedisMetrolinkDeparturesServerAddress)
+		},
+	}
+
+	metrolinkDeparturesSystemStatusPool := &redis.Pool{
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", cfg.RedisMetrolinkDeparturesServiceStatusServerAddress)
+		},
+	}
+
+	stopsInAreaPool := &redis.Pool{
+		DialContext: func(ctx context.Context) (redis.Conn, error) {
+			return redis.DialContext(ctx, "tcp", cfg.RedisStopsInAreaServerAddress)
+		},
+	}
+
+	timeLocation, err := time.LoadLocation(cfg.TimeLocation)
+	if err != nil {
+		panic(errors.Wrap(err, "error loading time location"))
+	}
+
+	var stopsInAreaRepository stopsInAreaRepository
+	var stopDirectoryRepository stopDirectoryRepository
+	var naptanStopsRepository naptanStopsRepository
+	var metrolinkDeparturesRepository metrolinkDeparturesRepository
+	var metrolinkMessagesRepository metrolinkMessagesRepository
+	var metrolinkTlarefsRepository metrolinkTlarefsRepository
+	var systemStatusRepository systemStatusRepository
+
+	switch cfg.StorageBackend {
+	case storageBackendRedis:
+		if cfg.RedisMetrolinkDeparturesServerAddress == "" || cfg.RedisMetrolinkDeparturesServiceStatusServerAddress == "" || cfg.RedisStopsInAreaServerAddress == "" {
+			panic(errors.New("every Redis server address is required for the redis storage backend"))
+		}
+
+		stopsInAreaRepository = naptan.NewNaptanRedis(baseLogger, stopsInAreaPool, cfg.RedisStopsInAreaKeyPrefix, cfg.RedisAtcoCodeStopAreasKeyPrefix, cfg.RedisStopsInAreaTimeToLive)
+
+		stopDirectoryRepository = naptan.NewMetrolinkStopDirectoryRedis(baseLogger, stopsInAreaPool, cfg.RedisStopDirectoryKey, cfg.RedisStopsInAreaTimeToLive)
+
+		naptanStopsRepository = naptan.NewNaptanStopsRedis(baseLogger, stopsInAreaPool, cfg.RedisNaptanStopsKey, cfg.RedisNaptanStopAreasKey, cfg.RedisStopsInAreaTimeToLive)
+
+		metrolinkDeparturesRepository = v1.NewMetrolinkDeparturesRepository(baseLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkDeparturesKeyPrefix, cfg.RedisMetrolinkDeparturesTimeToLive)
+
+		metrolinkMessagesRepository = v13.NewMetrolinkMessagesRepository(baseLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkMessagesKeyPrefix, cfg.RedisMetrolinkMessagesTimeToLive)
+
+		metrolinkTlarefsRepository = v1.NewMetrolinkTlarefsRepository(baseLogger, metrolinkDeparturesPool, cfg.RedisMetrolinkTlarefsKeyPrefix, cfg.RedisMetrolinkTlarefsTimeToLive)
+
+		systemStatusRepository = v12.NewMetrolinkDeparturesSystemStatusRepository(baseLogger, metrolinkDeparturesSystemStatusPool, cfg.RedisMetrolinkDeparturesServiceStatusKey)
+	case storageBackendMemory:
+		memoryStore := memory.NewStore(baseLogger, time.Now)
+
+		stopsInAreaRepository = memory.NewNaptanMemory(baseLogger, memoryStore, cfg.RedisStopsInAreaKeyPrefix, cfg.RedisAtcoCodeStopAreasKeyPrefix, cfg.RedisStopsInAreaTimeToLive)
+
+		stopDirectoryRepository = memory.NewMetrolinkStopDirectoryMemory(baseLogger, memoryStore, cfg.RedisStopDirectoryKey, cfg.RedisStopsInAreaTimeToLive)
+
+		naptanStopsRepository = memory.NewNaptanStopsMemory(baseLogger, memoryStore, cfg.RedisNaptanStopsKey, cfg.RedisNaptanStopAreasKey, cfg.RedisStopsInAreaTimeToLive)
+
+		metrolinkDeparturesRepository = memory.NewMetrolinkDeparturesRepository(baseLogger, memoryStore, cfg.RedisMetrolinkDeparturesKeyPrefix, cfg.RedisMetrolinkDeparturesTimeToLive)
+
+		metrolinkMessagesRepository = memory.NewMetrolinkMessagesRepository(baseLogger, memoryStore, cfg.RedisMetrolinkMessagesKeyPrefix, cfg.RedisMetrolinkMessagesTimeToLive)
+
+		metrolinkTlarefsRepository = memory.NewMetrolinkTlarefsRepository(baseLogger, memoryStore, cfg.RedisMetrolinkTlarefsKeyPrefix, cfg.RedisMetrolinkTlarefsTimeToLive)
+
+		systemStatusRepository = memory.NewMetrolinkDeparturesSystemStatusRepository(baseLogger, memoryStore, cfg.RedisMetrolinkDeparturesServiceStatusKey)
+	default:
+		panic(errors.Errorf("unknown storage backend %s", cfg.StorageBackend))
+	}
+
+	metrolinkDeparturesApi := api.NewApi(baseLogger, stopsInAreaRepository, stopsInAreaRepository, metrolinkTlarefsRepository, metrolinkDeparturesRepository, metrolinkMessagesRepository, metrolinkMessagesRepository, systemStatusRepository, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, timeLocation, cfg.MetrolinkDeparturesAgeAdjustedWaits)
+
+	stopsApi := api2.NewApi(baseLogger, stopDirectoryRepository, naptanStopsRepository, naptanStopsRepository, naptanStopsRepository, metrolinkDeparturesRepository, systemStatusRepository, metrolinkDeparturesApi)
+
+	metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(baseLogger, metrolinkDeparturesApi, metrolinkDeparturesApi, cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter, cfg.LineApiGatewayPathParameter)
+
+	router := httpserver.NewRouter(baseLogger, map[string]apigw.AwsApiGatewayHandler{
+		cfg.DeparturesApiGatewayResource:      apigw.NewMetrolinkDeparturesAwsApiGateway(baseLogger, metrolinkDeparturesApi, cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter),
+		cfg.DeparturesV2ApiGatewayResource:    apigw.NewMetrolinkDeparturesAwsApiGateway(baseLogger, core.StopAreaDeparturesJsonerFunc(metrolinkDeparturesApi.JsonV2), cfg.StopAreaCodeOrAtcoCodeApiGatewayPathParameter),
+		cfg.BatchDeparturesApiGatewayResource: apigw.NewMetrolinkDeparturesBatchAwsApiGateway(baseLogger, metrolinkDeparturesApi, cfg.LocationsApiGatewayQueryParameter),
+		cfg.MessagesApiGatewayResource:        metrolinkMessagesAwsApiGateway,
+		cfg.LineMessagesApiGatewayResource:    metrolinkMessagesAwsApiGateway,
+		cfg.StatusApiGatewayResource:          apigw.NewMetrolinkSystemStatusAwsApiGateway(baseLogger, metrolinkDeparturesApi),
+		cfg.StopsApiGatewayResource:           apigw.NewMetrolinkStopsAwsApiGateway(baseLogger, stopsApi),
+		cfg.NearbyStopsApiGatewayResource:     apigw.NewMetrolinkNearbyStopsAwsApiGateway(baseLogger, stopsApi),
+		cfg.StopSearchApiGatewayResource:      apigw.NewMetrolinkStopSearchAwsApiGateway(baseLogger, stopsApi),
+	})
+
+	server := &http.Server{
+		Addr:    cfg.HttpServerAddress,
+		Handler: httpserver.NewRequestLogger(baseLogger, router, time.Now),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	if cfg.MetrolinkDeparturesLoaderEnabled {
+		if err := loader2.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", cfg.RedisMetrolinkDeparturesTimeToLive, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, cfg.MetrolinkDeparturesLoaderInterval); err != nil {
+			panic(err)
+		}
+
+		if err := loader2.ValidateGenerationTimeToLive("REDIS_METROLINK_MESSAGES_TIME_TO_LIVE", cfg.RedisMetrolinkMessagesTimeToLive, cfg.MetrolinkDeparturesStaleDataThreshold, cfg.MetrolinkDeparturesDegradedDataThreshold, cfg.MetrolinkDeparturesLoaderInterval); err != nil {
+			panic(err)
+		}
+
+		httpClient := &http.Client{
+			Timeout: cfg.HttpClientTimeout,
+		}
+
+		tfgmDataSource := developer.NewTfgmDeveloperMetrolinkDataSource(baseLogger, httpClient, cfg.TfgmMetrolinksApiUrl, cfg.TfgmMetrolinksApiKey)
+
+		var metrolinkDataSource repository.MetrolinkDeparturesFetcher
+
+		switch cfg.MetrolinkDeparturesSource {
+		case metrolinkDeparturesSourceTfgm:
+			metrolinkDataSource = tfgmDataSource
+
+			if cfg.MetrolinkDeparturesRecordingDirectory != "" {
+				metrolinkDataSource = developer.NewTfgmDeveloperMetrolinkRecorder(baseLogger, tfgmDataSource, cfg.MetrolinkDeparturesRecordingDirectory, time.Now)
+			}
+		case metrolinkDeparturesSourceReplay:
+			metrolinkDataSource = developer.NewTfgmDeveloperMetrolinkReplayDataSource(baseLogger, tfgmDataSource, cfg.MetrolinkDeparturesReplayDirectory, cfg.MetrolinkDeparturesReplaySpeed, cfg.MetrolinkDeparturesReplayRebase, time.Now)
+		default:
+			panic(errors.Errorf("unknown Metrolink departures source %s", cfg.MetrolinkDeparturesSource))
+		}
+
+		platformNamer := filesystem.NewPlatformNamer(baseLogger)
+
+		metrolinkDeparturesLoader := loader2.NewMetrolinkDeparturesLoader(baseLogger, metrolinkDataSource, platformNamer, metrolinkDeparturesRepository, metrolinkMessagesRepository, metrolinkTlarefsRepository, systemStatusRepository, systemStatusRepository, time.Now, cfg.MetrolinkDeparturesStaleDataThreshold)
+
+		metrolinkDeparturesDataLoader := ticker.NewMetrolinkDeparturesDataLoader(baseLogger, metrolinkDeparturesLoader, cfg.MetrolinkDeparturesLoaderInterval)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metrolinkDeparturesDataLoader.Run(ctx)
+		}()
+	}
+
+	if cfg.NaptanLoaderEnabled {
+		if cfg.NaptanCsvLayout != naptan2.CSVLayoutLegacy && cfg.NaptanCsvLayout != naptan2.CSVLayoutExport {
+			panic(errors.Errorf("unknown NaPTAN CSV layout %s", cfg.NaptanCsvLayout))
+		}
+
+		if cfg.NaptanCsvLayout == naptan2.CSVLayoutExport && cfg.NaptanStopsCsvUrl == "" {
+			panic(errors.New("a NaPTAN stops CSV URL is required for the export NaPTAN CSV layout, which has no stops or stop areas files"))
+		}
+
+		naptanHttpClient := &http.Client{
+			Timeout: cfg.NaptanHttpClientTimeout,
+		}
+
+		httpZipFileFetcher := naptan2.NewRepository(baseLogger, naptanHttpClient, cfg.NaptanCsvUrl)
+
+		extractor := compression.NewDetectingExtractor(baseLogger)
+
+		httpStopsInAreaFetcher := naptan2.NewCSV(baseLogger, httpZipFileFetcher, extractor, cfg.NaptanCsvLayout, cfg.NaptanStopsInAreaFilename, cfg.NaptanStopsFilename, naptanCSVColumns(cfg), splitList(cfg.NaptanStopAreaCodePrefixes))
+
+		// The export layout has no stops or stop areas files, so they are read from a separate download of the archive
+		naptanStopsCsvUrl := cfg.NaptanCsvUrl
+		if cfg.NaptanStopsCsvUrl != "" {
+			naptanStopsCsvUrl = cfg.NaptanStopsCsvUrl
+		}
+
+		httpNaptanStopsFetcher := naptan2.NewStopsCSV(baseLogger, naptan2.NewRepository(baseLogger, naptanHttpClient, naptanStopsCsvUrl), extractor, cfg.NaptanStopsFilename, cfg.NaptanStopAreasFilename)
+
+		platformNamer := filesystem.NewPlatformNamer(baseLogger)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(baseLogger, httpStopsInAreaFetcher, stopsInAreaRepository, stopsInAreaRepository, platformNamer, stopDirectoryRepository, httpNaptanStopsFetcher, naptanStopsRepository, naptanStopsRepository, nil, cfg.NaptanMaximumShrinkPercentage)
+
+		naptanDataLoader := ticker.NewNaptanDataLoader(baseLogger, stopsInAreaLoader, cfg.NaptanLoaderInterval)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			naptanDataLoader.Run(ctx)
+		}()
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		baseLogger.Info("starting HTTP server", zap.String("address", cfg.HttpServerAddress), zap.String("storageBackend", cfg.StorageBackend), zap.Bool("metrolinkDeparturesLoaderEnabled", cfg.MetrolinkDeparturesLoaderEnabled), zap.Bool("naptanLoaderEnabled", cfg.NaptanLoaderEnabled))
+		serverErr <- server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		baseLogger.Error("HTTP server stopped", zap.Error(err))
+	case sig := <-signals:
+		baseLogger.Info("shutting down HTTP server", zap.String("signal", sig.String()))
+
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.HttpServerShutdownTimeout)
+		defer shutdownCancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			baseLogger.Error("error shutting down HTTP server", zap.Error(err))
+		}
+	}
+
+	cancel()
+	wg.Wait()
+
+	_ = baseLogger.Sync()
+}
+
+// splitList splits a comma-separated config value into its non-empty items
+func splitList(value string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func naptanCSVColumns(cfg Config) naptan2.CSVColumns {
+	return naptan2.CSVColumns{
+		StopAreaCode:   cfg.NaptanStopAreaCodeColumn,
+		AtcoCode:       cfg.NaptanAtcoCodeColumn,
+		RevisionNumber: cfg.NaptanRevisionNumberColumn,
+		Modification:   cfg.NaptanModificationColumn,
+		Status:         cfg.NaptanStatusColumn,
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"strconv"
+	"time"
+)
+
+// waitAdjustments maps each departure whose wait has been adjusted for the age of the data to the stored departure it
+// was copied from
+type waitAdjustments map[*domain.MetrolinkDeparture]*domain.MetrolinkDeparture
+
+// stored returns the stored departure which departure was copied from, and whether its wait has been adjusted
+func (a waitAdjustments) stored(departure *domain.MetrolinkDeparture) (*domain.MetrolinkDeparture, bool) {
+	if stored, ok := a[departure]; ok {
+		return stored, true
+	}
+
+	return departure, false
+}
+
+// adjustWaitsForAge takes the whole minutes which have passed since each departure was last updated off its wait, so
+// that waits keep counting down between updates from the passenger information display rather than jumping back up
+// when a client refreshes. The display shows waits in whole minutes, so a tram is only known to have left once a minute
+// more than its wait has passed; such departures are removed. Delayed departures are returned unchanged. Waits are
+// adjusted on copies of the departures, which are returned with the stored departures they were copied from.
+func (m *Api) adjustWaitsForAge(departures []*domain.MetrolinkDeparture) ([]*domain.MetrolinkDeparture, waitAdjustments) {
+	now := m.currentTimeFunc()
+
+	adjustedDepartures := make([]*domain.MetrolinkDeparture, 0, len(departures))
+	adjustments := make(waitAdjustments)
+
+	for _, departure := range departures {
+		if departure.Delayed {
+			adjustedDepartures = append(adjustedDepartures, departure)
+			continue
+		}
+
+		elapsedMinutes := int(now.Sub(departure.LastUpdated) / time.Minute)
+
+		if elapsedMinutes > departure.WaitMinutes {
+			continue
+		}
+
+		if elapsedMinutes > 0 {
+			adjusted := *departure
+			adjusted.WaitMinutes -= elapsedMinutes
+			adjusted.Wait = strconv.Itoa(adjusted.WaitMinutes)
+
+			adjustments[&adjusted] = departure
+			departure = &adjusted
+		}
+
+		adjustedDepartures = append(adjustedDepartures, departure)
+	}
+
+	return adjustedDepartures, adjustments
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApi_JsonV2_AgeAdjustedWaits(t *testing.T) {
+	t.Run(`Given age adjusted waits are enabled
+And departures were last updated more than a minute ago
+When JsonV2 is called
+Then the whole minutes since the departures were last updated are taken off their waits
+And the adjusted waits are marked as adjusted without changing the expected departure times
+And departures which must already have left are not returned
+And delayed and recently updated departures are returned unchanged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		// 2 minutes and 20 seconds before the current time
+		lastUpdated := time.Date(2021, time.April, 6, 21, 35, 10, 0, time.UTC)
+
+		metrolinkDeparturesForAtcoCode := []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 0, Destination: "Bury", Carriages: "Single", Status: "Due", Wait: "1", WaitMinutes: 1, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 1, Destination: "Victoria", Carriages: "Double", Status: "Due", Wait: "2", WaitMinutes: 2, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 2, Destination: "Bury", Carriages: "Double", Status: "Due", Wait: "5", WaitMinutes: 5, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 3, Destination: "Victoria", Carriages: "Single", Status: "Due", Wait: "DELAY", Delayed: true, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID06", StationLocation: "St Peter's Square", Order: 0, Destination: "Rochdale", Carriages: "Single", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC)},
+		}
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(metrolinkDeparturesForAtcoCode, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), true)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, validMetrolinkAtcoCode, map[string]string{"maxWait": "3"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"stationLocation": "St Peter's Square",
+	"tlaref": "SPS",
+	"departures": [
+		{
+			"atcoCode": "9400ZZMASTP1",
+			"sequence": 0,
+			"line": "Bury",
+			"direction": "Incoming",
+			"destination": "Victoria",
+			"status": "Due",
+			"wait": "0",
+			"waitAdjusted": true,
+			"expectedDepartureTime": "2021-04-06T22:37:10+01:00",
+			"carriages": "Double",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"pidref": "SPS-PID05",
+			"lastUpdated": "2021-04-06T22:35:10+01:00"
+		},
+		{
+			"atcoCode": "9400ZZMASTP1",
+			"sequence": 1,
+			"line": "Bury",
+			"direction": "Incoming",
+			"destination": "Bury",
+			"status": "Due",
+			"wait": "3",
+			"waitAdjusted": true,
+			"expectedDepartureTime": "2021-04-06T22:40:10+01:00",
+			"carriages": "Double",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"pidref": "SPS-PID05",
+			"lastUpdated": "2021-04-06T22:35:10+01:00"
+		}
+	],
+	"messages": [],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+}
+
+func TestApi_adjustWaitsForAge(t *testing.T) {
+	t.Run(`Given departures last updated less than a minute ago
+When adjustWaitsForAge is called
+Then the departures are returned unchanged`, func(t *testing.T) {
+		// Given
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, nil, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), true)
+
+		departures := []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Status: "Departing", Wait: "0", LastUpdated: time.Date(2021, time.April, 6, 21, 36, 31, 0, time.UTC)},
+			{AtcoCode: "9400ZZMASTP1", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: time.Date(2021, time.April, 6, 21, 36, 31, 0, time.UTC)},
+		}
+
+		// When
+		result, adjustments := api.adjustWaitsForAge(departures)
+
+		// Then
+		assert.Equal(t, []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Status: "Departing", Wait: "0", LastUpdated: time.Date(2021, time.April, 6, 21, 36, 31, 0, time.UTC)},
+			{AtcoCode: "9400ZZMASTP1", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: time.Date(2021, time.April, 6, 21, 36, 31, 0, time.UTC)},
+		}, result)
+		assert.Empty(t, adjustments)
+	})
+
+	t.Run(`Given a departure last updated more than a minute ago
+When adjustWaitsForAge is called
+Then a copy of the departure with the elapsed minutes taken off its wait is returned
+And the copy is mapped to the stored departure
+And the stored departure is unchanged`, func(t *testing.T) {
+		// Given
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, nil, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), true)
+
+		departure := &domain.MetrolinkDeparture{AtcoCode: "9400ZZMASTP1", Status: "Due", Wait: "5", WaitMinutes: 5, LastUpdated: time.Date(2021, time.April, 6, 21, 35, 10, 0, time.UTC)}
+
+		// When
+		result, adjustments := api.adjustWaitsForAge([]*domain.MetrolinkDeparture{departure})
+
+		// Then
+		assert.Equal(t, []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Status: "Due", Wait: "3", WaitMinutes: 3, LastUpdated: time.Date(2021, time.April, 6, 21, 35, 10, 0, time.UTC)},
+		}, result)
+		assert.Equal(t, &domain.MetrolinkDeparture{AtcoCode: "9400ZZMASTP1", Status: "Due", Wait: "5", WaitMinutes: 5, LastUpdated: time.Date(2021, time.April, 6, 21, 35, 10, 0, time.UTC)}, departure)
+
+		stored, adjusted := adjustments.stored(result[0])
+		assert.True(t, adjusted)
+		assert.Same(t, departure, stored)
+	})
+
+	t.Run(`Given a departing tram last updated a minute ago
+When adjustWaitsForAge is called
+Then the departure is removed`, func(t *testing.T) {
+		// Given
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, nil, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), true)
+
+		departures := []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Status: "Departing", Wait: "0", LastUpdated: time.Date(2021, time.April, 6, 21, 36, 30, 0, time.UTC)},
+		}
+
+		// When
+		result, adjustments := api.adjustWaitsForAge(departures)
+
+		// Then
+		assert.Equal(t, []*domain.MetrolinkDeparture{}, result)
+		assert.Empty(t, adjustments)
+	})
+}
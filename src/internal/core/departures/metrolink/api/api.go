@@ -9,7 +9,6 @@ import (
 	"github.com/Marchie/tf-experiment/lambda/internal/repository"
 	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
 	"github.com/gomodule/redigo/redis"
-	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"io"
@@ -18,46 +17,99 @@ import (
 	"regexp"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 )
 
 type Api struct {
-	logger                    *zap.Logger
-	stopsInAreaGetter         repository.StopsInAreaGetter
-	metrolinkDeparturesGetter repository.MetrolinkDeparturesGetter
-	systemStatusGetter        repository.SystemStatusGetter
-	currentTimeFunc           func() time.Time
-	staleDataThreshold        time.Duration
-	timeLocation              *time.Location
+	logger                      *zap.Logger
+	stopsInAreaGetter           repository.StopsInAreaGetter
+	atcoCodeLister              repository.AtcoCodeLister
+	tlarefGetter                repository.MetrolinkTlarefGetter
+	metrolinkDeparturesGetter   repository.MetrolinkDeparturesMultiGetter
+	metrolinkMessagesGetter     repository.MetrolinkMessagesGetter
+	metrolinkLineMessagesGetter repository.MetrolinkLineMessagesGetter
+	systemStatusGetter          repository.SystemStatusGetter
+	currentTimeFunc             func() time.Time
+	staleDataThreshold          time.Duration
+	degradedDataThreshold       time.Duration
+	timeLocation                *time.Location
+	ageAdjustedWaits            bool
 }
 
-func NewApi(logger *zap.Logger, stopsInAreaGetter repository.StopsInAreaGetter, metrolinkDeparturesGetter repository.MetrolinkDeparturesGetter, systemStatusGetter repository.SystemStatusGetter, currentTimeFunc func() time.Time, staleDataThreshold time.Duration, timeLocation *time.Location) *Api {
+func NewApi(logger *zap.Logger, stopsInAreaGetter repository.StopsInAreaGetter, atcoCodeLister repository.AtcoCodeLister, tlarefGetter repository.MetrolinkTlarefGetter, metrolinkDeparturesGetter repository.MetrolinkDeparturesMultiGetter, metrolinkMessagesGetter repository.MetrolinkMessagesGetter, metrolinkLineMessagesGetter repository.MetrolinkLineMessagesGetter, systemStatusGetter repository.SystemStatusGetter, currentTimeFunc func() time.Time, staleDataThreshold time.Duration, degradedDataThreshold time.Duration, timeLocation *time.Location, ageAdjustedWaits bool) *Api {
 	return &Api{
-		logger:                    logger,
-		stopsInAreaGetter:         stopsInAreaGetter,
-		metrolinkDeparturesGetter: metrolinkDeparturesGetter,
-		systemStatusGetter:        systemStatusGetter,
-		currentTimeFunc:           currentTimeFunc,
-		staleDataThreshold:        staleDataThreshold,
-		timeLocation:              timeLocation,
+		logger:                      logger,
+		stopsInAreaGetter:           stopsInAreaGetter,
+		atcoCodeLister:              atcoCodeLister,
+		tlarefGetter:                tlarefGetter,
+		metrolinkDeparturesGetter:   metrolinkDeparturesGetter,
+		metrolinkMessagesGetter:     metrolinkMessagesGetter,
+		metrolinkLineMessagesGetter: metrolinkLineMessagesGetter,
+		systemStatusGetter:          systemStatusGetter,
+		currentTimeFunc:             currentTimeFunc,
+		staleDataThreshold:          staleDataThreshold,
+		degradedDataThreshold:       degradedDataThreshold,
+		timeLocation:                timeLocation,
+		ageAdjustedWaits:            ageAdjustedWaits,
 	}
 }
 
-func (m *Api) Json(ctx context.Context, stopAreaCodeOrAtcoCode string) (io.ReadCloser, int, error) {
+// publicApiConverter converts departures and messages into a version of the public API response. stopArea is nil unless
+// a platform was requested: see getPlatformStopArea. adjustments holds the departures whose waits have been adjusted for
+// the age of the data: see adjustWaitsForAge. staleDataAge is nil unless the departures are stale: see checkDataAge.
+type publicApiConverter func(stopAreaCodeOrAtcoCode string, stopArea *platformStopArea, departures []*domain.MetrolinkDeparture, adjustments waitAdjustments, messages []*domain.MetrolinkMessage, lastUpdated time.Time, staleDataAge *time.Duration) interface{}
+
+// staleJsonResponse is a JSON response containing stale departures, which implements core.StaleDataJson
+type staleJsonResponse struct {
+	io.ReadCloser
+	dataAge time.Duration
+}
+
+func (r *staleJsonResponse) DataAge() time.Duration {
+	return r.dataAge
+}
+
+// Json returns departures for a StopAreaCode, AtcoCode or TLAREF. A TLAREF is resolved to the StopAreaCode of its stop,
+// which is returned as the requested location. When an AtcoCode is requested, the StopAreaCode of its stop area and the
+// AtcoCodes of the other platforms in the stop area are returned with the departures: see getPlatformStopArea.
+// Departures can be filtered with query parameters: see parseDeparturesFilter. Departures older than the stale data
+// threshold are served with a stale flag until they are older than the degraded data threshold: see checkDataAge. When
+// ageAdjustedWaits is true, waits are adjusted for the age of the data: see adjustWaitsForAge.
+func (m *Api) Json(ctx context.Context, stopAreaCodeOrAtcoCode string, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	return m.json(ctx, stopAreaCodeOrAtcoCode, queryParameters, func(stopAreaCodeOrAtcoCode string, stopArea *platformStopArea, departures []*domain.MetrolinkDeparture, adjustments waitAdjustments, messages []*domain.MetrolinkMessage, lastUpdated time.Time, staleDataAge *time.Duration) interface{} {
+		return m.convertToPublicApi(stopAreaCodeOrAtcoCode, stopArea, departures, adjustments, messages, lastUpdated, staleDataAge)
+	})
+}
+
+func (m *Api) json(ctx context.Context, stopAreaCodeOrAtcoCode string, queryParameters map[string]string, convert publicApiConverter) (io.ReadCloser, int, error) {
 	stopAreaCodeOrAtcoCode = strings.ToUpper(stopAreaCodeOrAtcoCode)
 
+	stopAreaCodeOrAtcoCode, known, err := m.resolveTlaref(ctx, stopAreaCodeOrAtcoCode)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if !known {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, "unknown TLAREF")
+	}
+
 	if !m.validateStopAreaCodeOrAtcoCode(stopAreaCodeOrAtcoCode) {
 		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, "invalid StopAreaCode or AtcoCode")
 	}
 
-	lastUpdated, err := m.systemStatusGetter.Get(ctx)
+	filter, err := m.parseDeparturesFilter(queryParameters)
+	if err != nil {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, err.Error())
+	}
+
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
 	if err != nil {
 		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting Metrolink departures system status")
 	}
 
-	if m.currentTimeFunc().Sub(*lastUpdated) > m.staleDataThreshold {
-		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadGateway, fmt.Sprintf("Metrolink departures data is outdated: last updated at %s", lastUpdated.Format(time.RFC3339)))
+	staleDataAge, outdated := m.checkDataAge(systemStatus.LastUpdated)
+	if outdated {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadGateway, fmt.Sprintf("Metrolink departures data is outdated: last updated at %s", systemStatus.LastUpdated.Format(time.RFC3339)))
 	}
 
 	atcoCodes, err := m.atcoCodesToQuery(ctx, stopAreaCodeOrAtcoCode)
@@ -69,125 +121,189 @@ func (m *Api) Json(ctx context.Context, stopAreaCodeOrAtcoCode string) (io.ReadC
 		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting ATCO codes for '%s'", stopAreaCodeOrAtcoCode)
 	}
 
-	departures, err := m.getDeparturesForAtcoCodes(ctx, atcoCodes)
+	departures, adjustments, err := m.getDeparturesForAtcoCodes(ctx, systemStatus.Generation, atcoCodes)
 	if err != nil {
 		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error fetching Metrolink departures for '%s'", stopAreaCodeOrAtcoCode)
 	}
 
+	messages, err := m.metrolinkMessagesGetter.Get(ctx, systemStatus.Generation, atcoCodes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error fetching Metrolink messages for '%s'", stopAreaCodeOrAtcoCode)
+	}
+
 	sort.Sort(ByWaitStatusDestinationOrderPlatformCarriages(departures))
 
-	return m.encodeJsonSuccessResponse(stopAreaCodeOrAtcoCode, departures, *lastUpdated)
-}
+	departures = filter.apply(departures)
 
-func (m *Api) validateStopAreaCodeOrAtcoCode(stopAreaCodeOrAtcoCode string) bool {
-	match, _ := regexp.MatchString("^940[0G]ZZMA[A-Z]{3}[1-4]?$", stopAreaCodeOrAtcoCode)
-	return match
+	return m.encodeJsonDataResponse(convert(stopAreaCodeOrAtcoCode, m.getPlatformStopArea(ctx, stopAreaCodeOrAtcoCode), departures, adjustments, messages, systemStatus.LastUpdated, staleDataAge), staleDataAge)
 }
 
-func (m *Api) atcoCodesToQuery(ctx context.Context, stopAreaCodeOrAtcoCode string) ([]string, error) {
-	if strings.HasPrefix(stopAreaCodeOrAtcoCode, "9400") {
-		return []string{stopAreaCodeOrAtcoCode}, nil
+// checkDataAge returns the age of data last updated at lastUpdated when it is stale, i.e. older than the stale data
+// threshold, or nil when it is not. outdated is true when the data is too old to be served at all, which is once it is
+// stale unless it is within the longer degraded data threshold. Departures, messages and the system status all use it.
+func (m *Api) checkDataAge(lastUpdated time.Time) (staleDataAge *time.Duration, outdated bool) {
+	dataAge := m.currentTimeFunc().Sub(lastUpdated)
+
+	if dataAge <= m.staleDataThreshold {
+		return nil, false
 	}
 
-	return m.stopsInAreaGetter.GetStopsInArea(ctx, stopAreaCodeOrAtcoCode)
-}
+	if dataAge > m.degradedDataThreshold {
+		return nil, true
+	}
 
-func (m *Api) getDeparturesForAtcoCodes(ctx context.Context, atcoCodes []string) ([]*domain.MetrolinkDeparture, error) {
-	wg := sync.WaitGroup{}
-	chMetrolinkDepartures := make(chan []*domain.MetrolinkDeparture)
-	chErr := make(chan error)
+	return &dataAge, false
+}
 
-	for _, atcoCode := range atcoCodes {
-		wg.Add(1)
-		go m.getDeparturesForAtcoCode(&wg, ctx, atcoCode, chMetrolinkDepartures, chErr)
+// resolveTlaref returns the StopAreaCode of the stop of a TLAREF, which is returned as the requested location, or
+// stopAreaCodeOrAtcoCode unchanged when it is not a TLAREF. known is false when the TLAREF has no stop.
+func (m *Api) resolveTlaref(ctx context.Context, stopAreaCodeOrAtcoCode string) (stopAreaCode string, known bool, err error) {
+	if !domain.IsTlaref(stopAreaCodeOrAtcoCode) {
+		return stopAreaCodeOrAtcoCode, true, nil
 	}
 
-	chCombinedMetrolinkDepartures := m.combineMetrolinkDepartures(chMetrolinkDepartures)
-	chCombinedErr := m.combineErrors(chErr)
-
-	go func() {
-		defer close(chErr)
-		defer close(chMetrolinkDepartures)
+	stopAreaCode, err = m.tlarefGetter.GetStopAreaCodeForTlaref(ctx, stopAreaCodeOrAtcoCode)
+	if err != nil {
+		if errors.Cause(err) == redis.ErrNil {
+			return stopAreaCodeOrAtcoCode, false, nil
+		}
 
-		wg.Wait()
-	}()
+		return "", false, errors.Wrapf(err, "error resolving TLAREF '%s'", stopAreaCodeOrAtcoCode)
+	}
 
-	return <-chCombinedMetrolinkDepartures, <-chCombinedErr
+	return stopAreaCode, true, nil
 }
 
-func (m *Api) getDeparturesForAtcoCode(wg *sync.WaitGroup, ctx context.Context, atcoCode string, chMetrolinkDepartures chan []*domain.MetrolinkDeparture, chErr chan error) {
-	defer wg.Done()
+// stopAreaCodeOrAtcoCodeRegexp matches the StopAreaCode of a Metrolink stop, e.g. 940GZZMASTP, or the AtcoCode of one of
+// its platforms, e.g. 9400ZZMASTP1
+var stopAreaCodeOrAtcoCodeRegexp = regexp.MustCompile("^940[0G]ZZMA[A-Z]{3}[1-4]?$")
 
-	metrolinkDepartures, err := m.metrolinkDeparturesGetter.Get(ctx, atcoCode)
-	if err != nil {
-		if err == redis.ErrNil {
-			return
-		}
+func (m *Api) validateStopAreaCodeOrAtcoCode(stopAreaCodeOrAtcoCode string) bool {
+	return stopAreaCodeOrAtcoCodeRegexp.MatchString(stopAreaCodeOrAtcoCode)
+}
 
-		chErr <- errors.Wrapf(err, "error getting departures for AtcoCode '%s'", atcoCode)
-		return
+func (m *Api) atcoCodesToQuery(ctx context.Context, stopAreaCodeOrAtcoCode string) ([]string, error) {
+	if strings.HasPrefix(stopAreaCodeOrAtcoCode, "9400") {
+		return []string{stopAreaCodeOrAtcoCode}, nil
 	}
 
-	chMetrolinkDepartures <- metrolinkDepartures
+	return m.stopsInAreaGetter.GetStopsInArea(ctx, stopAreaCodeOrAtcoCode)
 }
 
-func (m *Api) combineMetrolinkDepartures(chMetrolinkDepartures <-chan []*domain.MetrolinkDeparture) chan []*domain.MetrolinkDeparture {
-	chCombinedMetrolinkDepartures := make(chan []*domain.MetrolinkDeparture, 1)
-
-	go func() {
-		defer close(chCombinedMetrolinkDepartures)
+// platformStopArea is the stop area of a requested platform, with the AtcoCodes of the other platforms in it
+type platformStopArea struct {
+	stopAreaCode           string
+	otherPlatformAtcoCodes []string
+}
 
-		var combinedMetrolinkDepartures []*domain.MetrolinkDeparture
+// getPlatformStopArea returns the stop area of the platform with an AtcoCode, or nil when a stop area was requested or
+// the stop area of the platform is unknown. The stop area only adds to the response, so an error getting it is logged
+// rather than returned.
+func (m *Api) getPlatformStopArea(ctx context.Context, stopAreaCodeOrAtcoCode string) *platformStopArea {
+	if !strings.HasPrefix(stopAreaCodeOrAtcoCode, "9400") {
+		return nil
+	}
 
-		for metrolinkDepartures := range chMetrolinkDepartures {
-			combinedMetrolinkDepartures = append(combinedMetrolinkDepartures, metrolinkDepartures...)
+	stopAreaCode, atcoCodes, err := m.atcoCodeLister.GetStopAreaForAtcoCode(ctx, stopAreaCodeOrAtcoCode)
+	if err != nil {
+		if errors.Cause(err) != redis.ErrNil {
+			m.logger.Error("error getting stop area for AtcoCode", zap.Error(err), zap.String("atcoCode", stopAreaCodeOrAtcoCode))
 		}
 
-		chCombinedMetrolinkDepartures <- combinedMetrolinkDepartures
-	}()
+		return nil
+	}
 
-	return chCombinedMetrolinkDepartures
-}
+	stopArea := &platformStopArea{
+		stopAreaCode:           stopAreaCode,
+		otherPlatformAtcoCodes: make([]string, 0, len(atcoCodes)),
+	}
 
-func (m *Api) combineErrors(chErr <-chan error) chan error {
-	chCombinedErr := make(chan error, 1)
+	for _, atcoCode := range atcoCodes {
+		if atcoCode != stopAreaCodeOrAtcoCode {
+			stopArea.otherPlatformAtcoCodes = append(stopArea.otherPlatformAtcoCodes, atcoCode)
+		}
+	}
 
-	go func() {
-		defer close(chCombinedErr)
+	sort.Strings(stopArea.otherPlatformAtcoCodes)
 
-		var combinedErr error
+	return stopArea
+}
 
-		for err := range chErr {
-			combinedErr = multierror.Append(combinedErr, err)
-		}
+// getDeparturesForAtcoCodes fetches departures from the generation given by the system status, so that every AtcoCode
+// is read from the same snapshot. When ageAdjustedWaits is true, the waits are adjusted: see adjustWaitsForAge.
+func (m *Api) getDeparturesForAtcoCodes(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkDeparture, waitAdjustments, error) {
+	departures, err := m.metrolinkDeparturesGetter.GetMulti(ctx, generation, atcoCodes)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "error getting departures for AtcoCodes")
+	}
 
-		chCombinedErr <- combinedErr
-	}()
+	if !m.ageAdjustedWaits {
+		return departures, nil, nil
+	}
+
+	departures, adjustments := m.adjustWaitsForAge(departures)
 
-	return chCombinedErr
+	return departures, adjustments, nil
 }
 
-func (m *Api) convertToPublicApi(stopAreaCodeOrAtcoCode string, departures []*domain.MetrolinkDeparture, lastUpdated time.Time) *tfgm.MetrolinkDepartures {
+func (m *Api) convertToPublicApi(stopAreaCodeOrAtcoCode string, stopArea *platformStopArea, departures []*domain.MetrolinkDeparture, adjustments waitAdjustments, messages []*domain.MetrolinkMessage, lastUpdated time.Time, staleDataAge *time.Duration) *tfgm.MetrolinkDepartures {
 	convertedDepartures := make([]*tfgm.MetrolinkDeparture, 0)
 
 	for sequence, departure := range departures {
+		stored, waitAdjusted := adjustments.stored(departure)
+
 		convertedDepartures = append(convertedDepartures, &tfgm.MetrolinkDeparture{
-			AtcoCode:    departure.AtcoCode,
-			Sequence:    sequence,
-			Destination: departure.Destination,
-			Status:      departure.Status,
-			Wait:        departure.Wait,
-			Carriages:   departure.Carriages,
-			Platform:    departure.Platform,
-			LastUpdated: departure.LastUpdated.In(m.timeLocation),
+			AtcoCode:              departure.AtcoCode,
+			Sequence:              sequence,
+			Destination:           departure.Destination,
+			Status:                string(departure.Status),
+			Wait:                  departure.Wait,
+			WaitAdjusted:          waitAdjusted,
+			ExpectedDepartureTime: m.convertExpectedDepartureTimeToPublicApi(stored),
+			Carriages:             string(departure.Carriages),
+			Platform:              departure.Platform,
+			LastUpdated:           departure.LastUpdated.In(m.timeLocation),
 		})
 	}
 
-	return &tfgm.MetrolinkDepartures{
+	stale, dataAgeSeconds := convertStaleDataAgeToPublicApi(staleDataAge)
+
+	publicDepartures := &tfgm.MetrolinkDepartures{
 		RequestedLocation: stopAreaCodeOrAtcoCode,
 		Departures:        convertedDepartures,
+		Messages:          m.convertMessagesToPublicApi(messages),
 		LastUpdated:       lastUpdated.In(m.timeLocation),
+		Stale:             stale,
+		DataAgeSeconds:    dataAgeSeconds,
+	}
+
+	if stopArea != nil {
+		publicDepartures.StopAreaCode = stopArea.stopAreaCode
+		publicDepartures.OtherPlatforms = stopArea.otherPlatformAtcoCodes
+	}
+
+	return publicDepartures
+}
+
+// convertExpectedDepartureTimeToPublicApi returns the expected departure time of a departure in the time location of the
+// API, or nil when the tram is delayed
+func (m *Api) convertExpectedDepartureTimeToPublicApi(departure *domain.MetrolinkDeparture) *time.Time {
+	expected, ok := departure.ExpectedDepartureTime()
+	if !ok {
+		return nil
 	}
+
+	expected = expected.In(m.timeLocation)
+
+	return &expected
+}
+
+func convertStaleDataAgeToPublicApi(staleDataAge *time.Duration) (stale bool, dataAgeSeconds int) {
+	if staleDataAge == nil {
+		return false, 0
+	}
+
+	return true, int(staleDataAge.Seconds())
 }
 
 func (m *Api) encodeJsonResponse(v interface{}, statusCode int) (io.ReadCloser, int, error) {
@@ -202,8 +318,18 @@ func (m *Api) encodeJsonResponse(v interface{}, statusCode int) (io.ReadCloser,
 	return ioutil.NopCloser(buf), statusCode, nil
 }
 
-func (m *Api) encodeJsonSuccessResponse(stopAreaCodeOrAtcoCode string, departures []*domain.MetrolinkDeparture, lastUpdated time.Time) (io.ReadCloser, int, error) {
-	return m.encodeJsonResponse(m.convertToPublicApi(stopAreaCodeOrAtcoCode, departures, lastUpdated), http.StatusOK)
+// encodeJsonDataResponse encodes a successful departures or messages response, which is a core.StaleDataJson when
+// staleDataAge is not nil
+func (m *Api) encodeJsonDataResponse(v interface{}, staleDataAge *time.Duration) (io.ReadCloser, int, error) {
+	rc, statusCode, err := m.encodeJsonResponse(v, http.StatusOK)
+	if err != nil || staleDataAge == nil {
+		return rc, statusCode, err
+	}
+
+	return &staleJsonResponse{
+		ReadCloser: rc,
+		dataAge:    *staleDataAge,
+	}, statusCode, nil
 }
 
 func (m *Api) encodeJsonErrorResponse(stopAreaCodeOrAtcoCode string, statusCode int, errorMsg string) (io.ReadCloser, int, error) {
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
 	"github.com/golang/mock/gomock"
@@ -61,6 +62,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "4",
+			WaitMinutes: 4,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -71,6 +73,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "12",
+			WaitMinutes: 12,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -90,6 +93,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t *testing.T) []*domain.Met
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "1",
+			WaitMinutes: 1,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -100,6 +104,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "5",
+			WaitMinutes: 5,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -110,6 +115,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "9",
+			WaitMinutes: 9,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -139,6 +145,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP3(t *testing.T) []*domain.Met
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "12",
+			WaitMinutes: 12,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -149,6 +156,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP3(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "24",
+			WaitMinutes: 24,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -178,6 +186,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP4(t *testing.T) []*domain.Met
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "3",
+			WaitMinutes: 3,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -188,6 +197,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMASTP4(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "7",
+			WaitMinutes: 7,
 			Platform:    &platform,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -214,6 +224,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMAMKT1(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "4",
+			WaitMinutes: 4,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
 		{
@@ -223,6 +234,7 @@ func givenMetrolinkDeparturesForAtcoCode9400ZZMAMKT1(t *testing.T) []*domain.Met
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "12",
+			WaitMinutes: 12,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
 	}
@@ -274,6 +286,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "1",
+			WaitMinutes: 1,
 			Platform:    &platformC,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -284,6 +297,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "3",
+			WaitMinutes: 3,
 			Platform:    &platformA,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -294,6 +308,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "4",
+			WaitMinutes: 4,
 			Platform:    &platformD,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -304,6 +319,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "5",
+			WaitMinutes: 5,
 			Platform:    &platformC,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -314,6 +330,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "7",
+			WaitMinutes: 7,
 			Platform:    &platformA,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -324,6 +341,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "9",
+			WaitMinutes: 9,
 			Platform:    &platformC,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -334,6 +352,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "12",
+			WaitMinutes: 12,
 			Platform:    &platformB,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -344,6 +363,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "12",
+			WaitMinutes: 12,
 			Platform:    &platformD,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -354,6 +374,7 @@ func thenExpectDeparturesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkDepartu
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "24",
+			WaitMinutes: 24,
 			Platform:    &platformB,
 			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
 		},
@@ -376,6 +397,30 @@ func givenLastUpdatedTime(t *testing.T) *time.Time {
 	return &lastUpdatedTime
 }
 
+func givenGeneration(t *testing.T) string {
+	t.Helper()
+
+	return "1617745039000000000"
+}
+
+func givenSystemStatus(t *testing.T) *domain.SystemStatus {
+	t.Helper()
+
+	return &domain.SystemStatus{
+		Generation:  givenGeneration(t),
+		LastUpdated: *givenLastUpdatedTime(t),
+	}
+}
+
+func givenStaleSystemStatus(t *testing.T) *domain.SystemStatus {
+	t.Helper()
+
+	return &domain.SystemStatus{
+		Generation:  givenGeneration(t),
+		LastUpdated: *givenStaleLastUpdatedTime(t),
+	}
+}
+
 func givenStaleLastUpdatedTime(t *testing.T) *time.Time {
 	t.Helper()
 
@@ -398,12 +443,35 @@ func givenTimeLocation(t *testing.T) *time.Location {
 	return loc
 }
 
+// givenAtcoCodeListerWithoutStopAreas returns an AtcoCodeLister which does not know the stop area of any AtcoCode
+func givenAtcoCodeListerWithoutStopAreas(t *testing.T, ctrl *gomock.Controller) *mock_repository.MockAtcoCodeLister {
+	t.Helper()
+
+	atcoCodeLister := mock_repository.NewMockAtcoCodeLister(ctrl)
+	atcoCodeLister.EXPECT().GetStopAreaForAtcoCode(gomock.Any(), gomock.Any()).AnyTimes().Return("", nil, redis.ErrNil)
+
+	return atcoCodeLister
+}
+
 func thenExpectJsonError(t *testing.T, errorMsg string, requestedLocation string) string {
 	t.Helper()
 
 	return "{\n\t\"error\": \"" + errorMsg + "\",\n\t\"requestedLocation\": \"" + requestedLocation + "\"\n}\n"
 }
 
+// thenExpectJsonExpectedDepartureTime returns the expectedDepartureTime field of a departure, which is omitted when the
+// tram is delayed
+func thenExpectJsonExpectedDepartureTime(t *testing.T, departure *domain.MetrolinkDeparture) string {
+	t.Helper()
+
+	expected, ok := departure.ExpectedDepartureTime()
+	if !ok {
+		return ""
+	}
+
+	return "\n\t\t\t\"expectedDepartureTime\": \"" + expected.In(givenTimeLocation(t)).Format(time.RFC3339) + "\","
+}
+
 func thenExpectJsonDeparturesWithoutPlatform(t *testing.T, requestedLocation string, departures []*domain.MetrolinkDeparture, lastUpdated *time.Time) string {
 	t.Helper()
 
@@ -412,7 +480,7 @@ func thenExpectJsonDeparturesWithoutPlatform(t *testing.T, requestedLocation str
 		if sequence > 0 {
 			expString += ","
 		}
-		expString += fmt.Sprintf("\n\t\t{\n\t\t\t\"atcoCode\": \"%s\",\n\t\t\t\"sequence\": %d,\n\t\t\t\"destination\": \"%s\",\n\t\t\t\"status\": \"%s\",\n\t\t\t\"wait\": \"%s\",\n\t\t\t\"carriages\": \"%s\",\n\t\t\t\"lastUpdated\": \"%s\"\n\t\t}", departure.AtcoCode, sequence, departure.Destination, departure.Status, departure.Wait, departure.Carriages, departure.LastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339))
+		expString += fmt.Sprintf("\n\t\t{\n\t\t\t\"atcoCode\": \"%s\",\n\t\t\t\"sequence\": %d,\n\t\t\t\"destination\": \"%s\",\n\t\t\t\"status\": \"%s\",\n\t\t\t\"wait\": \"%s\",%s\n\t\t\t\"carriages\": \"%s\",\n\t\t\t\"lastUpdated\": \"%s\"\n\t\t}", departure.AtcoCode, sequence, departure.Destination, departure.Status, departure.Wait, thenExpectJsonExpectedDepartureTime(t, departure), departure.Carriages, departure.LastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339))
 	}
 	expString += "\n\t],\n\t\"lastUpdated\": \"" + lastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339) + "\"\n}\n"
 
@@ -427,7 +495,7 @@ func thenExpectJsonDeparturesWithPlatform(t *testing.T, requestedLocation string
 		if sequence > 0 {
 			expString += ","
 		}
-		expString += fmt.Sprintf("\n\t\t{\n\t\t\t\"atcoCode\": \"%s\",\n\t\t\t\"sequence\": %d,\n\t\t\t\"destination\": \"%s\",\n\t\t\t\"status\": \"%s\",\n\t\t\t\"wait\": \"%s\",\n\t\t\t\"carriages\": \"%s\",\n\t\t\t\"platform\": \"%s\",\n\t\t\t\"lastUpdated\": \"%s\"\n\t\t}", departure.AtcoCode, sequence, departure.Destination, departure.Status, departure.Wait, departure.Carriages, *departure.Platform, departure.LastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339))
+		expString += fmt.Sprintf("\n\t\t{\n\t\t\t\"atcoCode\": \"%s\",\n\t\t\t\"sequence\": %d,\n\t\t\t\"destination\": \"%s\",\n\t\t\t\"status\": \"%s\",\n\t\t\t\"wait\": \"%s\",%s\n\t\t\t\"carriages\": \"%s\",\n\t\t\t\"platform\": \"%s\",\n\t\t\t\"lastUpdated\": \"%s\"\n\t\t}", departure.AtcoCode, sequence, departure.Destination, departure.Status, departure.Wait, thenExpectJsonExpectedDepartureTime(t, departure), departure.Carriages, *departure.Platform, departure.LastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339))
 	}
 	expString += "\n\t],\n\t\"lastUpdated\": \"" + lastUpdated.In(givenTimeLocation(t)).Format(time.RFC3339) + "\"\n}\n"
 
@@ -454,16 +522,20 @@ Then an error JSON response is returned`, func(t *testing.T) {
 
 		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
 
 		invalidMetrolinkStopAreaCodeOrAtcoCode := "1800SB18811"
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, invalidMetrolinkStopAreaCodeOrAtcoCode)
+		rc, statusCode, err := api.Json(ctx, invalidMetrolinkStopAreaCodeOrAtcoCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -486,15 +558,19 @@ Then an error JSON response is returned`, func(t *testing.T) {
 
 		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
 		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(nil, redis.ErrNil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.Nil(t, rc)
@@ -518,16 +594,19 @@ Then an error is returned`, func(t *testing.T) {
 
 		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
-		staleLastUpdatedTime := givenStaleLastUpdatedTime(t)
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(staleLastUpdatedTime, nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.NotNil(t, rc)
@@ -536,6 +615,113 @@ Then an error is returned`, func(t *testing.T) {
 		assert.Equal(t, thenExpectJsonError(t, "Metrolink departures data is outdated: last updated at 2021-04-06T21:36:44Z", validMetrolinkStopAreaCode), readJson(t, rc))
 	})
 
+	t.Run(`Given the system status shows that the last updated time breaches the stale data threshold
+And the last updated time is within the degraded data threshold
+When Json is called
+Then the last known departures are returned with a stale flag and the age of the data
+And the response reports the age of the data for a Warning header`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return([]*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Destination: "Bury", Carriages: "Double", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: *givenStaleLastUpdatedTime(t)},
+		}, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
+
+		degradedDataThreshold := time.Minute * 5
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), degradedDataThreshold, givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		staleData, ok := rc.(core.StaleDataJson)
+		assert.True(t, ok)
+		assert.Equal(t, time.Second*46, staleData.DataAge())
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"departures": [
+		{
+			"atcoCode": "9400ZZMASTP1",
+			"sequence": 0,
+			"destination": "Bury",
+			"status": "Due",
+			"wait": "4",
+			"expectedDepartureTime": "2021-04-06T22:40:44+01:00",
+			"carriages": "Double",
+			"lastUpdated": "2021-04-06T22:36:44+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:36:44+01:00",
+	"stale": true,
+	"dataAgeSeconds": 46
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+
+	t.Run(`Given the system status shows that the last updated time breaches the stale data threshold
+And the last updated time breaches the degraded data threshold
+When Json is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkStopAreaCode := "940GZZMASTP"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
+
+		staleDataThreshold := time.Second * 30
+		degradedDataThreshold := time.Second * 45
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), staleDataThreshold, degradedDataThreshold, givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadGateway, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "Metrolink departures data is outdated: last updated at 2021-04-06T21:36:44Z", validMetrolinkStopAreaCode), readJson(t, rc))
+	})
+
 	t.Run(`Given an potentially valid StopAreaCode is requested
 And the StopAreaCode contains no AtcoCodes
 When Json is called
@@ -554,15 +740,19 @@ Then an error JSON response is returned`, func(t *testing.T) {
 		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
 		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, invalidMetrolinkStopAreaCode).Return(nil, wrappedRedisErrNil)
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenLastUpdatedTime(t), nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, invalidMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, invalidMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -586,18 +776,23 @@ Then departures are returned for that AtcoCode`, func(t *testing.T) {
 		validMetrolinkAtcoCode := "9400ZZMASTP1"
 
 		metrolinkDeparturesForAtcoCode := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t)
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, validMetrolinkAtcoCode).Return(metrolinkDeparturesForAtcoCode, nil)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(metrolinkDeparturesForAtcoCode, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		lastUpdatedTime := givenLastUpdatedTime(t)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(lastUpdatedTime, nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -623,18 +818,23 @@ And there is no platform value for the departures`, func(t *testing.T) {
 		validMetrolinkAtcoCode := "9400ZZMASTP1"
 
 		metrolinkDeparturesForAtcoCode := givenMetrolinkDeparturesForAtcoCode9400ZZMAMKT1(t)
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, validMetrolinkAtcoCode).Return(metrolinkDeparturesForAtcoCode, nil)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(metrolinkDeparturesForAtcoCode, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		lastUpdatedTime := givenLastUpdatedTime(t)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(lastUpdatedTime, nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -662,22 +862,28 @@ Then sorted departures are returned for each AtcoCode in that StopAreaCode`, fun
 		metrolinkDeparturesForAtcoCode9400ZZMASTP2 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t)
 		metrolinkDeparturesForAtcoCode9400ZZMASTP3 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP3(t)
 		metrolinkDeparturesForAtcoCode9400ZZMASTP4 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP4(t)
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP").Return(nil, redis.ErrNil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP1").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP1, nil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP2").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP2, nil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP3").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP3, nil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP4").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP4, nil)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		var metrolinkDeparturesForAtcoCodes []*domain.MetrolinkDeparture
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, metrolinkDeparturesForAtcoCode9400ZZMASTP1...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, metrolinkDeparturesForAtcoCode9400ZZMASTP2...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, metrolinkDeparturesForAtcoCode9400ZZMASTP3...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, metrolinkDeparturesForAtcoCode9400ZZMASTP4...)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}).Return(metrolinkDeparturesForAtcoCodes, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		lastUpdatedTime := givenLastUpdatedTime(t)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(lastUpdatedTime, nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -685,6 +891,99 @@ Then sorted departures are returned for each AtcoCode in that StopAreaCode`, fun
 		assert.Equal(t, thenExpectJsonDeparturesWithPlatform(t, validMetrolinkStopAreaCode, thenExpectDeparturesFor940GZZMASTP(t), lastUpdatedTime), readJson(t, rc))
 	})
 
+	t.Run(`Given a TLAREF is requested
+When Json is called
+Then sorted departures are returned for each AtcoCode in the StopAreaCode of the TLAREF
+And the StopAreaCode is returned as the requested location`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopAreaCode := "940GZZMASTP"
+		atcoCodes := []string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "SPS").Return(stopAreaCode, nil)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, stopAreaCode).Return(atcoCodes, nil)
+
+		var metrolinkDeparturesForAtcoCodes []*domain.MetrolinkDeparture
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t)...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t)...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, givenMetrolinkDeparturesForAtcoCode9400ZZMASTP3(t)...)
+		metrolinkDeparturesForAtcoCodes = append(metrolinkDeparturesForAtcoCodes, givenMetrolinkDeparturesForAtcoCode9400ZZMASTP4(t)...)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), atcoCodes).Return(metrolinkDeparturesForAtcoCodes, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), atcoCodes).Return(nil, nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, tlarefGetter, metrolinkDeparturesGetter, metrolinkMessagesGetter, mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, "sps", nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, thenExpectJsonDeparturesWithPlatform(t, stopAreaCode, thenExpectDeparturesFor940GZZMASTP(t), givenLastUpdatedTime(t)), readJson(t, rc))
+	})
+
+	t.Run(`Given a TLAREF which is not in the departures feed is requested
+When Json is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "XYZ").Return("", errors.Wrap(redis.ErrNil, "error getting StopAreaCode for TLAREF XYZ"))
+
+		api := NewApi(mockLogger(t), mock_repository.NewMockStopsInAreaGetter(ctrl), nil, tlarefGetter, mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockMetrolinkMessagesGetter(ctrl), mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, "XYZ", nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "unknown TLAREF", "XYZ"), readJson(t, rc))
+	})
+
+	t.Run(`Given an error occurs resolving a TLAREF
+When Json is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "SPS").Return("", errors.New("FUBAR"))
+
+		api := NewApi(mockLogger(t), mock_repository.NewMockStopsInAreaGetter(ctrl), nil, tlarefGetter, mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockMetrolinkMessagesGetter(ctrl), mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, "SPS", nil)
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.EqualError(t, err, "error resolving TLAREF 'SPS': FUBAR")
+	})
+
 	t.Run(`Given a valid Metrolink AtcoCode is requested
 And there are no departures for that AtcoCode
 When Json is called
@@ -701,18 +1000,23 @@ Then the departures value is an empty slice`, func(t *testing.T) {
 
 		validMetrolinkAtcoCode := "9400ZZMASTP1"
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, validMetrolinkAtcoCode).Return(nil, nil)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		lastUpdatedTime := givenLastUpdatedTime(t)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(lastUpdatedTime, nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, nil)
 
 		// Then
 		assert.Nil(t, err)
@@ -737,15 +1041,19 @@ Then an error is returned`, func(t *testing.T) {
 		stopsInAreaGetterErr := errors.New("FUBAR")
 		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, validMetrolinkStopAreaCode).Return(nil, stopsInAreaGetterErr)
 
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenLastUpdatedTime(t), nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.Nil(t, rc)
@@ -754,8 +1062,7 @@ Then an error is returned`, func(t *testing.T) {
 		assert.EqualError(t, err, "error getting ATCO codes for '940GZZMASTP': FUBAR")
 	})
 
-	t.Run(`Given an error occurs fetching departures for an AtcoCode
-And the error is not redis.ErrNil
+	t.Run(`Given an error occurs fetching departures for the AtcoCodes
 When Json is called
 Then an error is returned`, func(t *testing.T) {
 		// Given
@@ -771,30 +1078,79 @@ Then an error is returned`, func(t *testing.T) {
 		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
 		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, validMetrolinkStopAreaCode).Return([]string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}, nil)
 
-		metrolinkDeparturesForAtcoCode9400ZZMASTP1 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t)
-		metrolinkDeparturesForAtcoCode9400ZZMASTP2 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t)
-		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
 		metrolinkDeparturesGetterErr := errors.New("FUBAR")
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP").Return(nil, redis.ErrNil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP1").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP1, nil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP2").Return(metrolinkDeparturesForAtcoCode9400ZZMASTP2, nil)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP3").Return(nil, metrolinkDeparturesGetterErr)
-		metrolinkDeparturesGetter.EXPECT().Get(ctx, "9400ZZMASTP4").Return(nil, metrolinkDeparturesGetterErr)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}).Return(nil, metrolinkDeparturesGetterErr)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
 
 		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
-		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenLastUpdatedTime(t), nil)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
 
-		api := NewApi(logger, stopsInAreaGetter, metrolinkDeparturesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenTimeLocation(t))
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
 
 		// When
-		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode)
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, nil)
 
 		// Then
 		assert.Nil(t, rc)
 		assert.Equal(t, http.StatusInternalServerError, statusCode)
 		assert.NotNil(t, err)
-		assert.Contains(t, err.Error(), "error fetching Metrolink departures for '940GZZMASTP': 2 errors occurred:\n\t")
-		assert.Contains(t, err.Error(), "* error getting departures for AtcoCode '9400ZZMASTP3': FUBAR\n")
-		assert.Contains(t, err.Error(), "* error getting departures for AtcoCode '9400ZZMASTP4': FUBAR\n")
+		assert.Equal(t, "error fetching Metrolink departures for '940GZZMASTP': error getting departures for AtcoCodes: FUBAR", err.Error())
+	})
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+And there are messages for that AtcoCode
+When Json is called
+Then the messages are included in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t), nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(givenMetrolinkMessagesFor940GZZMASTP(t)[:1], nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Contains(t, readJson(t, rc), `
+	],
+	"messages": [
+		{
+			"message": "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			"atcoCodes": [
+				"9400ZZMASTP1"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:18+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`)
 	})
 }
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"io"
+	"time"
+)
+
+// JsonV2 returns departures in the version 2 response format, which includes the line, direction and station of each
+// departure
+func (m *Api) JsonV2(ctx context.Context, stopAreaCodeOrAtcoCode string, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	return m.json(ctx, stopAreaCodeOrAtcoCode, queryParameters, func(stopAreaCodeOrAtcoCode string, stopArea *platformStopArea, departures []*domain.MetrolinkDeparture, adjustments waitAdjustments, messages []*domain.MetrolinkMessage, lastUpdated time.Time, staleDataAge *time.Duration) interface{} {
+		return m.convertToPublicApiV2(stopAreaCodeOrAtcoCode, stopArea, departures, adjustments, messages, lastUpdated, staleDataAge)
+	})
+}
+
+func (m *Api) convertToPublicApiV2(stopAreaCodeOrAtcoCode string, stopArea *platformStopArea, departures []*domain.MetrolinkDeparture, adjustments waitAdjustments, messages []*domain.MetrolinkMessage, lastUpdated time.Time, staleDataAge *time.Duration) *tfgm.MetrolinkDeparturesV2 {
+	var stationLocation, tlaref string
+
+	convertedDepartures := make([]*tfgm.MetrolinkDepartureV2, 0)
+
+	for sequence, departure := range departures {
+		stored, waitAdjusted := adjustments.stored(departure)
+
+		if stationLocation == "" {
+			stationLocation = departure.StationLocation
+		}
+
+		if tlaref == "" {
+			tlaref = departure.Tlaref
+		}
+
+		convertedDepartures = append(convertedDepartures, &tfgm.MetrolinkDepartureV2{
+			AtcoCode:              departure.AtcoCode,
+			Sequence:              sequence,
+			Line:                  departure.Line,
+			Direction:             departure.Direction,
+			Destination:           departure.Destination,
+			Status:                string(departure.Status),
+			Wait:                  departure.Wait,
+			WaitAdjusted:          waitAdjusted,
+			ExpectedDepartureTime: m.convertExpectedDepartureTimeToPublicApi(stored),
+			Carriages:             string(departure.Carriages),
+			Platform:              departure.Platform,
+			StationLocation:       departure.StationLocation,
+			Tlaref:                departure.Tlaref,
+			Pidref:                departure.Pidref,
+			LastUpdated:           departure.LastUpdated.In(m.timeLocation),
+		})
+	}
+
+	stale, dataAgeSeconds := convertStaleDataAgeToPublicApi(staleDataAge)
+
+	publicDepartures := &tfgm.MetrolinkDeparturesV2{
+		RequestedLocation: stopAreaCodeOrAtcoCode,
+		StationLocation:   stationLocation,
+		Tlaref:            tlaref,
+		Departures:        convertedDepartures,
+		Messages:          m.convertMessagesToPublicApi(messages),
+		LastUpdated:       lastUpdated.In(m.timeLocation),
+		Stale:             stale,
+		DataAgeSeconds:    dataAgeSeconds,
+	}
+
+	if stopArea != nil {
+		publicDepartures.StopAreaCode = stopArea.stopAreaCode
+		publicDepartures.OtherPlatforms = stopArea.otherPlatformAtcoCodes
+	}
+
+	return publicDepartures
+}
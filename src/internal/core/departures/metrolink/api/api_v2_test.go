@@ -0,0 +1,310 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApi_JsonV2(t *testing.T) {
+	t.Run(`Given an invalid Metrolink StopAreaCode or AtcoCode is requested
+When JsonV2 is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		invalidMetrolinkStopAreaCode := "FOOBAR"
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, invalidMetrolinkStopAreaCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "invalid StopAreaCode or AtcoCode", invalidMetrolinkStopAreaCode), readJson(t, rc))
+	})
+
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+When JsonV2 is called
+Then departures are returned for that AtcoCode with line, direction and station details`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		platform := "D"
+
+		metrolinkDeparturesForAtcoCode := []*domain.MetrolinkDeparture{
+			{
+				AtcoCode:        "9400ZZMASTP1",
+				Line:            "Bury",
+				Direction:       "Incoming",
+				Tlaref:          "SPS",
+				Pidref:          "SPS-PID05",
+				StationLocation: "St Peter's Square",
+				Order:           0,
+				Destination:     "Bury",
+				Carriages:       "Double",
+				Status:          "Due",
+				Wait:            "4",
+				WaitMinutes:     4,
+				Platform:        &platform,
+				LastUpdated:     time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
+			},
+		}
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(metrolinkDeparturesForAtcoCode, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"stationLocation": "St Peter's Square",
+	"tlaref": "SPS",
+	"departures": [
+		{
+			"atcoCode": "9400ZZMASTP1",
+			"sequence": 0,
+			"line": "Bury",
+			"direction": "Incoming",
+			"destination": "Bury",
+			"status": "Due",
+			"wait": "4",
+			"expectedDepartureTime": "2021-04-06T22:41:19+01:00",
+			"carriages": "Double",
+			"platform": "D",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"pidref": "SPS-PID05",
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		}
+	],
+	"messages": [],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+And the stop area of the AtcoCode is known
+When JsonV2 is called
+Then the StopAreaCode and the AtcoCodes of the other platforms in the stop area are returned with the departures`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		atcoCodeLister := mock_repository.NewMockAtcoCodeLister(ctrl)
+		atcoCodeLister.EXPECT().GetStopAreaForAtcoCode(ctx, validMetrolinkAtcoCode).Return("940GZZMASTP", []string{"9400ZZMASTP4", "9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3"}, nil)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, mock_repository.NewMockStopsInAreaGetter(ctrl), atcoCodeLister, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"stopAreaCode": "940GZZMASTP",
+	"otherPlatforms": [
+		"9400ZZMASTP2",
+		"9400ZZMASTP3",
+		"9400ZZMASTP4"
+	],
+	"departures": [],
+	"messages": [],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+And an error occurs getting the stop area of the AtcoCode
+When JsonV2 is called
+Then the departures are returned without the stop area`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		atcoCodeLister := mock_repository.NewMockAtcoCodeLister(ctrl)
+		atcoCodeLister.EXPECT().GetStopAreaForAtcoCode(ctx, validMetrolinkAtcoCode).Return("", nil, errors.New("FUBAR"))
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, mock_repository.NewMockStopsInAreaGetter(ctrl), atcoCodeLister, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"departures": [],
+	"messages": [],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+And the only departure for that AtcoCode is delayed
+When JsonV2 is called
+Then the departure is returned without an expected departure time`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		metrolinkDeparturesForAtcoCode := []*domain.MetrolinkDeparture{
+			{
+				AtcoCode:        "9400ZZMASTP1",
+				Line:            "Bury",
+				Direction:       "Incoming",
+				Tlaref:          "SPS",
+				Pidref:          "SPS-PID05",
+				StationLocation: "St Peter's Square",
+				Order:           0,
+				Destination:     "Bury",
+				Carriages:       "Double",
+				Status:          "Due",
+				Wait:            "DELAY",
+				Delayed:         true,
+				LastUpdated:     time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
+			},
+		}
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(metrolinkDeparturesForAtcoCode, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.JsonV2(ctx, validMetrolinkAtcoCode, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocation": "9400ZZMASTP1",
+	"stationLocation": "St Peter's Square",
+	"tlaref": "SPS",
+	"departures": [
+		{
+			"atcoCode": "9400ZZMASTP1",
+			"sequence": 0,
+			"line": "Bury",
+			"direction": "Incoming",
+			"destination": "Bury",
+			"status": "Due",
+			"wait": "DELAY",
+			"carriages": "Double",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"pidref": "SPS-PID05",
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		}
+	],
+	"messages": [],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+}
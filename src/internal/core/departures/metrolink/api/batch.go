@@ -0,0 +1,174 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const maxBatchLocations = 20
+
+// BatchJson returns departures for several StopAreaCodes, AtcoCodes or TLAREFs, keyed by requested location. Each TLAREF
+// is resolved as by Json, so its departures have the StopAreaCode of its stop as their requested location: see
+// resolveTlaref. The ATCO codes for every location are fetched together in one round trip, and a location which cannot
+// be resolved has an error in its entry rather than failing the whole batch. Departures for every location are filtered with the same query
+// parameters, and are served when stale in the same way, as Json.
+func (m *Api) BatchJson(ctx context.Context, stopAreaCodesOrAtcoCodes []string, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	requestedLocations := m.normaliseRequestedLocations(stopAreaCodesOrAtcoCodes)
+
+	if len(requestedLocations) == 0 {
+		return m.encodeJsonBatchErrorResponse(requestedLocations, http.StatusBadRequest, "no StopAreaCodes or AtcoCodes requested")
+	}
+
+	if len(requestedLocations) > maxBatchLocations {
+		return m.encodeJsonBatchErrorResponse(requestedLocations, http.StatusBadRequest, fmt.Sprintf("too many StopAreaCodes or AtcoCodes requested: maximum is %d", maxBatchLocations))
+	}
+
+	filter, err := m.parseDeparturesFilter(queryParameters)
+	if err != nil {
+		return m.encodeJsonBatchErrorResponse(requestedLocations, http.StatusBadRequest, err.Error())
+	}
+
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting Metrolink departures system status")
+	}
+
+	staleDataAge, outdated := m.checkDataAge(systemStatus.LastUpdated)
+	if outdated {
+		return m.encodeJsonBatchErrorResponse(requestedLocations, http.StatusBadGateway, fmt.Sprintf("Metrolink departures data is outdated: last updated at %s", systemStatus.LastUpdated.Format(time.RFC3339)))
+	}
+
+	locations := make(map[string]*tfgm.MetrolinkDeparturesBatchLocationV2)
+	atcoCodesByLocation := make(map[string][]string)
+	stopAreaCodesOrAtcoCodesByLocation := make(map[string]string)
+
+	var allAtcoCodes []string
+
+	for _, requestedLocation := range requestedLocations {
+		stopAreaCodeOrAtcoCode, known, err := m.resolveTlaref(ctx, requestedLocation)
+		if err != nil {
+			m.logger.Error("error resolving TLAREF for batch location", zap.String("requestedLocation", requestedLocation), zap.Error(err))
+			locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{Error: "internal server error"}
+			continue
+		}
+
+		if !known {
+			locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{Error: "unknown TLAREF"}
+			continue
+		}
+
+		if !m.validateStopAreaCodeOrAtcoCode(stopAreaCodeOrAtcoCode) {
+			locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{Error: "invalid StopAreaCode or AtcoCode"}
+			continue
+		}
+
+		atcoCodes, err := m.atcoCodesToQuery(ctx, stopAreaCodeOrAtcoCode)
+		if err != nil {
+			if strings.HasSuffix(err.Error(), redis.ErrNil.Error()) {
+				locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{Error: "invalid StopAreaCode"}
+				continue
+			}
+
+			m.logger.Error("error getting ATCO codes for batch location", zap.String("requestedLocation", requestedLocation), zap.Error(err))
+			locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{Error: "internal server error"}
+			continue
+		}
+
+		atcoCodesByLocation[requestedLocation] = atcoCodes
+		stopAreaCodesOrAtcoCodesByLocation[requestedLocation] = stopAreaCodeOrAtcoCode
+
+		for _, atcoCode := range atcoCodes {
+			allAtcoCodes = appendIfMissing(allAtcoCodes, atcoCode)
+		}
+	}
+
+	departuresByAtcoCode, adjustments, err := m.getDeparturesByAtcoCode(ctx, systemStatus.Generation, allAtcoCodes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "error fetching Metrolink departures for batch")
+	}
+
+	messages, err := m.metrolinkMessagesGetter.Get(ctx, systemStatus.Generation, allAtcoCodes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "error fetching Metrolink messages for batch")
+	}
+
+	messagesByAtcoCode := make(map[string][]*domain.MetrolinkMessage)
+	for _, message := range messages {
+		messagesByAtcoCode[message.AtcoCode] = append(messagesByAtcoCode[message.AtcoCode], message)
+	}
+
+	for requestedLocation, atcoCodes := range atcoCodesByLocation {
+		var departures []*domain.MetrolinkDeparture
+		var locationMessages []*domain.MetrolinkMessage
+
+		for _, atcoCode := range atcoCodes {
+			departures = append(departures, departuresByAtcoCode[atcoCode]...)
+			locationMessages = append(locationMessages, messagesByAtcoCode[atcoCode]...)
+		}
+
+		sort.Sort(ByWaitStatusDestinationOrderPlatformCarriages(departures))
+
+		locations[requestedLocation] = &tfgm.MetrolinkDeparturesBatchLocationV2{
+			MetrolinkDeparturesV2: m.convertToPublicApiV2(stopAreaCodesOrAtcoCodesByLocation[requestedLocation], nil, filter.apply(departures), adjustments, locationMessages, systemStatus.LastUpdated, staleDataAge),
+		}
+	}
+
+	stale, dataAgeSeconds := convertStaleDataAgeToPublicApi(staleDataAge)
+
+	return m.encodeJsonDataResponse(&tfgm.MetrolinkDeparturesBatchV2{
+		RequestedLocations: requestedLocations,
+		Locations:          locations,
+		LastUpdated:        systemStatus.LastUpdated.In(m.timeLocation),
+		Stale:              stale,
+		DataAgeSeconds:     dataAgeSeconds,
+	}, staleDataAge)
+}
+
+// normaliseRequestedLocations upper cases the requested locations and removes blanks and duplicates, preserving the
+// order in which they were requested
+func (m *Api) normaliseRequestedLocations(stopAreaCodesOrAtcoCodes []string) []string {
+	requestedLocations := make([]string, 0)
+
+	for _, stopAreaCodeOrAtcoCode := range stopAreaCodesOrAtcoCodes {
+		stopAreaCodeOrAtcoCode = strings.ToUpper(strings.TrimSpace(stopAreaCodeOrAtcoCode))
+		if stopAreaCodeOrAtcoCode == "" {
+			continue
+		}
+
+		requestedLocations = appendIfMissing(requestedLocations, stopAreaCodeOrAtcoCode)
+	}
+
+	return requestedLocations
+}
+
+// getDeparturesByAtcoCode fetches departures for every AtcoCode together and groups them by AtcoCode
+func (m *Api) getDeparturesByAtcoCode(ctx context.Context, generation string, atcoCodes []string) (map[string][]*domain.MetrolinkDeparture, waitAdjustments, error) {
+	departures, adjustments, err := m.getDeparturesForAtcoCodes(ctx, generation, atcoCodes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	departuresByAtcoCode := make(map[string][]*domain.MetrolinkDeparture)
+	for _, departure := range departures {
+		departuresByAtcoCode[departure.AtcoCode] = append(departuresByAtcoCode[departure.AtcoCode], departure)
+	}
+
+	return departuresByAtcoCode, adjustments, nil
+}
+
+func (m *Api) encodeJsonBatchErrorResponse(requestedLocations []string, statusCode int, errorMsg string) (io.ReadCloser, int, error) {
+	return m.encodeJsonResponse(map[string]interface{}{
+		"requestedLocations": requestedLocations,
+		"error":              errorMsg,
+	}, statusCode)
+}
@@ -0,0 +1,314 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApi_BatchJson(t *testing.T) {
+	t.Run(`Given no StopAreaCodes or AtcoCodes are requested
+When BatchJson is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.BatchJson(ctx, []string{"", " "}, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, "{\n\t\"error\": \"no StopAreaCodes or AtcoCodes requested\",\n\t\"requestedLocations\": []\n}\n", readJson(t, rc))
+	})
+
+	t.Run(`Given more StopAreaCodes and AtcoCodes are requested than are allowed in a batch
+When BatchJson is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		var requestedLocations []string
+		for i := 0; i <= maxBatchLocations; i++ {
+			requestedLocations = append(requestedLocations, fmt.Sprintf("9400ZZMA%03d1", i))
+		}
+
+		// When
+		rc, statusCode, err := api.BatchJson(ctx, requestedLocations, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Contains(t, readJson(t, rc), `"error": "too many StopAreaCodes or AtcoCodes requested: maximum is 20"`)
+	})
+
+	t.Run(`Given valid and invalid StopAreaCodes and AtcoCodes are requested
+When BatchJson is called
+Then departures are returned for each valid location and errors are returned for the other locations`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, "940GZZMASTP").Return([]string{"9400ZZMASTP1", "9400ZZMASTP2"}, nil)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, "940GZZMAXXX").Return(nil, errors.Wrap(redis.ErrNil, "error getting stops in area"))
+
+		lastUpdated := time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMAPIC1"}).Return([]*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 0, Destination: "Bury", Carriages: "Double", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP2", Line: "Bury", Direction: "Outgoing", Tlaref: "SPS", Pidref: "SPS-PID03", StationLocation: "St Peter's Square", Order: 0, Destination: "Altrincham", Carriages: "Single", Status: "Due", Wait: "2", WaitMinutes: 2, LastUpdated: lastUpdated},
+		}, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMAPIC1"}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.BatchJson(ctx, []string{"940gzzmastp", "FOO1", "940GZZMAXXX", "9400ZZMAPIC1", "9400ZZMASTP1"}, map[string]string{"limit": "1"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocations": [
+		"940GZZMASTP",
+		"FOO1",
+		"940GZZMAXXX",
+		"9400ZZMAPIC1",
+		"9400ZZMASTP1"
+	],
+	"locations": {
+		"9400ZZMAPIC1": {
+			"requestedLocation": "9400ZZMAPIC1",
+			"departures": [],
+			"messages": [],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		},
+		"9400ZZMASTP1": {
+			"requestedLocation": "9400ZZMASTP1",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"departures": [
+				{
+					"atcoCode": "9400ZZMASTP1",
+					"sequence": 0,
+					"line": "Bury",
+					"direction": "Incoming",
+					"destination": "Bury",
+					"status": "Due",
+					"wait": "4",
+					"expectedDepartureTime": "2021-04-06T22:41:19+01:00",
+					"carriages": "Double",
+					"stationLocation": "St Peter's Square",
+					"tlaref": "SPS",
+					"pidref": "SPS-PID05",
+					"lastUpdated": "2021-04-06T22:37:19+01:00"
+				}
+			],
+			"messages": [],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		},
+		"940GZZMASTP": {
+			"requestedLocation": "940GZZMASTP",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"departures": [
+				{
+					"atcoCode": "9400ZZMASTP2",
+					"sequence": 0,
+					"line": "Bury",
+					"direction": "Outgoing",
+					"destination": "Altrincham",
+					"status": "Due",
+					"wait": "2",
+					"expectedDepartureTime": "2021-04-06T22:39:19+01:00",
+					"carriages": "Single",
+					"stationLocation": "St Peter's Square",
+					"tlaref": "SPS",
+					"pidref": "SPS-PID03",
+					"lastUpdated": "2021-04-06T22:37:19+01:00"
+				}
+			],
+			"messages": [],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		},
+		"940GZZMAXXX": {
+			"error": "invalid StopAreaCode"
+		},
+		"FOO1": {
+			"error": "invalid StopAreaCode or AtcoCode"
+		}
+	},
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+	t.Run(`Given TLAREFs are requested
+When BatchJson is called
+Then departures are returned for the StopAreaCode of each known TLAREF, keyed by the requested TLAREF
+And errors are returned for the TLAREFs which cannot be resolved`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "SPS").Return("940GZZMASTP", nil)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "XYZ").Return("", errors.Wrap(redis.ErrNil, "error getting StopAreaCode for TLAREF XYZ"))
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "PIC").Return("", errors.New("FUBAR"))
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, "940GZZMASTP").Return([]string{"9400ZZMASTP1"}, nil)
+
+		lastUpdated := time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"}).Return([]*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Tlaref: "SPS", Pidref: "SPS-PID05", StationLocation: "St Peter's Square", Order: 0, Destination: "Bury", Carriages: "Double", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: lastUpdated},
+		}, nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1"}).Return(nil, nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, tlarefGetter, metrolinkDeparturesGetter, metrolinkMessagesGetter, mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.BatchJson(ctx, []string{"sps", "XYZ", "PIC"}, nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		expJson := `{
+	"requestedLocations": [
+		"SPS",
+		"XYZ",
+		"PIC"
+	],
+	"locations": {
+		"PIC": {
+			"error": "internal server error"
+		},
+		"SPS": {
+			"requestedLocation": "940GZZMASTP",
+			"stationLocation": "St Peter's Square",
+			"tlaref": "SPS",
+			"departures": [
+				{
+					"atcoCode": "9400ZZMASTP1",
+					"sequence": 0,
+					"line": "Bury",
+					"direction": "Incoming",
+					"destination": "Bury",
+					"status": "Due",
+					"wait": "4",
+					"expectedDepartureTime": "2021-04-06T22:41:19+01:00",
+					"carriages": "Double",
+					"stationLocation": "St Peter's Square",
+					"tlaref": "SPS",
+					"pidref": "SPS-PID05",
+					"lastUpdated": "2021-04-06T22:37:19+01:00"
+				}
+			],
+			"messages": [],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		},
+		"XYZ": {
+			"error": "unknown TLAREF"
+		}
+	},
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+
+		assert.Equal(t, expJson, readJson(t, rc))
+	})
+
+	t.Run(`Given an error occurs fetching departures for the requested AtcoCodes
+When BatchJson is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMAPIC1"}).Return(nil, errors.New("FUBAR"))
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.BatchJson(ctx, []string{"9400ZZMASTP1", "9400ZZMAPIC1"}, nil)
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.Equal(t, "error fetching Metrolink departures for batch: error getting departures for AtcoCodes: FUBAR", err.Error())
+	})
+}
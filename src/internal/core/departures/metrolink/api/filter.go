@@ -0,0 +1,142 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"strconv"
+	"strings"
+)
+
+const (
+	destinationQueryParameter = "destination"
+	lineQueryParameter        = "line"
+	directionQueryParameter   = "direction"
+	platformQueryParameter    = "platform"
+	statusQueryParameter      = "status"
+	maxWaitQueryParameter     = "maxWait"
+	limitQueryParameter       = "limit"
+
+	maxFilterValueLength = 64
+)
+
+// departuresFilter restricts the departures returned by the API, e.g. for a platform screen which only shows trams
+// heading in one direction. Text values are matched case-insensitively and unset values match every departure.
+type departuresFilter struct {
+	destination string
+	line        string
+	direction   string
+	platform    string
+	status      string
+	maxWait     *int
+	limit       int
+}
+
+// parseDeparturesFilter reads a departuresFilter from request query parameters. Unrecognised query parameters are
+// ignored. The returned error describes the first invalid value and is suitable for returning to the client.
+func (m *Api) parseDeparturesFilter(queryParameters map[string]string) (*departuresFilter, error) {
+	filter := &departuresFilter{}
+
+	textFilters := []struct {
+		queryParameter string
+		value          *string
+	}{
+		{destinationQueryParameter, &filter.destination},
+		{lineQueryParameter, &filter.line},
+		{platformQueryParameter, &filter.platform},
+	}
+
+	for _, textFilter := range textFilters {
+		v := strings.TrimSpace(queryParameters[textFilter.queryParameter])
+
+		if len(v) > maxFilterValueLength {
+			return nil, fmt.Errorf("invalid %s: must be no more than %d characters", textFilter.queryParameter, maxFilterValueLength)
+		}
+
+		*textFilter.value = v
+	}
+
+	if direction := strings.TrimSpace(queryParameters[directionQueryParameter]); direction != "" {
+		if !strings.EqualFold(direction, "Incoming") && !strings.EqualFold(direction, "Outgoing") {
+			return nil, fmt.Errorf("invalid %s: must be Incoming or Outgoing", directionQueryParameter)
+		}
+
+		filter.direction = direction
+	}
+
+	if status := strings.TrimSpace(queryParameters[statusQueryParameter]); status != "" {
+		if !strings.EqualFold(status, "Departing") && !strings.EqualFold(status, "Arrived") && !strings.EqualFold(status, "Due") {
+			return nil, fmt.Errorf("invalid %s: must be Departing, Arrived or Due", statusQueryParameter)
+		}
+
+		filter.status = status
+	}
+
+	if maxWait := strings.TrimSpace(queryParameters[maxWaitQueryParameter]); maxWait != "" {
+		v, err := strconv.Atoi(maxWait)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid %s: must be a whole number of minutes", maxWaitQueryParameter)
+		}
+
+		filter.maxWait = &v
+	}
+
+	if limit := strings.TrimSpace(queryParameters[limitQueryParameter]); limit != "" {
+		v, err := strconv.Atoi(limit)
+		if err != nil || v < 1 {
+			return nil, fmt.Errorf("invalid %s: must be a positive whole number", limitQueryParameter)
+		}
+
+		filter.limit = v
+	}
+
+	return filter, nil
+}
+
+// apply returns the departures which match the filter. Departures must already be sorted, so that the limit keeps the
+// soonest departures.
+func (f *departuresFilter) apply(departures []*domain.MetrolinkDeparture) []*domain.MetrolinkDeparture {
+	var filteredDepartures []*domain.MetrolinkDeparture
+
+	for _, departure := range departures {
+		if f.limit > 0 && len(filteredDepartures) == f.limit {
+			break
+		}
+
+		if f.matches(departure) {
+			filteredDepartures = append(filteredDepartures, departure)
+		}
+	}
+
+	return filteredDepartures
+}
+
+func (f *departuresFilter) matches(departure *domain.MetrolinkDeparture) bool {
+	if f.destination != "" && !strings.EqualFold(f.destination, departure.Destination) {
+		return false
+	}
+
+	if f.line != "" && !strings.EqualFold(f.line, departure.Line) {
+		return false
+	}
+
+	if f.direction != "" && !strings.EqualFold(f.direction, departure.Direction) {
+		return false
+	}
+
+	if f.platform != "" && (departure.Platform == nil || !strings.EqualFold(f.platform, *departure.Platform)) {
+		return false
+	}
+
+	if f.status != "" && !strings.EqualFold(f.status, string(departure.Status)) {
+		return false
+	}
+
+	if f.maxWait != nil {
+		// A delayed departure cannot be within the maximum wait
+		if departure.Delayed || departure.WaitMinutes > *f.maxWait {
+			return false
+		}
+	}
+
+	return true
+}
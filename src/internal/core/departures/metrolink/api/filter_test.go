@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestApi_JsonWithFilters(t *testing.T) {
+	t.Run(`Given a valid Metrolink StopAreaCode is requested
+And an invalid filter is given in the query parameters
+When Json is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		validMetrolinkStopAreaCode := "940GZZMASTP"
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		testCases := map[string]string{
+			"direction": "invalid direction: must be Incoming or Outgoing",
+			"status":    "invalid status: must be Departing, Arrived or Due",
+			"maxWait":   "invalid maxWait: must be a whole number of minutes",
+			"limit":     "invalid limit: must be a positive whole number",
+		}
+
+		for queryParameter, expErrorMsg := range testCases {
+			// When
+			rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, map[string]string{queryParameter: "-1"})
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, statusCode)
+			assert.Equal(t, thenExpectJsonError(t, expErrorMsg, validMetrolinkStopAreaCode), readJson(t, rc))
+		}
+	})
+
+	t.Run(`Given a valid Metrolink StopAreaCode is requested
+And platform, status and limit filters are given in the query parameters
+When Json is called
+Then only the soonest matching departures are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkStopAreaCode := "940GZZMASTP"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, validMetrolinkStopAreaCode).Return([]string{"9400ZZMASTP1", "9400ZZMASTP2"}, nil)
+
+		metrolinkDeparturesForAtcoCode9400ZZMASTP2 := givenMetrolinkDeparturesForAtcoCode9400ZZMASTP2(t)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2"}).Return(append(givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t), metrolinkDeparturesForAtcoCode9400ZZMASTP2...), nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2"}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		lastUpdatedTime := givenLastUpdatedTime(t)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		queryParameters := map[string]string{
+			"platform": "c",
+			"status":   "due",
+			"limit":    "2",
+		}
+
+		// When
+		rc, statusCode, err := api.Json(ctx, validMetrolinkStopAreaCode, queryParameters)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, thenExpectJsonDeparturesWithPlatform(t, validMetrolinkStopAreaCode, metrolinkDeparturesForAtcoCode9400ZZMASTP2[:2], lastUpdatedTime), readJson(t, rc))
+	})
+
+	t.Run(`Given a valid Metrolink AtcoCode is requested
+And no departures match the filters given in the query parameters
+When Json is called
+Then an empty departures slice is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(givenMetrolinkDeparturesForAtcoCode9400ZZMASTP1(t), nil)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		lastUpdatedTime := givenLastUpdatedTime(t)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, givenAtcoCodeListerWithoutStopAreas(t, ctrl), nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.Json(ctx, validMetrolinkAtcoCode, map[string]string{"destination": "Eccles"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, thenExpectJsonWithEmptyDeparturesSlice(t, validMetrolinkAtcoCode, lastUpdatedTime), readJson(t, rc))
+	})
+}
+
+func TestDeparturesFilter_Apply(t *testing.T) {
+	t.Run(`Given departures on several lines and in both directions
+When a filter for a line, direction and maximum wait is applied
+Then only departures matching every filter are returned`, func(t *testing.T) {
+		// Given
+		lastUpdated := time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC)
+
+		departures := []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Destination: "Bury", Status: "Due", Wait: "3", WaitMinutes: 3, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Outgoing", Destination: "Altrincham", Status: "Due", Wait: "4", WaitMinutes: 4, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP2", Line: "Eccles", Direction: "Incoming", Destination: "Ashton-under-Lyne", Status: "Due", Wait: "5", WaitMinutes: 5, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Destination: "Bury", Status: "Due", Wait: "DELAY", Delayed: true, LastUpdated: lastUpdated},
+			{AtcoCode: "9400ZZMASTP1", Line: "Bury", Direction: "Incoming", Destination: "Bury", Status: "Due", Wait: "15", WaitMinutes: 15, LastUpdated: lastUpdated},
+		}
+
+		maxWait := 10
+
+		filter := &departuresFilter{
+			line:      "bury",
+			direction: "INCOMING",
+			maxWait:   &maxWait,
+		}
+
+		// When
+		result := filter.apply(departures)
+
+		// Then
+		assert.Equal(t, departures[:1], result)
+	})
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MessagesJson returns the service messages for a StopAreaCode, AtcoCode or TLAREF, which is resolved as by Json: see
+// resolveTlaref. Like departures, messages older than the stale data threshold are served with a stale flag until they
+// are older than the degraded data threshold: see checkDataAge.
+func (m *Api) MessagesJson(ctx context.Context, stopAreaCodeOrAtcoCode string) (io.ReadCloser, int, error) {
+	stopAreaCodeOrAtcoCode = strings.ToUpper(stopAreaCodeOrAtcoCode)
+
+	stopAreaCodeOrAtcoCode, known, err := m.resolveTlaref(ctx, stopAreaCodeOrAtcoCode)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	if !known {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, "unknown TLAREF")
+	}
+
+	if !m.validateStopAreaCodeOrAtcoCode(stopAreaCodeOrAtcoCode) {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, "invalid StopAreaCode or AtcoCode")
+	}
+
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting Metrolink departures system status")
+	}
+
+	staleDataAge, outdated := m.checkDataAge(systemStatus.LastUpdated)
+	if outdated {
+		return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadGateway, fmt.Sprintf("Metrolink departures data is outdated: last updated at %s", systemStatus.LastUpdated.Format(time.RFC3339)))
+	}
+
+	atcoCodes, err := m.atcoCodesToQuery(ctx, stopAreaCodeOrAtcoCode)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), redis.ErrNil.Error()) {
+			return m.encodeJsonErrorResponse(stopAreaCodeOrAtcoCode, http.StatusBadRequest, "invalid StopAreaCode")
+		}
+
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting ATCO codes for '%s'", stopAreaCodeOrAtcoCode)
+	}
+
+	messages, err := m.metrolinkMessagesGetter.Get(ctx, systemStatus.Generation, atcoCodes)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error fetching Metrolink messages for '%s'", stopAreaCodeOrAtcoCode)
+	}
+
+	stale, dataAgeSeconds := convertStaleDataAgeToPublicApi(staleDataAge)
+
+	return m.encodeJsonDataResponse(&tfgm.MetrolinkMessages{
+		RequestedLocation: stopAreaCodeOrAtcoCode,
+		Messages:          m.convertMessagesToPublicApi(messages),
+		LastUpdated:       systemStatus.LastUpdated.In(m.timeLocation),
+		Stale:             stale,
+		DataAgeSeconds:    dataAgeSeconds,
+	}, staleDataAge)
+}
+
+// LineMessagesJson returns the service messages for a Metrolink line, which are served with a stale flag in the same way
+// as MessagesJson
+func (m *Api) LineMessagesJson(ctx context.Context, line string) (io.ReadCloser, int, error) {
+	if !m.validateLine(line) {
+		return m.encodeJsonResponse(map[string]string{
+			"requestedLine": line,
+			"error":         "invalid Line",
+		}, http.StatusBadRequest)
+	}
+
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error getting Metrolink departures system status")
+	}
+
+	staleDataAge, outdated := m.checkDataAge(systemStatus.LastUpdated)
+	if outdated {
+		return m.encodeJsonResponse(map[string]string{
+			"requestedLine": line,
+			"error":         fmt.Sprintf("Metrolink departures data is outdated: last updated at %s", systemStatus.LastUpdated.Format(time.RFC3339)),
+		}, http.StatusBadGateway)
+	}
+
+	messages, err := m.metrolinkLineMessagesGetter.GetForLine(ctx, systemStatus.Generation, line)
+	if err != nil && err != redis.ErrNil {
+		return nil, http.StatusInternalServerError, errors.Wrapf(err, "error fetching Metrolink messages for line '%s'", line)
+	}
+
+	stale, dataAgeSeconds := convertStaleDataAgeToPublicApi(staleDataAge)
+
+	return m.encodeJsonDataResponse(&tfgm.MetrolinkMessages{
+		RequestedLine:  line,
+		Messages:       m.convertMessagesToPublicApi(messages),
+		LastUpdated:    systemStatus.LastUpdated.In(m.timeLocation),
+		Stale:          stale,
+		DataAgeSeconds: dataAgeSeconds,
+	}, staleDataAge)
+}
+
+func (m *Api) validateLine(line string) bool {
+	match, _ := regexp.MatchString("^[A-Za-z][A-Za-z &'-]{0,63}$", line)
+	return match
+}
+
+// convertMessagesToPublicApi combines identical messages, which are often shown on many Passenger Information Displays
+// along a line, into a single message listing every AtcoCode and Line it was displayed on
+func (m *Api) convertMessagesToPublicApi(messages []*domain.MetrolinkMessage) []*tfgm.MetrolinkMessage {
+	convertedMessages := make([]*tfgm.MetrolinkMessage, 0)
+
+	convertedMessagesByText := make(map[string]*tfgm.MetrolinkMessage)
+
+	for _, message := range messages {
+		convertedMessage := convertedMessagesByText[message.Message]
+		if convertedMessage == nil {
+			convertedMessage = &tfgm.MetrolinkMessage{
+				Message:   message.Message,
+				AtcoCodes: make([]string, 0),
+				Lines:     make([]string, 0),
+			}
+
+			convertedMessagesByText[message.Message] = convertedMessage
+			convertedMessages = append(convertedMessages, convertedMessage)
+		}
+
+		convertedMessage.AtcoCodes = appendIfMissing(convertedMessage.AtcoCodes, message.AtcoCode)
+
+		if message.Line != "" {
+			convertedMessage.Lines = appendIfMissing(convertedMessage.Lines, message.Line)
+		}
+
+		if lastUpdated := message.LastUpdated.In(m.timeLocation); lastUpdated.After(convertedMessage.LastUpdated) {
+			convertedMessage.LastUpdated = lastUpdated
+		}
+	}
+
+	for _, convertedMessage := range convertedMessages {
+		sort.Strings(convertedMessage.AtcoCodes)
+		sort.Strings(convertedMessage.Lines)
+	}
+
+	sort.SliceStable(convertedMessages, func(i, j int) bool {
+		return convertedMessages[i].Message < convertedMessages[j].Message
+	})
+
+	return convertedMessages
+}
+
+func appendIfMissing(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
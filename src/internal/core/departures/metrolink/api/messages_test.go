@@ -0,0 +1,524 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func givenMetrolinkMessagesFor940GZZMASTP(t *testing.T) []*domain.MetrolinkMessage {
+	t.Helper()
+
+	return []*domain.MetrolinkMessage{
+		{
+			AtcoCode:    "9400ZZMASTP1",
+			Line:        "Eccles",
+			Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 18, 0, time.UTC),
+		},
+		{
+			AtcoCode:    "9400ZZMASTP4",
+			Line:        "Eccles",
+			Message:     "Please see printed posters for first and last tram times.",
+			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
+		},
+		{
+			AtcoCode:    "9400ZZMASTP4",
+			Line:        "Altrincham",
+			Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			LastUpdated: time.Date(2021, time.April, 6, 21, 37, 19, 0, time.UTC),
+		},
+	}
+}
+
+func thenExpectJsonMessagesFor940GZZMASTP(t *testing.T) string {
+	t.Helper()
+
+	return `{
+	"requestedLocation": "940GZZMASTP",
+	"messages": [
+		{
+			"message": "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			"atcoCodes": [
+				"9400ZZMASTP1",
+				"9400ZZMASTP4"
+			],
+			"lines": [
+				"Altrincham",
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		},
+		{
+			"message": "Please see printed posters for first and last tram times.",
+			"atcoCodes": [
+				"9400ZZMASTP4"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`
+}
+
+func TestApi_MessagesJson(t *testing.T) {
+	t.Run(`Given a valid Metrolink StopAreaCode is requested
+When MessagesJson is called
+Then identical messages for each AtcoCode in that StopAreaCode are combined`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkStopAreaCode := "940GZZMASTP"
+		atcoCodes := []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, validMetrolinkStopAreaCode).Return(atcoCodes, nil)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), atcoCodes).Return(givenMetrolinkMessagesFor940GZZMASTP(t), nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, validMetrolinkStopAreaCode)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, thenExpectJsonMessagesFor940GZZMASTP(t), readJson(t, rc))
+	})
+
+	t.Run(`Given a TLAREF is requested
+When MessagesJson is called
+Then identical messages for each AtcoCode in the StopAreaCode of the TLAREF are combined
+And the StopAreaCode is returned as the requested location`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopAreaCode := "940GZZMASTP"
+		atcoCodes := []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "SPS").Return(stopAreaCode, nil)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		stopsInAreaGetter.EXPECT().GetStopsInArea(ctx, stopAreaCode).Return(atcoCodes, nil)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), atcoCodes).Return(givenMetrolinkMessagesFor940GZZMASTP(t), nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, tlarefGetter, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, "sps")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, thenExpectJsonMessagesFor940GZZMASTP(t), readJson(t, rc))
+	})
+
+	t.Run(`Given a TLAREF which is not in the departures feed is requested
+When MessagesJson is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		tlarefGetter := mock_repository.NewMockMetrolinkTlarefGetter(ctrl)
+		tlarefGetter.EXPECT().GetStopAreaCodeForTlaref(ctx, "XYZ").Return("", errors.Wrap(redis.ErrNil, "error getting StopAreaCode for TLAREF XYZ"))
+
+		api := NewApi(mockLogger(t), mock_repository.NewMockStopsInAreaGetter(ctrl), nil, tlarefGetter, mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockMetrolinkMessagesGetter(ctrl), mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, "XYZ")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "unknown TLAREF", "XYZ"), readJson(t, rc))
+	})
+
+	t.Run(`Given the system status shows that the last updated time breaches the stale data threshold
+And the last updated time is within the degraded data threshold
+When MessagesJson is called
+Then the last known messages are returned with a stale flag and the age of the data
+And the response reports the age of the data for a Warning header`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(givenMetrolinkMessagesFor940GZZMASTP(t)[:1], nil)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
+
+		degradedDataThreshold := time.Minute * 5
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), degradedDataThreshold, givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, validMetrolinkAtcoCode)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		staleData, ok := rc.(core.StaleDataJson)
+		assert.True(t, ok)
+		assert.Equal(t, time.Second*46, staleData.DataAge())
+
+		assert.Equal(t, `{
+	"requestedLocation": "9400ZZMASTP1",
+	"messages": [
+		{
+			"message": "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			"atcoCodes": [
+				"9400ZZMASTP1"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:18+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:36:44+01:00",
+	"stale": true,
+	"dataAgeSeconds": 46
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the system status shows that the last updated time breaches the degraded data threshold
+When MessagesJson is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, validMetrolinkAtcoCode)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadGateway, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "Metrolink departures data is outdated: last updated at 2021-04-06T21:36:44Z", validMetrolinkAtcoCode), readJson(t, rc))
+	})
+
+	t.Run(`Given an invalid Metrolink StopAreaCode or AtcoCode is requested
+When MessagesJson is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		invalidMetrolinkStopAreaCodeOrAtcoCode := "1800SB18811"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, invalidMetrolinkStopAreaCodeOrAtcoCode)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, thenExpectJsonError(t, "invalid StopAreaCode or AtcoCode", invalidMetrolinkStopAreaCodeOrAtcoCode), readJson(t, rc))
+	})
+
+	t.Run(`Given an error occurs fetching messages
+When MessagesJson is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		validMetrolinkAtcoCode := "9400ZZMASTP1"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkMessagesGetter.EXPECT().Get(ctx, givenGeneration(t), []string{validMetrolinkAtcoCode}).Return(nil, errors.New("FUBAR"))
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.MessagesJson(ctx, validMetrolinkAtcoCode)
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.EqualError(t, err, "error fetching Metrolink messages for '9400ZZMASTP1': FUBAR")
+	})
+}
+
+func TestApi_LineMessagesJson(t *testing.T) {
+	t.Run(`Given a line with messages is requested
+When LineMessagesJson is called
+Then identical messages for the line are combined`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		line := "Eccles"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter.EXPECT().GetForLine(ctx, givenGeneration(t), line).Return(givenMetrolinkMessagesFor940GZZMASTP(t)[:2], nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.LineMessagesJson(ctx, line)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"requestedLine": "Eccles",
+	"messages": [
+		{
+			"message": "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			"atcoCodes": [
+				"9400ZZMASTP1"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:18+01:00"
+		},
+		{
+			"message": "Please see printed posters for first and last tram times.",
+			"atcoCodes": [
+				"9400ZZMASTP4"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:37:19+01:00"
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the system status shows that the last updated time breaches the stale data threshold
+And the last updated time is within the degraded data threshold
+When LineMessagesJson is called
+Then the last known messages are returned with a stale flag and the age of the data
+And the response reports the age of the data for a Warning header`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		line := "Eccles"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter.EXPECT().GetForLine(ctx, givenGeneration(t), line).Return(givenMetrolinkMessagesFor940GZZMASTP(t)[1:2], nil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenStaleSystemStatus(t), nil)
+
+		degradedDataThreshold := time.Minute * 5
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), degradedDataThreshold, givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.LineMessagesJson(ctx, line)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		staleData, ok := rc.(core.StaleDataJson)
+		assert.True(t, ok)
+		assert.Equal(t, time.Second*46, staleData.DataAge())
+
+		assert.Equal(t, `{
+	"requestedLine": "Eccles",
+	"messages": [
+		{
+			"message": "Please see printed posters for first and last tram times.",
+			"atcoCodes": [
+				"9400ZZMASTP4"
+			],
+			"lines": [
+				"Eccles"
+			],
+			"lastUpdated": "2021-04-06T22:37:19+01:00"
+		}
+	],
+	"lastUpdated": "2021-04-06T22:36:44+01:00",
+	"stale": true,
+	"dataAgeSeconds": 46
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given a line without messages is requested
+When LineMessagesJson is called
+Then the messages value is an empty slice`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		line := "Bury"
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter.EXPECT().GetForLine(ctx, givenGeneration(t), line).Return(nil, redis.ErrNil)
+
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter.EXPECT().Get(ctx).Return(givenSystemStatus(t), nil)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.LineMessagesJson(ctx, line)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "{\n\t\"requestedLine\": \"Bury\",\n\t\"messages\": [],\n\t\"lastUpdated\": \"2021-04-06T22:37:19+01:00\"\n}\n", readJson(t, rc))
+	})
+
+	t.Run(`Given an invalid line is requested
+When LineMessagesJson is called
+Then an error JSON response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaGetter := mock_repository.NewMockStopsInAreaGetter(ctrl)
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkMessagesGetter := mock_repository.NewMockMetrolinkMessagesGetter(ctrl)
+		metrolinkLineMessagesGetter := mock_repository.NewMockMetrolinkLineMessagesGetter(ctrl)
+		metrolinkDeparturesSystemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		api := NewApi(logger, stopsInAreaGetter, nil, nil, metrolinkDeparturesGetter, metrolinkMessagesGetter, metrolinkLineMessagesGetter, metrolinkDeparturesSystemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.LineMessagesJson(ctx, "*")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, "{\n\t\"error\": \"invalid Line\",\n\t\"requestedLine\": \"*\"\n}\n", readJson(t, rc))
+	})
+}
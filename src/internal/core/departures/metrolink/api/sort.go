@@ -2,7 +2,6 @@ package api
 
 import (
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
-	"strconv"
 )
 
 type ByWaitStatusDestinationOrderPlatformCarriages []*domain.MetrolinkDeparture
@@ -12,7 +11,7 @@ func (s ByWaitStatusDestinationOrderPlatformCarriages) Len() int {
 }
 
 func (s ByWaitStatusDestinationOrderPlatformCarriages) Less(i, j int) bool {
-	if s[i].Wait == s[j].Wait {
+	if s[i].Delayed == s[j].Delayed && s[i].WaitMinutes == s[j].WaitMinutes {
 		if s[i].Status == s[j].Status {
 			if s[i].Order == s[j].Order {
 				if s[i].Destination == s[j].Destination {
@@ -20,7 +19,7 @@ func (s ByWaitStatusDestinationOrderPlatformCarriages) Less(i, j int) bool {
 						return *s[i].Platform < *s[j].Platform
 					}
 
-					return s[i].Carriages > s[j].Carriages
+					return carriagesRank(s[i].Carriages) < carriagesRank(s[j].Carriages)
 				}
 
 				return s[i].Destination < s[j].Destination
@@ -29,41 +28,41 @@ func (s ByWaitStatusDestinationOrderPlatformCarriages) Less(i, j int) bool {
 			return s[i].Order < s[j].Order
 		}
 
-		// The possible statuses are:
-		// Departing - the tram is setting off from the stop
-		// Arrived - the tram is at the stop
-		// Due - the tram is en route to the stop
-
-		if s[i].Status == "Departing" {
-			return true
-		}
-
-		if s[j].Status == "Departing" {
-			return false
-		}
-
-		if s[i].Status == "Arrived" {
-			return true
-		}
-
-		return false
+		return statusRank(s[i].Status) < statusRank(s[j].Status)
 	}
 
-	// Wait value is potentially "DELAY"
-	// Display "DELAY" values last
-	iWait, err := strconv.Atoi(s[i].Wait)
-	if err != nil {
-		return false
+	// Display delayed departures last
+	if s[i].Delayed != s[j].Delayed {
+		return s[j].Delayed
 	}
 
-	jWait, err := strconv.Atoi(s[j].Wait)
-	if err != nil {
-		return true
-	}
-
-	return iWait < jWait
+	return s[i].WaitMinutes < s[j].WaitMinutes
 }
 
 func (s ByWaitStatusDestinationOrderPlatformCarriages) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
+
+// statusRank orders trams leaving the stop before trams at the stop before trams en route to the stop
+func statusRank(status domain.MetrolinkDepartureStatus) int {
+	switch status {
+	case domain.MetrolinkDepartureStatusDeparting:
+		return 0
+	case domain.MetrolinkDepartureStatusArrived:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// carriagesRank orders single trams before double trams, with unrecognised carriages last
+func carriagesRank(carriages domain.MetrolinkCarriages) int {
+	switch carriages {
+	case domain.MetrolinkCarriagesSingle:
+		return 0
+	case domain.MetrolinkCarriagesDouble:
+		return 1
+	default:
+		return 2
+	}
+}
@@ -19,6 +19,7 @@ func givenASliceOfMetrolinkDeparture(t *testing.T) []*domain.MetrolinkDeparture
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "5",
+			WaitMinutes: 5,
 			Platform:    nil,
 		},
 		{
@@ -27,6 +28,7 @@ func givenASliceOfMetrolinkDeparture(t *testing.T) []*domain.MetrolinkDeparture
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "7",
+			WaitMinutes: 7,
 			Platform:    nil,
 		},
 	}
@@ -76,6 +78,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -84,6 +87,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "2",
+				WaitMinutes: 2,
 				Platform:    nil,
 			},
 		}
@@ -107,6 +111,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "2",
+				WaitMinutes: 2,
 				Platform:    nil,
 			},
 			{
@@ -115,6 +120,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -139,6 +145,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "DELAY",
+				Delayed:     true,
 				Platform:    nil,
 			},
 			{
@@ -147,6 +154,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -171,6 +179,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -179,6 +188,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "DELAY",
+				Delayed:     true,
 				Platform:    nil,
 			},
 		}
@@ -203,6 +213,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Departing",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -211,6 +222,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -236,6 +248,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Arrived",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -244,6 +257,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Departing",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -269,6 +283,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Arrived",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -277,6 +292,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -302,6 +318,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -310,6 +327,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Arrived",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -335,6 +353,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -343,6 +362,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -368,6 +388,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -376,6 +397,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -402,6 +424,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -410,6 +433,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -436,6 +460,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -444,6 +469,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -472,6 +498,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -480,6 +507,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -508,6 +536,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 			{
@@ -516,6 +545,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    nil,
 			},
 		}
@@ -546,6 +576,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platformA,
 			},
 			{
@@ -554,6 +585,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platformB,
 			},
 		}
@@ -584,6 +616,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platformB,
 			},
 			{
@@ -592,6 +625,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platformA,
 			},
 		}
@@ -622,6 +656,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platform1,
 			},
 			{
@@ -630,6 +665,7 @@ Then Less() returns true`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platform2,
 			},
 		}
@@ -660,6 +696,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platform2,
 			},
 			{
@@ -668,6 +705,7 @@ Then Less() returns false`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "1",
+				WaitMinutes: 1,
 				Platform:    &platform1,
 			},
 		}
@@ -692,6 +730,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "2",
+				WaitMinutes: 2,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -701,6 +740,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "9",
+				WaitMinutes: 9,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -710,6 +750,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "17",
+				WaitMinutes: 17,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -728,6 +769,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "5",
+				WaitMinutes: 5,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -737,6 +779,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "9",
+				WaitMinutes: 9,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -762,6 +805,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "2",
+				WaitMinutes: 2,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -771,6 +815,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "5",
+				WaitMinutes: 5,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -780,6 +825,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Double",
 				Status:      "Due",
 				Wait:        "9",
+				WaitMinutes: 9,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -789,6 +835,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "9",
+				WaitMinutes: 9,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
@@ -798,6 +845,7 @@ Then the MetrolinkDeparture slice is sorted correctly`, func(t *testing.T) {
 				Carriages:   "Single",
 				Status:      "Due",
 				Wait:        "17",
+				WaitMinutes: 17,
 				Platform:    nil,
 				LastUpdated: time.Time{},
 			},
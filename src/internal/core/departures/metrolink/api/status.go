@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"time"
+)
+
+// StatusJson describes the health of the Metrolink departures data for monitoring. The response is 200 OK while
+// departures can be served, even if recent loads have failed or stale departures are being served within the degraded
+// data threshold, and 503 Service Unavailable once the data is outdated or the system status cannot be read, so that a
+// failing loader, TfGM feed or Redis server can be told apart.
+func (m *Api) StatusJson(ctx context.Context) (io.ReadCloser, int, error) {
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
+	if err != nil {
+		if errors.Cause(err) == redis.ErrNil {
+			return m.encodeJsonResponse(&tfgm.MetrolinkSystemStatus{
+				Status: tfgm.MetrolinkSystemStatusUnavailable,
+				Error:  "Metrolink departures have never been loaded",
+			}, http.StatusServiceUnavailable)
+		}
+
+		m.logger.Error("error getting Metrolink departures system status", zap.Error(err))
+
+		return m.encodeJsonResponse(&tfgm.MetrolinkSystemStatus{
+			Status: tfgm.MetrolinkSystemStatusUnavailable,
+			Error:  "Metrolink departures system status cannot be read",
+		}, http.StatusServiceUnavailable)
+	}
+
+	status, statusCode := m.systemStatusHealth(systemStatus)
+
+	return m.encodeJsonResponse(&tfgm.MetrolinkSystemStatus{
+		Status:                       status,
+		LastUpdated:                  m.optionalTime(systemStatus.LastUpdated),
+		DataAgeSeconds:               m.dataAgeSeconds(systemStatus.LastUpdated),
+		LastAttempt:                  m.optionalTime(systemStatus.LastAttempt),
+		LastSuccess:                  m.optionalTime(systemStatus.LastSuccess),
+		ConsecutiveFailures:          systemStatus.ConsecutiveFailures,
+		LastErrorCategory:            string(systemStatus.LastErrorCategory),
+		PassengerInformationDisplays: systemStatus.PassengerInformationDisplays,
+		Departures:                   systemStatus.Departures,
+		Messages:                     systemStatus.Messages,
+	}, statusCode)
+}
+
+// systemStatusHealth returns the status of the departures data: outdated once it can no longer be served, degraded while
+// stale data is served or recent loads have failed, and OK otherwise. See checkDataAge.
+func (m *Api) systemStatusHealth(systemStatus *domain.SystemStatus) (string, int) {
+	if systemStatus.LastUpdated.IsZero() {
+		return tfgm.MetrolinkSystemStatusOutdated, http.StatusServiceUnavailable
+	}
+
+	staleDataAge, outdated := m.checkDataAge(systemStatus.LastUpdated)
+	if outdated {
+		return tfgm.MetrolinkSystemStatusOutdated, http.StatusServiceUnavailable
+	}
+
+	if staleDataAge != nil || systemStatus.ConsecutiveFailures > 0 {
+		return tfgm.MetrolinkSystemStatusDegraded, http.StatusOK
+	}
+
+	return tfgm.MetrolinkSystemStatusOk, http.StatusOK
+}
+
+func (m *Api) dataAgeSeconds(lastUpdated time.Time) *int {
+	if lastUpdated.IsZero() {
+		return nil
+	}
+
+	dataAgeSeconds := int(m.currentTimeFunc().Sub(lastUpdated).Seconds())
+
+	return &dataAgeSeconds
+}
+
+// optionalTime converts a time to the API's time location, omitting times which have never been set
+func (m *Api) optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	t = t.In(m.timeLocation)
+
+	return &t
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func givenHealthySystemStatus(t *testing.T) *domain.SystemStatus {
+	t.Helper()
+
+	return &domain.SystemStatus{
+		Generation:                   givenGeneration(t),
+		LastUpdated:                  *givenLastUpdatedTime(t),
+		LastAttempt:                  time.Date(2021, time.April, 6, 21, 37, 25, 0, time.UTC),
+		LastSuccess:                  time.Date(2021, time.April, 6, 21, 37, 25, 0, time.UTC),
+		PassengerInformationDisplays: 296,
+		Departures:                   412,
+		Messages:                     87,
+	}
+}
+
+func TestApi_StatusJson(t *testing.T) {
+	t.Run(`Given the last load succeeded
+And the data is not stale
+When StatusJson is called
+Then an OK status is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(givenHealthySystemStatus(t), nil)
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"status": "OK",
+	"lastUpdated": "2021-04-06T22:37:19+01:00",
+	"dataAgeSeconds": 11,
+	"lastAttempt": "2021-04-06T22:37:25+01:00",
+	"lastSuccess": "2021-04-06T22:37:25+01:00",
+	"consecutiveFailures": 0,
+	"passengerInformationDisplays": 296,
+	"departures": 412,
+	"messages": 87
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given recent loads have failed
+And the data is not stale
+When StatusJson is called
+Then a DEGRADED status is returned with the failure details`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatus := givenHealthySystemStatus(t)
+		systemStatus.LastAttempt = time.Date(2021, time.April, 6, 21, 37, 29, 0, time.UTC)
+		systemStatus.ConsecutiveFailures = 2
+		systemStatus.LastErrorCategory = domain.SystemStatusErrorCategoryStorage
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(systemStatus, nil)
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"status": "DEGRADED",
+	"lastUpdated": "2021-04-06T22:37:19+01:00",
+	"dataAgeSeconds": 11,
+	"lastAttempt": "2021-04-06T22:37:29+01:00",
+	"lastSuccess": "2021-04-06T22:37:25+01:00",
+	"consecutiveFailures": 2,
+	"lastErrorCategory": "storage",
+	"passengerInformationDisplays": 296,
+	"departures": 412,
+	"messages": 87
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the data is stale
+And the data is within the degraded data threshold
+When StatusJson is called
+Then a DEGRADED status is returned with the age of the data`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatus := givenHealthySystemStatus(t)
+		systemStatus.LastUpdated = *givenStaleLastUpdatedTime(t)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(systemStatus, nil)
+
+		degradedDataThreshold := time.Minute * 5
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), degradedDataThreshold, givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"status": "DEGRADED",
+	"lastUpdated": "2021-04-06T22:36:44+01:00",
+	"dataAgeSeconds": 46,
+	"lastAttempt": "2021-04-06T22:37:25+01:00",
+	"lastSuccess": "2021-04-06T22:37:25+01:00",
+	"consecutiveFailures": 0,
+	"passengerInformationDisplays": 296,
+	"departures": 412,
+	"messages": 87
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the data is stale
+And the data breaches the degraded data threshold
+When StatusJson is called
+Then an OUTDATED status is returned with a Service Unavailable status code`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatus := givenHealthySystemStatus(t)
+		systemStatus.LastUpdated = *givenStaleLastUpdatedTime(t)
+		systemStatus.ConsecutiveFailures = 5
+		systemStatus.LastErrorCategory = domain.SystemStatusErrorCategorySource
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(systemStatus, nil)
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Contains(t, readJson(t, rc), `"status": "OUTDATED"`)
+	})
+
+	t.Run(`Given no load has ever succeeded
+When StatusJson is called
+Then an OUTDATED status is returned without a last updated time`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(&domain.SystemStatus{
+			LastAttempt:         time.Date(2021, time.April, 6, 21, 37, 29, 0, time.UTC),
+			ConsecutiveFailures: 1,
+			LastErrorCategory:   domain.SystemStatusErrorCategorySource,
+		}, nil)
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, `{
+	"status": "OUTDATED",
+	"lastAttempt": "2021-04-06T22:37:29+01:00",
+	"consecutiveFailures": 1,
+	"lastErrorCategory": "source",
+	"passengerInformationDisplays": 0,
+	"departures": 0,
+	"messages": 0
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given no system status has been stored
+When StatusJson is called
+Then an UNAVAILABLE status is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(nil, redis.ErrNil)
+
+		api := NewApi(mockLogger(t), nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, `{
+	"status": "UNAVAILABLE",
+	"error": "Metrolink departures have never been loaded",
+	"consecutiveFailures": 0,
+	"passengerInformationDisplays": 0,
+	"departures": 0,
+	"messages": 0
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the system status cannot be read
+When StatusJson is called
+Then an UNAVAILABLE status is returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zapcore.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(nil, errors.New("dial tcp: connection refused"))
+
+		api := NewApi(logger, nil, nil, nil, nil, nil, nil, systemStatusGetter, givenCurrentTimeFunc(t), givenStaleDataThreshold(t), givenStaleDataThreshold(t), givenTimeLocation(t), false)
+
+		// When
+		rc, statusCode, err := api.StatusJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Contains(t, readJson(t, rc), `"error": "Metrolink departures system status cannot be read"`)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, "error getting Metrolink departures system status", observedLogs.All()[0].Message)
+	})
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"strconv"
 	"time"
 )
 
@@ -13,41 +16,64 @@ type MetrolinkDeparturesLoader struct {
 	departuresSource   repository.MetrolinkDeparturesFetcher
 	platformNamer      repository.PlatformNamer
 	departuresStorer   repository.MetrolinkDeparturesStorer
+	messagesStorer     repository.MetrolinkMessagesStorer
+	tlarefsStorer      repository.MetrolinkTlarefsStorer
+	systemStatusGetter repository.SystemStatusGetter
 	systemStatusSetter repository.SystemStatusSetter
 	currentTimeFunc    func() time.Time
 	staleDataThreshold time.Duration
 }
 
-func NewMetrolinkDeparturesLoader(logger *zap.Logger, departuresSource repository.MetrolinkDeparturesFetcher, platformNamer repository.PlatformNamer, departuresStorer repository.MetrolinkDeparturesStorer, systemStatusSetter repository.SystemStatusSetter, currentTimeFunc func() time.Time, staleDataThreshold time.Duration) *MetrolinkDeparturesLoader {
+func NewMetrolinkDeparturesLoader(logger *zap.Logger, departuresSource repository.MetrolinkDeparturesFetcher, platformNamer repository.PlatformNamer, departuresStorer repository.MetrolinkDeparturesStorer, messagesStorer repository.MetrolinkMessagesStorer, tlarefsStorer repository.MetrolinkTlarefsStorer, systemStatusGetter repository.SystemStatusGetter, systemStatusSetter repository.SystemStatusSetter, currentTimeFunc func() time.Time, staleDataThreshold time.Duration) *MetrolinkDeparturesLoader {
 	return &MetrolinkDeparturesLoader{
 		logger:             logger,
 		departuresSource:   departuresSource,
 		platformNamer:      platformNamer,
 		departuresStorer:   departuresStorer,
+		messagesStorer:     messagesStorer,
+		tlarefsStorer:      tlarefsStorer,
+		systemStatusGetter: systemStatusGetter,
 		systemStatusSetter: systemStatusSetter,
 		currentTimeFunc:    currentTimeFunc,
 		staleDataThreshold: staleDataThreshold,
 	}
 }
 
+// Load fetches departures and messages from the source and stores them as a new generation, along with the StopAreaCode
+// of each TLAREF in the source. The system status is only set to the new generation once everything has been stored,
+// so readers never see a partially stored snapshot and a failed load leaves the previous snapshot in place, with the
+// failure recorded in the system status.
 func (m *MetrolinkDeparturesLoader) Load(ctx context.Context) error {
+	attemptTime := m.currentTimeFunc()
+
 	departuresFromSource, err := m.departuresSource.Fetch(ctx)
 	if err != nil {
+		m.recordFailure(ctx, attemptTime, domain.SystemStatusErrorCategorySource)
 		return err
 	}
 
-	if err := m.systemStatusSetter.Set(ctx, departuresFromSource.LastUpdated); err != nil {
-		return err
-	}
+	generation := strconv.FormatInt(m.currentTimeFunc().UnixNano(), 10)
 
 	var departuresToStore []*domain.MetrolinkDeparture
 
 	for _, departure := range departuresFromSource.Departures {
-		if m.dataIsStale(departure) {
+		if m.dataIsStale(departure.LastUpdated) {
 			m.logger.Error("error with source data - stale data received", zap.String("atcoCode", departure.AtcoCode), zap.Time("lastUpdated", departure.LastUpdated), zap.Duration("staleDataThreshold", m.staleDataThreshold), zap.Duration("ageOfData", m.currentTimeFunc().Sub(departure.LastUpdated)))
 			continue
 		}
 
+		if err := parseDeparture(departure); err != nil {
+			m.logger.Error("error with source data - unrecognised departure", zap.Error(err), zap.String("atcoCode", departure.AtcoCode), zap.String("pidref", departure.Pidref))
+			continue
+		}
+
+		if carriages, ok := parseCarriages(departure.Carriages); ok {
+			departure.Carriages = carriages
+		} else {
+			m.logger.Warn("unrecognised carriages in source data", zap.String("atcoCode", departure.AtcoCode), zap.String("carriages", string(departure.Carriages)))
+			departure.Carriages = ""
+		}
+
 		platform, err := m.platformNamer.GetPlatformNameForAtcoCode(departure.AtcoCode)
 		if err != nil {
 			m.logger.Error("error getting platform name", zap.Error(err), zap.String("atcoCode", departure.AtcoCode))
@@ -58,9 +84,77 @@ func (m *MetrolinkDeparturesLoader) Load(ctx context.Context) error {
 		departuresToStore = append(departuresToStore, departure)
 	}
 
-	return m.departuresStorer.Store(ctx, departuresToStore)
+	if err := m.departuresStorer.Store(ctx, generation, departuresToStore); err != nil {
+		m.recordFailure(ctx, attemptTime, domain.SystemStatusErrorCategoryStorage)
+		return errors.Wrapf(err, "error storing departures for generation %s", generation)
+	}
+
+	var messagesToStore []*domain.MetrolinkMessage
+
+	for _, message := range departuresFromSource.Messages {
+		if m.dataIsStale(message.LastUpdated) {
+			m.logger.Error("error with source data - stale message received", zap.String("atcoCode", message.AtcoCode), zap.Time("lastUpdated", message.LastUpdated), zap.Duration("staleDataThreshold", m.staleDataThreshold), zap.Duration("ageOfData", m.currentTimeFunc().Sub(message.LastUpdated)))
+			continue
+		}
+
+		messagesToStore = append(messagesToStore, message)
+	}
+
+	if err := m.messagesStorer.Store(ctx, generation, messagesToStore); err != nil {
+		m.recordFailure(ctx, attemptTime, domain.SystemStatusErrorCategoryStorage)
+		return errors.Wrapf(err, "error storing messages for generation %s", generation)
+	}
+
+	// TLAREFs are not part of the generation, so a failure to store them is logged rather than failing the load
+	if tlarefs := stopAreaCodesByTlaref(departuresFromSource.Departures, departuresFromSource.Messages); len(tlarefs) > 0 {
+		if err := m.tlarefsStorer.StoreTlarefs(ctx, tlarefs); err != nil {
+			m.logger.Error("error storing TLAREFs", zap.Error(err))
+		}
+	}
+
+	if err := m.systemStatusSetter.Set(ctx, &domain.SystemStatus{
+		Generation:                   generation,
+		LastUpdated:                  departuresFromSource.LastUpdated,
+		LastAttempt:                  attemptTime,
+		LastSuccess:                  attemptTime,
+		PassengerInformationDisplays: departuresFromSource.PassengerInformationDisplays,
+		Departures:                   len(departuresToStore),
+		Messages:                     len(messagesToStore),
+	}); err != nil {
+		m.recordFailure(ctx, attemptTime, domain.SystemStatusErrorCategoryStorage)
+		return errors.Wrapf(err, "error setting system status for generation %s", generation)
+	}
+
+	return nil
+}
+
+// recordFailure adds a failed attempt to the system status, keeping the generation of the last successful load. Errors
+// are logged rather than returned, so that the error which caused the failure is the one reported by Load.
+//
+// The system status is read and then set, without a transaction, so when loads overlap (e.g. the server's ticker and
+// the data loader Lambda function writing to the same Redis) a failure recorded by one load can be overwritten by the
+// other, and ConsecutiveFailures may undercount. Run a single loader per store to keep the counts exact.
+func (m *MetrolinkDeparturesLoader) recordFailure(ctx context.Context, attemptTime time.Time, errorCategory domain.SystemStatusErrorCategory) {
+	systemStatus, err := m.systemStatusGetter.Get(ctx)
+	if err != nil {
+		if errors.Cause(err) != redis.ErrNil {
+			m.logger.Error("error getting system status to record failed load", zap.Error(err), zap.String("errorCategory", string(errorCategory)))
+			return
+		}
+
+		// No load has ever succeeded
+		systemStatus = &domain.SystemStatus{}
+	}
+
+	systemStatus.LastAttempt = attemptTime
+	systemStatus.ConsecutiveFailures++
+	systemStatus.LastErrorCategory = errorCategory
+
+	if err := m.systemStatusSetter.Set(ctx, systemStatus); err != nil {
+		m.logger.Error("error setting system status to record failed load", zap.Error(err), zap.String("errorCategory", string(errorCategory)))
+	}
 }
 
-func (m *MetrolinkDeparturesLoader) dataIsStale(departure *domain.MetrolinkDeparture) bool {
-	return departure.LastUpdated.Before(m.currentTimeFunc().Add(-m.staleDataThreshold))
+func (m *MetrolinkDeparturesLoader) dataIsStale(lastUpdated time.Time) bool {
+	return lastUpdated.Before(m.currentTimeFunc().Add(-m.staleDataThreshold))
 }
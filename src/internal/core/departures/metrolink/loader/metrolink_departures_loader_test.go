@@ -6,6 +6,7 @@ import (
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
 	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -30,6 +31,52 @@ func givenCurrentTimeFunction(t *testing.T) func() time.Time {
 	}
 }
 
+func givenGeneration(t *testing.T) string {
+	t.Helper()
+
+	return "1617143478000000000"
+}
+
+func givenSystemStatus(t *testing.T, departuresFromSource *domain.MetrolinkDepartures, departures int, messages int) *domain.SystemStatus {
+	t.Helper()
+
+	return &domain.SystemStatus{
+		Generation:                   givenGeneration(t),
+		LastUpdated:                  departuresFromSource.LastUpdated,
+		LastAttempt:                  givenCurrentTimeFunction(t)(),
+		LastSuccess:                  givenCurrentTimeFunction(t)(),
+		PassengerInformationDisplays: departuresFromSource.PassengerInformationDisplays,
+		Departures:                   departures,
+		Messages:                     messages,
+	}
+}
+
+func givenPreviousSystemStatus(t *testing.T) *domain.SystemStatus {
+	t.Helper()
+
+	return &domain.SystemStatus{
+		Generation:                   "1617143448000000000",
+		LastUpdated:                  time.Date(2021, time.March, 30, 22, 30, 46, 0, time.UTC),
+		LastAttempt:                  time.Date(2021, time.March, 30, 22, 31, 8, 0, time.UTC),
+		LastSuccess:                  time.Date(2021, time.March, 30, 22, 30, 48, 0, time.UTC),
+		ConsecutiveFailures:          1,
+		LastErrorCategory:            domain.SystemStatusErrorCategorySource,
+		PassengerInformationDisplays: 2,
+		Departures:                   2,
+	}
+}
+
+func givenFailedSystemStatus(t *testing.T, errorCategory domain.SystemStatusErrorCategory) *domain.SystemStatus {
+	t.Helper()
+
+	systemStatus := givenPreviousSystemStatus(t)
+	systemStatus.LastAttempt = givenCurrentTimeFunction(t)()
+	systemStatus.ConsecutiveFailures = 2
+	systemStatus.LastErrorCategory = errorCategory
+
+	return systemStatus
+}
+
 func givenStaleDataThreshold(t *testing.T) time.Duration {
 	t.Helper()
 
@@ -74,7 +121,8 @@ func givenMetrolinkDeparturesFromSource(t *testing.T) *domain.MetrolinkDeparture
 				LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
 			},
 		},
-		LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
+		PassengerInformationDisplays: 2,
+		LastUpdated:                  givenLastUpdatedTimeWithinThreshold(t),
 	}
 }
 
@@ -104,7 +152,8 @@ func givenStaleMetrolinkDeparturesFromSource(t *testing.T) *domain.MetrolinkDepa
 				LastUpdated: givenLastUpdatedTimeOutsideOfThreshold(t),
 			},
 		},
-		LastUpdated: givenLastUpdatedTimeOutsideOfThreshold(t),
+		PassengerInformationDisplays: 2,
+		LastUpdated:                  givenLastUpdatedTimeOutsideOfThreshold(t),
 	}
 }
 
@@ -121,6 +170,7 @@ func givenMetrolinkDeparturesFromSourceWithPlatformsExpectation(t *testing.T) []
 			Carriages:   "Single",
 			Status:      "Due",
 			Wait:        "2",
+			WaitMinutes: 2,
 			Platform:    &platform,
 			LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
 		},
@@ -131,17 +181,40 @@ func givenMetrolinkDeparturesFromSourceWithPlatformsExpectation(t *testing.T) []
 			Carriages:   "Double",
 			Status:      "Due",
 			Wait:        "5",
+			WaitMinutes: 5,
 			Platform:    &platform,
 			LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
 		},
 	}
 }
 
+func givenMetrolinkDeparturesWithMessagesFromSource(t *testing.T) *domain.MetrolinkDepartures {
+	t.Helper()
+
+	metrolinkDepartures := givenMetrolinkDeparturesFromSource(t)
+	metrolinkDepartures.Messages = []*domain.MetrolinkMessage{
+		{
+			AtcoCode:    "9400ZZMASTP1",
+			Line:        "Eccles",
+			Message:     "Engineering works this weekend",
+			LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
+		},
+		{
+			AtcoCode:    "9400ZZMASTP4",
+			Line:        "Eccles",
+			Message:     "Engineering works this weekend",
+			LastUpdated: givenLastUpdatedTimeOutsideOfThreshold(t),
+		},
+	}
+
+	return metrolinkDepartures
+}
+
 func TestMetrolinkDeparturesLoader_Load(t *testing.T) {
 	t.Run(`Given Metrolink Departures from a source
 When Load is executed
-Then the departures are stored in a repository
-And the system status is stored in a repository`, func(t *testing.T) {
+Then the departures are stored in a repository under a new generation
+And the system status is set to the new generation after the departures are stored`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -162,15 +235,23 @@ And the system status is stored in a repository`, func(t *testing.T) {
 
 		departuresFromSourceWithPlatformsExpectation := givenMetrolinkDeparturesFromSourceWithPlatformsExpectation(t)
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
-		departuresStorer.EXPECT().Store(ctx, departuresFromSourceWithPlatformsExpectation).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
 
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
-		systemStatusSetter.EXPECT().Set(ctx, departuresFromSource.LastUpdated).Return(nil)
+
+		gomock.InOrder(
+			departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSourceWithPlatformsExpectation).Return(nil),
+			messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil),
+			systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0)).Return(nil),
+		)
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
@@ -181,7 +262,8 @@ And the system status is stored in a repository`, func(t *testing.T) {
 
 	t.Run(`Given Metrolink Departures cannot be fetched from a source
 When Load is executed
-Then an error is returned`, func(t *testing.T) {
+Then an error is returned
+And a source failure is recorded in the system status`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -197,12 +279,18 @@ Then an error is returned`, func(t *testing.T) {
 		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
 
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(givenPreviousSystemStatus(t), nil)
+
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenFailedSystemStatus(t, domain.SystemStatusErrorCategorySource)).Return(nil)
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
@@ -234,15 +322,20 @@ And stale records are not stored`, func(t *testing.T) {
 		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
 
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
-		departuresStorer.EXPECT().Store(ctx, nil).Return(nil)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
 
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
-		systemStatusSetter.EXPECT().Set(ctx, departuresFromSource.LastUpdated).Return(nil)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 0, 0)).Return(nil)
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
@@ -288,15 +381,20 @@ Then an error is logged`, func(t *testing.T) {
 		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, platformNamerErr)
 
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
-		departuresStorer.EXPECT().Store(ctx, departuresFromSource.Departures).Return(nil)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Departures).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
 
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
-		systemStatusSetter.EXPECT().Set(ctx, departuresFromSource.LastUpdated)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0))
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
@@ -316,9 +414,81 @@ Then an error is logged`, func(t *testing.T) {
 		}
 	})
 
+	t.Run(`Given Metrolink departures with waits, statuses and carriages in different forms
+When Load is executed
+Then the waits are parsed and the statuses and carriages are normalised before the departures are stored
+And departures with an unrecognised wait or status are not stored
+And errors are logged for departures which are not stored`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zapcore.DebugLevel)
+		logger := zap.New(zapCore)
+
+		departuresFromSource := &domain.MetrolinkDepartures{
+			Departures: []*domain.MetrolinkDeparture{
+				{AtcoCode: "9400ZZMASTP1", Order: 0, Destination: "Victoria", Carriages: "double", Status: "due", Wait: "DELAY", LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+				{AtcoCode: "9400ZZMASTP1", Order: 1, Destination: "Rochdale", Carriages: "Single", Status: "Due", Wait: "--", LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+				{AtcoCode: "9400ZZMASTP1", Order: 2, Destination: "Bury", Carriages: "Single", Status: "Boarding", Wait: "3", LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+				{AtcoCode: "9400ZZMASTP1", Order: 3, Destination: "Shaw and Crompton", Carriages: "Triple", Status: "Arrived", Wait: " 12 ", LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+			},
+			PassengerInformationDisplays: 1,
+			LastUpdated:                  givenLastUpdatedTimeWithinThreshold(t),
+		}
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), []*domain.MetrolinkDeparture{
+			{AtcoCode: "9400ZZMASTP1", Order: 0, Destination: "Victoria", Carriages: domain.MetrolinkCarriagesDouble, Status: domain.MetrolinkDepartureStatusDue, Wait: "DELAY", Delayed: true, LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+			{AtcoCode: "9400ZZMASTP1", Order: 3, Destination: "Shaw and Crompton", Carriages: "", Status: domain.MetrolinkDepartureStatusArrived, Wait: " 12 ", WaitMinutes: 12, LastUpdated: givenLastUpdatedTimeWithinThreshold(t)},
+		}).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0)).Return(nil)
+
+		currentTimeFunc := givenCurrentTimeFunction(t)
+		staleDataThreshold := givenStaleDataThreshold(t)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, 3, observedLogs.Len())
+		loggedItems := observedLogs.TakeAll()
+
+		assert.Equal(t, zapcore.ErrorLevel, loggedItems[0].Level)
+		assert.Equal(t, "error with source data - unrecognised departure", loggedItems[0].Message)
+		assert.Equal(t, `invalid wait "--"`, loggedItems[0].Context[0].Interface.(error).Error())
+
+		assert.Equal(t, zapcore.ErrorLevel, loggedItems[1].Level)
+		assert.Equal(t, "error with source data - unrecognised departure", loggedItems[1].Message)
+		assert.Equal(t, `invalid status "Boarding"`, loggedItems[1].Context[0].Interface.(error).Error())
+
+		assert.Equal(t, zapcore.WarnLevel, loggedItems[2].Level)
+		assert.Equal(t, "unrecognised carriages in source data", loggedItems[2].Message)
+	})
+
 	t.Run(`Given system status cannot be stored
 When Load is executed
-Then an error is returned`, func(t *testing.T) {
+Then an error is returned
+And a storage failure is recorded in the system status without changing the generation`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -333,28 +503,40 @@ Then an error is returned`, func(t *testing.T) {
 		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
 
 		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
 
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Departures).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(givenPreviousSystemStatus(t), nil)
 
 		systemStatusErr := errors.New("FUBAR")
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
-		systemStatusSetter.EXPECT().Set(ctx, departuresFromSource.LastUpdated).Return(systemStatusErr)
+		gomock.InOrder(
+			systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0)).Return(systemStatusErr),
+			systemStatusSetter.EXPECT().Set(ctx, givenFailedSystemStatus(t, domain.SystemStatusErrorCategoryStorage)).Return(nil),
+		)
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
 
 		// Then
-		assert.Equal(t, systemStatusErr, err)
+		assert.Equal(t, systemStatusErr, errors.Cause(err))
 	})
 
 	t.Run(`Given Metrolink Departures cannot be stored
 When Load is executed
-Then an error is returned`, func(t *testing.T) {
+Then an error is returned
+And a storage failure is recorded in the system status without changing the generation`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -376,20 +558,353 @@ Then an error is returned`, func(t *testing.T) {
 		departuresStorerErr := errors.New("FUBAR")
 		departuresFromSourceWithPlatformsExpectation := givenMetrolinkDeparturesFromSourceWithPlatformsExpectation(t)
 		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
-		departuresStorer.EXPECT().Store(ctx, departuresFromSourceWithPlatformsExpectation).Return(departuresStorerErr)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSourceWithPlatformsExpectation).Return(departuresStorerErr)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(givenPreviousSystemStatus(t), nil)
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenFailedSystemStatus(t, domain.SystemStatusErrorCategoryStorage)).Return(nil)
+
+		currentTimeFunc := givenCurrentTimeFunction(t)
+		staleDataThreshold := givenStaleDataThreshold(t)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Equal(t, departuresStorerErr, errors.Cause(err))
+	})
+
+	t.Run(`Given Metrolink Departures with messages from a source
+When Load is executed
+Then messages which are not stale are stored in a repository`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zapcore.DebugLevel)
+		logger := zap.New(zapCore)
+
+		departuresFromSource := givenMetrolinkDeparturesWithMessagesFromSource(t)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Departures).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Messages[:1]).Return(nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 1)).Return(nil)
+
+		currentTimeFunc := givenCurrentTimeFunction(t)
+		staleDataThreshold := givenStaleDataThreshold(t)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		loggedItems := observedLogs.TakeAll()
+		assert.Equal(t, zapcore.ErrorLevel, loggedItems[0].Level)
+		assert.Equal(t, "error with source data - stale message received", loggedItems[0].Message)
+		assert.Equal(t, "atcoCode", loggedItems[0].Context[0].Key)
+		assert.Equal(t, "9400ZZMASTP4", loggedItems[0].Context[0].String)
+	})
+
+	t.Run(`Given Metrolink messages cannot be stored
+When Load is executed
+Then an error is returned
+And a storage failure is recorded in the system status without changing the generation`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		departuresFromSource := givenMetrolinkDeparturesFromSource(t)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Departures).Return(nil)
+
+		messagesStorerErr := errors.New("FUBAR")
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(messagesStorerErr)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(givenPreviousSystemStatus(t), nil)
 
 		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
-		systemStatusSetter.EXPECT().Set(ctx, departuresFromSource.LastUpdated).Return(nil)
+		systemStatusSetter.EXPECT().Set(ctx, givenFailedSystemStatus(t, domain.SystemStatusErrorCategoryStorage)).Return(nil)
 
 		currentTimeFunc := givenCurrentTimeFunction(t)
 		staleDataThreshold := givenStaleDataThreshold(t)
 
-		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
 
 		// When
 		err := metrolinkDeparturesLoader.Load(ctx)
 
 		// Then
-		assert.Equal(t, departuresStorerErr, err)
+		assert.Equal(t, messagesStorerErr, errors.Cause(err))
+	})
+
+	t.Run(`Given Metrolink Departures cannot be fetched from a source
+And no load has ever succeeded
+When Load is executed
+Then an error is returned
+And the first failure is recorded in the system status`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcherErr := errors.New("FUBAR")
+		fetcher.EXPECT().Fetch(ctx).Return(nil, fetcherErr)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(nil, redis.ErrNil)
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, &domain.SystemStatus{
+			LastAttempt:         givenCurrentTimeFunction(t)(),
+			ConsecutiveFailures: 1,
+			LastErrorCategory:   domain.SystemStatusErrorCategorySource,
+		}).Return(nil)
+
+		currentTimeFunc := givenCurrentTimeFunction(t)
+		staleDataThreshold := givenStaleDataThreshold(t)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Equal(t, fetcherErr, err)
+	})
+
+	t.Run(`Given Metrolink Departures cannot be fetched from a source
+And the system status cannot be read
+When Load is executed
+Then the fetch error is returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zapcore.DebugLevel)
+		logger := zap.New(zapCore)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcherErr := errors.New("FUBAR")
+		fetcher.EXPECT().Fetch(ctx).Return(nil, fetcherErr)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(nil, errors.New("connection refused"))
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+
+		currentTimeFunc := givenCurrentTimeFunction(t)
+		staleDataThreshold := givenStaleDataThreshold(t)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, mock_repository.NewMockMetrolinkTlarefsStorer(ctrl), systemStatusGetter, systemStatusSetter, currentTimeFunc, staleDataThreshold)
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Equal(t, fetcherErr, err)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		loggedItems := observedLogs.TakeAll()
+		assert.Equal(t, zapcore.ErrorLevel, loggedItems[0].Level)
+		assert.Equal(t, "error getting system status to record failed load", loggedItems[0].Message)
+	})
+
+	t.Run(`Given Metrolink Departures with TLAREFs from a source
+When Load is executed
+Then the StopAreaCode of each TLAREF is stored before the system status is set`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		departuresFromSource := givenMetrolinkDeparturesFromSource(t)
+		for _, departure := range departuresFromSource.Departures {
+			departure.Tlaref = "SPS"
+		}
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		tlarefsStorer := mock_repository.NewMockMetrolinkTlarefsStorer(ctrl)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+
+		gomock.InOrder(
+			departuresStorer.EXPECT().Store(ctx, givenGeneration(t), gomock.Any()).Return(nil),
+			messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil),
+			tlarefsStorer.EXPECT().StoreTlarefs(ctx, map[string]string{"SPS": "940GZZMASTP"}).Return(nil),
+			systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0)).Return(nil),
+		)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, tlarefsStorer, systemStatusGetter, systemStatusSetter, givenCurrentTimeFunction(t), givenStaleDataThreshold(t))
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given Metrolink Departures from a source
+And messages with TLAREFs for a stop without departures
+When Load is executed
+Then the StopAreaCode of each TLAREF of the messages is stored`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		departuresFromSource := givenMetrolinkDeparturesFromSource(t)
+		departuresFromSource.Messages = []*domain.MetrolinkMessage{
+			{
+				AtcoCode:    "9400ZZMAPIC1",
+				Line:        "Eccles",
+				Message:     "Piccadilly is closed this weekend",
+				LastUpdated: givenLastUpdatedTimeWithinThreshold(t),
+				Tlaref:      "PIC",
+			},
+		}
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), gomock.Any()).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), departuresFromSource.Messages).Return(nil)
+
+		tlarefsStorer := mock_repository.NewMockMetrolinkTlarefsStorer(ctrl)
+		tlarefsStorer.EXPECT().StoreTlarefs(ctx, map[string]string{"PIC": "940GZZMAPIC"}).Return(nil)
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 1)).Return(nil)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, tlarefsStorer, mock_repository.NewMockSystemStatusGetter(ctrl), systemStatusSetter, givenCurrentTimeFunction(t), givenStaleDataThreshold(t))
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given Metrolink Departures with TLAREFs from a source
+And the TLAREFs cannot be stored
+When Load is executed
+Then the system status is set to the new generation
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zapcore.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		departuresFromSource := givenMetrolinkDeparturesFromSource(t)
+		for _, departure := range departuresFromSource.Departures {
+			departure.Tlaref = "SPS"
+		}
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Times(2).Return(nil, nil)
+
+		departuresStorer := mock_repository.NewMockMetrolinkDeparturesStorer(ctrl)
+		departuresStorer.EXPECT().Store(ctx, givenGeneration(t), gomock.Any()).Return(nil)
+
+		messagesStorer := mock_repository.NewMockMetrolinkMessagesStorer(ctrl)
+		messagesStorer.EXPECT().Store(ctx, givenGeneration(t), nil).Return(nil)
+
+		tlarefsStorer := mock_repository.NewMockMetrolinkTlarefsStorer(ctrl)
+		tlarefsStorer.EXPECT().StoreTlarefs(ctx, gomock.Any()).Return(errors.New("FUBAR"))
+
+		systemStatusSetter := mock_repository.NewMockSystemStatusSetter(ctrl)
+		systemStatusSetter.EXPECT().Set(ctx, givenSystemStatus(t, departuresFromSource, 2, 0)).Return(nil)
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresStorer, messagesStorer, tlarefsStorer, mock_repository.NewMockSystemStatusGetter(ctrl), systemStatusSetter, givenCurrentTimeFunction(t), givenStaleDataThreshold(t))
+
+		// When
+		err := metrolinkDeparturesLoader.Load(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, "error storing TLAREFs", observedLogs.All()[0].Message)
 	})
 }
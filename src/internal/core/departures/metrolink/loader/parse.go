@@ -0,0 +1,62 @@
+package loader
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+// delayedWait is the wait shown on a passenger information display when a tram is delayed
+const delayedWait = "DELAY"
+
+// parseDeparture parses the wait shown on the display into minutes and a delayed flag, and normalises the status and
+// carriages, so that readers of stored departures don't have to. An error is returned when the wait or status is not
+// recognised, as the departure cannot then be ordered.
+func parseDeparture(departure *domain.MetrolinkDeparture) error {
+	wait := strings.TrimSpace(departure.Wait)
+
+	if strings.EqualFold(wait, delayedWait) {
+		departure.Delayed = true
+		departure.WaitMinutes = 0
+	} else {
+		minutes, err := strconv.Atoi(wait)
+		if err != nil || minutes < 0 {
+			return errors.Errorf("invalid wait %q", departure.Wait)
+		}
+
+		departure.Delayed = false
+		departure.WaitMinutes = minutes
+	}
+
+	status, err := parseStatus(departure.Status)
+	if err != nil {
+		return err
+	}
+
+	departure.Status = status
+
+	return nil
+}
+
+func parseStatus(status domain.MetrolinkDepartureStatus) (domain.MetrolinkDepartureStatus, error) {
+	for _, s := range []domain.MetrolinkDepartureStatus{domain.MetrolinkDepartureStatusDeparting, domain.MetrolinkDepartureStatusArrived, domain.MetrolinkDepartureStatusDue} {
+		if strings.EqualFold(strings.TrimSpace(string(status)), string(s)) {
+			return s, nil
+		}
+	}
+
+	return "", errors.Errorf("invalid status %q", status)
+}
+
+// parseCarriages returns false when the carriages are not recognised. Unlike the wait and status, the carriages are not
+// needed to order departures, so a departure with unrecognised carriages is still stored.
+func parseCarriages(carriages domain.MetrolinkCarriages) (domain.MetrolinkCarriages, bool) {
+	for _, c := range []domain.MetrolinkCarriages{domain.MetrolinkCarriagesSingle, domain.MetrolinkCarriagesDouble} {
+		if strings.EqualFold(strings.TrimSpace(string(carriages)), string(c)) {
+			return c, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,26 @@
+package loader
+
+import (
+	"github.com/pkg/errors"
+	"time"
+)
+
+// ValidateGenerationTimeToLive returns an error when departures or messages stored with timeToLive could expire while
+// their generation is still served. The system status points at a generation until a later load succeeds, and it is
+// served until its data is older than staleDataThreshold, or degradedDataThreshold when that is longer, so the time to
+// live must cover the longer threshold and one more loadInterval in case a load is missed. Otherwise degraded mode would
+// serve stale responses without any departures once the generation had expired.
+func ValidateGenerationTimeToLive(name string, timeToLive time.Duration, staleDataThreshold time.Duration, degradedDataThreshold time.Duration, loadInterval time.Duration) error {
+	servedDataThreshold := staleDataThreshold
+	if degradedDataThreshold > servedDataThreshold {
+		servedDataThreshold = degradedDataThreshold
+	}
+
+	minimumTimeToLive := servedDataThreshold + loadInterval
+
+	if timeToLive < minimumTimeToLive {
+		return errors.Errorf("%s of %s is shorter than %s, the longer of the stale and degraded data thresholds plus one load interval", name, timeToLive, minimumTimeToLive)
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package loader_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/departures/metrolink/loader"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestValidateGenerationTimeToLive(t *testing.T) {
+	t.Run(`Given a time to live of the stale data threshold plus one load interval
+When ValidateGenerationTimeToLive is called
+Then no error is returned`, func(t *testing.T) {
+		// When
+		err := loader.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", 40*time.Second, 30*time.Second, 0, 10*time.Second)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given a time to live shorter than the stale data threshold plus one load interval
+When ValidateGenerationTimeToLive is called
+Then an error naming the time to live is returned`, func(t *testing.T) {
+		// When
+		err := loader.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", 15*time.Second, 30*time.Second, 0, 10*time.Second)
+
+		// Then
+		assert.EqualError(t, err, "REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE of 15s is shorter than 40s, the longer of the stale and degraded data thresholds plus one load interval")
+	})
+
+	t.Run(`Given a degraded data threshold longer than the stale data threshold
+And a time to live which covers the stale data threshold but not the degraded data threshold
+When ValidateGenerationTimeToLive is called
+Then an error is returned, because the generation would expire while its stale data is served`, func(t *testing.T) {
+		// When
+		err := loader.ValidateGenerationTimeToLive("REDIS_METROLINK_MESSAGES_TIME_TO_LIVE", time.Minute, 30*time.Second, 5*time.Minute, 10*time.Second)
+
+		// Then
+		assert.EqualError(t, err, "REDIS_METROLINK_MESSAGES_TIME_TO_LIVE of 1m0s is shorter than 5m10s, the longer of the stale and degraded data thresholds plus one load interval")
+	})
+
+	t.Run(`Given departures stored in a generation with a valid time to live
+When the next load is missed
+Then the departures of the generation in the system status are still stored until the data is stale`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		now := givenCurrentTimeFunction(t)()
+		currentTimeFunc := func() time.Time {
+			return now
+		}
+
+		staleDataThreshold := givenStaleDataThreshold(t)
+		loadInterval := 10 * time.Second
+		timeToLive := staleDataThreshold + loadInterval
+
+		assert.Nil(t, loader.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", timeToLive, staleDataThreshold, 0, loadInterval))
+
+		departuresFromSource := givenMetrolinkDeparturesFromSource(t)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		gomock.InOrder(
+			fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil),
+			fetcher.EXPECT().Fetch(ctx).Return(nil, errors.New("FUBAR")),
+		)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Return(nil, nil).AnyTimes()
+
+		store := memory.NewStore(logger, currentTimeFunc)
+		departuresRepository := memory.NewMetrolinkDeparturesRepository(logger, store, "metrolink_departures", timeToLive)
+		messagesRepository := memory.NewMetrolinkMessagesRepository(logger, store, "metrolink_messages", timeToLive)
+		tlarefsRepository := memory.NewMetrolinkTlarefsRepository(logger, store, "metrolink_tlarefs", 24*time.Hour)
+		systemStatusRepository := memory.NewMetrolinkDeparturesSystemStatusRepository(logger, store, "metrolink_departures_service_status")
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresRepository, messagesRepository, tlarefsRepository, systemStatusRepository, systemStatusRepository, currentTimeFunc, staleDataThreshold)
+
+		assert.Nil(t, metrolinkDeparturesLoader.Load(ctx))
+
+		// When
+		now = now.Add(loadInterval)
+
+		assert.NotNil(t, metrolinkDeparturesLoader.Load(ctx))
+
+		// Then
+		now = departuresFromSource.LastUpdated.Add(staleDataThreshold)
+
+		systemStatus, err := systemStatusRepository.Get(ctx)
+		assert.Nil(t, err)
+
+		departures, err := departuresRepository.GetMulti(ctx, systemStatus.Generation, []string{"9400ZZMASTP1"})
+		assert.Nil(t, err)
+		assert.Len(t, departures, 2)
+	})
+
+	t.Run(`Given departures stored in a generation with a time to live which is valid for a degraded data threshold
+When loads are missed until the data is stale
+Then the departures and messages of the generation in the system status are still stored until the data is outdated`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		now := givenCurrentTimeFunction(t)()
+		currentTimeFunc := func() time.Time {
+			return now
+		}
+
+		staleDataThreshold := givenStaleDataThreshold(t)
+		degradedDataThreshold := 5 * time.Minute
+		loadInterval := 10 * time.Second
+		timeToLive := degradedDataThreshold + loadInterval
+
+		assert.Nil(t, loader.ValidateGenerationTimeToLive("REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE", timeToLive, staleDataThreshold, degradedDataThreshold, loadInterval))
+
+		departuresFromSource := givenMetrolinkDeparturesWithMessagesFromSource(t)
+
+		fetcher := mock_repository.NewMockMetrolinkDeparturesFetcher(ctrl)
+		gomock.InOrder(
+			fetcher.EXPECT().Fetch(ctx).Return(departuresFromSource, nil),
+			fetcher.EXPECT().Fetch(ctx).Return(nil, errors.New("FUBAR")).AnyTimes(),
+		)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+		platformNamer.EXPECT().GetPlatformNameForAtcoCode("9400ZZMASTP1").Return(nil, nil).AnyTimes()
+
+		store := memory.NewStore(logger, currentTimeFunc)
+		departuresRepository := memory.NewMetrolinkDeparturesRepository(logger, store, "metrolink_departures", timeToLive)
+		messagesRepository := memory.NewMetrolinkMessagesRepository(logger, store, "metrolink_messages", timeToLive)
+		tlarefsRepository := memory.NewMetrolinkTlarefsRepository(logger, store, "metrolink_tlarefs", 24*time.Hour)
+		systemStatusRepository := memory.NewMetrolinkDeparturesSystemStatusRepository(logger, store, "metrolink_departures_service_status")
+
+		metrolinkDeparturesLoader := loader.NewMetrolinkDeparturesLoader(logger, fetcher, platformNamer, departuresRepository, messagesRepository, tlarefsRepository, systemStatusRepository, systemStatusRepository, currentTimeFunc, staleDataThreshold)
+
+		assert.Nil(t, metrolinkDeparturesLoader.Load(ctx))
+
+		// When
+		for now.Sub(departuresFromSource.LastUpdated) <= staleDataThreshold {
+			now = now.Add(loadInterval)
+
+			assert.NotNil(t, metrolinkDeparturesLoader.Load(ctx))
+		}
+
+		// Then
+		now = departuresFromSource.LastUpdated.Add(degradedDataThreshold)
+
+		systemStatus, err := systemStatusRepository.Get(ctx)
+		assert.Nil(t, err)
+
+		departures, err := departuresRepository.GetMulti(ctx, systemStatus.Generation, []string{"9400ZZMASTP1"})
+		assert.Nil(t, err)
+		assert.Len(t, departures, 2)
+
+		messages, err := messagesRepository.Get(ctx, systemStatus.Generation, []string{"9400ZZMASTP1"})
+		assert.Nil(t, err)
+		assert.Len(t, messages, 1)
+	})
+}
@@ -0,0 +1,42 @@
+package loader
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"regexp"
+)
+
+// metrolinkAtcoCodeRegexp matches the AtcoCode of a Metrolink platform. The StopAreaCode of the stop is the AtcoCode with
+// 940G in place of 9400 and without the platform number, e.g. 940GZZMAPIC for 9400ZZMAPIC1.
+var metrolinkAtcoCodeRegexp = regexp.MustCompile("^9400(ZZMA[A-Z]{3})[1-4]$")
+
+// stopAreaCodesByTlaref returns the StopAreaCode of the stop of each TLAREF in the departures and messages. The TLAREF
+// is not always the same as the code in the StopAreaCode, e.g. Deansgate-Castlefield is DCF in the departures feed and
+// 940GZZMAGMX in NaPTAN. Messages are included so that a stop whose passenger information displays only show a message,
+// e.g. while it is closed, can still be requested by TLAREF. Departures and messages without a TLAREF or Metrolink
+// AtcoCode are skipped.
+func stopAreaCodesByTlaref(departures []*domain.MetrolinkDeparture, messages []*domain.MetrolinkMessage) map[string]string {
+	stopAreaCodes := make(map[string]string)
+
+	for _, departure := range departures {
+		addStopAreaCodeForTlaref(stopAreaCodes, departure.Tlaref, departure.AtcoCode)
+	}
+
+	for _, message := range messages {
+		addStopAreaCodeForTlaref(stopAreaCodes, message.Tlaref, message.AtcoCode)
+	}
+
+	return stopAreaCodes
+}
+
+func addStopAreaCodeForTlaref(stopAreaCodes map[string]string, tlaref string, atcoCode string) {
+	if !domain.IsTlaref(tlaref) {
+		return
+	}
+
+	match := metrolinkAtcoCodeRegexp.FindStringSubmatch(atcoCode)
+	if match == nil {
+		return
+	}
+
+	stopAreaCodes[tlaref] = "940G" + match[1]
+}
@@ -3,10 +3,55 @@ package core
 import (
 	"context"
 	"io"
+	"time"
 )
 
 type StopAreaDeparturesJsoner interface {
-	Json(ctx context.Context, stopAreaCode string) (io.ReadCloser, int, error)
+	Json(ctx context.Context, stopAreaCode string, queryParameters map[string]string) (io.ReadCloser, int, error)
+}
+
+// StopAreaDeparturesJsonerFunc allows an ordinary function, such as an alternative version of the departures API, to
+// be used as a StopAreaDeparturesJsoner
+type StopAreaDeparturesJsonerFunc func(ctx context.Context, stopAreaCode string, queryParameters map[string]string) (io.ReadCloser, int, error)
+
+func (f StopAreaDeparturesJsonerFunc) Json(ctx context.Context, stopAreaCode string, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	return f(ctx, stopAreaCode, queryParameters)
+}
+
+// StaleDataJson is a JSON response containing departures which are older than they should be. Departures which are
+// stale, but not yet outdated, are served while the source of departures is unavailable rather than returning an error,
+// and transports can use DataAge to warn clients that the response is stale.
+type StaleDataJson interface {
+	io.ReadCloser
+	DataAge() time.Duration
+}
+
+type BatchDeparturesJsoner interface {
+	BatchJson(ctx context.Context, stopAreaCodesOrAtcoCodes []string, queryParameters map[string]string) (io.ReadCloser, int, error)
+}
+
+type StopAreaMessagesJsoner interface {
+	MessagesJson(ctx context.Context, stopAreaCodeOrAtcoCode string) (io.ReadCloser, int, error)
+}
+
+type LineMessagesJsoner interface {
+	LineMessagesJson(ctx context.Context, line string) (io.ReadCloser, int, error)
+}
+
+type StopDirectoryJsoner interface {
+	StopsJson(ctx context.Context) (io.ReadCloser, int, error)
+}
+
+type NearbyStopsJsoner interface {
+	NearbyJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error)
+}
+
+type StopSearchJsoner interface {
+	SearchJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error)
+}
+
+type SystemStatusJsoner interface {
+	StatusJson(ctx context.Context) (io.ReadCloser, int, error)
 }
 
 type EventScheduler interface {
@@ -0,0 +1,182 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sort"
+)
+
+// validateStopsInArea compares the fetched stops in area with those already stored, logs and publishes a report of the
+// changes, and returns an error when the fetched stops in area should not replace those stored, e.g. because the NaPTAN
+// download was truncated. The first load, when nothing is stored, is always accepted.
+func (s *StopsInAreaLoader) validateStopsInArea(ctx context.Context, stopsInAreaMap map[string][]string) error {
+	storedStopsInAreaMap, err := s.stopsInAreaLister.GetAllStopsInArea(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error getting stored stops in area")
+	}
+
+	report := stopsInAreaChangeReport(storedStopsInAreaMap, stopsInAreaMap)
+	report.Reason = s.rejectionReason(report)
+	report.Accepted = report.Reason == ""
+
+	s.logChangeReport(report)
+
+	if s.changeReportPublisher != nil {
+		if err := s.changeReportPublisher.PublishStopsInAreaChangeReport(ctx, report); err != nil {
+			s.logger.Error("error publishing stops in area change report", zap.Error(err))
+		}
+	}
+
+	if !report.Accepted {
+		return errors.Errorf("refusing to store stops in area: %s", report.Reason)
+	}
+
+	return nil
+}
+
+// rejectionReason returns why the changes in a report should not be stored, or an empty string if they should
+func (s *StopsInAreaLoader) rejectionReason(report *domain.StopsInAreaChangeReport) string {
+	if shrinkPercentage := shrinkPercentage(report.StoredStopAreas, report.FetchedStopAreas); shrinkPercentage > s.maximumShrinkPercentage {
+		return fmt.Sprintf("stop areas would shrink by %.1f%% from %d to %d, more than the maximum of %.1f%%", shrinkPercentage, report.StoredStopAreas, report.FetchedStopAreas, s.maximumShrinkPercentage)
+	}
+
+	if shrinkPercentage := shrinkPercentage(report.StoredStops, report.FetchedStops); shrinkPercentage > s.maximumShrinkPercentage {
+		return fmt.Sprintf("stops in area would shrink by %.1f%% from %d to %d, more than the maximum of %.1f%%", shrinkPercentage, report.StoredStops, report.FetchedStops, s.maximumShrinkPercentage)
+	}
+
+	return ""
+}
+
+func (s *StopsInAreaLoader) logChangeReport(report *domain.StopsInAreaChangeReport) {
+	fields := []zap.Field{
+		zap.Bool("accepted", report.Accepted),
+		zap.Int("storedStopAreas", report.StoredStopAreas),
+		zap.Int("fetchedStopAreas", report.FetchedStopAreas),
+		zap.Int("storedStops", report.StoredStops),
+		zap.Int("fetchedStops", report.FetchedStops),
+		zap.Strings("addedStopAreaCodes", report.AddedStopAreaCodes),
+		zap.Strings("removedStopAreaCodes", report.RemovedStopAreaCodes),
+		zap.Strings("addedAtcoCodes", report.AddedAtcoCodes),
+		zap.Strings("removedAtcoCodes", report.RemovedAtcoCodes),
+		zap.Int("changedStopAreas", len(report.ChangedStopAreas)),
+	}
+
+	if !report.Accepted {
+		s.logger.Warn("rejected NaPTAN stops in area changes", append(fields, zap.String("reason", report.Reason))...)
+		return
+	}
+
+	s.logger.Info("NaPTAN stops in area changes", fields...)
+}
+
+// shrinkPercentage returns the percentage by which a count would fall from stored to fetched, or 0 if it would not fall
+func shrinkPercentage(stored int, fetched int) float64 {
+	if stored == 0 || fetched >= stored {
+		return 0
+	}
+
+	return float64(stored-fetched) / float64(stored) * 100
+}
+
+// stopsInAreaChangeReport returns the differences between the stored and fetched stops in area
+func stopsInAreaChangeReport(stored map[string][]string, fetched map[string][]string) *domain.StopsInAreaChangeReport {
+	report := &domain.StopsInAreaChangeReport{
+		StoredStopAreas:      len(stored),
+		FetchedStopAreas:     len(fetched),
+		StoredStops:          countStopsInArea(stored),
+		FetchedStops:         countStopsInArea(fetched),
+		AddedStopAreaCodes:   difference(stopAreaCodes(fetched), stopAreaCodes(stored)),
+		RemovedStopAreaCodes: difference(stopAreaCodes(stored), stopAreaCodes(fetched)),
+		AddedAtcoCodes:       difference(atcoCodes(fetched), atcoCodes(stored)),
+		RemovedAtcoCodes:     difference(atcoCodes(stored), atcoCodes(fetched)),
+		ChangedStopAreas:     make([]*domain.StopAreaChange, 0),
+	}
+
+	for _, stopAreaCode := range sortedKeys(fetched) {
+		storedAtcoCodes, ok := stored[stopAreaCode]
+		if !ok {
+			continue
+		}
+
+		added := difference(set(fetched[stopAreaCode]), set(storedAtcoCodes))
+		removed := difference(set(storedAtcoCodes), set(fetched[stopAreaCode]))
+
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+
+		report.ChangedStopAreas = append(report.ChangedStopAreas, &domain.StopAreaChange{
+			StopAreaCode:     stopAreaCode,
+			AddedAtcoCodes:   added,
+			RemovedAtcoCodes: removed,
+		})
+	}
+
+	return report
+}
+
+func countStopsInArea(stopsInAreaMap map[string][]string) int {
+	count := 0
+
+	for _, atcoCodes := range stopsInAreaMap {
+		count += len(atcoCodes)
+	}
+
+	return count
+}
+
+func stopAreaCodes(stopsInAreaMap map[string][]string) map[string]struct{} {
+	return set(sortedKeys(stopsInAreaMap))
+}
+
+func atcoCodes(stopsInAreaMap map[string][]string) map[string]struct{} {
+	codes := make(map[string]struct{})
+
+	for _, atcoCodes := range stopsInAreaMap {
+		for _, atcoCode := range atcoCodes {
+			codes[atcoCode] = struct{}{}
+		}
+	}
+
+	return codes
+}
+
+func set(codes []string) map[string]struct{} {
+	s := make(map[string]struct{}, len(codes))
+
+	for _, code := range codes {
+		s[code] = struct{}{}
+	}
+
+	return s
+}
+
+// difference returns the sorted codes which are in a but not in b
+func difference(a map[string]struct{}, b map[string]struct{}) []string {
+	codes := make([]string, 0)
+
+	for code := range a {
+		if _, ok := b[code]; !ok {
+			codes = append(codes, code)
+		}
+	}
+
+	sort.Strings(codes)
+
+	return codes
+}
+
+func sortedKeys(stopsInAreaMap map[string][]string) []string {
+	keys := make([]string, 0, len(stopsInAreaMap))
+
+	for key := range stopsInAreaMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,214 @@
+package loader_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/naptan/loader"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func givenStoredStopsInArea(t *testing.T, ctrl *gomock.Controller, stored map[string][]string) *mock_repository.MockStopsInAreaLister {
+	t.Helper()
+
+	stopsInAreaLister := mock_repository.NewMockStopsInAreaLister(ctrl)
+	stopsInAreaLister.EXPECT().GetAllStopsInArea(gomock.Any()).Return(stored, nil)
+
+	return stopsInAreaLister
+}
+
+func TestStopsInAreaLoader_LoadStopsInArea_ChangeReport(t *testing.T) {
+	t.Run(`Given stops in area are stored
+And the fetched stops in area add a stop area and remove a stop
+When LoadStopsInArea is called
+Then the fetched stops in area are stored
+And a report of the changes is logged and published`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, logs := observer.New(zapcore.InfoLevel)
+		logger := zap.New(zapCore)
+
+		stopsInAreaMap := mockStopsInAreaMap(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
+
+		stopsInAreaLister := givenStoredStopsInArea(t, ctrl, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4", "9400ZZMASTP5"},
+			"940GZZMAPIC": {"9400ZZMAPIC1", "9400ZZMAPIC2"},
+		})
+
+		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(nil)
+
+		platformNamer := givenPlatformNamer(t, ctrl, map[string]*string{})
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, gomock.Any()).Return(nil)
+
+		naptanStops, naptanStopAreas := givenNaptanStops(t)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+		naptanStopsFetcher.EXPECT().FetchStopsAndStopAreas(ctx).Return(naptanStops, naptanStopAreas, nil)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+		naptanStopsStorer.EXPECT().StoreStops(ctx, naptanStops).Return(nil)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+		naptanStopAreasStorer.EXPECT().StoreStopAreas(ctx, naptanStopAreas).Return(nil)
+
+		expectedReport := &domain.StopsInAreaChangeReport{
+			Accepted:             true,
+			StoredStopAreas:      2,
+			FetchedStopAreas:     3,
+			StoredStops:          7,
+			FetchedStops:         7,
+			AddedStopAreaCodes:   []string{"180GMNCHPIC"},
+			RemovedStopAreaCodes: []string{},
+			AddedAtcoCodes:       []string{"1800MNCHPIC0"},
+			RemovedAtcoCodes:     []string{"9400ZZMASTP5"},
+			ChangedStopAreas: []*domain.StopAreaChange{
+				{
+					StopAreaCode:     "940GZZMASTP",
+					AddedAtcoCodes:   []string{},
+					RemovedAtcoCodes: []string{"9400ZZMASTP5"},
+				},
+			},
+		}
+
+		changeReportPublisher := mock_repository.NewMockStopsInAreaChangeReportPublisher(ctrl)
+		changeReportPublisher.EXPECT().PublishStopsInAreaChangeReport(ctx, expectedReport).Return(nil)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaLister, stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, changeReportPublisher, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		reportLogs := logs.FilterMessage("NaPTAN stops in area changes").All()
+		if assert.Equal(t, 1, len(reportLogs)) {
+			assert.Equal(t, true, reportLogs[0].ContextMap()["accepted"])
+			assert.Equal(t, int64(2), reportLogs[0].ContextMap()["storedStopAreas"])
+			assert.Equal(t, int64(3), reportLogs[0].ContextMap()["fetchedStopAreas"])
+			assert.Equal(t, []interface{}{"9400ZZMASTP5"}, reportLogs[0].ContextMap()["removedAtcoCodes"])
+		}
+	})
+
+	t.Run(`Given stops in area are stored
+And the fetched stops in area have more than the maximum percentage fewer stop areas
+When LoadStopsInArea is called
+Then an error is returned
+And nothing is stored
+And the rejected report is published`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+		}, nil)
+
+		stopsInAreaLister := givenStoredStopsInArea(t, ctrl, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+		})
+
+		changeReportPublisher := mock_repository.NewMockStopsInAreaChangeReportPublisher(ctrl)
+		changeReportPublisher.EXPECT().PublishStopsInAreaChangeReport(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, report *domain.StopsInAreaChangeReport) error {
+			assert.False(t, report.Accepted)
+			assert.Equal(t, []string{"940GZZMAPIC"}, report.RemovedStopAreaCodes)
+			return nil
+		})
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaLister, mock_repository.NewMockStopsInAreaStorer(ctrl), mock_repository.NewMockPlatformNamer(ctrl), mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl), mock_repository.NewMockNaptanStopsFetcher(ctrl), mock_repository.NewMockNaptanStopsStorer(ctrl), mock_repository.NewMockNaptanStopAreasStorer(ctrl), changeReportPublisher, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.EqualError(t, err, "refusing to store stops in area: stop areas would shrink by 50.0% from 2 to 1, more than the maximum of 10.0%")
+	})
+
+	t.Run(`Given stops in area are stored
+And the fetched stops in area have more than the maximum percentage fewer stops
+And an error occurs publishing the report
+When LoadStopsInArea is called
+Then an error is returned
+And the publishing error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, logs := observer.New(zapcore.DebugLevel)
+		logger := zap.New(zapCore)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+		}, nil)
+
+		stopsInAreaLister := givenStoredStopsInArea(t, ctrl, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"},
+		})
+
+		changeReportPublisher := mock_repository.NewMockStopsInAreaChangeReportPublisher(ctrl)
+		changeReportPublisher.EXPECT().PublishStopsInAreaChangeReport(ctx, gomock.Any()).Return(errors.New("FUBAR"))
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaLister, mock_repository.NewMockStopsInAreaStorer(ctrl), mock_repository.NewMockPlatformNamer(ctrl), mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl), mock_repository.NewMockNaptanStopsFetcher(ctrl), mock_repository.NewMockNaptanStopsStorer(ctrl), mock_repository.NewMockNaptanStopAreasStorer(ctrl), changeReportPublisher, 50)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.EqualError(t, err, "refusing to store stops in area: stops in area would shrink by 75.0% from 4 to 1, more than the maximum of 50.0%")
+
+		assert.Equal(t, 1, logs.FilterMessage("rejected NaPTAN stops in area changes").Len())
+		assert.Equal(t, 1, logs.FilterMessage("error publishing stops in area change report").Len())
+	})
+
+	t.Run(`Given an error occurs getting the stored stops in area
+When LoadStopsInArea is called
+Then an error is returned
+And nothing is stored`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(mockStopsInAreaMap(t), nil)
+
+		stopsInAreaLister := mock_repository.NewMockStopsInAreaLister(ctrl)
+		stopsInAreaLister.EXPECT().GetAllStopsInArea(ctx).Return(nil, errors.New("FUBAR"))
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaLister, mock_repository.NewMockStopsInAreaStorer(ctrl), mock_repository.NewMockPlatformNamer(ctrl), mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl), mock_repository.NewMockNaptanStopsFetcher(ctrl), mock_repository.NewMockNaptanStopsStorer(ctrl), mock_repository.NewMockNaptanStopAreasStorer(ctrl), nil, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.EqualError(t, err, "error getting stored stops in area: FUBAR")
+	})
+}
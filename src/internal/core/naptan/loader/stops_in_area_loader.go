@@ -2,29 +2,147 @@ package loader
 
 import (
 	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"sort"
+	"strings"
 )
 
+// metrolinkStopAreaCodePrefix is the start of the NaPTAN StopAreaCode of every Metrolink stop area
+const metrolinkStopAreaCodePrefix = "940GZZMA"
+
 type StopsInAreaLoader struct {
-	logger             *zap.Logger
-	stopsInAreaFetcher repository.StopsInAreaFetcher
-	stopsInAreaStorer  repository.StopsInAreaStorer
+	logger                  *zap.Logger
+	stopsInAreaFetcher      repository.StopsInAreaFetcher
+	stopsInAreaLister       repository.StopsInAreaLister
+	stopsInAreaStorer       repository.StopsInAreaStorer
+	platformNamer           repository.PlatformNamer
+	stopDirectoryStorer     repository.MetrolinkStopDirectoryStorer
+	naptanStopsFetcher      repository.NaptanStopsFetcher
+	naptanStopsStorer       repository.NaptanStopsStorer
+	naptanStopAreasStorer   repository.NaptanStopAreasStorer
+	changeReportPublisher   repository.StopsInAreaChangeReportPublisher
+	maximumShrinkPercentage float64
 }
 
-func NewStopsInAreaLoader(logger *zap.Logger, stopsInAreaFetcher repository.StopsInAreaFetcher, stopsInAreaStorer repository.StopsInAreaStorer) *StopsInAreaLoader {
+// NewStopsInAreaLoader returns a StopsInAreaLoader which refuses to store stops in area when the number of stop areas or
+// stops would shrink by more than maximumShrinkPercentage. The change report of each load is published with
+// changeReportPublisher, which may be nil when the report only needs to be logged.
+func NewStopsInAreaLoader(logger *zap.Logger, stopsInAreaFetcher repository.StopsInAreaFetcher, stopsInAreaLister repository.StopsInAreaLister, stopsInAreaStorer repository.StopsInAreaStorer, platformNamer repository.PlatformNamer, stopDirectoryStorer repository.MetrolinkStopDirectoryStorer, naptanStopsFetcher repository.NaptanStopsFetcher, naptanStopsStorer repository.NaptanStopsStorer, naptanStopAreasStorer repository.NaptanStopAreasStorer, changeReportPublisher repository.StopsInAreaChangeReportPublisher, maximumShrinkPercentage float64) *StopsInAreaLoader {
 	return &StopsInAreaLoader{
-		logger:             logger,
-		stopsInAreaFetcher: stopsInAreaFetcher,
-		stopsInAreaStorer:  stopsInAreaStorer,
+		logger:                  logger,
+		stopsInAreaFetcher:      stopsInAreaFetcher,
+		stopsInAreaLister:       stopsInAreaLister,
+		stopsInAreaStorer:       stopsInAreaStorer,
+		platformNamer:           platformNamer,
+		stopDirectoryStorer:     stopDirectoryStorer,
+		naptanStopsFetcher:      naptanStopsFetcher,
+		naptanStopsStorer:       naptanStopsStorer,
+		naptanStopAreasStorer:   naptanStopAreasStorer,
+		changeReportPublisher:   changeReportPublisher,
+		maximumShrinkPercentage: maximumShrinkPercentage,
 	}
 }
 
+// LoadStopsInArea stores the stops in every NaPTAN stop area together with the stop area of every stop, then the
+// directory of Metrolink stop areas built from them, then the names and locations of Metrolink stops and stop areas.
+// Nothing is stored when the fetched stops in area fail validation against those already stored.
 func (s *StopsInAreaLoader) LoadStopsInArea(ctx context.Context) error {
 	stopsInAreaMap, err := s.stopsInAreaFetcher.FetchStopsInArea(ctx)
 	if err != nil {
 		return err
 	}
 
-	return s.stopsInAreaStorer.StoreStopsInArea(ctx, stopsInAreaMap)
+	if err := s.validateStopsInArea(ctx, stopsInAreaMap); err != nil {
+		return err
+	}
+
+	if err := s.stopsInAreaStorer.StoreStopsInArea(ctx, stopsInAreaMap, stopAreaCodesByAtcoCode(stopsInAreaMap)); err != nil {
+		return err
+	}
+
+	if err := s.stopDirectoryStorer.StoreStopDirectory(ctx, s.metrolinkStopDirectory(stopsInAreaMap)); err != nil {
+		return errors.Wrap(err, "error storing Metrolink stop directory")
+	}
+
+	return s.loadStops(ctx)
+}
+
+func (s *StopsInAreaLoader) loadStops(ctx context.Context) error {
+	stops, stopAreas, err := s.naptanStopsFetcher.FetchStopsAndStopAreas(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.naptanStopsStorer.StoreStops(ctx, stops); err != nil {
+		return err
+	}
+
+	if err := s.naptanStopAreasStorer.StoreStopAreas(ctx, stopAreas); err != nil {
+		return err
+	}
+
+	s.logger.Info("loaded NaPTAN stops", zap.Int("stops", len(stops)), zap.Int("stopAreas", len(stopAreas)))
+
+	return nil
+}
+
+// metrolinkStopDirectory returns the Metrolink stop areas, with the platform name of each stop, ordered by StopAreaCode
+// and AtcoCode
+func (s *StopsInAreaLoader) metrolinkStopDirectory(stopsInAreaMap map[string][]string) []*domain.MetrolinkStopArea {
+	stopAreas := make([]*domain.MetrolinkStopArea, 0)
+
+	for stopAreaCode, atcoCodes := range stopsInAreaMap {
+		if !strings.HasPrefix(stopAreaCode, metrolinkStopAreaCodePrefix) {
+			continue
+		}
+
+		sortedAtcoCodes := append([]string(nil), atcoCodes...)
+		sort.Strings(sortedAtcoCodes)
+
+		stopArea := &domain.MetrolinkStopArea{
+			StopAreaCode: stopAreaCode,
+			Stops:        make([]*domain.MetrolinkStop, 0, len(sortedAtcoCodes)),
+		}
+
+		for _, atcoCode := range sortedAtcoCodes {
+			platform, err := s.platformNamer.GetPlatformNameForAtcoCode(atcoCode)
+			if err != nil {
+				s.logger.Error("error getting platform name", zap.Error(err), zap.String("atcoCode", atcoCode))
+			}
+
+			stopArea.Stops = append(stopArea.Stops, &domain.MetrolinkStop{
+				AtcoCode: atcoCode,
+				Platform: platform,
+			})
+		}
+
+		stopAreas = append(stopAreas, stopArea)
+	}
+
+	sort.Slice(stopAreas, func(i, j int) bool {
+		return stopAreas[i].StopAreaCode < stopAreas[j].StopAreaCode
+	})
+
+	return stopAreas
+}
+
+// stopAreaCodesByAtcoCode returns the StopAreaCode of the stop area each AtcoCode belongs to. An AtcoCode in more than
+// one stop area is given the first of them by StopAreaCode, so that the same stop area is stored by every load.
+func stopAreaCodesByAtcoCode(stopsInAreaMap map[string][]string) map[string]string {
+	stopAreaCodes := make(map[string]string)
+
+	for stopAreaCode, atcoCodes := range stopsInAreaMap {
+		for _, atcoCode := range atcoCodes {
+			if existing, ok := stopAreaCodes[atcoCode]; ok && existing < stopAreaCode {
+				continue
+			}
+
+			stopAreaCodes[atcoCode] = stopAreaCode
+		}
+	}
+
+	return stopAreaCodes
 }
@@ -3,6 +3,7 @@ package loader_test
 import (
 	"context"
 	"github.com/Marchie/tf-experiment/lambda/internal/core/naptan/loader"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
@@ -32,13 +33,96 @@ func mockStopsInAreaMap(t *testing.T) map[string][]string {
 		"9400ZZMASTP4",
 	}
 
+	stopsInAreaMap["940GZZMAPIC"] = []string{
+		"9400ZZMAPIC2",
+		"9400ZZMAPIC1",
+	}
+
+	stopsInAreaMap["180GMNCHPIC"] = []string{
+		"1800MNCHPIC0",
+	}
+
 	return stopsInAreaMap
 }
 
+func thenExpectStopAreaCodesByAtcoCode(t *testing.T) map[string]string {
+	t.Helper()
+
+	return map[string]string{
+		"9400ZZMASTP1": "940GZZMASTP",
+		"9400ZZMASTP2": "940GZZMASTP",
+		"9400ZZMASTP3": "940GZZMASTP",
+		"9400ZZMASTP4": "940GZZMASTP",
+		"9400ZZMAPIC1": "940GZZMAPIC",
+		"9400ZZMAPIC2": "940GZZMAPIC",
+		"1800MNCHPIC0": "180GMNCHPIC",
+	}
+}
+
+func thenExpectMetrolinkStopDirectory(t *testing.T, platforms map[string]*string) []*domain.MetrolinkStopArea {
+	t.Helper()
+
+	return []*domain.MetrolinkStopArea{
+		{
+			StopAreaCode: "940GZZMAPIC",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMAPIC1", Platform: platforms["9400ZZMAPIC1"]},
+				{AtcoCode: "9400ZZMAPIC2", Platform: platforms["9400ZZMAPIC2"]},
+			},
+		},
+		{
+			StopAreaCode: "940GZZMASTP",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMASTP1", Platform: platforms["9400ZZMASTP1"]},
+				{AtcoCode: "9400ZZMASTP2", Platform: platforms["9400ZZMASTP2"]},
+				{AtcoCode: "9400ZZMASTP3", Platform: platforms["9400ZZMASTP3"]},
+				{AtcoCode: "9400ZZMASTP4", Platform: platforms["9400ZZMASTP4"]},
+			},
+		},
+	}
+}
+
+func givenPlatformNamer(t *testing.T, ctrl *gomock.Controller, platforms map[string]*string) *mock_repository.MockPlatformNamer {
+	t.Helper()
+
+	platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+	platformNamer.EXPECT().GetPlatformNameForAtcoCode(gomock.Any()).AnyTimes().DoAndReturn(func(atcoCode string) (*string, error) {
+		return platforms[atcoCode], nil
+	})
+
+	return platformNamer
+}
+
+func givenNoStoredStopsInArea(t *testing.T, ctrl *gomock.Controller) *mock_repository.MockStopsInAreaLister {
+	t.Helper()
+
+	stopsInAreaLister := mock_repository.NewMockStopsInAreaLister(ctrl)
+	stopsInAreaLister.EXPECT().GetAllStopsInArea(gomock.Any()).AnyTimes().Return(map[string][]string{}, nil)
+
+	return stopsInAreaLister
+}
+
+func givenNaptanStops(t *testing.T) ([]*domain.NaptanStop, []*domain.NaptanStopArea) {
+	t.Helper()
+
+	stops := []*domain.NaptanStop{
+		{AtcoCode: "9400ZZMAPIC1", CommonName: "Piccadilly (Manchester Metrolink)", Latitude: 53.4776, Longitude: -2.2301, Status: "act"},
+	}
+
+	stopAreas := []*domain.NaptanStopArea{
+		{StopAreaCode: "940GZZMAPIC", Name: "Piccadilly (Manchester Metrolink)", Latitude: 53.4775, Longitude: -2.2302, Status: "act"},
+	}
+
+	return stops, stopAreas
+}
+
 func TestStopsInAreaLoader_LoadStopsInArea(t *testing.T) {
 	t.Run(`Given stops in area data can be fetched
 When LoadStopsInArea is called
-Then stops in area data is stored in the repository`, func(t *testing.T) {
+Then stops in area data is stored in the repository
+And the StopAreaCode of each AtcoCode is stored
+And a directory of Metrolink stop areas with platform names is stored, ordered by StopAreaCode and AtcoCode
+And NaPTAN stops and stop areas are stored`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -53,9 +137,31 @@ Then stops in area data is stored in the repository`, func(t *testing.T) {
 		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
 
 		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
-		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap).Return(nil)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(nil)
 
-		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaStorer)
+		platformD, platformC := "D", "C"
+		platforms := map[string]*string{
+			"9400ZZMASTP1": &platformD,
+			"9400ZZMASTP2": &platformC,
+		}
+
+		platformNamer := givenPlatformNamer(t, ctrl, platforms)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, thenExpectMetrolinkStopDirectory(t, platforms)).Return(nil)
+
+		naptanStops, naptanStopAreas := givenNaptanStops(t)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+		naptanStopsFetcher.EXPECT().FetchStopsAndStopAreas(ctx).Return(naptanStops, naptanStopAreas, nil)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+		naptanStopsStorer.EXPECT().StoreStops(ctx, naptanStops).Return(nil)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+		naptanStopAreasStorer.EXPECT().StoreStopAreas(ctx, naptanStopAreas).Return(nil)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
 
 		// When
 		err := stopsInAreaLoader.LoadStopsInArea(ctx)
@@ -81,7 +187,17 @@ Then an error is returned`, func(t *testing.T) {
 
 		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
 
-		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaStorer)
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
 
 		// When
 		err := stopsInAreaLoader.LoadStopsInArea(ctx)
@@ -110,9 +226,19 @@ Then an error is returned`, func(t *testing.T) {
 
 		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
 		stopsInAreaStorerErr := errors.New("FUBAR")
-		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap).Return(stopsInAreaStorerErr)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(stopsInAreaStorerErr)
+
+		platformNamer := mock_repository.NewMockPlatformNamer(ctrl)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
 
-		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, stopsInAreaStorer)
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
 
 		// When
 		err := stopsInAreaLoader.LoadStopsInArea(ctx)
@@ -121,4 +247,181 @@ Then an error is returned`, func(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.Equal(t, stopsInAreaStorerErr, err)
 	})
+
+	t.Run(`Given stops in area data in which an AtcoCode is in more than one stop area
+When LoadStopsInArea is called
+Then the first of the stop areas by StopAreaCode is stored as the stop area of the AtcoCode`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaMap := map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMAPCC": {"9400ZZMAPIC1"},
+		}
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
+
+		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, map[string]string{"9400ZZMAPIC1": "940GZZMAPCC"}).Return(nil)
+
+		platformNamer := givenPlatformNamer(t, ctrl, nil)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, gomock.Any()).Return(nil)
+
+		naptanStops, naptanStopAreas := givenNaptanStops(t)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+		naptanStopsFetcher.EXPECT().FetchStopsAndStopAreas(ctx).Return(naptanStops, naptanStopAreas, nil)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+		naptanStopsStorer.EXPECT().StoreStops(ctx, naptanStops).Return(nil)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+		naptanStopAreasStorer.EXPECT().StoreStopAreas(ctx, naptanStopAreas).Return(nil)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given stops in area data can be fetched and stored
+And an error occurs storing the Metrolink stop directory
+When LoadStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaMap := mockStopsInAreaMap(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
+
+		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(nil)
+
+		platformNamer := givenPlatformNamer(t, ctrl, nil)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, thenExpectMetrolinkStopDirectory(t, nil)).Return(errors.New("FUBAR"))
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.EqualError(t, err, "error storing Metrolink stop directory: FUBAR")
+	})
+
+	t.Run(`Given stops in area data can be fetched and stored
+And NaPTAN stops fail to fetch
+When LoadStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaMap := mockStopsInAreaMap(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
+
+		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(nil)
+
+		platformNamer := givenPlatformNamer(t, ctrl, nil)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, thenExpectMetrolinkStopDirectory(t, nil)).Return(nil)
+
+		naptanStopsFetcherErr := errors.New("FUBAR")
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+		naptanStopsFetcher.EXPECT().FetchStopsAndStopAreas(ctx).Return(nil, nil, naptanStopsFetcherErr)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.Equal(t, naptanStopsFetcherErr, err)
+	})
+
+	t.Run(`Given NaPTAN stops can be fetched
+And an error occurs storing the NaPTAN stop areas
+When LoadStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopsInAreaMap := mockStopsInAreaMap(t)
+
+		stopsInAreaFetcher := mock_repository.NewMockStopsInAreaFetcher(ctrl)
+		stopsInAreaFetcher.EXPECT().FetchStopsInArea(ctx).Return(stopsInAreaMap, nil)
+
+		stopsInAreaStorer := mock_repository.NewMockStopsInAreaStorer(ctrl)
+		stopsInAreaStorer.EXPECT().StoreStopsInArea(ctx, stopsInAreaMap, thenExpectStopAreaCodesByAtcoCode(t)).Return(nil)
+
+		platformNamer := givenPlatformNamer(t, ctrl, nil)
+
+		stopDirectoryStorer := mock_repository.NewMockMetrolinkStopDirectoryStorer(ctrl)
+		stopDirectoryStorer.EXPECT().StoreStopDirectory(ctx, thenExpectMetrolinkStopDirectory(t, nil)).Return(nil)
+
+		naptanStops, naptanStopAreas := givenNaptanStops(t)
+
+		naptanStopsFetcher := mock_repository.NewMockNaptanStopsFetcher(ctrl)
+		naptanStopsFetcher.EXPECT().FetchStopsAndStopAreas(ctx).Return(naptanStops, naptanStopAreas, nil)
+
+		naptanStopsStorer := mock_repository.NewMockNaptanStopsStorer(ctrl)
+		naptanStopsStorer.EXPECT().StoreStops(ctx, naptanStops).Return(nil)
+
+		naptanStopAreasStorerErr := errors.New("FUBAR")
+
+		naptanStopAreasStorer := mock_repository.NewMockNaptanStopAreasStorer(ctrl)
+		naptanStopAreasStorer.EXPECT().StoreStopAreas(ctx, naptanStopAreas).Return(naptanStopAreasStorerErr)
+
+		stopsInAreaLoader := loader.NewStopsInAreaLoader(logger, stopsInAreaFetcher, givenNoStoredStopsInArea(t, ctrl), stopsInAreaStorer, platformNamer, stopDirectoryStorer, naptanStopsFetcher, naptanStopsStorer, naptanStopAreasStorer, nil, 10)
+
+		// When
+		err := stopsInAreaLoader.LoadStopsInArea(ctx)
+
+		// Then
+		assert.Equal(t, naptanStopAreasStorerErr, err)
+	})
 }
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Api describes Metrolink stops, so that clients can discover the StopAreaCodes and AtcoCodes which the departures API
+// accepts
+type Api struct {
+	logger                      *zap.Logger
+	stopDirectoryGetter         repository.MetrolinkStopDirectoryGetter
+	naptanStopsGetter           repository.NaptanStopsGetter
+	naptanStopAreasGetter       repository.NaptanStopAreasGetter
+	naptanStopAreasNearbyGetter repository.NaptanStopAreasNearbyGetter
+	metrolinkDeparturesGetter   repository.MetrolinkDeparturesMultiGetter
+	systemStatusGetter          repository.SystemStatusGetter
+	batchDeparturesJsoner       core.BatchDeparturesJsoner
+}
+
+func NewApi(logger *zap.Logger, stopDirectoryGetter repository.MetrolinkStopDirectoryGetter, naptanStopsGetter repository.NaptanStopsGetter, naptanStopAreasGetter repository.NaptanStopAreasGetter, naptanStopAreasNearbyGetter repository.NaptanStopAreasNearbyGetter, metrolinkDeparturesGetter repository.MetrolinkDeparturesMultiGetter, systemStatusGetter repository.SystemStatusGetter, batchDeparturesJsoner core.BatchDeparturesJsoner) *Api {
+	return &Api{
+		logger:                      logger,
+		stopDirectoryGetter:         stopDirectoryGetter,
+		naptanStopsGetter:           naptanStopsGetter,
+		naptanStopAreasGetter:       naptanStopAreasGetter,
+		naptanStopAreasNearbyGetter: naptanStopAreasNearbyGetter,
+		metrolinkDeparturesGetter:   metrolinkDeparturesGetter,
+		systemStatusGetter:          systemStatusGetter,
+		batchDeparturesJsoner:       batchDeparturesJsoner,
+	}
+}
+
+// StopsJson lists every Metrolink stop area with its stops and their platform names, from the directory stored when
+// NaPTAN data is loaded. The names and locations of stop areas and stops come from the NaPTAN stops stored alongside
+// the directory. The station name and TLAREF of each stop area come from the current departures, so they are omitted
+// for stop areas without departures, or when departures cannot be read.
+func (a *Api) StopsJson(ctx context.Context) (io.ReadCloser, int, error) {
+	stopAreas, err := a.stopDirectoryGetter.GetStopDirectory(ctx)
+	if err != nil {
+		if errors.Cause(err) == redis.ErrNil {
+			return a.encodeJsonErrorResponse(http.StatusServiceUnavailable, "Metrolink stops have not been loaded")
+		}
+
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "error getting Metrolink stop directory")
+	}
+
+	stations := a.getStationsByAtcoCode(ctx, stopAreas)
+
+	naptanStops := a.getNaptanStops(ctx)
+
+	naptanStopAreas := a.getNaptanStopAreas(ctx)
+
+	return a.encodeJsonResponse(a.convertToPublicApi(stopAreas, stations, naptanStops, naptanStopAreas), http.StatusOK)
+}
+
+// getNaptanStops returns the NaPTAN stops by AtcoCode. As with station details, errors are logged rather than returned.
+func (a *Api) getNaptanStops(ctx context.Context) map[string]*domain.NaptanStop {
+	naptanStops := make(map[string]*domain.NaptanStop)
+
+	stops, err := a.naptanStopsGetter.GetStops(ctx)
+	if err != nil {
+		if errors.Cause(err) != redis.ErrNil {
+			a.logger.Error("error getting NaPTAN stops", zap.Error(err))
+		}
+	}
+
+	for _, stop := range stops {
+		naptanStops[stop.AtcoCode] = stop
+	}
+
+	return naptanStops
+}
+
+// getNaptanStopAreas returns the NaPTAN stop areas by StopAreaCode. Errors are logged rather than returned.
+func (a *Api) getNaptanStopAreas(ctx context.Context) map[string]*domain.NaptanStopArea {
+	naptanStopAreas := make(map[string]*domain.NaptanStopArea)
+
+	stopAreas, err := a.naptanStopAreasGetter.GetStopAreas(ctx)
+	if err != nil {
+		if errors.Cause(err) != redis.ErrNil {
+			a.logger.Error("error getting NaPTAN stop areas", zap.Error(err))
+		}
+	}
+
+	for _, stopArea := range stopAreas {
+		naptanStopAreas[stopArea.StopAreaCode] = stopArea
+	}
+
+	return naptanStopAreas
+}
+
+// getStationsByAtcoCode returns a departure for each AtcoCode with departures in the current snapshot, from which the
+// station name and TLAREF can be read. Errors are logged rather than returned, as the directory is still useful
+// without station details.
+func (a *Api) getStationsByAtcoCode(ctx context.Context, stopAreas []*domain.MetrolinkStopArea) map[string]*domain.MetrolinkDeparture {
+	stations := make(map[string]*domain.MetrolinkDeparture)
+
+	systemStatus, err := a.systemStatusGetter.Get(ctx)
+	if err != nil {
+		if errors.Cause(err) != redis.ErrNil {
+			a.logger.Error("error getting Metrolink departures system status for stop directory", zap.Error(err))
+		}
+
+		return stations
+	}
+
+	var atcoCodes []string
+
+	for _, stopArea := range stopAreas {
+		for _, stop := range stopArea.Stops {
+			atcoCodes = append(atcoCodes, stop.AtcoCode)
+		}
+	}
+
+	if len(atcoCodes) == 0 {
+		return stations
+	}
+
+	departures, err := a.metrolinkDeparturesGetter.GetMulti(ctx, systemStatus.Generation, atcoCodes)
+	if err != nil {
+		a.logger.Error("error getting Metrolink departures for stop directory", zap.Error(err))
+		return stations
+	}
+
+	for _, departure := range departures {
+		if _, ok := stations[departure.AtcoCode]; !ok {
+			stations[departure.AtcoCode] = departure
+		}
+	}
+
+	return stations
+}
+
+func (a *Api) convertToPublicApi(stopAreas []*domain.MetrolinkStopArea, stations map[string]*domain.MetrolinkDeparture, naptanStops map[string]*domain.NaptanStop, naptanStopAreas map[string]*domain.NaptanStopArea) *tfgm.MetrolinkStopAreas {
+	convertedStopAreas := make([]*tfgm.MetrolinkStopArea, 0, len(stopAreas))
+
+	for _, stopArea := range stopAreas {
+		convertedStopArea := &tfgm.MetrolinkStopArea{
+			StopAreaCode: stopArea.StopAreaCode,
+			Stops:        make([]*tfgm.MetrolinkStop, 0, len(stopArea.Stops)),
+		}
+
+		if naptanStopArea, ok := naptanStopAreas[stopArea.StopAreaCode]; ok {
+			convertedStopArea.Name = naptanStopArea.Name
+			convertedStopArea.Latitude = &naptanStopArea.Latitude
+			convertedStopArea.Longitude = &naptanStopArea.Longitude
+		}
+
+		for _, stop := range stopArea.Stops {
+			if station, ok := stations[stop.AtcoCode]; ok && convertedStopArea.StationLocation == "" {
+				convertedStopArea.StationLocation = station.StationLocation
+				convertedStopArea.Tlaref = station.Tlaref
+			}
+
+			convertedStop := &tfgm.MetrolinkStop{
+				AtcoCode: stop.AtcoCode,
+				Platform: stop.Platform,
+			}
+
+			if naptanStop, ok := naptanStops[stop.AtcoCode]; ok {
+				convertedStop.CommonName = naptanStop.CommonName
+				convertedStop.Indicator = naptanStop.Indicator
+				convertedStop.Street = naptanStop.Street
+				convertedStop.Latitude = &naptanStop.Latitude
+				convertedStop.Longitude = &naptanStop.Longitude
+			}
+
+			convertedStopArea.Stops = append(convertedStopArea.Stops, convertedStop)
+		}
+
+		convertedStopAreas = append(convertedStopAreas, convertedStopArea)
+	}
+
+	return &tfgm.MetrolinkStopAreas{
+		StopAreas: convertedStopAreas,
+	}
+}
+
+func (a *Api) encodeJsonResponse(v interface{}, statusCode int) (io.ReadCloser, int, error) {
+	buf := new(bytes.Buffer)
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", "\t")
+
+	if err := enc.Encode(v); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return ioutil.NopCloser(buf), statusCode, nil
+}
+
+func (a *Api) encodeJsonErrorResponse(statusCode int, errorMsg string) (io.ReadCloser, int, error) {
+	return a.encodeJsonResponse(map[string]string{
+		"error": errorMsg,
+	}, statusCode)
+}
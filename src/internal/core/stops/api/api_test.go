@@ -0,0 +1,303 @@
+package api_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/stops/api"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func mockLogger(t *testing.T) *zap.Logger {
+	t.Helper()
+
+	zapCore, _ := observer.New(zapcore.ErrorLevel)
+	return zap.New(zapCore)
+}
+
+func readJson(t *testing.T, rc io.ReadCloser) string {
+	t.Helper()
+
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	return string(b)
+}
+
+func givenStopDirectory(t *testing.T) []*domain.MetrolinkStopArea {
+	t.Helper()
+
+	platformA := "A"
+	platformB := "B"
+
+	return []*domain.MetrolinkStopArea{
+		{
+			StopAreaCode: "940GZZMAPIC",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMAPIC1", Platform: &platformA},
+				{AtcoCode: "9400ZZMAPIC2", Platform: &platformB},
+			},
+		},
+		{
+			StopAreaCode: "940GZZMASTP",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMASTP1"},
+			},
+		},
+	}
+}
+
+func givenNaptanStopsGetters(t *testing.T, ctrl *gomock.Controller, ctx context.Context, stops []*domain.NaptanStop, stopAreas []*domain.NaptanStopArea) (*mock_repository.MockNaptanStopsGetter, *mock_repository.MockNaptanStopAreasGetter) {
+	t.Helper()
+
+	naptanStopsGetter := mock_repository.NewMockNaptanStopsGetter(ctrl)
+	naptanStopAreasGetter := mock_repository.NewMockNaptanStopAreasGetter(ctrl)
+
+	if stops == nil {
+		naptanStopsGetter.EXPECT().GetStops(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting NaPTAN stops"))
+	} else {
+		naptanStopsGetter.EXPECT().GetStops(ctx).Return(stops, nil)
+	}
+
+	if stopAreas == nil {
+		naptanStopAreasGetter.EXPECT().GetStopAreas(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting NaPTAN stop areas"))
+	} else {
+		naptanStopAreasGetter.EXPECT().GetStopAreas(ctx).Return(stopAreas, nil)
+	}
+
+	return naptanStopsGetter, naptanStopAreasGetter
+}
+
+func TestApi_StopsJson(t *testing.T) {
+	t.Run(`Given a stop directory
+And departures for some of its stops
+When StopsJson is called
+Then every stop area is returned with its stops
+And the NaPTAN names and locations of stops and stop areas
+And the station name and TLAREF of stop areas with departures`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(givenStopDirectory(t), nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(&domain.SystemStatus{Generation: "1"}, nil)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, "1", []string{"9400ZZMAPIC1", "9400ZZMAPIC2", "9400ZZMASTP1"}).Return([]*domain.MetrolinkDeparture{
+			{
+				AtcoCode:        "9400ZZMAPIC2",
+				Tlaref:          "PIC",
+				StationLocation: "Piccadilly",
+				Destination:     "Altrincham",
+			},
+		}, nil)
+
+		naptanStopsGetter, naptanStopAreasGetter := givenNaptanStopsGetters(t, ctrl, ctx, []*domain.NaptanStop{
+			{AtcoCode: "9400ZZMAPIC1", CommonName: "Piccadilly (Manchester Metrolink)", Indicator: "Platform A", Street: "London Road", Latitude: 53.4776, Longitude: -2.2301},
+		}, []*domain.NaptanStopArea{
+			{StopAreaCode: "940GZZMAPIC", Name: "Piccadilly (Manchester Metrolink)", Latitude: 53.4775, Longitude: -2.2302},
+		})
+
+		stopsApi := api.NewApi(mockLogger(t), stopDirectoryGetter, naptanStopsGetter, naptanStopAreasGetter, mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), metrolinkDeparturesGetter, systemStatusGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.StopsJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"stationLocation": "Piccadilly",
+			"tlaref": "PIC",
+			"latitude": 53.4775,
+			"longitude": -2.2302,
+			"stops": [
+				{
+					"atcoCode": "9400ZZMAPIC1",
+					"platform": "A",
+					"commonName": "Piccadilly (Manchester Metrolink)",
+					"indicator": "Platform A",
+					"street": "London Road",
+					"latitude": 53.4776,
+					"longitude": -2.2301
+				},
+				{
+					"atcoCode": "9400ZZMAPIC2",
+					"platform": "B"
+				}
+			]
+		},
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"stops": [
+				{
+					"atcoCode": "9400ZZMASTP1"
+				}
+			]
+		}
+	]
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given a stop directory
+And the departures system status is not available
+And NaPTAN stops have not been loaded
+When StopsJson is called
+Then every stop area is returned without station names
+And no error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(givenStopDirectory(t)[1:], nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting system status"))
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+
+		naptanStopsGetter, naptanStopAreasGetter := givenNaptanStopsGetters(t, ctrl, ctx, nil, nil)
+
+		stopsApi := api.NewApi(logger, stopDirectoryGetter, naptanStopsGetter, naptanStopAreasGetter, mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), metrolinkDeparturesGetter, systemStatusGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.StopsJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"stops": [
+				{
+					"atcoCode": "9400ZZMASTP1"
+				}
+			]
+		}
+	]
+}
+`, readJson(t, rc))
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a stop directory
+And an error occurs getting departures
+When StopsJson is called
+Then every stop area is returned without station names
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(givenStopDirectory(t)[1:], nil)
+
+		systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+		systemStatusGetter.EXPECT().Get(ctx).Return(&domain.SystemStatus{Generation: "1"}, nil)
+
+		metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+		metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, "1", []string{"9400ZZMASTP1"}).Return(nil, errors.New("FUBAR"))
+
+		naptanStopsGetter, naptanStopAreasGetter := givenNaptanStopsGetters(t, ctrl, ctx, nil, nil)
+
+		stopsApi := api.NewApi(logger, stopDirectoryGetter, naptanStopsGetter, naptanStopAreasGetter, mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), metrolinkDeparturesGetter, systemStatusGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.StopsJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Contains(t, readJson(t, rc), `"stopAreaCode": "940GZZMASTP"`)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, "error getting Metrolink departures for stop directory", observedLogs.All()[0].Message)
+	})
+
+	t.Run(`Given the stop directory has not been loaded
+When StopsJson is called
+Then a service unavailable response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting Metrolink stop directory"))
+
+		stopsApi := api.NewApi(mockLogger(t), stopDirectoryGetter, mock_repository.NewMockNaptanStopsGetter(ctrl), mock_repository.NewMockNaptanStopAreasGetter(ctrl), mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.StopsJson(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, `{
+	"error": "Metrolink stops have not been loaded"
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given an error occurs getting the stop directory
+When StopsJson is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(nil, errors.New("FUBAR"))
+
+		stopsApi := api.NewApi(mockLogger(t), stopDirectoryGetter, mock_repository.NewMockNaptanStopsGetter(ctrl), mock_repository.NewMockNaptanStopAreasGetter(ctrl), mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.StopsJson(ctx)
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.Equal(t, "error getting Metrolink stop directory: FUBAR", err.Error())
+	})
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	latitudeQueryParameter        = "latitude"
+	longitudeQueryParameter       = "longitude"
+	radiusQueryParameter          = "radius"
+	limitQueryParameter           = "limit"
+	departuresQueryParameter      = "departures"
+	departuresLimitQueryParameter = "departuresLimit"
+
+	// batchLimitQueryParameter is the query parameter of the batch departures API which limits the departures returned
+	// for each stop area
+	batchLimitQueryParameter = "limit"
+
+	defaultRadiusMetres = 1000
+	maxRadiusMetres     = 5000
+	defaultLimit        = 5
+	maxLimit            = 20
+)
+
+// batchErrorResponse is the error response of the batch departures API
+type batchErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// nearbyQuery is a search for the stop areas closest to a location
+type nearbyQuery struct {
+	latitude        float64
+	longitude       float64
+	radiusMetres    float64
+	limit           int
+	departures      bool
+	departuresLimit int
+}
+
+// NearbyJson returns the Metrolink stop areas within a radius of a location, nearest first. When departures are
+// requested, the departures for the stop areas are included in the format of the batch departures API: see
+// getNearbyDepartures.
+func (a *Api) NearbyJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	query, err := parseNearbyQuery(queryParameters)
+	if err != nil {
+		return a.encodeJsonErrorResponse(http.StatusBadRequest, err.Error())
+	}
+
+	nearbyStopAreas, err := a.naptanStopAreasNearbyGetter.GetStopAreasNearby(ctx, query.latitude, query.longitude, query.radiusMetres, query.limit)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "error getting nearby Metrolink stop areas")
+	}
+
+	nearby := a.convertNearbyToPublicApi(query, nearbyStopAreas, a.getNaptanStopAreas(ctx))
+
+	if query.departures && len(nearbyStopAreas) > 0 {
+		if statusCode, err := a.getNearbyDepartures(ctx, query, nearbyStopAreas, nearby); err != nil {
+			return nil, statusCode, err
+		}
+	}
+
+	return a.encodeJsonResponse(nearby, http.StatusOK)
+}
+
+// parseNearbyQuery reads a nearbyQuery from request query parameters. The returned error describes the first invalid
+// value and is suitable for returning to the client.
+func parseNearbyQuery(queryParameters map[string]string) (*nearbyQuery, error) {
+	query := &nearbyQuery{
+		radiusMetres: defaultRadiusMetres,
+	}
+
+	latitude, err := strconv.ParseFloat(strings.TrimSpace(queryParameters[latitudeQueryParameter]), 64)
+	if err != nil || latitude < -90 || latitude > 90 {
+		return nil, fmt.Errorf("invalid %s: must be a number between -90 and 90", latitudeQueryParameter)
+	}
+
+	query.latitude = latitude
+
+	longitude, err := strconv.ParseFloat(strings.TrimSpace(queryParameters[longitudeQueryParameter]), 64)
+	if err != nil || longitude < -180 || longitude > 180 {
+		return nil, fmt.Errorf("invalid %s: must be a number between -180 and 180", longitudeQueryParameter)
+	}
+
+	query.longitude = longitude
+
+	if radius := strings.TrimSpace(queryParameters[radiusQueryParameter]); radius != "" {
+		v, err := strconv.ParseFloat(radius, 64)
+		if err != nil || v <= 0 || v > maxRadiusMetres {
+			return nil, fmt.Errorf("invalid %s: must be a number of metres greater than 0 and no more than %d", radiusQueryParameter, maxRadiusMetres)
+		}
+
+		query.radiusMetres = v
+	}
+
+	limit, err := parseLimit(queryParameters, limitQueryParameter, defaultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	query.limit = limit
+
+	if departures := strings.TrimSpace(queryParameters[departuresQueryParameter]); departures != "" {
+		v, err := strconv.ParseBool(departures)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: must be true or false", departuresQueryParameter)
+		}
+
+		query.departures = v
+	}
+
+	// Without a departures limit, every departure for each stop area is included
+	departuresLimit, err := parseLimit(queryParameters, departuresLimitQueryParameter, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	query.departuresLimit = departuresLimit
+
+	return query, nil
+}
+
+// getNearbyDepartures adds the batch departures response for the nearby stop areas to nearby. The nearby response does
+// not take its status from the batch response: stop areas are returned with a 200 response whether or not departures
+// can be served, outdated departures are reported in DeparturesError, and stale departures are flagged with
+// DeparturesStale rather than a Warning header on the nearby response. The query has been validated, so any other
+// status of the batch response is an error.
+func (a *Api) getNearbyDepartures(ctx context.Context, query *nearbyQuery, nearbyStopAreas []*domain.NearbyStopArea, nearby *tfgm.MetrolinkNearbyStopAreas) (int, error) {
+	stopAreaCodes := make([]string, len(nearbyStopAreas))
+	for i, nearbyStopArea := range nearbyStopAreas {
+		stopAreaCodes[i] = nearbyStopArea.StopAreaCode
+	}
+
+	batchQueryParameters := make(map[string]string)
+	if query.departuresLimit > 0 {
+		batchQueryParameters[batchLimitQueryParameter] = strconv.Itoa(query.departuresLimit)
+	}
+
+	readCloser, statusCode, err := a.batchDeparturesJsoner.BatchJson(ctx, stopAreaCodes, batchQueryParameters)
+	if err != nil {
+		return statusCode, errors.Wrap(err, "error getting departures for nearby Metrolink stop areas")
+	}
+	defer func() {
+		if err := readCloser.Close(); err != nil {
+			a.logger.Error("error closing departures for nearby Metrolink stop areas", zap.Error(err))
+		}
+	}()
+
+	departures, err := ioutil.ReadAll(readCloser)
+	if err != nil {
+		return http.StatusInternalServerError, errors.Wrap(err, "error reading departures for nearby Metrolink stop areas")
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+		nearby.Departures = departures
+
+		if _, ok := readCloser.(core.StaleDataJson); ok {
+			nearby.DeparturesStale = true
+		}
+	case http.StatusBadGateway:
+		var batchError batchErrorResponse
+		if err := json.Unmarshal(departures, &batchError); err != nil {
+			return http.StatusInternalServerError, errors.Wrap(err, "error decoding departures error for nearby Metrolink stop areas")
+		}
+
+		nearby.DeparturesError = batchError.Error
+	default:
+		return http.StatusInternalServerError, errors.Errorf("unexpected status %d getting departures for nearby Metrolink stop areas: %s", statusCode, strings.TrimSpace(string(departures)))
+	}
+
+	return http.StatusOK, nil
+}
+
+func (a *Api) convertNearbyToPublicApi(query *nearbyQuery, nearbyStopAreas []*domain.NearbyStopArea, naptanStopAreas map[string]*domain.NaptanStopArea) *tfgm.MetrolinkNearbyStopAreas {
+	nearby := &tfgm.MetrolinkNearbyStopAreas{
+		Latitude:     query.latitude,
+		Longitude:    query.longitude,
+		RadiusMetres: query.radiusMetres,
+		StopAreas:    make([]*tfgm.MetrolinkNearbyStopArea, len(nearbyStopAreas)),
+	}
+
+	for i, nearbyStopArea := range nearbyStopAreas {
+		publicStopArea := &tfgm.MetrolinkNearbyStopArea{
+			StopAreaCode:   nearbyStopArea.StopAreaCode,
+			DistanceMetres: int(math.Round(nearbyStopArea.DistanceMetres)),
+		}
+
+		if naptanStopArea, ok := naptanStopAreas[nearbyStopArea.StopAreaCode]; ok {
+			latitude, longitude := naptanStopArea.Latitude, naptanStopArea.Longitude
+
+			publicStopArea.Name = naptanStopArea.Name
+			publicStopArea.Latitude = &latitude
+			publicStopArea.Longitude = &longitude
+		}
+
+		nearby.StopAreas[i] = publicStopArea
+	}
+
+	return nearby
+}
+
+// parseLimit reads a maximum number of results to return from the queryParameter of request query parameters,
+// returning defaultValue when it is not given
+func parseLimit(queryParameters map[string]string, queryParameter string, defaultValue int) (int, error) {
+	limit := strings.TrimSpace(queryParameters[queryParameter])
+	if limit == "" {
+		return defaultValue, nil
+	}
+
+	v, err := strconv.Atoi(limit)
+	if err != nil || v < 1 || v > maxLimit {
+		return 0, fmt.Errorf("invalid %s: must be a whole number between 1 and %d", queryParameter, maxLimit)
+	}
+
+	return v, nil
+}
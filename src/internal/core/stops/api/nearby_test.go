@@ -0,0 +1,433 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/stops/api"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func givenNearbyStopAreas(t *testing.T) []*domain.NearbyStopArea {
+	t.Helper()
+
+	return []*domain.NearbyStopArea{
+		{StopAreaCode: "940GZZMAPIC", DistanceMetres: 11.4},
+		{StopAreaCode: "940GZZMASTP", DistanceMetres: 889.6},
+	}
+}
+
+func givenNaptanStopAreasGetter(t *testing.T, ctrl *gomock.Controller, ctx context.Context) *mock_repository.MockNaptanStopAreasGetter {
+	t.Helper()
+
+	naptanStopAreasGetter := mock_repository.NewMockNaptanStopAreasGetter(ctrl)
+	naptanStopAreasGetter.EXPECT().GetStopAreas(ctx).Return([]*domain.NaptanStopArea{
+		{StopAreaCode: "940GZZMAPIC", Name: "Piccadilly (Manchester Metrolink)", Latitude: 53.4775, Longitude: -2.2302, Status: "act"},
+	}, nil)
+
+	return naptanStopAreasGetter
+}
+
+// staleDataJson is a JSON response containing stale departures
+type staleDataJson struct {
+	io.ReadCloser
+	dataAge time.Duration
+}
+
+func (s *staleDataJson) DataAge() time.Duration {
+	return s.dataAge
+}
+
+func givenNearbyApi(t *testing.T, ctrl *gomock.Controller, naptanStopAreasGetter *mock_repository.MockNaptanStopAreasGetter, naptanStopAreasNearbyGetter *mock_repository.MockNaptanStopAreasNearbyGetter, batchDeparturesJsoner *mock_core.MockBatchDeparturesJsoner) *api.Api {
+	t.Helper()
+
+	return api.NewApi(mockLogger(t), mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl), mock_repository.NewMockNaptanStopsGetter(ctrl), naptanStopAreasGetter, naptanStopAreasNearbyGetter, mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), batchDeparturesJsoner)
+}
+
+func TestApi_NearbyJson(t *testing.T) {
+	t.Run(`Given Metrolink stop areas near a location
+When NearbyJson is called with the location
+Then the nearby stop areas are returned nearest first, with their NaPTAN names and locations where known`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(givenNearbyStopAreas(t), nil)
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":  "53.4776",
+			"longitude": "-2.2301",
+		})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"latitude": 53.4776,
+	"longitude": -2.2301,
+	"radiusMetres": 1000,
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"latitude": 53.4775,
+			"longitude": -2.2302,
+			"distanceMetres": 11
+		},
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"distanceMetres": 890
+		}
+	]
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given Metrolink stop areas near a location
+When NearbyJson is called with the location, a radius, a limit and departures requested
+Then the nearby stop areas are returned with the batch departures response for them`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(2500), 2).Return(givenNearbyStopAreas(t), nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMAPIC", "940GZZMASTP"}, map[string]string{"limit": "3"}).Return(ioutil.NopCloser(bytes.NewBufferString(`{"locations":{}}`)), http.StatusOK, nil)
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, batchDeparturesJsoner)
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":        "53.4776",
+			"longitude":       "-2.2301",
+			"radius":          "2500",
+			"limit":           "2",
+			"departures":      "true",
+			"departuresLimit": "3",
+		})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"latitude": 53.4776,
+	"longitude": -2.2301,
+	"radiusMetres": 2500,
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"latitude": 53.4775,
+			"longitude": -2.2302,
+			"distanceMetres": 11
+		},
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"distanceMetres": 890
+		}
+	],
+	"departures": {
+		"locations": {}
+	}
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given no Metrolink stop areas near a location
+When NearbyJson is called with departures requested
+Then an empty list of stop areas is returned
+And departures are not requested`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 51.5, -0.12, float64(1000), 5).Return([]*domain.NearbyStopArea{}, nil)
+
+		naptanStopAreasGetter := mock_repository.NewMockNaptanStopAreasGetter(ctrl)
+		naptanStopAreasGetter.EXPECT().GetStopAreas(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting NaPTAN stop areas"))
+
+		stopsApi := givenNearbyApi(t, ctrl, naptanStopAreasGetter, naptanStopAreasNearbyGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":   "51.5",
+			"longitude":  "-0.12",
+			"departures": "true",
+		})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"latitude": 51.5,
+	"longitude": -0.12,
+	"radiusMetres": 1000,
+	"stopAreas": []
+}
+`, readJson(t, rc))
+	})
+
+	invalidQueryParameters := []struct {
+		queryParameters map[string]string
+		expError        string
+	}{
+		{map[string]string{"longitude": "-2.2301"}, "invalid latitude: must be a number between -90 and 90"},
+		{map[string]string{"latitude": "91", "longitude": "-2.2301"}, "invalid latitude: must be a number between -90 and 90"},
+		{map[string]string{"latitude": "53.4776", "longitude": "west"}, "invalid longitude: must be a number between -180 and 180"},
+		{map[string]string{"latitude": "53.4776", "longitude": "-2.2301", "radius": "5001"}, "invalid radius: must be a number of metres greater than 0 and no more than 5000"},
+		{map[string]string{"latitude": "53.4776", "longitude": "-2.2301", "limit": "21"}, "invalid limit: must be a whole number between 1 and 20"},
+		{map[string]string{"latitude": "53.4776", "longitude": "-2.2301", "departures": "please"}, "invalid departures: must be true or false"},
+		{map[string]string{"latitude": "53.4776", "longitude": "-2.2301", "departures": "true", "departuresLimit": "0"}, "invalid departuresLimit: must be a whole number between 1 and 20"},
+		{map[string]string{"latitude": "53.4776", "longitude": "-2.2301", "departures": "true", "departuresLimit": "lots"}, "invalid departuresLimit: must be a whole number between 1 and 20"},
+	}
+
+	for _, tc := range invalidQueryParameters {
+		t.Run(`Given invalid query parameters
+When NearbyJson is called
+Then a bad request response is returned describing the invalid query parameter`, func(t *testing.T) {
+			// Given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+
+			stopsApi := givenNearbyApi(t, ctrl, mock_repository.NewMockNaptanStopAreasGetter(ctrl), mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+			// When
+			rc, statusCode, err := stopsApi.NearbyJson(ctx, tc.queryParameters)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusBadRequest, statusCode)
+			assert.Equal(t, `{
+	"error": "`+tc.expError+`"
+}
+`, readJson(t, rc))
+		})
+	}
+
+	t.Run(`Given an error occurs searching for nearby stop areas
+When NearbyJson is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(nil, errors.New("FUBAR"))
+
+		stopsApi := givenNearbyApi(t, ctrl, mock_repository.NewMockNaptanStopAreasGetter(ctrl), naptanStopAreasNearbyGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":  "53.4776",
+			"longitude": "-2.2301",
+		})
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.EqualError(t, err, "error getting nearby Metrolink stop areas: FUBAR")
+	})
+
+	t.Run(`Given an error occurs getting departures for nearby stop areas
+When NearbyJson is called with departures requested
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(givenNearbyStopAreas(t), nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMAPIC", "940GZZMASTP"}, map[string]string{}).Return(nil, http.StatusInternalServerError, errors.New("FUBAR"))
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, batchDeparturesJsoner)
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":   "53.4776",
+			"longitude":  "-2.2301",
+			"departures": "true",
+		})
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.EqualError(t, err, "error getting departures for nearby Metrolink stop areas: FUBAR")
+	})
+
+	t.Run(`Given Metrolink stop areas near a location
+And the departures for them are stale
+When NearbyJson is called with departures requested
+Then the nearby stop areas are returned with the batch departures response for them
+And the departures are flagged as stale`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(givenNearbyStopAreas(t), nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMAPIC", "940GZZMASTP"}, map[string]string{}).Return(&staleDataJson{
+			ReadCloser: ioutil.NopCloser(bytes.NewBufferString(`{"locations":{},"stale":true,"dataAgeSeconds":45}`)),
+			dataAge:    45 * time.Second,
+		}, http.StatusOK, nil)
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, batchDeparturesJsoner)
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":   "53.4776",
+			"longitude":  "-2.2301",
+			"departures": "true",
+		})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+
+		_, isStaleDataJson := rc.(core.StaleDataJson)
+		assert.False(t, isStaleDataJson)
+
+		assert.Equal(t, `{
+	"latitude": 53.4776,
+	"longitude": -2.2301,
+	"radiusMetres": 1000,
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"latitude": 53.4775,
+			"longitude": -2.2302,
+			"distanceMetres": 11
+		},
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"distanceMetres": 890
+		}
+	],
+	"departures": {
+		"locations": {},
+		"stale": true,
+		"dataAgeSeconds": 45
+	},
+	"departuresStale": true
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given Metrolink stop areas near a location
+And the departures for them are outdated
+When NearbyJson is called with departures requested
+Then the nearby stop areas are returned in a successful response
+And the reason departures could not be served is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(givenNearbyStopAreas(t), nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMAPIC", "940GZZMASTP"}, map[string]string{}).Return(ioutil.NopCloser(bytes.NewBufferString(`{"requestedLocations":["940GZZMAPIC","940GZZMASTP"],"error":"Metrolink departures data is outdated: last updated at 2021-03-21T15:34:56Z"}`)), http.StatusBadGateway, nil)
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, batchDeparturesJsoner)
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":   "53.4776",
+			"longitude":  "-2.2301",
+			"departures": "true",
+		})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"latitude": 53.4776,
+	"longitude": -2.2301,
+	"radiusMetres": 1000,
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"latitude": 53.4775,
+			"longitude": -2.2302,
+			"distanceMetres": 11
+		},
+		{
+			"stopAreaCode": "940GZZMASTP",
+			"distanceMetres": 890
+		}
+	],
+	"departuresError": "Metrolink departures data is outdated: last updated at 2021-03-21T15:34:56Z"
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given Metrolink stop areas near a location
+And the batch departures response has an unexpected status
+When NearbyJson is called with departures requested
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		naptanStopAreasNearbyGetter := mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl)
+		naptanStopAreasNearbyGetter.EXPECT().GetStopAreasNearby(ctx, 53.4776, -2.2301, float64(1000), 5).Return(givenNearbyStopAreas(t), nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMAPIC", "940GZZMASTP"}, map[string]string{}).Return(ioutil.NopCloser(bytes.NewBufferString(`{"error":"FUBAR"}`)), http.StatusBadRequest, nil)
+
+		stopsApi := givenNearbyApi(t, ctrl, givenNaptanStopAreasGetter(t, ctrl, ctx), naptanStopAreasNearbyGetter, batchDeparturesJsoner)
+
+		// When
+		rc, statusCode, err := stopsApi.NearbyJson(ctx, map[string]string{
+			"latitude":   "53.4776",
+			"longitude":  "-2.2301",
+			"departures": "true",
+		})
+
+		// Then
+		assert.Nil(t, rc)
+		assert.Equal(t, http.StatusInternalServerError, statusCode)
+		assert.EqualError(t, err, `unexpected status 400 getting departures for nearby Metrolink stop areas: {"error":"FUBAR"}`)
+	})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	searchQueryParameter = "q"
+
+	maxSearchQueryLength = 64
+)
+
+// SearchJson returns the Metrolink stop areas whose names best match a free-text query, e.g. "picadilly" or
+// "st peters". The query is matched against the NaPTAN names, StationLocations and TLAREFs of stop areas, allowing for
+// prefixes and typos, and the StopAreaCode of each result can be passed to the departures API.
+func (a *Api) SearchJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	query := strings.TrimSpace(queryParameters[searchQueryParameter])
+
+	if query == "" || len(query) > maxSearchQueryLength {
+		return a.encodeJsonErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid %s: must be between 1 and %d characters", searchQueryParameter, maxSearchQueryLength))
+	}
+
+	limit, err := parseLimit(queryParameters, limitQueryParameter, defaultLimit)
+	if err != nil {
+		return a.encodeJsonErrorResponse(http.StatusBadRequest, err.Error())
+	}
+
+	stopAreas, err := a.stopDirectoryGetter.GetStopDirectory(ctx)
+	if err != nil {
+		if errors.Cause(err) == redis.ErrNil {
+			return a.encodeJsonErrorResponse(http.StatusServiceUnavailable, "Metrolink stops have not been loaded")
+		}
+
+		return nil, http.StatusInternalServerError, errors.Wrap(err, "error getting Metrolink stop directory")
+	}
+
+	stations := a.getStationsByAtcoCode(ctx, stopAreas)
+
+	naptanStopAreas := a.getNaptanStopAreas(ctx)
+
+	index := newStopSearchIndex(a.convertToPublicApi(stopAreas, stations, nil, naptanStopAreas).StopAreas)
+
+	matches := index.search(query, limit)
+
+	results := &tfgm.MetrolinkStopSearchResults{
+		Query:     query,
+		StopAreas: make([]*tfgm.MetrolinkStopSearchResult, len(matches)),
+	}
+
+	for i, match := range matches {
+		results.StopAreas[i] = &tfgm.MetrolinkStopSearchResult{
+			StopAreaCode:    match.stopArea.StopAreaCode,
+			Name:            match.stopArea.Name,
+			StationLocation: match.stopArea.StationLocation,
+			Tlaref:          match.stopArea.Tlaref,
+			Score:           match.score,
+		}
+	}
+
+	return a.encodeJsonResponse(results, http.StatusOK)
+}
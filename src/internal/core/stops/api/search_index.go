@@ -0,0 +1,249 @@
+package api
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scores of the ways in which a search query can match a stop area. Typo-tolerant matches score less for each typo.
+const (
+	exactMatchScore      = 100
+	prefixMatchScore     = 90
+	wordPrefixMatchScore = 80
+	typoMatchScore       = 70
+	typoPenalty          = 10
+)
+
+// metrolinkNameSuffix is appended to the NaPTAN names of Metrolink stops, and is removed before matching so that it
+// does not match every stop area
+const metrolinkNameSuffix = "(manchester metrolink)"
+
+// stopSearchIndex matches search queries against the names of stop areas: the NaPTAN name, the StationLocation used
+// by the TfGM feed and the TLAREF. Names are normalised so that case, punctuation and apostrophes are ignored, e.g. so
+// that "st peters" matches "St Peter's Square".
+type stopSearchIndex struct {
+	entries []*stopSearchIndexEntry
+}
+
+type stopSearchIndexEntry struct {
+	stopArea *tfgm.MetrolinkStopArea
+	names    []string
+	tlaref   string
+}
+
+// stopSearchMatch is a stop area which matches a search query
+type stopSearchMatch struct {
+	stopArea *tfgm.MetrolinkStopArea
+	score    int
+}
+
+func newStopSearchIndex(stopAreas []*tfgm.MetrolinkStopArea) *stopSearchIndex {
+	index := &stopSearchIndex{
+		entries: make([]*stopSearchIndexEntry, 0, len(stopAreas)),
+	}
+
+	for _, stopArea := range stopAreas {
+		entry := &stopSearchIndexEntry{
+			stopArea: stopArea,
+			tlaref:   strings.ToLower(stopArea.Tlaref),
+		}
+
+		for _, name := range []string{stopArea.Name, stopArea.StationLocation} {
+			if normalisedName := normaliseStopName(name); normalisedName != "" {
+				entry.names = append(entry.names, normalisedName)
+			}
+		}
+
+		index.entries = append(index.entries, entry)
+	}
+
+	return index
+}
+
+// search returns up to limit stop areas matching the query, best match first. Stop areas with equal scores are
+// ordered by name.
+func (i *stopSearchIndex) search(query string, limit int) []*stopSearchMatch {
+	normalisedQuery := normaliseStopName(query)
+
+	matches := make([]*stopSearchMatch, 0)
+
+	if normalisedQuery == "" {
+		return matches
+	}
+
+	for _, entry := range i.entries {
+		if score := entry.score(normalisedQuery); score > 0 {
+			matches = append(matches, &stopSearchMatch{
+				stopArea: entry.stopArea,
+				score:    score,
+			})
+		}
+	}
+
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].score != matches[b].score {
+			return matches[a].score > matches[b].score
+		}
+
+		return displayName(matches[a].stopArea) < displayName(matches[b].stopArea)
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	return matches
+}
+
+// score returns the best score of the query against the names of the stop area, or 0 if the query does not match
+func (e *stopSearchIndexEntry) score(query string) int {
+	if e.tlaref != "" && query == e.tlaref {
+		return exactMatchScore
+	}
+
+	best := 0
+
+	for _, name := range e.names {
+		if score := scoreName(query, name); score > best {
+			best = score
+		}
+	}
+
+	return best
+}
+
+func scoreName(query string, name string) int {
+	if query == name {
+		return exactMatchScore
+	}
+
+	if strings.HasPrefix(name, query) {
+		return prefixMatchScore
+	}
+
+	if strings.Contains(" "+name, " "+query) {
+		return wordPrefixMatchScore
+	}
+
+	maxTypos := maxTyposForQuery(query)
+	if maxTypos == 0 {
+		return 0
+	}
+
+	// Compare the query with the start of the name and the start of each later word in the name, allowing the query to
+	// be a character shorter or longer than the text it is compared with, so that missing and extra characters are
+	// tolerated
+	typos := maxTypos + 1
+
+	words := strings.Fields(name)
+
+	for w := range words {
+		text := []rune(strings.Join(words[w:], " "))
+		queryLength := len([]rune(query))
+
+		for length := queryLength - 1; length <= queryLength+1; length++ {
+			if length < 1 || length > len(text) {
+				continue
+			}
+
+			if d := editDistance([]rune(query), text[:length]); d < typos {
+				typos = d
+			}
+		}
+	}
+
+	if typos > maxTypos {
+		return 0
+	}
+
+	return typoMatchScore - typos*typoPenalty
+}
+
+// maxTyposForQuery returns the number of typos tolerated in a query. Short queries must match exactly, as almost any
+// short text is a few edits away from them.
+func maxTyposForQuery(query string) int {
+	switch length := len([]rune(query)); {
+	case length < 4:
+		return 0
+	case length < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the number of single character insertions, deletions, substitutions and transpositions of
+// adjacent characters needed to change a into b
+func editDistance(a []rune, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+
+	return min
+}
+
+// normaliseStopName lower cases a stop name, removes the Metrolink suffix and apostrophes, and replaces other
+// punctuation with spaces
+func normaliseStopName(name string) string {
+	name = strings.ToLower(name)
+	name = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(name), metrolinkNameSuffix))
+	name = strings.NewReplacer("'", "", "’", "", "&", " and ").Replace(name)
+
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+
+		return ' '
+	}, name)
+
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// displayName returns the name by which a stop area is best known
+func displayName(stopArea *tfgm.MetrolinkStopArea) string {
+	if stopArea.Name != "" {
+		return stopArea.Name
+	}
+
+	if stopArea.StationLocation != "" {
+		return stopArea.StationLocation
+	}
+
+	return stopArea.StopAreaCode
+}
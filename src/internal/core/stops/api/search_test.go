@@ -0,0 +1,227 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/core/stops/api"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	mock_repository "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository"
+	"github.com/Marchie/tf-experiment/lambda/pkg/tfgm"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+// givenSearchApi returns an Api with a stop directory of four stop areas. Three have NaPTAN names, and Market Street is
+// only known by its StationLocation and TLAREF from the departures feed.
+func givenSearchApi(t *testing.T, ctrl *gomock.Controller, ctx context.Context) *api.Api {
+	t.Helper()
+
+	stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+	stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return([]*domain.MetrolinkStopArea{
+		{StopAreaCode: "940GZZMAPIC", Stops: []*domain.MetrolinkStop{{AtcoCode: "9400ZZMAPIC1"}}},
+		{StopAreaCode: "940GZZMAPGD", Stops: []*domain.MetrolinkStop{{AtcoCode: "9400ZZMAPGD1"}}},
+		{StopAreaCode: "940GZZMASTP", Stops: []*domain.MetrolinkStop{{AtcoCode: "9400ZZMASTP1"}}},
+		{StopAreaCode: "940GZZMAMKT", Stops: []*domain.MetrolinkStop{{AtcoCode: "9400ZZMAMKT1"}}},
+	}, nil)
+
+	systemStatusGetter := mock_repository.NewMockSystemStatusGetter(ctrl)
+	systemStatusGetter.EXPECT().Get(ctx).Return(&domain.SystemStatus{Generation: "1"}, nil)
+
+	metrolinkDeparturesGetter := mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl)
+	metrolinkDeparturesGetter.EXPECT().GetMulti(ctx, "1", gomock.Any()).Return([]*domain.MetrolinkDeparture{
+		{AtcoCode: "9400ZZMAPIC1", Tlaref: "PIC", StationLocation: "Piccadilly"},
+		{AtcoCode: "9400ZZMAMKT1", Tlaref: "MKT", StationLocation: "Market Street"},
+	}, nil)
+
+	naptanStopAreasGetter := mock_repository.NewMockNaptanStopAreasGetter(ctrl)
+	naptanStopAreasGetter.EXPECT().GetStopAreas(ctx).Return([]*domain.NaptanStopArea{
+		{StopAreaCode: "940GZZMAPIC", Name: "Piccadilly (Manchester Metrolink)"},
+		{StopAreaCode: "940GZZMAPGD", Name: "Piccadilly Gardens (Manchester Metrolink)"},
+		{StopAreaCode: "940GZZMASTP", Name: "St Peter's Square (Manchester Metrolink)"},
+	}, nil)
+
+	return api.NewApi(mockLogger(t), stopDirectoryGetter, mock_repository.NewMockNaptanStopsGetter(ctrl), naptanStopAreasGetter, mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), metrolinkDeparturesGetter, systemStatusGetter, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+}
+
+type searchResult struct {
+	stopAreaCode string
+	score        int
+}
+
+func thenSearchResults(t *testing.T, body string) []searchResult {
+	t.Helper()
+
+	var results tfgm.MetrolinkStopSearchResults
+	if err := json.Unmarshal([]byte(body), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	searchResults := make([]searchResult, 0, len(results.StopAreas))
+	for _, stopArea := range results.StopAreas {
+		searchResults = append(searchResults, searchResult{stopArea.StopAreaCode, stopArea.Score})
+	}
+
+	return searchResults
+}
+
+func TestApi_SearchJson(t *testing.T) {
+	t.Run(`Given a stop directory
+When SearchJson is called with a misspelt stop name
+Then the stop areas with similar names are returned, best match first`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopsApi := givenSearchApi(t, ctrl, ctx)
+
+		// When
+		rc, statusCode, err := stopsApi.SearchJson(ctx, map[string]string{"q": "picadilly"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{
+	"query": "picadilly",
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"name": "Piccadilly (Manchester Metrolink)",
+			"stationLocation": "Piccadilly",
+			"tlaref": "PIC",
+			"score": 60
+		},
+		{
+			"stopAreaCode": "940GZZMAPGD",
+			"name": "Piccadilly Gardens (Manchester Metrolink)",
+			"score": 60
+		}
+	]
+}
+`, readJson(t, rc))
+	})
+
+	searchTestCases := []struct {
+		description string
+		queryParams map[string]string
+		expResults  []searchResult
+	}{
+		{
+			description: "an exact stop name",
+			queryParams: map[string]string{"q": "Piccadilly"},
+			expResults:  []searchResult{{"940GZZMAPIC", 100}, {"940GZZMAPGD", 90}},
+		},
+		{
+			description: "a stop name without punctuation",
+			queryParams: map[string]string{"q": "st peters"},
+			expResults:  []searchResult{{"940GZZMASTP", 90}},
+		},
+		{
+			description: "a stop name with transposed letters",
+			queryParams: map[string]string{"q": "st petres square"},
+			expResults:  []searchResult{{"940GZZMASTP", 60}},
+		},
+		{
+			description: "a TLAREF",
+			queryParams: map[string]string{"q": "mkt"},
+			expResults:  []searchResult{{"940GZZMAMKT", 100}},
+		},
+		{
+			description: "the prefix of a StationLocation",
+			queryParams: map[string]string{"q": "MARKET"},
+			expResults:  []searchResult{{"940GZZMAMKT", 90}},
+		},
+		{
+			description: "a word in a stop name",
+			queryParams: map[string]string{"q": "gardens"},
+			expResults:  []searchResult{{"940GZZMAPGD", 80}},
+		},
+		{
+			description: "a limit",
+			queryParams: map[string]string{"q": "piccadilly", "limit": "1"},
+			expResults:  []searchResult{{"940GZZMAPIC", 100}},
+		},
+		{
+			description: "a name which does not match any stop",
+			queryParams: map[string]string{"q": "Victoria"},
+			expResults:  []searchResult{},
+		},
+	}
+
+	for _, tc := range searchTestCases {
+		t.Run(`Given a stop directory
+When SearchJson is called with `+tc.description+`
+Then the matching stop areas are returned with their scores`, func(t *testing.T) {
+			// Given
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ctx := context.Background()
+
+			stopsApi := givenSearchApi(t, ctrl, ctx)
+
+			// When
+			rc, statusCode, err := stopsApi.SearchJson(ctx, tc.queryParams)
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, statusCode)
+			assert.Equal(t, tc.expResults, thenSearchResults(t, readJson(t, rc)))
+		})
+	}
+
+	t.Run(`Given no search query
+When SearchJson is called
+Then a bad request response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopsApi := api.NewApi(mockLogger(t), mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl), mock_repository.NewMockNaptanStopsGetter(ctrl), mock_repository.NewMockNaptanStopAreasGetter(ctrl), mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.SearchJson(ctx, map[string]string{"q": " "})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, `{
+	"error": "invalid q: must be between 1 and 64 characters"
+}
+`, readJson(t, rc))
+	})
+
+	t.Run(`Given the stop directory has not been loaded
+When SearchJson is called
+Then a service unavailable response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		stopDirectoryGetter := mock_repository.NewMockMetrolinkStopDirectoryGetter(ctrl)
+		stopDirectoryGetter.EXPECT().GetStopDirectory(ctx).Return(nil, errors.Wrap(redis.ErrNil, "error getting Metrolink stop directory"))
+
+		stopsApi := api.NewApi(mockLogger(t), stopDirectoryGetter, mock_repository.NewMockNaptanStopsGetter(ctrl), mock_repository.NewMockNaptanStopAreasGetter(ctrl), mock_repository.NewMockNaptanStopAreasNearbyGetter(ctrl), mock_repository.NewMockMetrolinkDeparturesMultiGetter(ctrl), mock_repository.NewMockSystemStatusGetter(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		// When
+		rc, statusCode, err := stopsApi.SearchJson(ctx, map[string]string{"q": "piccadilly"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, `{
+	"error": "Metrolink stops have not been loaded"
+}
+`, readJson(t, rc))
+	})
+}
@@ -4,18 +4,62 @@ import (
 	"time"
 )
 
+// MetrolinkDepartureStatus describes where a tram is in relation to the stop
+type MetrolinkDepartureStatus string
+
+const (
+	// MetrolinkDepartureStatusDeparting means the tram is setting off from the stop
+	MetrolinkDepartureStatusDeparting MetrolinkDepartureStatus = "Departing"
+	// MetrolinkDepartureStatusArrived means the tram is at the stop
+	MetrolinkDepartureStatusArrived MetrolinkDepartureStatus = "Arrived"
+	// MetrolinkDepartureStatusDue means the tram is en route to the stop
+	MetrolinkDepartureStatusDue MetrolinkDepartureStatus = "Due"
+)
+
+// MetrolinkCarriages describes the length of a tram
+type MetrolinkCarriages string
+
+const (
+	// MetrolinkCarriagesSingle means the tram is a single unit
+	MetrolinkCarriagesSingle MetrolinkCarriages = "Single"
+	// MetrolinkCarriagesDouble means the tram is two units coupled together
+	MetrolinkCarriagesDouble MetrolinkCarriages = "Double"
+)
+
 type MetrolinkDepartures struct {
-	Departures  []*MetrolinkDeparture
-	LastUpdated time.Time
+	Departures                   []*MetrolinkDeparture
+	Messages                     []*MetrolinkMessage
+	PassengerInformationDisplays int
+	LastUpdated                  time.Time
 }
 
+// MetrolinkDeparture is a departure shown on a passenger information display. Wait is the value shown on the display,
+// which is either a number of minutes or "DELAY"; the loader parses it into WaitMinutes and Delayed, and normalises
+// Status and Carriages, before departures are stored.
 type MetrolinkDeparture struct {
-	AtcoCode    string
-	Order       int
-	Destination string
-	Carriages   string
-	Status      string
-	Wait        string
-	Platform    *string
-	LastUpdated time.Time
+	AtcoCode        string
+	Line            string
+	Direction       string
+	Tlaref          string
+	Pidref          string
+	StationLocation string
+	Order           int
+	Destination     string
+	Carriages       MetrolinkCarriages
+	Status          MetrolinkDepartureStatus
+	Wait            string
+	WaitMinutes     int
+	Delayed         bool
+	Platform        *string
+	LastUpdated     time.Time
+}
+
+// ExpectedDepartureTime returns the time at which the tram is expected to depart, based on the wait shown on the
+// display when it was last updated. ok is false when the tram is delayed, as there is then no expected time.
+func (d *MetrolinkDeparture) ExpectedDepartureTime() (expected time.Time, ok bool) {
+	if d.Delayed {
+		return time.Time{}, false
+	}
+
+	return d.LastUpdated.Add(time.Duration(d.WaitMinutes) * time.Minute), true
 }
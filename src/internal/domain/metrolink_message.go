@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type MetrolinkMessage struct {
+	AtcoCode    string
+	Line        string
+	Message     string
+	LastUpdated time.Time
+	Tlaref      string
+}
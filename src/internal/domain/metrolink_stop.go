@@ -0,0 +1,24 @@
+package domain
+
+import "regexp"
+
+// tlarefRegexp matches a TLAREF, the three letter code which TfGM use for a Metrolink stop
+var tlarefRegexp = regexp.MustCompile("^[A-Z]{3}$")
+
+// IsTlaref returns whether code is a TLAREF, such as SPS for St Peter's Square
+func IsTlaref(code string) bool {
+	return tlarefRegexp.MatchString(code)
+}
+
+// MetrolinkStopArea is a Metrolink stop, such as St Peter's Square, identified by its NaPTAN StopAreaCode, and the
+// stops within it, which are its platforms
+type MetrolinkStopArea struct {
+	StopAreaCode string
+	Stops        []*MetrolinkStop
+}
+
+// MetrolinkStop is a platform within a Metrolink stop area, identified by its NaPTAN AtcoCode
+type MetrolinkStop struct {
+	AtcoCode string
+	Platform *string
+}
@@ -0,0 +1,24 @@
+package domain
+
+// StopsInAreaChangeReport describes the differences between the NaPTAN stops in area which are stored and those fetched
+// by a load, and whether the load was accepted. Codes are sorted.
+type StopsInAreaChangeReport struct {
+	Accepted             bool              `json:"accepted"`
+	Reason               string            `json:"reason,omitempty"`
+	StoredStopAreas      int               `json:"storedStopAreas"`
+	FetchedStopAreas     int               `json:"fetchedStopAreas"`
+	StoredStops          int               `json:"storedStops"`
+	FetchedStops         int               `json:"fetchedStops"`
+	AddedStopAreaCodes   []string          `json:"addedStopAreaCodes"`
+	RemovedStopAreaCodes []string          `json:"removedStopAreaCodes"`
+	AddedAtcoCodes       []string          `json:"addedAtcoCodes"`
+	RemovedAtcoCodes     []string          `json:"removedAtcoCodes"`
+	ChangedStopAreas     []*StopAreaChange `json:"changedStopAreas"`
+}
+
+// StopAreaChange describes the AtcoCodes added to and removed from a stop area which is both stored and fetched
+type StopAreaChange struct {
+	StopAreaCode     string   `json:"stopAreaCode"`
+	AddedAtcoCodes   []string `json:"addedAtcoCodes"`
+	RemovedAtcoCodes []string `json:"removedAtcoCodes"`
+}
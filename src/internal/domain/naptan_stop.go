@@ -0,0 +1,28 @@
+package domain
+
+// NaptanStop is a NaPTAN stop point, e.g. a Metrolink platform. Status is the NaPTAN record status, e.g. "act" for an
+// active stop.
+type NaptanStop struct {
+	AtcoCode   string
+	CommonName string
+	Indicator  string
+	Street     string
+	Latitude   float64
+	Longitude  float64
+	Status     string
+}
+
+// NaptanStopArea is a NaPTAN stop area, e.g. a Metrolink stop, which groups the stop points of its platforms
+type NaptanStopArea struct {
+	StopAreaCode string
+	Name         string
+	Latitude     float64
+	Longitude    float64
+	Status       string
+}
+
+// NearbyStopArea is a stop area found by a search around a location, with its distance from that location
+type NearbyStopArea struct {
+	StopAreaCode   string
+	DistanceMetres float64
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// SystemStatusErrorCategory describes which part of the system caused the most recent failed load
+type SystemStatusErrorCategory string
+
+const (
+	// SystemStatusErrorCategorySource means departures could not be fetched from the source, e.g. the TfGM feed is down
+	SystemStatusErrorCategorySource SystemStatusErrorCategory = "source"
+	// SystemStatusErrorCategoryStorage means departures were fetched but could not be stored, e.g. Redis is unreachable
+	SystemStatusErrorCategoryStorage SystemStatusErrorCategory = "storage"
+)
+
+// SystemStatus describes the most recent complete snapshot of Metrolink departures. Departures and messages are stored
+// under the snapshot's Generation, so readers which use the Generation from one SystemStatus see a consistent snapshot
+// even while the next one is being loaded.
+//
+// The status also records the outcome of every load attempt. Failed attempts do not change the Generation or the
+// counts, which always describe the last successful load.
+type SystemStatus struct {
+	Generation                   string
+	LastUpdated                  time.Time
+	LastAttempt                  time.Time
+	LastSuccess                  time.Time
+	ConsecutiveFailures          int
+	LastErrorCategory            SystemStatusErrorCategory
+	PassengerInformationDisplays int
+	Departures                   int
+	Messages                     int
+}
@@ -9,6 +9,7 @@ import (
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
+	time "time"
 )
 
 // MockStopAreaDeparturesJsoner is a mock of StopAreaDeparturesJsoner interface
@@ -35,9 +36,9 @@ func (m *MockStopAreaDeparturesJsoner) EXPECT() *MockStopAreaDeparturesJsonerMoc
 }
 
 // Json mocks base method
-func (m *MockStopAreaDeparturesJsoner) Json(ctx context.Context, stopAreaCode string) (io.ReadCloser, int, error) {
+func (m *MockStopAreaDeparturesJsoner) Json(ctx context.Context, stopAreaCode string, queryParameters map[string]string) (io.ReadCloser, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Json", ctx, stopAreaCode)
+	ret := m.ctrl.Call(m, "Json", ctx, stopAreaCode, queryParameters)
 	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(int)
 	ret2, _ := ret[2].(error)
@@ -45,9 +46,348 @@ func (m *MockStopAreaDeparturesJsoner) Json(ctx context.Context, stopAreaCode st
 }
 
 // Json indicates an expected call of Json
-func (mr *MockStopAreaDeparturesJsonerMockRecorder) Json(ctx, stopAreaCode interface{}) *gomock.Call {
+func (mr *MockStopAreaDeparturesJsonerMockRecorder) Json(ctx, stopAreaCode, queryParameters interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Json", reflect.TypeOf((*MockStopAreaDeparturesJsoner)(nil).Json), ctx, stopAreaCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Json", reflect.TypeOf((*MockStopAreaDeparturesJsoner)(nil).Json), ctx, stopAreaCode, queryParameters)
+}
+
+// MockStaleDataJson is a mock of StaleDataJson interface
+type MockStaleDataJson struct {
+	ctrl     *gomock.Controller
+	recorder *MockStaleDataJsonMockRecorder
+}
+
+// MockStaleDataJsonMockRecorder is the mock recorder for MockStaleDataJson
+type MockStaleDataJsonMockRecorder struct {
+	mock *MockStaleDataJson
+}
+
+// NewMockStaleDataJson creates a new mock instance
+func NewMockStaleDataJson(ctrl *gomock.Controller) *MockStaleDataJson {
+	mock := &MockStaleDataJson{ctrl: ctrl}
+	mock.recorder = &MockStaleDataJsonMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStaleDataJson) EXPECT() *MockStaleDataJsonMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method
+func (m *MockStaleDataJson) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockStaleDataJsonMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockStaleDataJson)(nil).Close))
+}
+
+// DataAge mocks base method
+func (m *MockStaleDataJson) DataAge() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DataAge")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// DataAge indicates an expected call of DataAge
+func (mr *MockStaleDataJsonMockRecorder) DataAge() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DataAge", reflect.TypeOf((*MockStaleDataJson)(nil).DataAge))
+}
+
+// Read mocks base method
+func (m *MockStaleDataJson) Read(p []byte) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", p)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read
+func (mr *MockStaleDataJsonMockRecorder) Read(p interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockStaleDataJson)(nil).Read), p)
+}
+
+// MockBatchDeparturesJsoner is a mock of BatchDeparturesJsoner interface
+type MockBatchDeparturesJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchDeparturesJsonerMockRecorder
+}
+
+// MockBatchDeparturesJsonerMockRecorder is the mock recorder for MockBatchDeparturesJsoner
+type MockBatchDeparturesJsonerMockRecorder struct {
+	mock *MockBatchDeparturesJsoner
+}
+
+// NewMockBatchDeparturesJsoner creates a new mock instance
+func NewMockBatchDeparturesJsoner(ctrl *gomock.Controller) *MockBatchDeparturesJsoner {
+	mock := &MockBatchDeparturesJsoner{ctrl: ctrl}
+	mock.recorder = &MockBatchDeparturesJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockBatchDeparturesJsoner) EXPECT() *MockBatchDeparturesJsonerMockRecorder {
+	return m.recorder
+}
+
+// BatchJson mocks base method
+func (m *MockBatchDeparturesJsoner) BatchJson(ctx context.Context, stopAreaCodesOrAtcoCodes []string, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchJson", ctx, stopAreaCodesOrAtcoCodes, queryParameters)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BatchJson indicates an expected call of BatchJson
+func (mr *MockBatchDeparturesJsonerMockRecorder) BatchJson(ctx, stopAreaCodesOrAtcoCodes, queryParameters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchJson", reflect.TypeOf((*MockBatchDeparturesJsoner)(nil).BatchJson), ctx, stopAreaCodesOrAtcoCodes, queryParameters)
+}
+
+// MockStopAreaMessagesJsoner is a mock of StopAreaMessagesJsoner interface
+type MockStopAreaMessagesJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopAreaMessagesJsonerMockRecorder
+}
+
+// MockStopAreaMessagesJsonerMockRecorder is the mock recorder for MockStopAreaMessagesJsoner
+type MockStopAreaMessagesJsonerMockRecorder struct {
+	mock *MockStopAreaMessagesJsoner
+}
+
+// NewMockStopAreaMessagesJsoner creates a new mock instance
+func NewMockStopAreaMessagesJsoner(ctrl *gomock.Controller) *MockStopAreaMessagesJsoner {
+	mock := &MockStopAreaMessagesJsoner{ctrl: ctrl}
+	mock.recorder = &MockStopAreaMessagesJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStopAreaMessagesJsoner) EXPECT() *MockStopAreaMessagesJsonerMockRecorder {
+	return m.recorder
+}
+
+// MessagesJson mocks base method
+func (m *MockStopAreaMessagesJsoner) MessagesJson(ctx context.Context, stopAreaCodeOrAtcoCode string) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MessagesJson", ctx, stopAreaCodeOrAtcoCode)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// MessagesJson indicates an expected call of MessagesJson
+func (mr *MockStopAreaMessagesJsonerMockRecorder) MessagesJson(ctx, stopAreaCodeOrAtcoCode interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessagesJson", reflect.TypeOf((*MockStopAreaMessagesJsoner)(nil).MessagesJson), ctx, stopAreaCodeOrAtcoCode)
+}
+
+// MockLineMessagesJsoner is a mock of LineMessagesJsoner interface
+type MockLineMessagesJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockLineMessagesJsonerMockRecorder
+}
+
+// MockLineMessagesJsonerMockRecorder is the mock recorder for MockLineMessagesJsoner
+type MockLineMessagesJsonerMockRecorder struct {
+	mock *MockLineMessagesJsoner
+}
+
+// NewMockLineMessagesJsoner creates a new mock instance
+func NewMockLineMessagesJsoner(ctrl *gomock.Controller) *MockLineMessagesJsoner {
+	mock := &MockLineMessagesJsoner{ctrl: ctrl}
+	mock.recorder = &MockLineMessagesJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockLineMessagesJsoner) EXPECT() *MockLineMessagesJsonerMockRecorder {
+	return m.recorder
+}
+
+// LineMessagesJson mocks base method
+func (m *MockLineMessagesJsoner) LineMessagesJson(ctx context.Context, line string) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LineMessagesJson", ctx, line)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LineMessagesJson indicates an expected call of LineMessagesJson
+func (mr *MockLineMessagesJsonerMockRecorder) LineMessagesJson(ctx, line interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LineMessagesJson", reflect.TypeOf((*MockLineMessagesJsoner)(nil).LineMessagesJson), ctx, line)
+}
+
+// MockStopDirectoryJsoner is a mock of StopDirectoryJsoner interface
+type MockStopDirectoryJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopDirectoryJsonerMockRecorder
+}
+
+// MockStopDirectoryJsonerMockRecorder is the mock recorder for MockStopDirectoryJsoner
+type MockStopDirectoryJsonerMockRecorder struct {
+	mock *MockStopDirectoryJsoner
+}
+
+// NewMockStopDirectoryJsoner creates a new mock instance
+func NewMockStopDirectoryJsoner(ctrl *gomock.Controller) *MockStopDirectoryJsoner {
+	mock := &MockStopDirectoryJsoner{ctrl: ctrl}
+	mock.recorder = &MockStopDirectoryJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStopDirectoryJsoner) EXPECT() *MockStopDirectoryJsonerMockRecorder {
+	return m.recorder
+}
+
+// StopsJson mocks base method
+func (m *MockStopDirectoryJsoner) StopsJson(ctx context.Context) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopsJson", ctx)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StopsJson indicates an expected call of StopsJson
+func (mr *MockStopDirectoryJsonerMockRecorder) StopsJson(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopsJson", reflect.TypeOf((*MockStopDirectoryJsoner)(nil).StopsJson), ctx)
+}
+
+// MockNearbyStopsJsoner is a mock of NearbyStopsJsoner interface
+type MockNearbyStopsJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockNearbyStopsJsonerMockRecorder
+}
+
+// MockNearbyStopsJsonerMockRecorder is the mock recorder for MockNearbyStopsJsoner
+type MockNearbyStopsJsonerMockRecorder struct {
+	mock *MockNearbyStopsJsoner
+}
+
+// NewMockNearbyStopsJsoner creates a new mock instance
+func NewMockNearbyStopsJsoner(ctrl *gomock.Controller) *MockNearbyStopsJsoner {
+	mock := &MockNearbyStopsJsoner{ctrl: ctrl}
+	mock.recorder = &MockNearbyStopsJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNearbyStopsJsoner) EXPECT() *MockNearbyStopsJsonerMockRecorder {
+	return m.recorder
+}
+
+// NearbyJson mocks base method
+func (m *MockNearbyStopsJsoner) NearbyJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NearbyJson", ctx, queryParameters)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// NearbyJson indicates an expected call of NearbyJson
+func (mr *MockNearbyStopsJsonerMockRecorder) NearbyJson(ctx, queryParameters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NearbyJson", reflect.TypeOf((*MockNearbyStopsJsoner)(nil).NearbyJson), ctx, queryParameters)
+}
+
+// MockStopSearchJsoner is a mock of StopSearchJsoner interface
+type MockStopSearchJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopSearchJsonerMockRecorder
+}
+
+// MockStopSearchJsonerMockRecorder is the mock recorder for MockStopSearchJsoner
+type MockStopSearchJsonerMockRecorder struct {
+	mock *MockStopSearchJsoner
+}
+
+// NewMockStopSearchJsoner creates a new mock instance
+func NewMockStopSearchJsoner(ctrl *gomock.Controller) *MockStopSearchJsoner {
+	mock := &MockStopSearchJsoner{ctrl: ctrl}
+	mock.recorder = &MockStopSearchJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStopSearchJsoner) EXPECT() *MockStopSearchJsonerMockRecorder {
+	return m.recorder
+}
+
+// SearchJson mocks base method
+func (m *MockStopSearchJsoner) SearchJson(ctx context.Context, queryParameters map[string]string) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchJson", ctx, queryParameters)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchJson indicates an expected call of SearchJson
+func (mr *MockStopSearchJsonerMockRecorder) SearchJson(ctx, queryParameters interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchJson", reflect.TypeOf((*MockStopSearchJsoner)(nil).SearchJson), ctx, queryParameters)
+}
+
+// MockSystemStatusJsoner is a mock of SystemStatusJsoner interface
+type MockSystemStatusJsoner struct {
+	ctrl     *gomock.Controller
+	recorder *MockSystemStatusJsonerMockRecorder
+}
+
+// MockSystemStatusJsonerMockRecorder is the mock recorder for MockSystemStatusJsoner
+type MockSystemStatusJsonerMockRecorder struct {
+	mock *MockSystemStatusJsoner
+}
+
+// NewMockSystemStatusJsoner creates a new mock instance
+func NewMockSystemStatusJsoner(ctrl *gomock.Controller) *MockSystemStatusJsoner {
+	mock := &MockSystemStatusJsoner{ctrl: ctrl}
+	mock.recorder = &MockSystemStatusJsonerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSystemStatusJsoner) EXPECT() *MockSystemStatusJsonerMockRecorder {
+	return m.recorder
+}
+
+// StatusJson mocks base method
+func (m *MockSystemStatusJsoner) StatusJson(ctx context.Context) (io.ReadCloser, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatusJson", ctx)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// StatusJson indicates an expected call of StatusJson
+func (mr *MockSystemStatusJsonerMockRecorder) StatusJson(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatusJson", reflect.TypeOf((*MockSystemStatusJsoner)(nil).StatusJson), ctx)
 }
 
 // MockEventScheduler is a mock of EventScheduler interface
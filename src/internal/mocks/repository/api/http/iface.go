@@ -6,8 +6,8 @@ package mock_http
 
 import (
 	context "context"
+	compression "github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
 	gomock "github.com/golang/mock/gomock"
-	io "io"
 	reflect "reflect"
 )
 
@@ -35,10 +35,10 @@ func (m *MockZipFileFetcher) EXPECT() *MockZipFileFetcherMockRecorder {
 }
 
 // FetchZipFile mocks base method
-func (m *MockZipFileFetcher) FetchZipFile(ctx context.Context) (io.ReadCloser, error) {
+func (m *MockZipFileFetcher) FetchZipFile(ctx context.Context) (compression.Archive, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FetchZipFile", ctx)
-	ret0, _ := ret[0].(io.ReadCloser)
+	ret0, _ := ret[0].(compression.Archive)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -5,11 +5,78 @@
 package mock_compression
 
 import (
+	compression "github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
 	gomock "github.com/golang/mock/gomock"
 	io "io"
 	reflect "reflect"
 )
 
+// MockArchive is a mock of Archive interface
+type MockArchive struct {
+	ctrl     *gomock.Controller
+	recorder *MockArchiveMockRecorder
+}
+
+// MockArchiveMockRecorder is the mock recorder for MockArchive
+type MockArchiveMockRecorder struct {
+	mock *MockArchive
+}
+
+// NewMockArchive creates a new mock instance
+func NewMockArchive(ctrl *gomock.Controller) *MockArchive {
+	mock := &MockArchive{ctrl: ctrl}
+	mock.recorder = &MockArchiveMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockArchive) EXPECT() *MockArchiveMockRecorder {
+	return m.recorder
+}
+
+// Close mocks base method
+func (m *MockArchive) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close
+func (mr *MockArchiveMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockArchive)(nil).Close))
+}
+
+// ReadAt mocks base method
+func (m *MockArchive) ReadAt(p []byte, off int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAt", p, off)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAt indicates an expected call of ReadAt
+func (mr *MockArchiveMockRecorder) ReadAt(p, off interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAt", reflect.TypeOf((*MockArchive)(nil).ReadAt), p, off)
+}
+
+// Size mocks base method
+func (m *MockArchive) Size() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Size")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// Size indicates an expected call of Size
+func (mr *MockArchiveMockRecorder) Size() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Size", reflect.TypeOf((*MockArchive)(nil).Size))
+}
+
 // MockExtractor is a mock of Extractor interface
 type MockExtractor struct {
 	ctrl     *gomock.Controller
@@ -34,16 +101,16 @@ func (m *MockExtractor) EXPECT() *MockExtractorMockRecorder {
 }
 
 // ExtractFile mocks base method
-func (m *MockExtractor) ExtractFile(zipData []byte, filename string) (io.ReadCloser, error) {
+func (m *MockExtractor) ExtractFile(archive compression.Archive, filename string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExtractFile", zipData, filename)
+	ret := m.ctrl.Call(m, "ExtractFile", archive, filename)
 	ret0, _ := ret[0].(io.ReadCloser)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ExtractFile indicates an expected call of ExtractFile
-func (mr *MockExtractorMockRecorder) ExtractFile(zipData, filename interface{}) *gomock.Call {
+func (mr *MockExtractorMockRecorder) ExtractFile(archive, filename interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractFile", reflect.TypeOf((*MockExtractor)(nil).ExtractFile), zipData, filename)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractFile", reflect.TypeOf((*MockExtractor)(nil).ExtractFile), archive, filename)
 }
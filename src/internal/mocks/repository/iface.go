@@ -9,7 +9,6 @@ import (
 	domain "github.com/Marchie/tf-experiment/lambda/internal/domain"
 	gomock "github.com/golang/mock/gomock"
 	reflect "reflect"
-	time "time"
 )
 
 // MockAtcoCodeLister is a mock of AtcoCodeLister interface
@@ -35,19 +34,20 @@ func (m *MockAtcoCodeLister) EXPECT() *MockAtcoCodeListerMockRecorder {
 	return m.recorder
 }
 
-// GetAtcoCodesInStopArea mocks base method
-func (m *MockAtcoCodeLister) GetAtcoCodesInStopArea(stopAreaCode string) ([]string, error) {
+// GetStopAreaForAtcoCode mocks base method
+func (m *MockAtcoCodeLister) GetStopAreaForAtcoCode(ctx context.Context, atcoCode string) (string, []string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAtcoCodesInStopArea", stopAreaCode)
-	ret0, _ := ret[0].([]string)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "GetStopAreaForAtcoCode", ctx, atcoCode)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
-// GetAtcoCodesInStopArea indicates an expected call of GetAtcoCodesInStopArea
-func (mr *MockAtcoCodeListerMockRecorder) GetAtcoCodesInStopArea(stopAreaCode interface{}) *gomock.Call {
+// GetStopAreaForAtcoCode indicates an expected call of GetStopAreaForAtcoCode
+func (mr *MockAtcoCodeListerMockRecorder) GetStopAreaForAtcoCode(ctx, atcoCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAtcoCodesInStopArea", reflect.TypeOf((*MockAtcoCodeLister)(nil).GetAtcoCodesInStopArea), stopAreaCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStopAreaForAtcoCode", reflect.TypeOf((*MockAtcoCodeLister)(nil).GetStopAreaForAtcoCode), ctx, atcoCode)
 }
 
 // MockEventScheduler is a mock of EventScheduler interface
@@ -125,42 +125,42 @@ func (mr *MockMetrolinkDeparturesFetcherMockRecorder) Fetch(ctx interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockMetrolinkDeparturesFetcher)(nil).Fetch), ctx)
 }
 
-// MockMetrolinkDeparturesGetter is a mock of MetrolinkDeparturesGetter interface
-type MockMetrolinkDeparturesGetter struct {
+// MockMetrolinkDeparturesMultiGetter is a mock of MetrolinkDeparturesMultiGetter interface
+type MockMetrolinkDeparturesMultiGetter struct {
 	ctrl     *gomock.Controller
-	recorder *MockMetrolinkDeparturesGetterMockRecorder
+	recorder *MockMetrolinkDeparturesMultiGetterMockRecorder
 }
 
-// MockMetrolinkDeparturesGetterMockRecorder is the mock recorder for MockMetrolinkDeparturesGetter
-type MockMetrolinkDeparturesGetterMockRecorder struct {
-	mock *MockMetrolinkDeparturesGetter
+// MockMetrolinkDeparturesMultiGetterMockRecorder is the mock recorder for MockMetrolinkDeparturesMultiGetter
+type MockMetrolinkDeparturesMultiGetterMockRecorder struct {
+	mock *MockMetrolinkDeparturesMultiGetter
 }
 
-// NewMockMetrolinkDeparturesGetter creates a new mock instance
-func NewMockMetrolinkDeparturesGetter(ctrl *gomock.Controller) *MockMetrolinkDeparturesGetter {
-	mock := &MockMetrolinkDeparturesGetter{ctrl: ctrl}
-	mock.recorder = &MockMetrolinkDeparturesGetterMockRecorder{mock}
+// NewMockMetrolinkDeparturesMultiGetter creates a new mock instance
+func NewMockMetrolinkDeparturesMultiGetter(ctrl *gomock.Controller) *MockMetrolinkDeparturesMultiGetter {
+	mock := &MockMetrolinkDeparturesMultiGetter{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkDeparturesMultiGetterMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockMetrolinkDeparturesGetter) EXPECT() *MockMetrolinkDeparturesGetterMockRecorder {
+func (m *MockMetrolinkDeparturesMultiGetter) EXPECT() *MockMetrolinkDeparturesMultiGetterMockRecorder {
 	return m.recorder
 }
 
-// Get mocks base method
-func (m *MockMetrolinkDeparturesGetter) Get(ctx context.Context, stopAreaCode string) ([]*domain.MetrolinkDeparture, error) {
+// GetMulti mocks base method
+func (m *MockMetrolinkDeparturesMultiGetter) GetMulti(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkDeparture, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, stopAreaCode)
+	ret := m.ctrl.Call(m, "GetMulti", ctx, generation, atcoCodes)
 	ret0, _ := ret[0].([]*domain.MetrolinkDeparture)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Get indicates an expected call of Get
-func (mr *MockMetrolinkDeparturesGetterMockRecorder) Get(ctx, stopAreaCode interface{}) *gomock.Call {
+// GetMulti indicates an expected call of GetMulti
+func (mr *MockMetrolinkDeparturesMultiGetterMockRecorder) GetMulti(ctx, generation, atcoCodes interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetrolinkDeparturesGetter)(nil).Get), ctx, stopAreaCode)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMulti", reflect.TypeOf((*MockMetrolinkDeparturesMultiGetter)(nil).GetMulti), ctx, generation, atcoCodes)
 }
 
 // MockMetrolinkDeparturesStorer is a mock of MetrolinkDeparturesStorer interface
@@ -187,17 +187,205 @@ func (m *MockMetrolinkDeparturesStorer) EXPECT() *MockMetrolinkDeparturesStorerM
 }
 
 // Store mocks base method
-func (m *MockMetrolinkDeparturesStorer) Store(ctx context.Context, departures []*domain.MetrolinkDeparture) error {
+func (m *MockMetrolinkDeparturesStorer) Store(ctx context.Context, generation string, departures []*domain.MetrolinkDeparture) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Store", ctx, departures)
+	ret := m.ctrl.Call(m, "Store", ctx, generation, departures)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Store indicates an expected call of Store
-func (mr *MockMetrolinkDeparturesStorerMockRecorder) Store(ctx, departures interface{}) *gomock.Call {
+func (mr *MockMetrolinkDeparturesStorerMockRecorder) Store(ctx, generation, departures interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockMetrolinkDeparturesStorer)(nil).Store), ctx, generation, departures)
+}
+
+// MockMetrolinkMessagesGetter is a mock of MetrolinkMessagesGetter interface
+type MockMetrolinkMessagesGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkMessagesGetterMockRecorder
+}
+
+// MockMetrolinkMessagesGetterMockRecorder is the mock recorder for MockMetrolinkMessagesGetter
+type MockMetrolinkMessagesGetterMockRecorder struct {
+	mock *MockMetrolinkMessagesGetter
+}
+
+// NewMockMetrolinkMessagesGetter creates a new mock instance
+func NewMockMetrolinkMessagesGetter(ctrl *gomock.Controller) *MockMetrolinkMessagesGetter {
+	mock := &MockMetrolinkMessagesGetter{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkMessagesGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkMessagesGetter) EXPECT() *MockMetrolinkMessagesGetterMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method
+func (m *MockMetrolinkMessagesGetter) Get(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, generation, atcoCodes)
+	ret0, _ := ret[0].([]*domain.MetrolinkMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockMetrolinkMessagesGetterMockRecorder) Get(ctx, generation, atcoCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockMetrolinkMessagesGetter)(nil).Get), ctx, generation, atcoCodes)
+}
+
+// MockMetrolinkLineMessagesGetter is a mock of MetrolinkLineMessagesGetter interface
+type MockMetrolinkLineMessagesGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkLineMessagesGetterMockRecorder
+}
+
+// MockMetrolinkLineMessagesGetterMockRecorder is the mock recorder for MockMetrolinkLineMessagesGetter
+type MockMetrolinkLineMessagesGetterMockRecorder struct {
+	mock *MockMetrolinkLineMessagesGetter
+}
+
+// NewMockMetrolinkLineMessagesGetter creates a new mock instance
+func NewMockMetrolinkLineMessagesGetter(ctrl *gomock.Controller) *MockMetrolinkLineMessagesGetter {
+	mock := &MockMetrolinkLineMessagesGetter{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkLineMessagesGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkLineMessagesGetter) EXPECT() *MockMetrolinkLineMessagesGetterMockRecorder {
+	return m.recorder
+}
+
+// GetForLine mocks base method
+func (m *MockMetrolinkLineMessagesGetter) GetForLine(ctx context.Context, generation, line string) ([]*domain.MetrolinkMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForLine", ctx, generation, line)
+	ret0, _ := ret[0].([]*domain.MetrolinkMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForLine indicates an expected call of GetForLine
+func (mr *MockMetrolinkLineMessagesGetterMockRecorder) GetForLine(ctx, generation, line interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForLine", reflect.TypeOf((*MockMetrolinkLineMessagesGetter)(nil).GetForLine), ctx, generation, line)
+}
+
+// MockMetrolinkMessagesStorer is a mock of MetrolinkMessagesStorer interface
+type MockMetrolinkMessagesStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkMessagesStorerMockRecorder
+}
+
+// MockMetrolinkMessagesStorerMockRecorder is the mock recorder for MockMetrolinkMessagesStorer
+type MockMetrolinkMessagesStorerMockRecorder struct {
+	mock *MockMetrolinkMessagesStorer
+}
+
+// NewMockMetrolinkMessagesStorer creates a new mock instance
+func NewMockMetrolinkMessagesStorer(ctrl *gomock.Controller) *MockMetrolinkMessagesStorer {
+	mock := &MockMetrolinkMessagesStorer{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkMessagesStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkMessagesStorer) EXPECT() *MockMetrolinkMessagesStorerMockRecorder {
+	return m.recorder
+}
+
+// Store mocks base method
+func (m *MockMetrolinkMessagesStorer) Store(ctx context.Context, generation string, messages []*domain.MetrolinkMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, generation, messages)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store
+func (mr *MockMetrolinkMessagesStorerMockRecorder) Store(ctx, generation, messages interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockMetrolinkMessagesStorer)(nil).Store), ctx, generation, messages)
+}
+
+// MockMetrolinkTlarefGetter is a mock of MetrolinkTlarefGetter interface
+type MockMetrolinkTlarefGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkTlarefGetterMockRecorder
+}
+
+// MockMetrolinkTlarefGetterMockRecorder is the mock recorder for MockMetrolinkTlarefGetter
+type MockMetrolinkTlarefGetterMockRecorder struct {
+	mock *MockMetrolinkTlarefGetter
+}
+
+// NewMockMetrolinkTlarefGetter creates a new mock instance
+func NewMockMetrolinkTlarefGetter(ctrl *gomock.Controller) *MockMetrolinkTlarefGetter {
+	mock := &MockMetrolinkTlarefGetter{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkTlarefGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkTlarefGetter) EXPECT() *MockMetrolinkTlarefGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStopAreaCodeForTlaref mocks base method
+func (m *MockMetrolinkTlarefGetter) GetStopAreaCodeForTlaref(ctx context.Context, tlaref string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStopAreaCodeForTlaref", ctx, tlaref)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStopAreaCodeForTlaref indicates an expected call of GetStopAreaCodeForTlaref
+func (mr *MockMetrolinkTlarefGetterMockRecorder) GetStopAreaCodeForTlaref(ctx, tlaref interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockMetrolinkDeparturesStorer)(nil).Store), ctx, departures)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStopAreaCodeForTlaref", reflect.TypeOf((*MockMetrolinkTlarefGetter)(nil).GetStopAreaCodeForTlaref), ctx, tlaref)
+}
+
+// MockMetrolinkTlarefsStorer is a mock of MetrolinkTlarefsStorer interface
+type MockMetrolinkTlarefsStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkTlarefsStorerMockRecorder
+}
+
+// MockMetrolinkTlarefsStorerMockRecorder is the mock recorder for MockMetrolinkTlarefsStorer
+type MockMetrolinkTlarefsStorerMockRecorder struct {
+	mock *MockMetrolinkTlarefsStorer
+}
+
+// NewMockMetrolinkTlarefsStorer creates a new mock instance
+func NewMockMetrolinkTlarefsStorer(ctrl *gomock.Controller) *MockMetrolinkTlarefsStorer {
+	mock := &MockMetrolinkTlarefsStorer{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkTlarefsStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkTlarefsStorer) EXPECT() *MockMetrolinkTlarefsStorerMockRecorder {
+	return m.recorder
+}
+
+// StoreTlarefs mocks base method
+func (m *MockMetrolinkTlarefsStorer) StoreTlarefs(ctx context.Context, stopAreaCodesByTlaref map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreTlarefs", ctx, stopAreaCodesByTlaref)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreTlarefs indicates an expected call of StoreTlarefs
+func (mr *MockMetrolinkTlarefsStorerMockRecorder) StoreTlarefs(ctx, stopAreaCodesByTlaref interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreTlarefs", reflect.TypeOf((*MockMetrolinkTlarefsStorer)(nil).StoreTlarefs), ctx, stopAreaCodesByTlaref)
 }
 
 // MockSystemStatusGetter is a mock of SystemStatusGetter interface
@@ -224,10 +412,10 @@ func (m *MockSystemStatusGetter) EXPECT() *MockSystemStatusGetterMockRecorder {
 }
 
 // Get mocks base method
-func (m *MockSystemStatusGetter) Get(ctx context.Context) (*time.Time, error) {
+func (m *MockSystemStatusGetter) Get(ctx context.Context) (*domain.SystemStatus, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Get", ctx)
-	ret0, _ := ret[0].(*time.Time)
+	ret0, _ := ret[0].(*domain.SystemStatus)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -262,17 +450,319 @@ func (m *MockSystemStatusSetter) EXPECT() *MockSystemStatusSetterMockRecorder {
 }
 
 // Set mocks base method
-func (m *MockSystemStatusSetter) Set(ctx context.Context, lastUpdated time.Time) error {
+func (m *MockSystemStatusSetter) Set(ctx context.Context, systemStatus *domain.SystemStatus) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Set", ctx, lastUpdated)
+	ret := m.ctrl.Call(m, "Set", ctx, systemStatus)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Set indicates an expected call of Set
-func (mr *MockSystemStatusSetterMockRecorder) Set(ctx, lastUpdated interface{}) *gomock.Call {
+func (mr *MockSystemStatusSetterMockRecorder) Set(ctx, systemStatus interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSystemStatusSetter)(nil).Set), ctx, systemStatus)
+}
+
+// MockMetrolinkStopDirectoryGetter is a mock of MetrolinkStopDirectoryGetter interface
+type MockMetrolinkStopDirectoryGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkStopDirectoryGetterMockRecorder
+}
+
+// MockMetrolinkStopDirectoryGetterMockRecorder is the mock recorder for MockMetrolinkStopDirectoryGetter
+type MockMetrolinkStopDirectoryGetterMockRecorder struct {
+	mock *MockMetrolinkStopDirectoryGetter
+}
+
+// NewMockMetrolinkStopDirectoryGetter creates a new mock instance
+func NewMockMetrolinkStopDirectoryGetter(ctrl *gomock.Controller) *MockMetrolinkStopDirectoryGetter {
+	mock := &MockMetrolinkStopDirectoryGetter{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkStopDirectoryGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkStopDirectoryGetter) EXPECT() *MockMetrolinkStopDirectoryGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStopDirectory mocks base method
+func (m *MockMetrolinkStopDirectoryGetter) GetStopDirectory(ctx context.Context) ([]*domain.MetrolinkStopArea, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStopDirectory", ctx)
+	ret0, _ := ret[0].([]*domain.MetrolinkStopArea)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStopDirectory indicates an expected call of GetStopDirectory
+func (mr *MockMetrolinkStopDirectoryGetterMockRecorder) GetStopDirectory(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStopDirectory", reflect.TypeOf((*MockMetrolinkStopDirectoryGetter)(nil).GetStopDirectory), ctx)
+}
+
+// MockMetrolinkStopDirectoryStorer is a mock of MetrolinkStopDirectoryStorer interface
+type MockMetrolinkStopDirectoryStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetrolinkStopDirectoryStorerMockRecorder
+}
+
+// MockMetrolinkStopDirectoryStorerMockRecorder is the mock recorder for MockMetrolinkStopDirectoryStorer
+type MockMetrolinkStopDirectoryStorerMockRecorder struct {
+	mock *MockMetrolinkStopDirectoryStorer
+}
+
+// NewMockMetrolinkStopDirectoryStorer creates a new mock instance
+func NewMockMetrolinkStopDirectoryStorer(ctrl *gomock.Controller) *MockMetrolinkStopDirectoryStorer {
+	mock := &MockMetrolinkStopDirectoryStorer{ctrl: ctrl}
+	mock.recorder = &MockMetrolinkStopDirectoryStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockMetrolinkStopDirectoryStorer) EXPECT() *MockMetrolinkStopDirectoryStorerMockRecorder {
+	return m.recorder
+}
+
+// StoreStopDirectory mocks base method
+func (m *MockMetrolinkStopDirectoryStorer) StoreStopDirectory(ctx context.Context, stopAreas []*domain.MetrolinkStopArea) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreStopDirectory", ctx, stopAreas)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreStopDirectory indicates an expected call of StoreStopDirectory
+func (mr *MockMetrolinkStopDirectoryStorerMockRecorder) StoreStopDirectory(ctx, stopAreas interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStopDirectory", reflect.TypeOf((*MockMetrolinkStopDirectoryStorer)(nil).StoreStopDirectory), ctx, stopAreas)
+}
+
+// MockNaptanStopsFetcher is a mock of NaptanStopsFetcher interface
+type MockNaptanStopsFetcher struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopsFetcherMockRecorder
+}
+
+// MockNaptanStopsFetcherMockRecorder is the mock recorder for MockNaptanStopsFetcher
+type MockNaptanStopsFetcherMockRecorder struct {
+	mock *MockNaptanStopsFetcher
+}
+
+// NewMockNaptanStopsFetcher creates a new mock instance
+func NewMockNaptanStopsFetcher(ctrl *gomock.Controller) *MockNaptanStopsFetcher {
+	mock := &MockNaptanStopsFetcher{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopsFetcherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopsFetcher) EXPECT() *MockNaptanStopsFetcherMockRecorder {
+	return m.recorder
+}
+
+// FetchStopsAndStopAreas mocks base method
+func (m *MockNaptanStopsFetcher) FetchStopsAndStopAreas(ctx context.Context) ([]*domain.NaptanStop, []*domain.NaptanStopArea, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchStopsAndStopAreas", ctx)
+	ret0, _ := ret[0].([]*domain.NaptanStop)
+	ret1, _ := ret[1].([]*domain.NaptanStopArea)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FetchStopsAndStopAreas indicates an expected call of FetchStopsAndStopAreas
+func (mr *MockNaptanStopsFetcherMockRecorder) FetchStopsAndStopAreas(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchStopsAndStopAreas", reflect.TypeOf((*MockNaptanStopsFetcher)(nil).FetchStopsAndStopAreas), ctx)
+}
+
+// MockNaptanStopsGetter is a mock of NaptanStopsGetter interface
+type MockNaptanStopsGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopsGetterMockRecorder
+}
+
+// MockNaptanStopsGetterMockRecorder is the mock recorder for MockNaptanStopsGetter
+type MockNaptanStopsGetterMockRecorder struct {
+	mock *MockNaptanStopsGetter
+}
+
+// NewMockNaptanStopsGetter creates a new mock instance
+func NewMockNaptanStopsGetter(ctrl *gomock.Controller) *MockNaptanStopsGetter {
+	mock := &MockNaptanStopsGetter{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopsGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopsGetter) EXPECT() *MockNaptanStopsGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStops mocks base method
+func (m *MockNaptanStopsGetter) GetStops(ctx context.Context) ([]*domain.NaptanStop, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStops", ctx)
+	ret0, _ := ret[0].([]*domain.NaptanStop)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStops indicates an expected call of GetStops
+func (mr *MockNaptanStopsGetterMockRecorder) GetStops(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStops", reflect.TypeOf((*MockNaptanStopsGetter)(nil).GetStops), ctx)
+}
+
+// MockNaptanStopsStorer is a mock of NaptanStopsStorer interface
+type MockNaptanStopsStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopsStorerMockRecorder
+}
+
+// MockNaptanStopsStorerMockRecorder is the mock recorder for MockNaptanStopsStorer
+type MockNaptanStopsStorerMockRecorder struct {
+	mock *MockNaptanStopsStorer
+}
+
+// NewMockNaptanStopsStorer creates a new mock instance
+func NewMockNaptanStopsStorer(ctrl *gomock.Controller) *MockNaptanStopsStorer {
+	mock := &MockNaptanStopsStorer{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopsStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopsStorer) EXPECT() *MockNaptanStopsStorerMockRecorder {
+	return m.recorder
+}
+
+// StoreStops mocks base method
+func (m *MockNaptanStopsStorer) StoreStops(ctx context.Context, stops []*domain.NaptanStop) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreStops", ctx, stops)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreStops indicates an expected call of StoreStops
+func (mr *MockNaptanStopsStorerMockRecorder) StoreStops(ctx, stops interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStops", reflect.TypeOf((*MockNaptanStopsStorer)(nil).StoreStops), ctx, stops)
+}
+
+// MockNaptanStopAreasGetter is a mock of NaptanStopAreasGetter interface
+type MockNaptanStopAreasGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopAreasGetterMockRecorder
+}
+
+// MockNaptanStopAreasGetterMockRecorder is the mock recorder for MockNaptanStopAreasGetter
+type MockNaptanStopAreasGetterMockRecorder struct {
+	mock *MockNaptanStopAreasGetter
+}
+
+// NewMockNaptanStopAreasGetter creates a new mock instance
+func NewMockNaptanStopAreasGetter(ctrl *gomock.Controller) *MockNaptanStopAreasGetter {
+	mock := &MockNaptanStopAreasGetter{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopAreasGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopAreasGetter) EXPECT() *MockNaptanStopAreasGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStopAreas mocks base method
+func (m *MockNaptanStopAreasGetter) GetStopAreas(ctx context.Context) ([]*domain.NaptanStopArea, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStopAreas", ctx)
+	ret0, _ := ret[0].([]*domain.NaptanStopArea)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStopAreas indicates an expected call of GetStopAreas
+func (mr *MockNaptanStopAreasGetterMockRecorder) GetStopAreas(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStopAreas", reflect.TypeOf((*MockNaptanStopAreasGetter)(nil).GetStopAreas), ctx)
+}
+
+// MockNaptanStopAreasNearbyGetter is a mock of NaptanStopAreasNearbyGetter interface
+type MockNaptanStopAreasNearbyGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopAreasNearbyGetterMockRecorder
+}
+
+// MockNaptanStopAreasNearbyGetterMockRecorder is the mock recorder for MockNaptanStopAreasNearbyGetter
+type MockNaptanStopAreasNearbyGetterMockRecorder struct {
+	mock *MockNaptanStopAreasNearbyGetter
+}
+
+// NewMockNaptanStopAreasNearbyGetter creates a new mock instance
+func NewMockNaptanStopAreasNearbyGetter(ctrl *gomock.Controller) *MockNaptanStopAreasNearbyGetter {
+	mock := &MockNaptanStopAreasNearbyGetter{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopAreasNearbyGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopAreasNearbyGetter) EXPECT() *MockNaptanStopAreasNearbyGetterMockRecorder {
+	return m.recorder
+}
+
+// GetStopAreasNearby mocks base method
+func (m *MockNaptanStopAreasNearbyGetter) GetStopAreasNearby(ctx context.Context, latitude, longitude, radiusMetres float64, limit int) ([]*domain.NearbyStopArea, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStopAreasNearby", ctx, latitude, longitude, radiusMetres, limit)
+	ret0, _ := ret[0].([]*domain.NearbyStopArea)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStopAreasNearby indicates an expected call of GetStopAreasNearby
+func (mr *MockNaptanStopAreasNearbyGetterMockRecorder) GetStopAreasNearby(ctx, latitude, longitude, radiusMetres, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockSystemStatusSetter)(nil).Set), ctx, lastUpdated)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStopAreasNearby", reflect.TypeOf((*MockNaptanStopAreasNearbyGetter)(nil).GetStopAreasNearby), ctx, latitude, longitude, radiusMetres, limit)
+}
+
+// MockNaptanStopAreasStorer is a mock of NaptanStopAreasStorer interface
+type MockNaptanStopAreasStorer struct {
+	ctrl     *gomock.Controller
+	recorder *MockNaptanStopAreasStorerMockRecorder
+}
+
+// MockNaptanStopAreasStorerMockRecorder is the mock recorder for MockNaptanStopAreasStorer
+type MockNaptanStopAreasStorerMockRecorder struct {
+	mock *MockNaptanStopAreasStorer
+}
+
+// NewMockNaptanStopAreasStorer creates a new mock instance
+func NewMockNaptanStopAreasStorer(ctrl *gomock.Controller) *MockNaptanStopAreasStorer {
+	mock := &MockNaptanStopAreasStorer{ctrl: ctrl}
+	mock.recorder = &MockNaptanStopAreasStorerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockNaptanStopAreasStorer) EXPECT() *MockNaptanStopAreasStorerMockRecorder {
+	return m.recorder
+}
+
+// StoreStopAreas mocks base method
+func (m *MockNaptanStopAreasStorer) StoreStopAreas(ctx context.Context, stopAreas []*domain.NaptanStopArea) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreStopAreas", ctx, stopAreas)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StoreStopAreas indicates an expected call of StoreStopAreas
+func (mr *MockNaptanStopAreasStorerMockRecorder) StoreStopAreas(ctx, stopAreas interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStopAreas", reflect.TypeOf((*MockNaptanStopAreasStorer)(nil).StoreStopAreas), ctx, stopAreas)
 }
 
 // MockPlatformNamer is a mock of PlatformNamer interface
@@ -351,6 +841,81 @@ func (mr *MockStopsInAreaFetcherMockRecorder) FetchStopsInArea(ctx interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchStopsInArea", reflect.TypeOf((*MockStopsInAreaFetcher)(nil).FetchStopsInArea), ctx)
 }
 
+// MockStopsInAreaLister is a mock of StopsInAreaLister interface
+type MockStopsInAreaLister struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopsInAreaListerMockRecorder
+}
+
+// MockStopsInAreaListerMockRecorder is the mock recorder for MockStopsInAreaLister
+type MockStopsInAreaListerMockRecorder struct {
+	mock *MockStopsInAreaLister
+}
+
+// NewMockStopsInAreaLister creates a new mock instance
+func NewMockStopsInAreaLister(ctrl *gomock.Controller) *MockStopsInAreaLister {
+	mock := &MockStopsInAreaLister{ctrl: ctrl}
+	mock.recorder = &MockStopsInAreaListerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStopsInAreaLister) EXPECT() *MockStopsInAreaListerMockRecorder {
+	return m.recorder
+}
+
+// GetAllStopsInArea mocks base method
+func (m *MockStopsInAreaLister) GetAllStopsInArea(ctx context.Context) (map[string][]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllStopsInArea", ctx)
+	ret0, _ := ret[0].(map[string][]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllStopsInArea indicates an expected call of GetAllStopsInArea
+func (mr *MockStopsInAreaListerMockRecorder) GetAllStopsInArea(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllStopsInArea", reflect.TypeOf((*MockStopsInAreaLister)(nil).GetAllStopsInArea), ctx)
+}
+
+// MockStopsInAreaChangeReportPublisher is a mock of StopsInAreaChangeReportPublisher interface
+type MockStopsInAreaChangeReportPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockStopsInAreaChangeReportPublisherMockRecorder
+}
+
+// MockStopsInAreaChangeReportPublisherMockRecorder is the mock recorder for MockStopsInAreaChangeReportPublisher
+type MockStopsInAreaChangeReportPublisherMockRecorder struct {
+	mock *MockStopsInAreaChangeReportPublisher
+}
+
+// NewMockStopsInAreaChangeReportPublisher creates a new mock instance
+func NewMockStopsInAreaChangeReportPublisher(ctrl *gomock.Controller) *MockStopsInAreaChangeReportPublisher {
+	mock := &MockStopsInAreaChangeReportPublisher{ctrl: ctrl}
+	mock.recorder = &MockStopsInAreaChangeReportPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockStopsInAreaChangeReportPublisher) EXPECT() *MockStopsInAreaChangeReportPublisherMockRecorder {
+	return m.recorder
+}
+
+// PublishStopsInAreaChangeReport mocks base method
+func (m *MockStopsInAreaChangeReportPublisher) PublishStopsInAreaChangeReport(ctx context.Context, report *domain.StopsInAreaChangeReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishStopsInAreaChangeReport", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishStopsInAreaChangeReport indicates an expected call of PublishStopsInAreaChangeReport
+func (mr *MockStopsInAreaChangeReportPublisherMockRecorder) PublishStopsInAreaChangeReport(ctx, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishStopsInAreaChangeReport", reflect.TypeOf((*MockStopsInAreaChangeReportPublisher)(nil).PublishStopsInAreaChangeReport), ctx, report)
+}
+
 // MockStopsInAreaGetter is a mock of StopsInAreaGetter interface
 type MockStopsInAreaGetter struct {
 	ctrl     *gomock.Controller
@@ -413,15 +978,15 @@ func (m *MockStopsInAreaStorer) EXPECT() *MockStopsInAreaStorerMockRecorder {
 }
 
 // StoreStopsInArea mocks base method
-func (m *MockStopsInAreaStorer) StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string) error {
+func (m *MockStopsInAreaStorer) StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string, stopAreaCodesByAtcoCode map[string]string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreStopsInArea", ctx, stopsInArea)
+	ret := m.ctrl.Call(m, "StoreStopsInArea", ctx, stopsInArea, stopAreaCodesByAtcoCode)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreStopsInArea indicates an expected call of StoreStopsInArea
-func (mr *MockStopsInAreaStorerMockRecorder) StoreStopsInArea(ctx, stopsInArea interface{}) *gomock.Call {
+func (mr *MockStopsInAreaStorerMockRecorder) StoreStopsInArea(ctx, stopsInArea, stopAreaCodesByAtcoCode interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStopsInArea", reflect.TypeOf((*MockStopsInAreaStorer)(nil).StoreStopsInArea), ctx, stopsInArea)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreStopsInArea", reflect.TypeOf((*MockStopsInAreaStorer)(nil).StoreStopsInArea), ctx, stopsInArea, stopAreaCodesByAtcoCode)
 }
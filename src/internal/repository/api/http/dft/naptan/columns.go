@@ -0,0 +1,57 @@
+package naptan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// utf8ByteOrderMark is written at the start of some NaPTAN CSV exports, and would otherwise be read as part of the name
+// of the first column
+const utf8ByteOrderMark = "\ufeff"
+
+// csvHeader finds columns of a CSV file by name rather than by position, so that files are read correctly when columns
+// are added or reordered. Names are matched case-insensitively, e.g. AtcoCode matches the ATCOCode column of Stops.csv.
+type csvHeader map[string]int
+
+func newCSVHeader(row []string) csvHeader {
+	header := make(csvHeader, len(row))
+
+	for i, name := range row {
+		key := normaliseColumnName(name)
+		if _, ok := header[key]; !ok {
+			header[key] = i
+		}
+	}
+
+	return header
+}
+
+// index returns the index of a column, or -1 if the column is not in the header
+func (h csvHeader) index(name string) int {
+	if i, ok := h[normaliseColumnName(name)]; ok {
+		return i
+	}
+
+	return -1
+}
+
+// require returns an error naming every one of the columns which is not in the header
+func (h csvHeader) require(names ...string) error {
+	missing := make([]string, 0)
+
+	for _, name := range names {
+		if h.index(name) < 0 {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("required column(s) %s not found in header", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func normaliseColumnName(name string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, utf8ByteOrderMark)))
+}
@@ -1,12 +1,32 @@
 package naptan_test
 
 import (
+	"bytes"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
 	"testing"
 )
 
+// bytesArchive is a compression.Archive held in memory, which records whether it has been closed
+type bytesArchive struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (a *bytesArchive) Close() error {
+	a.closed = true
+	return nil
+}
+
+func givenArchive(t *testing.T, data []byte) *bytesArchive {
+	t.Helper()
+
+	return &bytesArchive{
+		Reader: bytes.NewReader(data),
+	}
+}
+
 func mockLogger(t *testing.T) *zap.Logger {
 	t.Helper()
 
@@ -5,73 +5,263 @@ import (
 	"encoding/csv"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"io"
-	"io/ioutil"
 )
 
+// Layouts of the NaPTAN CSV download
+const (
+	// CSVLayoutLegacy is a zip archive in which stop areas are listed in a stops in area file, and the status of each stop
+	// in a separate stops file
+	CSVLayoutLegacy = "legacy"
+	// CSVLayoutExport is a single CSV file, with a row for each stop giving its stop area and status, which is read from
+	// the stops in area file of the archive, or from the download itself when no stops in area filename is configured
+	CSVLayoutExport = "export"
+)
+
+// CSVColumns are the names of the columns read from the NaPTAN CSV files. Names are matched case-insensitively, so the
+// same names are used for the stops in area file and the stops file of the legacy layout. StopAreaCode and AtcoCode are
+// required in every file, and Status is required in the stops file of the legacy layout; the other columns are optional.
+type CSVColumns struct {
+	StopAreaCode   string
+	AtcoCode       string
+	RevisionNumber string
+	Modification   string
+	Status         string
+}
+
 type CSV struct {
-	logger                  *zap.Logger
-	zipFileFetcher          http.ZipFileFetcher
-	extractor               compression.Extractor
-	stopsInAreaFilename     string
-	stopAreaCodeColumnIndex int
-	atcoCodeColumnIndex     int
+	logger               *zap.Logger
+	zipFileFetcher       http.ZipFileFetcher
+	extractor            compression.Extractor
+	layout               string
+	stopsInAreaFilename  string
+	stopsFilename        string
+	columns              CSVColumns
+	stopAreaCodePrefixes []string
 }
 
-func NewCSV(logger *zap.Logger, zipFileFetcher http.ZipFileFetcher, extractor compression.Extractor, stopsInAreaFilename string, stopAreaCodeColumnIndex int, atcoCodeColumnIndex int) *CSV {
+func NewCSV(logger *zap.Logger, zipFileFetcher http.ZipFileFetcher, extractor compression.Extractor, layout string, stopsInAreaFilename string, stopsFilename string, columns CSVColumns, stopAreaCodePrefixes []string) *CSV {
 	return &CSV{
-		logger:                  logger,
-		zipFileFetcher:          zipFileFetcher,
-		extractor:               extractor,
-		stopsInAreaFilename:     stopsInAreaFilename,
-		stopAreaCodeColumnIndex: stopAreaCodeColumnIndex,
-		atcoCodeColumnIndex:     atcoCodeColumnIndex,
+		logger:               logger,
+		zipFileFetcher:       zipFileFetcher,
+		extractor:            extractor,
+		layout:               layout,
+		stopsInAreaFilename:  stopsInAreaFilename,
+		stopsFilename:        stopsFilename,
+		columns:              columns,
+		stopAreaCodePrefixes: stopAreaCodePrefixes,
 	}
 }
 
+// FetchStopsInArea returns the AtcoCodes in each stop area, in the order in which they first appear in the stops in area
+// file. When a StopAreaCode and AtcoCode pair appears more than once, only the record with the highest revision number
+// is used. Records whose Modification is del, and stops which are deleted or not active in the stops file, are excluded.
+// When stopAreaCodePrefixes is not empty, only stop areas whose StopAreaCode starts with one of the prefixes are read.
 func (c *CSV) FetchStopsInArea(ctx context.Context) (map[string][]string, error) {
-	zipFile, err := c.zipFileFetcher.FetchZipFile(ctx)
+	archive, err := c.zipFileFetcher.FetchZipFile(ctx)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			c.logger.Error("error closing NaPTAN download", zap.Error(err))
+		}
+	}()
+
+	var records []*stopInArea
+	var duplicates int
+	var inactiveAtcoCodes map[string]string
+
+	if c.layout == CSVLayoutExport {
+		records, duplicates, inactiveAtcoCodes, err = c.readExport(archive)
+	} else {
+		records, duplicates, inactiveAtcoCodes, err = c.readLegacy(archive)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return excludeStopsInArea(c.logger, records, duplicates, inactiveAtcoCodes), nil
+}
+
+// readLegacy reads the stops in area file and the stops file from the zip archive of the legacy layout
+func (c *CSV) readLegacy(archive compression.Archive) ([]*stopInArea, int, map[string]string, error) {
+	records, duplicates, err := c.readStopsInArea(archive)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	inactiveAtcoCodes, err := c.readInactiveAtcoCodes(archive)
+	if err != nil {
+		return nil, 0, nil, errors.Wrapf(err, "error reading %s", c.stopsFilename)
+	}
+
+	return records, duplicates, inactiveAtcoCodes, nil
+}
+
+// readExport reads the stops in area, and the status of each stop, from the single CSV file of the export layout. Stops
+// which are not in a stop area are skipped.
+func (c *CSV) readExport(archive compression.Archive) ([]*stopInArea, int, map[string]string, error) {
+	var csvFile io.Reader
+
+	if c.stopsInAreaFilename == "" {
+		csvFile = io.NewSectionReader(archive, 0, archive.Size())
+	} else {
+		readCloser, err := c.extractor.ExtractFile(archive, c.stopsInAreaFilename)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		defer func() {
+			if err := readCloser.Close(); err != nil {
+				c.logger.Error("error closing file extracted from NaPTAN archive", zap.String("filename", c.stopsInAreaFilename), zap.Error(err))
+			}
+		}()
+
+		csvFile = readCloser
+	}
+
+	records, duplicates, err := c.readStopsInAreaRows(csvFile)
+	if err != nil {
+		return nil, 0, nil, errors.Wrap(err, "error reading NaPTAN CSV export")
+	}
+
+	inactiveAtcoCodes := make(map[string]string)
+
+	for _, record := range records {
+		if record.status != "" && !isActive(record.status) {
+			inactiveAtcoCodes[record.atcoCode] = record.status
+		}
+	}
+
+	return records, duplicates, inactiveAtcoCodes, nil
+}
+
+// readStopsInArea returns the latest revision of each StopAreaCode and AtcoCode pair in the stops in area file, in the
+// order in which the pairs first appear, and the number of duplicate records which were discarded
+func (c *CSV) readStopsInArea(archive compression.Archive) ([]*stopInArea, int, error) {
+	stopsInAreaReadCloser, err := c.extractor.ExtractFile(archive, c.stopsInAreaFilename)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		if err := stopsInAreaReadCloser.Close(); err != nil {
+			c.logger.Error("error closing file extracted from NaPTAN zip archive", zap.String("filename", c.stopsInAreaFilename), zap.Error(err))
+		}
+	}()
 
-	zipData, err := ioutil.ReadAll(zipFile)
+	return c.readStopsInAreaRows(stopsInAreaReadCloser)
+}
+
+// readStopsInAreaRows returns the latest revision of each StopAreaCode and AtcoCode pair in a CSV file, in the order in
+// which the pairs first appear, and the number of duplicate records which were discarded. Columns are found by name from
+// the header row.
+func (c *CSV) readStopsInAreaRows(reader io.Reader) ([]*stopInArea, int, error) {
+	csvReader := csv.NewReader(reader)
+
+	headerRow, err := csvReader.Read()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	header := newCSVHeader(headerRow)
+	if err := header.require(c.columns.StopAreaCode, c.columns.AtcoCode); err != nil {
+		return nil, 0, err
+	}
+
+	stopAreaCodeColumnIndex := header.index(c.columns.StopAreaCode)
+	atcoCodeColumnIndex := header.index(c.columns.AtcoCode)
+	revisionNumberColumnIndex := header.index(c.columns.RevisionNumber)
+	modificationColumnIndex := header.index(c.columns.Modification)
+	statusColumnIndex := header.index(c.columns.Status)
+
+	records := newStopsInAreaRecords()
+
+	for {
+		row, err := csvReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return records.records, records.duplicates, nil
+			}
+
+			return nil, 0, err
+		}
+
+		stopAreaCode := c.column(row, stopAreaCodeColumnIndex)
+		if stopAreaCode == "" || !isWantedStopArea(c.stopAreaCodePrefixes, stopAreaCode) {
+			continue
+		}
+
+		records.add(&stopInArea{
+			stopAreaCode:   stopAreaCode,
+			atcoCode:       c.column(row, atcoCodeColumnIndex),
+			revisionNumber: parseRevisionNumber(c.column(row, revisionNumberColumnIndex)),
+			modification:   c.column(row, modificationColumnIndex),
+			status:         c.column(row, statusColumnIndex),
+		})
+	}
+}
+
+// readInactiveAtcoCodes returns the status of every stop in the stops file which is deleted or not active, by AtcoCode
+func (c *CSV) readInactiveAtcoCodes(archive compression.Archive) (map[string]string, error) {
+	readCloser, err := c.extractor.ExtractFile(archive, c.stopsFilename)
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := readCloser.Close(); err != nil {
+			c.logger.Error("error closing file extracted from NaPTAN zip archive", zap.String("filename", c.stopsFilename), zap.Error(err))
+		}
+	}()
+
+	csvReader := csv.NewReader(readCloser)
 
-	stopsInAreaReadCloser, err := c.extractor.ExtractFile(zipData, c.stopsInAreaFilename)
+	headerRow, err := csvReader.Read()
 	if err != nil {
 		return nil, err
 	}
 
-	csvReader := csv.NewReader(stopsInAreaReadCloser)
-	if err := c.skipHeaderRow(csvReader); err != nil {
+	header := newCSVHeader(headerRow)
+	if err := header.require(c.columns.AtcoCode, c.columns.Status); err != nil {
 		return nil, err
 	}
 
-	stopsInArea := make(map[string][]string)
+	atcoCodeColumnIndex := header.index(c.columns.AtcoCode)
+	modificationColumnIndex := header.index(c.columns.Modification)
+	statusColumnIndex := header.index(c.columns.Status)
+
+	inactiveAtcoCodes := make(map[string]string)
 
 	for {
 		row, err := csvReader.Read()
 		if err != nil {
 			if err == io.EOF {
-				break
+				return inactiveAtcoCodes, nil
 			}
 
 			return nil, err
 		}
 
-		stopAreaCode, atcoCode := row[c.stopAreaCodeColumnIndex], row[c.atcoCodeColumnIndex]
+		atcoCode := c.column(row, atcoCodeColumnIndex)
 
-		stopsInArea[stopAreaCode] = append(stopsInArea[stopAreaCode], atcoCode)
-	}
+		if c.column(row, modificationColumnIndex) == modificationDeleted {
+			inactiveAtcoCodes[atcoCode] = modificationDeleted
+			continue
+		}
 
-	return stopsInArea, nil
+		if status := c.column(row, statusColumnIndex); !isActive(status) {
+			inactiveAtcoCodes[atcoCode] = status
+		}
+	}
 }
 
-func (*CSV) skipHeaderRow(csvReader *csv.Reader) error {
-	_, err := csvReader.Read()
-	return err
+// column returns the value of a column which may be missing from the file
+func (*CSV) column(row []string, columnIndex int) string {
+	if columnIndex < 0 || columnIndex >= len(row) {
+		return ""
+	}
+
+	return row[columnIndex]
 }
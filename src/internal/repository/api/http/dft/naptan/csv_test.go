@@ -9,16 +9,25 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"io"
 	"io/ioutil"
 	"strings"
 	"testing"
 )
 
-func givenArbitraryZipData(t *testing.T, data []byte) io.ReadCloser {
+func givenCSVColumns(t *testing.T) naptan.CSVColumns {
 	t.Helper()
 
-	return ioutil.NopCloser(bytes.NewBuffer(data))
+	return naptan.CSVColumns{
+		StopAreaCode:   "StopAreaCode",
+		AtcoCode:       "AtcoCode",
+		RevisionNumber: "RevisionNumber",
+		Modification:   "Modification",
+		Status:         "Status",
+	}
 }
 
 func givenStopsInAreaCsv(t *testing.T) io.ReadCloser {
@@ -38,6 +47,67 @@ func givenStopsInAreaCsv(t *testing.T) io.ReadCloser {
 	return ioutil.NopCloser(bytes.NewBufferString(csvData))
 }
 
+func givenStopsInAreaCsvWithDeletedAndDuplicateRecords(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		"StopAreaCode,AtcoCode,CreationDateTime,ModificationDateTime,RevisionNumber,Modification",
+		"940GZZMAPIC,9400ZZMAPIC1,2006-12-12T00:00:00,2006-12-12T00:00:00,0,new",
+		"940GZZMAPIC,9400ZZMAPIC2,2006-12-12T00:00:00,2006-12-12T00:00:00,0,new",
+		"940GZZMAPIC,9400ZZMAPIC3,2006-12-12T00:00:00,2006-12-12T00:00:00,0,new",
+		"940GZZMAPIC,9400ZZMAPIC3,2006-12-12T00:00:00,2021-03-01T10:00:00,2,del",
+		"940GZZMAPIC,9400ZZMAPIC4,2006-12-12T00:00:00,2006-12-12T00:00:00,0,new",
+		"940GZZMAPIC,9400ZZMAPIC1,2006-12-12T00:00:00,2020-02-26T11:56:45,1,rev",
+		"940GZZMASTP,9400ZZMASTP1,2006-12-12T00:00:00,2021-03-01T10:00:00,3,rev",
+		"940GZZMASTP,9400ZZMASTP1,2006-12-12T00:00:00,2020-02-26T11:56:45,2,del",
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenStopsCsvWithInactiveStops(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		givenCsvRow(t, 43, map[int]string{0: "ATCOCode", 41: "Modification", 42: "Status"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMAPIC1", 41: "rev", 42: "act"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMAPIC2", 41: "rev", 42: "ina"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMAPIC4", 41: "del", 42: "act"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMASTP1", 41: "new", 42: "act"}),
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenReorderedStopsInAreaCsv(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		"\ufeffAtcoCode,Modification,RevisionNumber,StopAreaCode",
+		"9400ZZMAPIC1,new,0,940GZZMAPIC",
+		"9400ZZMAPIC2,new,0,940GZZMAPIC",
+		"9400ZZMAPIC2,del,1,940GZZMAPIC",
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenNaptanCsvExport(t *testing.T) []byte {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		"ATCOCode,CommonName,StopAreaCode,RevisionNumber,Modification,Status",
+		"9400ZZMAPIC1,Piccadilly (Manchester Metrolink),940GZZMAPIC,0,new,active",
+		"9400ZZMAPIC2,Piccadilly (Manchester Metrolink),940GZZMAPIC,0,new,inactive",
+		"9400ZZMASTP1,St Peter's Square (Manchester Metrolink),940GZZMASTP,1,rev,act",
+		"9400ZZMASTP1,St Peter's Square (Manchester Metrolink),940GZZMASTP,2,del,act",
+		"9400ZZMASTP2,St Peter's Square (Manchester Metrolink),940GZZMASTP,0,new,act",
+		"1800SB01231,Piccadilly Gardens,,0,new,act",
+	}, "\n")
+
+	return []byte(csvData)
+}
+
 func givenEmptyCsvData(t *testing.T) io.ReadCloser {
 	t.Helper()
 
@@ -47,7 +117,7 @@ func givenEmptyCsvData(t *testing.T) io.ReadCloser {
 func givenCorruptCsvData(t *testing.T) io.ReadCloser {
 	t.Helper()
 
-	return ioutil.NopCloser(bytes.NewBufferString("not CSV data\nanother,line\n\n\n"))
+	return ioutil.NopCloser(bytes.NewBufferString("StopAreaCode,AtcoCode\nnot CSV data\nanother,line\n\n\n"))
 }
 
 func TestCSV_FetchStopsInArea(t *testing.T) {
@@ -62,19 +132,19 @@ Then a map of StopAreaCodes to StopAreas is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		zipData := []byte("arbitrary data")
+		archive := givenArchive(t, []byte("arbitrary data"))
 
 		stopsInAreaFilename := "StopsInArea.csv"
-		stopAreaCodeColumnIndex := 0
-		atcoCodeColumnIndex := 1
+		stopsFilename := "Stops.csv"
 
 		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
-		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArbitraryZipData(t, zipData), nil)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
 
 		extractor := mock_compression.NewMockExtractor(ctrl)
-		extractor.EXPECT().ExtractFile(zipData, stopsInAreaFilename).Return(givenStopsInAreaCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, stopsInAreaFilename).Return(givenStopsInAreaCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, stopsFilename).Return(givenStopsCsv(t), nil)
 
-		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, stopsInAreaFilename, stopAreaCodeColumnIndex, atcoCodeColumnIndex)
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, stopsInAreaFilename, stopsFilename, givenCSVColumns(t), nil)
 
 		// When
 		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
@@ -89,6 +159,41 @@ Then a map of StopAreaCodes to StopAreas is returned`, func(t *testing.T) {
 		expectedStopsInAreaMap["940GZZMASTP"] = []string{"9400ZZMASTP4", "9400ZZMASTP2", "9400ZZMASTP", "9400ZZMASTP3", "9400ZZMASTP1"}
 
 		assert.Equal(t, expectedStopsInAreaMap, stopsInAreaMap)
+		assert.True(t, archive.closed)
+	})
+
+	t.Run(`Given valid NaPTAN CSV data is retrieved
+And StopAreaCode prefixes are configured
+When FetchStopsInArea is called
+Then only the stop areas whose StopAreaCode starts with one of the prefixes are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(givenStopsInAreaCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsCsv(t), nil)
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, "StopsInArea.csv", "Stops.csv", givenCSVColumns(t), []string{"940GZZMAS", "940GZZMAAW"})
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAAWT": {"9400ZZMAAWT1"},
+			"940GZZMASTP": {"9400ZZMASTP4", "9400ZZMASTP2", "9400ZZMASTP", "9400ZZMASTP3", "9400ZZMASTP1"},
+		}, stopsInAreaMap)
 	})
 
 	t.Run(`Given NaPTAN CSV data cannot be retrieved
@@ -103,8 +208,7 @@ Then an error is returned`, func(t *testing.T) {
 		logger := mockLogger(t)
 
 		stopsInAreaFilename := "StopsInArea.csv"
-		stopAreaCodeColumnIndex := 0
-		atcoCodeColumnIndex := 1
+		stopsFilename := "Stops.csv"
 
 		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
 		zipFileFetcherErr := errors.New("FUBAR")
@@ -112,7 +216,7 @@ Then an error is returned`, func(t *testing.T) {
 
 		extractor := mock_compression.NewMockExtractor(ctrl)
 
-		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, stopsInAreaFilename, stopAreaCodeColumnIndex, atcoCodeColumnIndex)
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, stopsInAreaFilename, stopsFilename, givenCSVColumns(t), nil)
 
 		// When
 		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
@@ -134,20 +238,19 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		zipData := []byte("arbitrary data")
+		archive := givenArchive(t, []byte("arbitrary data"))
 
 		stopsInAreaFilename := "StopsInArea.csv"
-		stopAreaCodeColumnIndex := 0
-		atcoCodeColumnIndex := 1
+		stopsFilename := "Stops.csv"
 
 		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
-		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArbitraryZipData(t, zipData), nil)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
 
 		extractor := mock_compression.NewMockExtractor(ctrl)
 		extractorErr := errors.New("FUBAR")
-		extractor.EXPECT().ExtractFile(zipData, stopsInAreaFilename).Return(nil, extractorErr)
+		extractor.EXPECT().ExtractFile(archive, stopsInAreaFilename).Return(nil, extractorErr)
 
-		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, stopsInAreaFilename, stopAreaCodeColumnIndex, atcoCodeColumnIndex)
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, stopsInAreaFilename, stopsFilename, givenCSVColumns(t), nil)
 
 		// When
 		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
@@ -169,19 +272,18 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		zipData := []byte("arbitrary data")
+		archive := givenArchive(t, []byte("arbitrary data"))
 
 		stopsInAreaFilename := "StopsInArea.csv"
-		stopAreaCodeColumnIndex := 0
-		atcoCodeColumnIndex := 1
+		stopsFilename := "Stops.csv"
 
 		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
-		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArbitraryZipData(t, zipData), nil)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
 
 		extractor := mock_compression.NewMockExtractor(ctrl)
-		extractor.EXPECT().ExtractFile(zipData, stopsInAreaFilename).Return(givenEmptyCsvData(t), nil)
+		extractor.EXPECT().ExtractFile(archive, stopsInAreaFilename).Return(givenEmptyCsvData(t), nil)
 
-		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, stopsInAreaFilename, stopAreaCodeColumnIndex, atcoCodeColumnIndex)
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, stopsInAreaFilename, stopsFilename, givenCSVColumns(t), nil)
 
 		// When
 		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
@@ -203,19 +305,18 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		zipData := []byte("arbitrary data")
+		archive := givenArchive(t, []byte("arbitrary data"))
 
 		stopsInAreaFilename := "StopsInArea.csv"
-		stopAreaCodeColumnIndex := 0
-		atcoCodeColumnIndex := 1
+		stopsFilename := "Stops.csv"
 
 		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
-		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArbitraryZipData(t, zipData), nil)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
 
 		extractor := mock_compression.NewMockExtractor(ctrl)
-		extractor.EXPECT().ExtractFile(zipData, stopsInAreaFilename).Return(givenCorruptCsvData(t), nil)
+		extractor.EXPECT().ExtractFile(archive, stopsInAreaFilename).Return(givenCorruptCsvData(t), nil)
 
-		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, stopsInAreaFilename, stopAreaCodeColumnIndex, atcoCodeColumnIndex)
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, stopsInAreaFilename, stopsFilename, givenCSVColumns(t), nil)
 
 		// When
 		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
@@ -225,4 +326,207 @@ Then an error is returned`, func(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.EqualError(t, err, "record on line 2: wrong number of fields")
 	})
+	t.Run(`Given NaPTAN CSV data with deleted, inactive and duplicate stops in area
+When FetchStopsInArea is called
+Then the latest revision of each stop in area is used
+And deleted stops in area and stops which are deleted or inactive are excluded
+And the number of excluded records is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, logs := observer.New(zapcore.InfoLevel)
+		logger := zap.New(zapCore)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(givenStopsInAreaCsvWithDeletedAndDuplicateRecords(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsCsvWithInactiveStops(t), nil)
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, "StopsInArea.csv", "Stops.csv", givenCSVColumns(t), nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMASTP": {"9400ZZMASTP1"},
+		}, stopsInAreaMap)
+
+		if assert.Equal(t, 1, logs.Len()) {
+			assert.Equal(t, map[string]interface{}{
+				"stopAreas":          int64(2),
+				"stopsInArea":        int64(2),
+				"excludedDeleted":    int64(1),
+				"excludedInactive":   int64(2),
+				"excludedDuplicates": int64(3),
+			}, logs.All()[0].ContextMap())
+		}
+	})
+
+	t.Run(`Given the NaPTAN Stops CSV file cannot be extracted
+When FetchStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(givenStopsInAreaCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(nil, errors.New("FUBAR"))
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, "StopsInArea.csv", "Stops.csv", givenCSVColumns(t), nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInAreaMap)
+		assert.EqualError(t, err, "error reading Stops.csv: FUBAR")
+	})
+
+	t.Run(`Given the columns of the NaPTAN stops in area file are in a different order
+When FetchStopsInArea is called
+Then the columns are found by name from the header row`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(givenReorderedStopsInAreaCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsCsv(t), nil)
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, "StopsInArea.csv", "Stops.csv", givenCSVColumns(t), nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+		}, stopsInAreaMap)
+	})
+
+	t.Run(`Given a required column is missing from the NaPTAN stops in area file
+When FetchStopsInArea is called
+Then an error naming the missing column is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(givenStopsInAreaCsv(t), nil)
+
+		columns := givenCSVColumns(t)
+		columns.AtcoCode = "StopPointRef"
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutLegacy, "StopsInArea.csv", "Stops.csv", columns, nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInAreaMap)
+		assert.EqualError(t, err, "required column(s) StopPointRef not found in header")
+	})
+
+	t.Run(`Given NaPTAN data in the CSV export layout
+When FetchStopsInArea is called
+Then the stop area and status of each stop are read from the stops in area file extracted from the archive
+And stops which are not in a stop area are skipped`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "StopsInArea.csv").Return(ioutil.NopCloser(bytes.NewReader(givenNaptanCsvExport(t))), nil)
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutExport, "StopsInArea.csv", "Stops.csv", givenCSVColumns(t), nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMASTP": {"9400ZZMASTP2"},
+		}, stopsInAreaMap)
+	})
+
+	t.Run(`Given NaPTAN data in the CSV export layout
+And no stops in area filename is configured
+When FetchStopsInArea is called
+Then the stop area and status of each stop are read from the download itself
+And stops which are not in a stop area are skipped`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArchive(t, givenNaptanCsvExport(t)), nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+
+		naptanCsv := naptan.NewCSV(logger, zipFileFetcher, extractor, naptan.CSVLayoutExport, "", "", givenCSVColumns(t), nil)
+
+		// When
+		stopsInAreaMap, err := naptanCsv.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMASTP": {"9400ZZMASTP2"},
+		}, stopsInAreaMap)
+	})
 }
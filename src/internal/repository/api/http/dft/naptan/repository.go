@@ -3,8 +3,9 @@ package naptan
 import (
 	"context"
 	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
-	"io"
 	"net/http"
 )
 
@@ -22,7 +23,9 @@ func NewRepository(logger *zap.Logger, httpClient *http.Client, url string) *Rep
 	}
 }
 
-func (r *Repository) FetchZipFile(ctx context.Context) (io.ReadCloser, error) {
+// FetchZipFile spools the download to a temporary file, so that the whole archive is never held in memory. The
+// temporary file is removed when the returned archive is closed.
+func (r *Repository) FetchZipFile(ctx context.Context) (compression.Archive, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", r.url, nil)
 	if err != nil {
 		return nil, err
@@ -33,9 +36,22 @@ func (r *Repository) FetchZipFile(ctx context.Context) (io.ReadCloser, error) {
 		return nil, err
 	}
 
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			r.logger.Error("error closing response body", zap.String("url", r.url), zap.Error(err))
+		}
+	}()
+
 	if res.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("error response from %s: %s", r.url, res.Status)
 	}
 
-	return res.Body, nil
+	archive, err := compression.SpoolToTempFile(res.Body, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "error downloading %s", r.url)
+	}
+
+	r.logger.Debug("downloaded archive to temporary file", zap.String("url", r.url), zap.String("filename", archive.Name()), zap.Int64("size", archive.Size()))
+
+	return archive, nil
 }
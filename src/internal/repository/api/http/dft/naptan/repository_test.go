@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/dft/naptan"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
 	"github.com/stretchr/testify/assert"
 	"io"
 	"io/ioutil"
@@ -36,10 +37,10 @@ func givenNaptanEndpointReturns404(t *testing.T) *httptest.Server {
 	}))
 }
 
-func readData(t *testing.T, rc io.ReadCloser) []byte {
+func readData(t *testing.T, archive compression.Archive) []byte {
 	t.Helper()
 
-	data, err := ioutil.ReadAll(rc)
+	data, err := ioutil.ReadAll(io.NewSectionReader(archive, 0, archive.Size()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -50,7 +51,8 @@ func readData(t *testing.T, rc io.ReadCloser) []byte {
 func TestRepository_FetchZipFile(t *testing.T) {
 	t.Run(`Given a HTTP endpoint containing NaPTAN data
 When FetchZipFile is called
-Then the file is returned`, func(t *testing.T) {
+Then the file is returned from a temporary file
+And the temporary file is removed when the file is closed`, func(t *testing.T) {
 		// Given
 		ctx := context.Background()
 
@@ -68,6 +70,14 @@ Then the file is returned`, func(t *testing.T) {
 		assert.NotNil(t, rc)
 		assert.Nil(t, err)
 		assert.Equal(t, []byte("arbitrary data"), readData(t, rc))
+		assert.Equal(t, int64(14), rc.Size())
+
+		tempFileArchive, ok := rc.(*compression.TempFileArchive)
+		if assert.True(t, ok) {
+			assert.FileExists(t, tempFileArchive.Name())
+			assert.Nil(t, rc.Close())
+			assert.NoFileExists(t, tempFileArchive.Name())
+		}
 	})
 
 	t.Run(`Given a HTTP endpoint cannot be reached
@@ -0,0 +1,203 @@
+package naptan
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	metrolinkAtcoCodePrefix     = "9400ZZMA"
+	metrolinkStopAreaCodePrefix = "940GZZMA"
+)
+
+// Names of the columns read from the NaPTAN Stops.csv file
+const (
+	stopsAtcoCodeColumn   = "ATCOCode"
+	stopsCommonNameColumn = "CommonName"
+	stopsStreetColumn     = "Street"
+	stopsIndicatorColumn  = "Indicator"
+	stopsLongitudeColumn  = "Longitude"
+	stopsLatitudeColumn   = "Latitude"
+	stopsStatusColumn     = "Status"
+)
+
+// Names of the columns read from the NaPTAN StopAreas.csv file
+const (
+	stopAreasStopAreaCodeColumn = "StopAreaCode"
+	stopAreasNameColumn         = "Name"
+	stopAreasLongitudeColumn    = "Longitude"
+	stopAreasLatitudeColumn     = "Latitude"
+	stopAreasStatusColumn       = "Status"
+)
+
+// StopsCSV fetches the names and locations of Metrolink stops and stop areas from the Stops.csv and StopAreas.csv files
+// in the NaPTAN CSV zip archive. Records for other modes of transport are skipped, so that only the small number of
+// Metrolink records are held in memory.
+type StopsCSV struct {
+	logger            *zap.Logger
+	zipFileFetcher    http.ZipFileFetcher
+	extractor         compression.Extractor
+	stopsFilename     string
+	stopAreasFilename string
+}
+
+func NewStopsCSV(logger *zap.Logger, zipFileFetcher http.ZipFileFetcher, extractor compression.Extractor, stopsFilename string, stopAreasFilename string) *StopsCSV {
+	return &StopsCSV{
+		logger:            logger,
+		zipFileFetcher:    zipFileFetcher,
+		extractor:         extractor,
+		stopsFilename:     stopsFilename,
+		stopAreasFilename: stopAreasFilename,
+	}
+}
+
+// FetchStopsAndStopAreas reads both files from a single download of the NaPTAN zip archive
+func (c *StopsCSV) FetchStopsAndStopAreas(ctx context.Context) ([]*domain.NaptanStop, []*domain.NaptanStopArea, error) {
+	archive, err := c.zipFileFetcher.FetchZipFile(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			c.logger.Error("error closing NaPTAN download", zap.Error(err))
+		}
+	}()
+
+	stops, err := c.readStops(archive)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error reading %s", c.stopsFilename)
+	}
+
+	stopAreas, err := c.readStopAreas(archive)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error reading %s", c.stopAreasFilename)
+	}
+
+	return stops, stopAreas, nil
+}
+
+func (c *StopsCSV) readStops(archive compression.Archive) ([]*domain.NaptanStop, error) {
+	stops := make([]*domain.NaptanStop, 0)
+
+	columns := []string{stopsAtcoCodeColumn, stopsCommonNameColumn, stopsStreetColumn, stopsIndicatorColumn, stopsLongitudeColumn, stopsLatitudeColumn, stopsStatusColumn}
+
+	err := c.readMetrolinkRows(archive, c.stopsFilename, metrolinkAtcoCodePrefix, columns, func(column func(name string) string) {
+		latitude, longitude, err := parseCoordinates(column(stopsLatitudeColumn), column(stopsLongitudeColumn))
+		if err != nil {
+			c.logger.Warn("invalid coordinates in NaPTAN stop", zap.String("atcoCode", column(stopsAtcoCodeColumn)), zap.Error(err))
+			return
+		}
+
+		stops = append(stops, &domain.NaptanStop{
+			AtcoCode:   column(stopsAtcoCodeColumn),
+			CommonName: column(stopsCommonNameColumn),
+			Indicator:  column(stopsIndicatorColumn),
+			Street:     column(stopsStreetColumn),
+			Latitude:   latitude,
+			Longitude:  longitude,
+			Status:     column(stopsStatusColumn),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stops, nil
+}
+
+func (c *StopsCSV) readStopAreas(archive compression.Archive) ([]*domain.NaptanStopArea, error) {
+	stopAreas := make([]*domain.NaptanStopArea, 0)
+
+	columns := []string{stopAreasStopAreaCodeColumn, stopAreasNameColumn, stopAreasLongitudeColumn, stopAreasLatitudeColumn, stopAreasStatusColumn}
+
+	err := c.readMetrolinkRows(archive, c.stopAreasFilename, metrolinkStopAreaCodePrefix, columns, func(column func(name string) string) {
+		latitude, longitude, err := parseCoordinates(column(stopAreasLatitudeColumn), column(stopAreasLongitudeColumn))
+		if err != nil {
+			c.logger.Warn("invalid coordinates in NaPTAN stop area", zap.String("stopAreaCode", column(stopAreasStopAreaCodeColumn)), zap.Error(err))
+			return
+		}
+
+		stopAreas = append(stopAreas, &domain.NaptanStopArea{
+			StopAreaCode: column(stopAreasStopAreaCodeColumn),
+			Name:         column(stopAreasNameColumn),
+			Latitude:     latitude,
+			Longitude:    longitude,
+			Status:       column(stopAreasStatusColumn),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stopAreas, nil
+}
+
+// readMetrolinkRows calls handleRow with each row of a file whose code, in the first of columns, starts with the
+// Metrolink prefix. Columns are found by name from the header row, and an error naming any of columns which is missing is
+// returned. handleRow gets the values of the row by column name.
+func (c *StopsCSV) readMetrolinkRows(archive compression.Archive, filename string, prefix string, columns []string, handleRow func(column func(name string) string)) error {
+	readCloser, err := c.extractor.ExtractFile(archive, filename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := readCloser.Close(); err != nil {
+			c.logger.Error("error closing file extracted from NaPTAN zip archive", zap.String("filename", filename), zap.Error(err))
+		}
+	}()
+
+	csvReader := csv.NewReader(readCloser)
+
+	headerRow, err := csvReader.Read()
+	if err != nil {
+		return err
+	}
+
+	header := newCSVHeader(headerRow)
+	if err := header.require(columns...); err != nil {
+		return err
+	}
+
+	codeColumnIndex := header.index(columns[0])
+
+	for {
+		row, err := csvReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			return err
+		}
+
+		if !strings.HasPrefix(row[codeColumnIndex], prefix) {
+			continue
+		}
+
+		handleRow(func(name string) string {
+			return row[header.index(name)]
+		})
+	}
+}
+
+func parseCoordinates(latitude string, longitude string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid latitude")
+	}
+
+	lon, err := strconv.ParseFloat(longitude, 64)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "invalid longitude")
+	}
+
+	return lat, lon, nil
+}
@@ -0,0 +1,288 @@
+package naptan_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_http "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository/api/http"
+	mock_compression "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository/compression"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/dft/naptan"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// givenCsvRow returns a CSV row with the given number of columns, with values at the given column indexes
+func givenCsvRow(t *testing.T, columns int, values map[int]string) string {
+	t.Helper()
+
+	row := make([]string, columns)
+	for i, value := range values {
+		row[i] = value
+	}
+
+	return strings.Join(row, ",")
+}
+
+func givenStopsCsv(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		givenCsvRow(t, 43, map[int]string{0: "ATCOCode", 4: "CommonName", 10: "Street", 14: "Indicator", 29: "Longitude", 30: "Latitude", 42: "Status"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMAPIC1", 4: "Piccadilly (Manchester Metrolink)", 10: "London Road", 14: "Platform A", 29: "-2.2301", 30: "53.4776", 42: "act"}),
+		givenCsvRow(t, 43, map[int]string{0: "1800SB01231", 4: "Piccadilly Gardens", 10: "Parker Street", 14: "Stop A", 29: "-2.2374", 30: "53.4810", 42: "act"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMASTP1", 4: "St Peter's Square (Manchester Metrolink)", 10: "", 14: "", 29: "-2.2435", 30: "53.4781", 42: "act"}),
+		givenCsvRow(t, 43, map[int]string{0: "9400ZZMAXXX1", 4: "Nowhere (Manchester Metrolink)", 29: "", 30: "", 42: "act"}),
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenStopAreasCsv(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		givenCsvRow(t, 15, map[int]string{0: "StopAreaCode", 1: "Name", 8: "Longitude", 9: "Latitude", 14: "Status"}),
+		givenCsvRow(t, 15, map[int]string{0: "940GZZMAPIC", 1: "Piccadilly (Manchester Metrolink)", 8: "-2.2302", 9: "53.4775", 14: "act"}),
+		givenCsvRow(t, 15, map[int]string{0: "180GPGA", 1: "Piccadilly Gardens", 8: "-2.2370", 9: "53.4810", 14: "act"}),
+		givenCsvRow(t, 15, map[int]string{0: "940GZZMASTP", 1: "St Peter's Square (Manchester Metrolink)", 8: "-2.2436", 9: "53.4780", 14: "act"}),
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenReorderedStopsCsv(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		"\ufeffStatus,Latitude,Longitude,NewColumn,Indicator,Street,CommonName,ATCOCode",
+		"act,53.4776,-2.2301,new,Platform A,London Road,Piccadilly (Manchester Metrolink),9400ZZMAPIC1",
+		"act,53.4810,-2.2374,new,Stop A,Parker Street,Piccadilly Gardens,1800SB01231",
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func givenReorderedStopAreasCsv(t *testing.T) io.ReadCloser {
+	t.Helper()
+
+	csvData := strings.Join([]string{
+		"NewColumn,Name,Status,StopAreaCode,Latitude,Longitude",
+		"new,Piccadilly (Manchester Metrolink),act,940GZZMAPIC,53.4775,-2.2302",
+		"new,Piccadilly Gardens,act,180GPGA,53.4810,-2.2370",
+	}, "\n")
+
+	return ioutil.NopCloser(bytes.NewBufferString(csvData))
+}
+
+func TestStopsCSV_FetchStopsAndStopAreas(t *testing.T) {
+	t.Run(`Given valid NaPTAN Stops and StopAreas CSV data is retrieved
+When FetchStopsAndStopAreas is called
+Then the Metrolink stops and stop areas are returned
+And records for other modes and records without coordinates are skipped`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "StopAreas.csv").Return(givenStopAreasCsv(t), nil)
+
+		stopsCsv := naptan.NewStopsCSV(logger, zipFileFetcher, extractor, "Stops.csv", "StopAreas.csv")
+
+		// When
+		stops, stopAreas, err := stopsCsv.FetchStopsAndStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, []*domain.NaptanStop{
+			{
+				AtcoCode:   "9400ZZMAPIC1",
+				CommonName: "Piccadilly (Manchester Metrolink)",
+				Indicator:  "Platform A",
+				Street:     "London Road",
+				Latitude:   53.4776,
+				Longitude:  -2.2301,
+				Status:     "act",
+			},
+			{
+				AtcoCode:   "9400ZZMASTP1",
+				CommonName: "St Peter's Square (Manchester Metrolink)",
+				Latitude:   53.4781,
+				Longitude:  -2.2435,
+				Status:     "act",
+			},
+		}, stops)
+
+		assert.Equal(t, []*domain.NaptanStopArea{
+			{
+				StopAreaCode: "940GZZMAPIC",
+				Name:         "Piccadilly (Manchester Metrolink)",
+				Latitude:     53.4775,
+				Longitude:    -2.2302,
+				Status:       "act",
+			},
+			{
+				StopAreaCode: "940GZZMASTP",
+				Name:         "St Peter's Square (Manchester Metrolink)",
+				Latitude:     53.4780,
+				Longitude:    -2.2436,
+				Status:       "act",
+			},
+		}, stopAreas)
+	})
+
+	t.Run(`Given NaPTAN Stops and StopAreas CSV data with columns added and reordered
+When FetchStopsAndStopAreas is called
+Then the columns are found by name from the header row
+And the Metrolink stops and stop areas are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenReorderedStopsCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "StopAreas.csv").Return(givenReorderedStopAreasCsv(t), nil)
+
+		stopsCsv := naptan.NewStopsCSV(logger, zipFileFetcher, extractor, "Stops.csv", "StopAreas.csv")
+
+		// When
+		stops, stopAreas, err := stopsCsv.FetchStopsAndStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, []*domain.NaptanStop{
+			{
+				AtcoCode:   "9400ZZMAPIC1",
+				CommonName: "Piccadilly (Manchester Metrolink)",
+				Indicator:  "Platform A",
+				Street:     "London Road",
+				Latitude:   53.4776,
+				Longitude:  -2.2301,
+				Status:     "act",
+			},
+		}, stops)
+
+		assert.Equal(t, []*domain.NaptanStopArea{
+			{
+				StopAreaCode: "940GZZMAPIC",
+				Name:         "Piccadilly (Manchester Metrolink)",
+				Latitude:     53.4775,
+				Longitude:    -2.2302,
+				Status:       "act",
+			},
+		}, stopAreas)
+	})
+
+	t.Run(`Given NaPTAN CSV data cannot be retrieved
+When FetchStopsAndStopAreas is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		zipFileFetcherErr := errors.New("FUBAR")
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(nil, zipFileFetcherErr)
+
+		stopsCsv := naptan.NewStopsCSV(logger, zipFileFetcher, mock_compression.NewMockExtractor(ctrl), "Stops.csv", "StopAreas.csv")
+
+		// When
+		stops, stopAreas, err := stopsCsv.FetchStopsAndStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, stops)
+		assert.Nil(t, stopAreas)
+		assert.Equal(t, zipFileFetcherErr, err)
+	})
+
+	t.Run(`Given the NaPTAN StopAreas CSV file cannot be extracted
+When FetchStopsAndStopAreas is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsCsv(t), nil)
+		extractor.EXPECT().ExtractFile(archive, "StopAreas.csv").Return(nil, errors.New("FUBAR"))
+
+		stopsCsv := naptan.NewStopsCSV(logger, zipFileFetcher, extractor, "Stops.csv", "StopAreas.csv")
+
+		// When
+		stops, stopAreas, err := stopsCsv.FetchStopsAndStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, stops)
+		assert.Nil(t, stopAreas)
+		assert.EqualError(t, err, "error reading StopAreas.csv: FUBAR")
+	})
+
+	t.Run(`Given the NaPTAN Stops CSV file is missing columns
+When FetchStopsAndStopAreas is called
+Then an error naming the missing columns is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "Stops.csv").Return(givenStopsInAreaCsv(t), nil)
+
+		stopsCsv := naptan.NewStopsCSV(logger, zipFileFetcher, extractor, "Stops.csv", "StopAreas.csv")
+
+		// When
+		stops, stopAreas, err := stopsCsv.FetchStopsAndStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, stops)
+		assert.Nil(t, stopAreas)
+		assert.EqualError(t, err, "error reading Stops.csv: required column(s) CommonName, Street, Indicator, Longitude, Latitude, Status not found in header")
+	})
+}
@@ -0,0 +1,129 @@
+package naptan
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+)
+
+// Values of the Modification and Status of NaPTAN records. The CSV export layout and the XML format spell out the
+// status of active stops in full, and the XML format spells out deletions in full.
+const (
+	modificationDeleted       = "del"
+	modificationDeletedInFull = "delete"
+	statusActive              = "act"
+	statusActiveInFull        = "active"
+)
+
+// stopInArea is a record of a stop in a stop area
+type stopInArea struct {
+	stopAreaCode   string
+	atcoCode       string
+	revisionNumber int
+	modification   string
+	status         string
+}
+
+// stopsInAreaExclusions counts the records of stops in area which are not returned
+type stopsInAreaExclusions struct {
+	deleted    int
+	inactive   int
+	duplicates int
+}
+
+// stopsInAreaRecords collects the latest revision of each StopAreaCode and AtcoCode pair, in the order in which the
+// pairs are first added, and counts the duplicate records which are discarded
+type stopsInAreaRecords struct {
+	records    []*stopInArea
+	indexes    map[string]int
+	duplicates int
+}
+
+func newStopsInAreaRecords() *stopsInAreaRecords {
+	return &stopsInAreaRecords{
+		records: make([]*stopInArea, 0),
+		indexes: make(map[string]int),
+	}
+}
+
+func (r *stopsInAreaRecords) add(record *stopInArea) {
+	key := fmt.Sprintf("%s_%s", record.stopAreaCode, record.atcoCode)
+
+	i, ok := r.indexes[key]
+	if !ok {
+		r.indexes[key] = len(r.records)
+		r.records = append(r.records, record)
+		return
+	}
+
+	r.duplicates++
+
+	if record.revisionNumber >= r.records[i].revisionNumber {
+		r.records[i] = record
+	}
+}
+
+// excludeStopsInArea returns the AtcoCodes in each stop area, excluding deleted records and the stops in
+// inactiveAtcoCodes, and logs the number of records which were read and excluded
+func excludeStopsInArea(logger *zap.Logger, records []*stopInArea, duplicates int, inactiveAtcoCodes map[string]string) map[string][]string {
+	exclusions := stopsInAreaExclusions{
+		duplicates: duplicates,
+	}
+
+	stopsInArea := make(map[string][]string)
+
+	for _, record := range records {
+		if isDeleted(record.modification) {
+			logger.Debug("excluding deleted NaPTAN stop in area", zap.String("stopAreaCode", record.stopAreaCode), zap.String("atcoCode", record.atcoCode), zap.Int("revisionNumber", record.revisionNumber))
+			exclusions.deleted++
+			continue
+		}
+
+		if status, ok := inactiveAtcoCodes[record.atcoCode]; ok {
+			logger.Debug("excluding inactive NaPTAN stop in area", zap.String("stopAreaCode", record.stopAreaCode), zap.String("atcoCode", record.atcoCode), zap.String("status", status))
+			exclusions.inactive++
+			continue
+		}
+
+		stopsInArea[record.stopAreaCode] = append(stopsInArea[record.stopAreaCode], record.atcoCode)
+	}
+
+	logger.Info("read NaPTAN stops in area", zap.Int("stopAreas", len(stopsInArea)), zap.Int("stopsInArea", len(records)-exclusions.deleted-exclusions.inactive), zap.Int("excludedDeleted", exclusions.deleted), zap.Int("excludedInactive", exclusions.inactive), zap.Int("excludedDuplicates", exclusions.duplicates))
+
+	return stopsInArea
+}
+
+func isActive(status string) bool {
+	return strings.EqualFold(status, statusActive) || strings.EqualFold(status, statusActiveInFull)
+}
+
+func isDeleted(modification string) bool {
+	return strings.EqualFold(modification, modificationDeleted) || strings.EqualFold(modification, modificationDeletedInFull)
+}
+
+// isWantedStopArea returns whether a StopAreaCode starts with one of the configured prefixes, or true when no prefixes
+// are configured
+func isWantedStopArea(stopAreaCodePrefixes []string, stopAreaCode string) bool {
+	if len(stopAreaCodePrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range stopAreaCodePrefixes {
+		if strings.HasPrefix(stopAreaCode, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseRevisionNumber returns the revision number of a stops in area record, which is 0 when it is missing or invalid
+func parseRevisionNumber(value string) int {
+	revisionNumber, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0
+	}
+
+	return revisionNumber
+}
@@ -0,0 +1,141 @@
+package naptan
+
+import (
+	"context"
+	"encoding/xml"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+)
+
+// xmlStopPoint is a StopPoint element of the NaPTAN XML, with the stop area references of the stop
+type xmlStopPoint struct {
+	AtcoCode     string           `xml:"AtcoCode"`
+	Modification string           `xml:"Modification,attr"`
+	Status       string           `xml:"Status,attr"`
+	StopAreaRefs []xmlStopAreaRef `xml:"StopAreas>StopAreaRef"`
+}
+
+// xmlStopAreaRef is a reference from a StopPoint to the StopAreaCode of a stop area it is in
+type xmlStopAreaRef struct {
+	StopAreaCode   string `xml:",chardata"`
+	Modification   string `xml:"Modification,attr"`
+	RevisionNumber string `xml:"RevisionNumber,attr"`
+}
+
+type XML struct {
+	logger               *zap.Logger
+	zipFileFetcher       http.ZipFileFetcher
+	extractor            compression.Extractor
+	filename             string
+	stopAreaCodePrefixes []string
+}
+
+// NewXML returns a StopsInAreaFetcher which reads the NaPTAN XML. The XML file is extracted by filename from the
+// downloaded archive, or when filename is empty the download is the XML file itself.
+func NewXML(logger *zap.Logger, zipFileFetcher http.ZipFileFetcher, extractor compression.Extractor, filename string, stopAreaCodePrefixes []string) *XML {
+	return &XML{
+		logger:               logger,
+		zipFileFetcher:       zipFileFetcher,
+		extractor:            extractor,
+		filename:             filename,
+		stopAreaCodePrefixes: stopAreaCodePrefixes,
+	}
+}
+
+// FetchStopsInArea returns the AtcoCodes in each stop area, from the StopAreaRefs of each StopPoint, in the order in
+// which they first appear in the XML. The same records are excluded as from the NaPTAN CSV: StopAreaRefs whose
+// Modification is delete, and StopPoints which are deleted or not active. When stopAreaCodePrefixes is not empty, only
+// stop areas whose StopAreaCode starts with one of the prefixes are read.
+func (x *XML) FetchStopsInArea(ctx context.Context) (map[string][]string, error) {
+	archive, err := x.zipFileFetcher.FetchZipFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := archive.Close(); err != nil {
+			x.logger.Error("error closing NaPTAN download", zap.Error(err))
+		}
+	}()
+
+	var xmlFile io.Reader
+
+	if x.filename == "" {
+		xmlFile = io.NewSectionReader(archive, 0, archive.Size())
+	} else {
+		readCloser, err := x.extractor.ExtractFile(archive, x.filename)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := readCloser.Close(); err != nil {
+				x.logger.Error("error closing file extracted from NaPTAN archive", zap.String("filename", x.filename), zap.Error(err))
+			}
+		}()
+
+		xmlFile = readCloser
+	}
+
+	records, inactiveAtcoCodes, err := x.readStopPoints(xmlFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading NaPTAN XML")
+	}
+
+	return excludeStopsInArea(x.logger, records.records, records.duplicates, inactiveAtcoCodes), nil
+}
+
+// readStopPoints decodes one StopPoint element at a time, so that the whole XML document is never held in memory, and
+// returns the stops in area and the status of every stop which is deleted or not active, by AtcoCode
+func (x *XML) readStopPoints(reader io.Reader) (*stopsInAreaRecords, map[string]string, error) {
+	decoder := xml.NewDecoder(reader)
+
+	records := newStopsInAreaRecords()
+	inactiveAtcoCodes := make(map[string]string)
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return records, inactiveAtcoCodes, nil
+			}
+
+			return nil, nil, err
+		}
+
+		startElement, ok := token.(xml.StartElement)
+		if !ok || startElement.Name.Local != "StopPoint" {
+			continue
+		}
+
+		var stopPoint xmlStopPoint
+		if err := decoder.DecodeElement(&stopPoint, &startElement); err != nil {
+			return nil, nil, err
+		}
+
+		atcoCode := strings.TrimSpace(stopPoint.AtcoCode)
+
+		if isDeleted(stopPoint.Modification) {
+			inactiveAtcoCodes[atcoCode] = stopPoint.Modification
+		} else if stopPoint.Status != "" && !isActive(stopPoint.Status) {
+			inactiveAtcoCodes[atcoCode] = stopPoint.Status
+		}
+
+		for _, stopAreaRef := range stopPoint.StopAreaRefs {
+			stopAreaCode := strings.TrimSpace(stopAreaRef.StopAreaCode)
+			if stopAreaCode == "" || !isWantedStopArea(x.stopAreaCodePrefixes, stopAreaCode) {
+				continue
+			}
+
+			records.add(&stopInArea{
+				stopAreaCode:   stopAreaCode,
+				atcoCode:       atcoCode,
+				revisionNumber: parseRevisionNumber(stopAreaRef.RevisionNumber),
+				modification:   stopAreaRef.Modification,
+				status:         stopPoint.Status,
+			})
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package naptan_test
+
+import (
+	"bytes"
+	"context"
+	mock_http "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository/api/http"
+	mock_compression "github.com/Marchie/tf-experiment/lambda/internal/mocks/repository/compression"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/dft/naptan"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func givenNaptanXml(t *testing.T) []byte {
+	t.Helper()
+
+	return []byte(`<?xml version="1.0" encoding="utf-8"?>
+<NaPTAN xmlns="http://www.naptan.org.uk/" SchemaVersion="2.4">
+  <StopPoints>
+    <StopPoint CreationDateTime="2013-09-03T09:52:00" Modification="new" RevisionNumber="0" Status="active">
+      <AtcoCode>9400ZZMAPIC1</AtcoCode>
+      <Descriptor>
+        <CommonName>Piccadilly (Manchester Metrolink)</CommonName>
+      </Descriptor>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="0" Status="active">940GZZMAPIC</StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="revise" RevisionNumber="2" Status="inactive">
+      <AtcoCode>9400ZZMAPIC2</AtcoCode>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="0" Status="active">940GZZMAPIC</StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="revise" RevisionNumber="3" Status="active">
+      <AtcoCode>9400ZZMASTP1</AtcoCode>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="1" Status="active">940GZZMASTP</StopAreaRef>
+        <StopAreaRef Modification="delete" RevisionNumber="2" Status="active">940GZZMASTP</StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="new" RevisionNumber="0" Status="active">
+      <AtcoCode>9400ZZMASTP2</AtcoCode>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="0" Status="active">
+          940GZZMASTP
+        </StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="delete" RevisionNumber="4" Status="active">
+      <AtcoCode>9400ZZMASTP3</AtcoCode>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="0" Status="active">940GZZMASTP</StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="new" RevisionNumber="0" Status="active">
+      <AtcoCode>1800SB01231</AtcoCode>
+      <StopAreas>
+        <StopAreaRef Modification="new" RevisionNumber="0" Status="active">180GPGDN</StopAreaRef>
+      </StopAreas>
+    </StopPoint>
+    <StopPoint Modification="new" RevisionNumber="0" Status="active">
+      <AtcoCode>1800SB01232</AtcoCode>
+    </StopPoint>
+  </StopPoints>
+  <StopAreas>
+    <StopArea Modification="new" RevisionNumber="0" Status="active">
+      <StopAreaCode>940GZZMAPIC</StopAreaCode>
+      <Name>Piccadilly (Manchester Metrolink)</Name>
+    </StopArea>
+  </StopAreas>
+</NaPTAN>`)
+}
+
+func givenXmlFile(t *testing.T, data []byte) io.ReadCloser {
+	t.Helper()
+
+	return ioutil.NopCloser(bytes.NewBuffer(data))
+}
+
+func TestXML_FetchStopsInArea(t *testing.T) {
+	t.Run(`Given an archive containing NaPTAN XML is retrieved
+When FetchStopsInArea is called
+Then a map of StopAreaCodes to the AtcoCodes of active stops in the stop areas is returned
+And deleted stop area references and deleted and inactive stops are excluded`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "NaPTAN.xml").Return(givenXmlFile(t, givenNaptanXml(t)), nil)
+
+		naptanXml := naptan.NewXML(logger, zipFileFetcher, extractor, "NaPTAN.xml", nil)
+
+		// When
+		stopsInAreaMap, err := naptanXml.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMASTP": {"9400ZZMASTP2"},
+			"180GPGDN":    {"1800SB01231"},
+		}, stopsInAreaMap)
+		assert.True(t, archive.closed)
+	})
+
+	t.Run(`Given NaPTAN XML is retrieved as a file which is not compressed
+And StopAreaCode prefixes are configured
+When FetchStopsInArea is called
+Then the download is read as XML
+And only the stop areas whose StopAreaCode starts with one of the prefixes are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(givenArchive(t, givenNaptanXml(t)), nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+
+		naptanXml := naptan.NewXML(logger, zipFileFetcher, extractor, "", []string{"940GZZMA"})
+
+		// When
+		stopsInAreaMap, err := naptanXml.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1"},
+			"940GZZMASTP": {"9400ZZMASTP2"},
+		}, stopsInAreaMap)
+	})
+
+	t.Run(`Given NaPTAN XML cannot be retrieved
+When FetchStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(nil, errors.New("FUBAR"))
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+
+		naptanXml := naptan.NewXML(logger, zipFileFetcher, extractor, "NaPTAN.xml", nil)
+
+		// When
+		stopsInAreaMap, err := naptanXml.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInAreaMap)
+		assert.EqualError(t, err, "FUBAR")
+	})
+
+	t.Run(`Given NaPTAN XML cannot be extracted
+When FetchStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "NaPTAN.xml").Return(nil, errors.New("FUBAR"))
+
+		naptanXml := naptan.NewXML(logger, zipFileFetcher, extractor, "NaPTAN.xml", nil)
+
+		// When
+		stopsInAreaMap, err := naptanXml.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInAreaMap)
+		assert.EqualError(t, err, "FUBAR")
+		assert.True(t, archive.closed)
+	})
+
+	t.Run(`Given NaPTAN XML is corrupt
+When FetchStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("arbitrary data"))
+
+		zipFileFetcher := mock_http.NewMockZipFileFetcher(ctrl)
+		zipFileFetcher.EXPECT().FetchZipFile(ctx).Return(archive, nil)
+
+		extractor := mock_compression.NewMockExtractor(ctrl)
+		extractor.EXPECT().ExtractFile(archive, "NaPTAN.xml").Return(givenXmlFile(t, []byte("<NaPTAN><StopPoints><StopPoint>")), nil)
+
+		naptanXml := naptan.NewXML(logger, zipFileFetcher, extractor, "NaPTAN.xml", nil)
+
+		// When
+		stopsInAreaMap, err := naptanXml.FetchStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInAreaMap)
+		assert.EqualError(t, err, "error reading NaPTAN XML: XML syntax error on line 1: unexpected EOF")
+	})
+}
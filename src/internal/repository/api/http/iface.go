@@ -2,9 +2,10 @@ package http
 
 import (
 	"context"
-	"io"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
 )
 
+// ZipFileFetcher downloads an archive, which the caller must close
 type ZipFileFetcher interface {
-	FetchZipFile(ctx context.Context) (io.ReadCloser, error)
+	FetchZipFile(ctx context.Context) (compression.Archive, error)
 }
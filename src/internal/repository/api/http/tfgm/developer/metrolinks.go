@@ -8,7 +8,9 @@ import (
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -73,6 +75,16 @@ func NewTfgmDeveloperMetrolinkDataSource(logger *zap.Logger, httpClient *http.Cl
 }
 
 func (ds *TfgmDeveloperMetrolinkDataSource) Fetch(ctx context.Context) (*domain.MetrolinkDepartures, error) {
+	body, err := ds.FetchJson(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.DecodeJson(body)
+}
+
+// FetchJson returns the raw JSON response body from the Metrolinks API
+func (ds *TfgmDeveloperMetrolinkDataSource) FetchJson(ctx context.Context) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", ds.url, nil)
 	if err != nil {
 		return nil, err
@@ -84,20 +96,34 @@ func (ds *TfgmDeveloperMetrolinkDataSource) Fetch(ctx context.Context) (*domain.
 	if err != nil {
 		return nil, err
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ds.logger.Error("error closing response body", zap.Error(err))
+		}
+	}()
 
 	if resp.StatusCode != http.StatusOK {
 		buf := new(bytes.Buffer)
 		_, _ = buf.ReadFrom(resp.Body)
 
 		errMsg := "error response from data source"
-
 		ds.logger.Error(errMsg, zap.Int("StatusCode", resp.StatusCode), zap.String("Status", resp.Status), zap.String("Body", buf.String()))
-
 		return nil, fmt.Errorf("%s: %s", errMsg, resp.Status)
 	}
 
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading response body")
+	}
+
+	return body, nil
+}
+
+// DecodeJson converts a Metrolinks API JSON response body into Metrolink departures and messages. It is separate from
+// FetchJson so that recorded responses can be decoded exactly as live responses are.
+func (ds *TfgmDeveloperMetrolinkDataSource) DecodeJson(body []byte) (*domain.MetrolinkDepartures, error) {
 	var metrolinkDepartures MetrolinkDepartures
-	if err := json.NewDecoder(resp.Body).Decode(&metrolinkDepartures); err != nil {
+	if err := json.NewDecoder(bytes.NewReader(body)).Decode(&metrolinkDepartures); err != nil {
 		return nil, errors.Wrap(err, "error decoding body as JSON")
 	}
 
@@ -109,61 +135,126 @@ func (ds *TfgmDeveloperMetrolinkDataSource) Fetch(ctx context.Context) (*domain.
 		metrolinkDepartures.PassengerInformationDisplays[i].LastUpdated = ds.correctLastUpdatedTime(metrolinkDepartures.PassengerInformationDisplays[i].LastUpdated)
 	}
 
+	// Messages must be converted before departures, as filtering duplicate Passenger Information Displays discards
+	// displays which share an AtcoCode but show a different message
+	messages := ds.convertToDomainMetrolinkMessages(&metrolinkDepartures)
+
 	return &domain.MetrolinkDepartures{
-		Departures:  ds.convertToDomainMetrolinkDepartures(&metrolinkDepartures),
-		LastUpdated: metrolinkDepartures.LastUpdated(),
+		Departures:                   ds.convertToDomainMetrolinkDepartures(&metrolinkDepartures),
+		Messages:                     messages,
+		PassengerInformationDisplays: len(metrolinkDepartures.PassengerInformationDisplays),
+		LastUpdated:                  metrolinkDepartures.LastUpdated(),
 	}, nil
 }
 
+func (ds *TfgmDeveloperMetrolinkDataSource) convertToDomainMetrolinkMessages(metrolinkDepartures *MetrolinkDepartures) []*domain.MetrolinkMessage {
+	var domainMetrolinkMessages []*domain.MetrolinkMessage
+
+	processedMessages := make(map[string]map[string]*domain.MetrolinkMessage)
+
+	for _, passengerInformationDisplay := range metrolinkDepartures.PassengerInformationDisplays {
+		message := strings.TrimSpace(passengerInformationDisplay.MessageBoard)
+		if message == "" {
+			continue
+		}
+
+		if processedMessages[passengerInformationDisplay.AtcoCode] == nil {
+			processedMessages[passengerInformationDisplay.AtcoCode] = make(map[string]*domain.MetrolinkMessage)
+		}
+
+		if processedMessage := processedMessages[passengerInformationDisplay.AtcoCode][message]; processedMessage != nil {
+			if passengerInformationDisplay.LastUpdated.After(processedMessage.LastUpdated) {
+				processedMessage.LastUpdated = passengerInformationDisplay.LastUpdated
+			}
+
+			continue
+		}
+
+		domainMetrolinkMessage := &domain.MetrolinkMessage{
+			AtcoCode:    passengerInformationDisplay.AtcoCode,
+			Line:        passengerInformationDisplay.Line,
+			Message:     message,
+			LastUpdated: passengerInformationDisplay.LastUpdated,
+			Tlaref:      passengerInformationDisplay.TLAREF,
+		}
+
+		processedMessages[passengerInformationDisplay.AtcoCode][message] = domainMetrolinkMessage
+
+		domainMetrolinkMessages = append(domainMetrolinkMessages, domainMetrolinkMessage)
+	}
+
+	return domainMetrolinkMessages
+}
+
 func (ds *TfgmDeveloperMetrolinkDataSource) convertToDomainMetrolinkDepartures(metrolinkDepartures *MetrolinkDepartures) []*domain.MetrolinkDeparture {
 	var domainMetrolinkDepartures []*domain.MetrolinkDeparture
 
 	for _, passengerInformationDisplay := range ds.filterDuplicatePassengerInformationDisplays(metrolinkDepartures.PassengerInformationDisplays) {
 		if passengerInformationDisplay.Status0 != "" {
 			domainMetrolinkDepartures = append(domainMetrolinkDepartures, &domain.MetrolinkDeparture{
-				AtcoCode:    passengerInformationDisplay.AtcoCode,
-				Order:       0,
-				Destination: passengerInformationDisplay.Dest0,
-				Carriages:   passengerInformationDisplay.Carriages0,
-				Status:      passengerInformationDisplay.Status0,
-				Wait:        passengerInformationDisplay.Wait0,
-				LastUpdated: passengerInformationDisplay.LastUpdated,
+				AtcoCode:        passengerInformationDisplay.AtcoCode,
+				Line:            passengerInformationDisplay.Line,
+				Direction:       passengerInformationDisplay.Direction,
+				Tlaref:          passengerInformationDisplay.TLAREF,
+				Pidref:          passengerInformationDisplay.PIDREF,
+				StationLocation: passengerInformationDisplay.StationLocation,
+				Order:           0,
+				Destination:     passengerInformationDisplay.Dest0,
+				Carriages:       domain.MetrolinkCarriages(passengerInformationDisplay.Carriages0),
+				Status:          domain.MetrolinkDepartureStatus(passengerInformationDisplay.Status0),
+				Wait:            passengerInformationDisplay.Wait0,
+				LastUpdated:     passengerInformationDisplay.LastUpdated,
 			})
 		}
 
 		if passengerInformationDisplay.Status1 != "" {
 			domainMetrolinkDepartures = append(domainMetrolinkDepartures, &domain.MetrolinkDeparture{
-				AtcoCode:    passengerInformationDisplay.AtcoCode,
-				Order:       1,
-				Destination: passengerInformationDisplay.Dest1,
-				Carriages:   passengerInformationDisplay.Carriages1,
-				Status:      passengerInformationDisplay.Status1,
-				Wait:        passengerInformationDisplay.Wait1,
-				LastUpdated: passengerInformationDisplay.LastUpdated,
+				AtcoCode:        passengerInformationDisplay.AtcoCode,
+				Line:            passengerInformationDisplay.Line,
+				Direction:       passengerInformationDisplay.Direction,
+				Tlaref:          passengerInformationDisplay.TLAREF,
+				Pidref:          passengerInformationDisplay.PIDREF,
+				StationLocation: passengerInformationDisplay.StationLocation,
+				Order:           1,
+				Destination:     passengerInformationDisplay.Dest1,
+				Carriages:       domain.MetrolinkCarriages(passengerInformationDisplay.Carriages1),
+				Status:          domain.MetrolinkDepartureStatus(passengerInformationDisplay.Status1),
+				Wait:            passengerInformationDisplay.Wait1,
+				LastUpdated:     passengerInformationDisplay.LastUpdated,
 			})
 		}
 
 		if passengerInformationDisplay.Status2 != "" {
 			domainMetrolinkDepartures = append(domainMetrolinkDepartures, &domain.MetrolinkDeparture{
-				AtcoCode:    passengerInformationDisplay.AtcoCode,
-				Order:       2,
-				Destination: passengerInformationDisplay.Dest2,
-				Carriages:   passengerInformationDisplay.Carriages2,
-				Status:      passengerInformationDisplay.Status2,
-				Wait:        passengerInformationDisplay.Wait2,
-				LastUpdated: passengerInformationDisplay.LastUpdated,
+				AtcoCode:        passengerInformationDisplay.AtcoCode,
+				Line:            passengerInformationDisplay.Line,
+				Direction:       passengerInformationDisplay.Direction,
+				Tlaref:          passengerInformationDisplay.TLAREF,
+				Pidref:          passengerInformationDisplay.PIDREF,
+				StationLocation: passengerInformationDisplay.StationLocation,
+				Order:           2,
+				Destination:     passengerInformationDisplay.Dest2,
+				Carriages:       domain.MetrolinkCarriages(passengerInformationDisplay.Carriages2),
+				Status:          domain.MetrolinkDepartureStatus(passengerInformationDisplay.Status2),
+				Wait:            passengerInformationDisplay.Wait2,
+				LastUpdated:     passengerInformationDisplay.LastUpdated,
 			})
 		}
 
 		if passengerInformationDisplay.Status3 != "" {
 			domainMetrolinkDepartures = append(domainMetrolinkDepartures, &domain.MetrolinkDeparture{
-				AtcoCode:    passengerInformationDisplay.AtcoCode,
-				Order:       3,
-				Destination: passengerInformationDisplay.Dest3,
-				Carriages:   passengerInformationDisplay.Carriages3,
-				Status:      passengerInformationDisplay.Status3,
-				Wait:        passengerInformationDisplay.Wait3,
-				LastUpdated: passengerInformationDisplay.LastUpdated,
+				AtcoCode:        passengerInformationDisplay.AtcoCode,
+				Line:            passengerInformationDisplay.Line,
+				Direction:       passengerInformationDisplay.Direction,
+				Tlaref:          passengerInformationDisplay.TLAREF,
+				Pidref:          passengerInformationDisplay.PIDREF,
+				StationLocation: passengerInformationDisplay.StationLocation,
+				Order:           3,
+				Destination:     passengerInformationDisplay.Dest3,
+				Carriages:       domain.MetrolinkCarriages(passengerInformationDisplay.Carriages3),
+				Status:          domain.MetrolinkDepartureStatus(passengerInformationDisplay.Status3),
+				Wait:            passengerInformationDisplay.Wait3,
+				LastUpdated:     passengerInformationDisplay.LastUpdated,
 			})
 		}
 	}
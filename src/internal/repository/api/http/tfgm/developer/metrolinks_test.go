@@ -431,61 +431,115 @@ And duplicated information caused by there being multiple Passenger Information
 		expDomainMetrolinkDepartures := &domain.MetrolinkDepartures{
 			Departures: []*domain.MetrolinkDeparture{
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       0,
-					Destination: "Victoria",
-					Carriages:   "Single",
-					Status:      "Due",
-					Wait:        "2",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           0,
+					Destination:     "Victoria",
+					Carriages:       "Single",
+					Status:          "Due",
+					Wait:            "2",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       1,
-					Destination: "Ashton-under-Lyne",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "9",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           1,
+					Destination:     "Ashton-under-Lyne",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "9",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       3,
-					Destination: "Piccadilly",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "12",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           3,
+					Destination:     "Piccadilly",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "12",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP4",
-					Order:       0,
-					Destination: "Altrincham",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "6",
-					LastUpdated: expLastUpdated.Add(-time.Second),
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           0,
+					Destination:     "Altrincham",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "6",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+				{
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           1,
+					Destination:     "Manchester Airport",
+					Carriages:       "Single",
+					Status:          "Due",
+					Wait:            "10",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+				{
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           2,
+					Destination:     "MediaCityUK",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "14",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+			},
+			Messages: []*domain.MetrolinkMessage{
+				{
+					AtcoCode:    "9400ZZMASTP1",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
+					LastUpdated: expLastUpdated,
+					Tlaref:      "SPS",
 				},
 				{
 					AtcoCode:    "9400ZZMASTP4",
-					Order:       1,
-					Destination: "Manchester Airport",
-					Carriages:   "Single",
-					Status:      "Due",
-					Wait:        "10",
+					Line:        "Eccles",
+					Message:     "Please see printed posters forfirst and last tram times.info: www.metrolink.co.uk",
 					LastUpdated: expLastUpdated.Add(-time.Second),
+					Tlaref:      "SPS",
 				},
 				{
 					AtcoCode:    "9400ZZMASTP4",
-					Order:       2,
-					Destination: "MediaCityUK",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "14",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
 					LastUpdated: expLastUpdated.Add(-time.Second),
+					Tlaref:      "SPS",
 				},
 			},
-			LastUpdated: expLastUpdated,
+			PassengerInformationDisplays: 3,
+			LastUpdated:                  expLastUpdated,
 		}
 
 		assert.EqualValues(t, expDomainMetrolinkDepartures, result)
@@ -525,8 +579,25 @@ And the LastUpdated time is populated`, func(t *testing.T) {
 		}
 
 		expDomainMetrolinkDepartures := &domain.MetrolinkDepartures{
-			Departures:  nil,
-			LastUpdated: expLastUpdated,
+			Departures: nil,
+			Messages: []*domain.MetrolinkMessage{
+				{
+					AtcoCode:    "9400ZZMASTP1",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
+					LastUpdated: expLastUpdated,
+					Tlaref:      "SPS",
+				},
+				{
+					AtcoCode:    "9400ZZMASTP4",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
+					LastUpdated: expLastUpdated,
+					Tlaref:      "SPS",
+				},
+			},
+			PassengerInformationDisplays: 3,
+			LastUpdated:                  expLastUpdated,
 		}
 
 		assert.EqualValues(t, expDomainMetrolinkDepartures, result)
@@ -569,61 +640,115 @@ And the LastUpdated times are corrected to their true values`, func(t *testing.T
 		expDomainMetrolinkDepartures := &domain.MetrolinkDepartures{
 			Departures: []*domain.MetrolinkDeparture{
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       0,
-					Destination: "Victoria",
-					Carriages:   "Single",
-					Status:      "Due",
-					Wait:        "2",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           0,
+					Destination:     "Victoria",
+					Carriages:       "Single",
+					Status:          "Due",
+					Wait:            "2",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       1,
-					Destination: "Ashton-under-Lyne",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "9",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           1,
+					Destination:     "Ashton-under-Lyne",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "9",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP1",
-					Order:       3,
-					Destination: "Piccadilly",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "12",
-					LastUpdated: expLastUpdated,
+					AtcoCode:        "9400ZZMASTP1",
+					Line:            "Eccles",
+					Direction:       "Incoming",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID05",
+					StationLocation: "St Peter's Square",
+					Order:           3,
+					Destination:     "Piccadilly",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "12",
+					LastUpdated:     expLastUpdated,
 				},
 				{
-					AtcoCode:    "9400ZZMASTP4",
-					Order:       0,
-					Destination: "Altrincham",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "6",
-					LastUpdated: expLastUpdated.Add(-time.Second),
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           0,
+					Destination:     "Altrincham",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "6",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+				{
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           1,
+					Destination:     "Manchester Airport",
+					Carriages:       "Single",
+					Status:          "Due",
+					Wait:            "10",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+				{
+					AtcoCode:        "9400ZZMASTP4",
+					Line:            "Eccles",
+					Direction:       "Outgoing",
+					Tlaref:          "SPS",
+					Pidref:          "SPS-PID01",
+					StationLocation: "St Peter's Square",
+					Order:           2,
+					Destination:     "MediaCityUK",
+					Carriages:       "Double",
+					Status:          "Due",
+					Wait:            "14",
+					LastUpdated:     expLastUpdated.Add(-time.Second),
+				},
+			},
+			Messages: []*domain.MetrolinkMessage{
+				{
+					AtcoCode:    "9400ZZMASTP1",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
+					LastUpdated: expLastUpdated,
+					Tlaref:      "SPS",
 				},
 				{
 					AtcoCode:    "9400ZZMASTP4",
-					Order:       1,
-					Destination: "Manchester Airport",
-					Carriages:   "Single",
-					Status:      "Due",
-					Wait:        "10",
+					Line:        "Eccles",
+					Message:     "Please see printed posters forfirst and last tram times.info: www.metrolink.co.uk",
 					LastUpdated: expLastUpdated.Add(-time.Second),
+					Tlaref:      "SPS",
 				},
 				{
 					AtcoCode:    "9400ZZMASTP4",
-					Order:       2,
-					Destination: "MediaCityUK",
-					Carriages:   "Double",
-					Status:      "Due",
-					Wait:        "14",
+					Line:        "Eccles",
+					Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK. A bus replacement service is operating at Eccles and MediaCityUK only. For more info please visit tfgm.com",
 					LastUpdated: expLastUpdated.Add(-time.Second),
+					Tlaref:      "SPS",
 				},
 			},
-			LastUpdated: expLastUpdated,
+			PassengerInformationDisplays: 3,
+			LastUpdated:                  expLastUpdated,
 		}
 
 		assert.EqualValues(t, expDomainMetrolinkDepartures, result)
@@ -0,0 +1,66 @@
+package developer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// recordingTimeFormat is the format of the time at which a response was recorded in a recording's filename. Filenames
+// sort in the order in which responses were recorded.
+const recordingTimeFormat = "20060102T150405.000000000Z"
+
+const recordingFilenamePrefix = "metrolinks_"
+
+// TfgmDeveloperMetrolinkRecorder fetches Metrolink departures from a TfGM Developer Metrolinks data source and writes
+// each raw response to a directory, so that it can be replayed later by TfgmDeveloperMetrolinkReplayDataSource.
+// Responses are recorded before they are decoded, so a response which cannot be decoded is still recorded.
+type TfgmDeveloperMetrolinkRecorder struct {
+	logger          *zap.Logger
+	dataSource      *TfgmDeveloperMetrolinkDataSource
+	directory       string
+	currentTimeFunc func() time.Time
+}
+
+func NewTfgmDeveloperMetrolinkRecorder(logger *zap.Logger, dataSource *TfgmDeveloperMetrolinkDataSource, directory string, currentTimeFunc func() time.Time) *TfgmDeveloperMetrolinkRecorder {
+	return &TfgmDeveloperMetrolinkRecorder{
+		logger:          logger,
+		dataSource:      dataSource,
+		directory:       directory,
+		currentTimeFunc: currentTimeFunc,
+	}
+}
+
+// Fetch fetches and records a response from the data source. An error recording the response is logged rather than
+// returned, so that recording never stops departures being loaded.
+func (r *TfgmDeveloperMetrolinkRecorder) Fetch(ctx context.Context) (*domain.MetrolinkDepartures, error) {
+	body, err := r.dataSource.FetchJson(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.record(body); err != nil {
+		r.logger.Error("error recording TfGM Metrolinks API response", zap.String("directory", r.directory), zap.Error(err))
+	}
+
+	return r.dataSource.DecodeJson(body)
+}
+
+func (r *TfgmDeveloperMetrolinkRecorder) record(body []byte) error {
+	if err := os.MkdirAll(r.directory, 0755); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(r.directory, recordingFilename(r.currentTimeFunc()))
+
+	return ioutil.WriteFile(filename, body, 0644)
+}
+
+func recordingFilename(recordedAt time.Time) string {
+	return fmt.Sprintf("%s%s.json", recordingFilenamePrefix, recordedAt.UTC().Format(recordingTimeFormat))
+}
@@ -0,0 +1,154 @@
+package developer_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/tfgm/developer"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// givenRecordedResponse returns a TfGM Developer Metrolinks API response with one passenger information display
+func givenRecordedResponse(t *testing.T, wait string, lastUpdated string) string {
+	t.Helper()
+
+	return fmt.Sprintf(`{
+    "@odata.context": "https://opendataclientapi.azurewebsites.net/odata/$metadata#Metrolinks",
+    "value": [
+        {
+            "Id": 846,
+            "Line": "Eccles",
+            "TLAREF": "SPS",
+            "PIDREF": "SPS-PID05",
+            "StationLocation": "St Peter's Square",
+            "AtcoCode": "9400ZZMASTP1",
+            "Direction": "Incoming",
+            "Dest0": "Victoria",
+            "Carriages0": "Single",
+            "Status0": "Due",
+            "Wait0": "%s",
+            "MessageBoard": "Welcome to Metrolink",
+            "LastUpdated": "%s"
+        }
+    ]
+}`, wait, lastUpdated)
+}
+
+func mockMetrolinksServerWithBody(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestTfgmDeveloperMetrolinkRecorder_Fetch(t *testing.T) {
+	t.Run(`Given data is available from the TfGM Developer Metrolinks API
+When data is fetched through a recorder
+Then the departures are returned
+And the raw response is written to the recordings directory, named by the time it was recorded`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		body := givenRecordedResponse(t, "2", "2021-03-21T15:34:54Z")
+
+		metrolinksServer := mockMetrolinksServerWithBody(t, body)
+		defer metrolinksServer.Close()
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, metrolinksServer.URL, "abc123")
+
+		directory := t.TempDir()
+
+		currentTimeFunc := func() time.Time {
+			return time.Date(2021, time.March, 21, 15, 34, 56, 123000000, time.UTC)
+		}
+
+		recorder := developer.NewTfgmDeveloperMetrolinkRecorder(logger, dataSource, directory, currentTimeFunc)
+
+		ctx := context.Background()
+
+		// When
+		result, err := recorder.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Departures))
+		assert.Equal(t, "2", result.Departures[0].Wait)
+
+		recorded, err := ioutil.ReadFile(filepath.Join(directory, "metrolinks_20210321T153456.123000000Z.json"))
+		assert.Nil(t, err)
+		assert.Equal(t, body, string(recorded))
+	})
+
+	t.Run(`Given the TfGM Developer Metrolinks API returns invalid JSON
+When data is fetched through a recorder
+Then an error is returned
+And the raw response is still recorded`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		metrolinksServer := mockMetrolinksServerWithBody(t, "{")
+		defer metrolinksServer.Close()
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, metrolinksServer.URL, "abc123")
+
+		directory := t.TempDir()
+
+		recorder := developer.NewTfgmDeveloperMetrolinkRecorder(logger, dataSource, directory, time.Now)
+
+		ctx := context.Background()
+
+		// When
+		result, err := recorder.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, result)
+		assert.Equal(t, "error decoding body as JSON: unexpected EOF", err.Error())
+
+		files, err := ioutil.ReadDir(directory)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(files))
+	})
+
+	t.Run(`Given data is available from the TfGM Developer Metrolinks API
+When data is fetched through a recorder
+And the response cannot be recorded
+Then the departures are returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		metrolinksServer := mockMetrolinksServerWithBody(t, givenRecordedResponse(t, "2", "2021-03-21T15:34:54Z"))
+		defer metrolinksServer.Close()
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, metrolinksServer.URL, "abc123")
+
+		// A file where the recordings directory should be
+		file := filepath.Join(t.TempDir(), "recordings")
+		assert.Nil(t, ioutil.WriteFile(file, nil, 0644))
+
+		recorder := developer.NewTfgmDeveloperMetrolinkRecorder(logger, dataSource, file, time.Now)
+
+		ctx := context.Background()
+
+		// When
+		result, err := recorder.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(result.Departures))
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+		assert.Equal(t, "error recording TfGM Metrolinks API response", observedLogs.All()[0].Message)
+	})
+}
@@ -0,0 +1,176 @@
+package developer
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TfgmDeveloperMetrolinkReplayDataSource replays a directory of recorded TfGM Developer Metrolinks API responses, e.g.
+// written by TfgmDeveloperMetrolinkRecorder, in place of the live API. Recordings are decoded by a TfGM Developer
+// Metrolinks data source exactly as live responses are, so that bugs in decoding can be reproduced.
+//
+// With a speed of zero, each call to Fetch returns the next recording in filename order. With a positive speed,
+// recordings are replayed against the clock at that multiple of the speed at which they were recorded: Fetch returns the
+// latest recording made within the time elapsed since the first Fetch, which needs the recording time in filenames
+// written by TfgmDeveloperMetrolinkRecorder. In both modes the replay starts again from the first recording after the
+// last.
+//
+// When rebase is true, every LastUpdated time is moved forward by the age of the recording, so that the replayed data
+// appears to have just been updated and is not rejected as outdated.
+type TfgmDeveloperMetrolinkReplayDataSource struct {
+	logger          *zap.Logger
+	dataSource      *TfgmDeveloperMetrolinkDataSource
+	directory       string
+	speed           float64
+	rebase          bool
+	currentTimeFunc func() time.Time
+	mu              sync.Mutex
+	recordings      []*recording
+	next            int
+	startedAt       time.Time
+}
+
+type recording struct {
+	filename   string
+	recordedAt time.Time
+}
+
+func NewTfgmDeveloperMetrolinkReplayDataSource(logger *zap.Logger, dataSource *TfgmDeveloperMetrolinkDataSource, directory string, speed float64, rebase bool, currentTimeFunc func() time.Time) *TfgmDeveloperMetrolinkReplayDataSource {
+	return &TfgmDeveloperMetrolinkReplayDataSource{
+		logger:          logger,
+		dataSource:      dataSource,
+		directory:       directory,
+		speed:           speed,
+		rebase:          rebase,
+		currentTimeFunc: currentTimeFunc,
+	}
+}
+
+func (r *TfgmDeveloperMetrolinkReplayDataSource) Fetch(ctx context.Context) (*domain.MetrolinkDepartures, error) {
+	rec, err := r.nextRecording()
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Debug("replaying TfGM Metrolinks API response", zap.String("filename", rec.filename))
+
+	body, err := ioutil.ReadFile(rec.filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading recording %s", rec.filename)
+	}
+
+	metrolinkDepartures, err := r.dataSource.DecodeJson(body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding recording %s", rec.filename)
+	}
+
+	if r.rebase {
+		rebaseMetrolinkDepartures(metrolinkDepartures, r.currentTimeFunc().Sub(metrolinkDepartures.LastUpdated))
+	}
+
+	return metrolinkDepartures, nil
+}
+
+func (r *TfgmDeveloperMetrolinkReplayDataSource) nextRecording() (*recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.recordings == nil {
+		recordings, err := r.listRecordings()
+		if err != nil {
+			return nil, err
+		}
+
+		r.recordings = recordings
+		r.startedAt = r.currentTimeFunc()
+	}
+
+	if r.speed <= 0 {
+		rec := r.recordings[r.next]
+		r.next = (r.next + 1) % len(r.recordings)
+		return rec, nil
+	}
+
+	first := r.recordings[0].recordedAt
+	span := r.recordings[len(r.recordings)-1].recordedAt.Sub(first)
+
+	elapsed := time.Duration(float64(r.currentTimeFunc().Sub(r.startedAt)) * r.speed)
+	if span > 0 {
+		elapsed %= span + time.Nanosecond
+	}
+
+	target := first.Add(elapsed)
+
+	// The recording in use is the last one recorded at or before the target time
+	i := sort.Search(len(r.recordings), func(i int) bool {
+		return r.recordings[i].recordedAt.After(target)
+	})
+
+	return r.recordings[i-1], nil
+}
+
+// listRecordings returns the JSON files in the directory in filename order
+func (r *TfgmDeveloperMetrolinkReplayDataSource) listRecordings() ([]*recording, error) {
+	files, err := ioutil.ReadDir(r.directory)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading recordings directory %s", r.directory)
+	}
+
+	var recordings []*recording
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		rec := &recording{
+			filename: filepath.Join(r.directory, file.Name()),
+		}
+
+		if r.speed > 0 {
+			recordedAt, err := parseRecordingFilename(file.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			rec.recordedAt = recordedAt
+		}
+
+		recordings = append(recordings, rec)
+	}
+
+	if len(recordings) == 0 {
+		return nil, errors.Errorf("no recordings in directory %s", r.directory)
+	}
+
+	return recordings, nil
+}
+
+func parseRecordingFilename(filename string) (time.Time, error) {
+	recordedAt, err := time.Parse(recordingTimeFormat, strings.TrimSuffix(strings.TrimPrefix(filename, recordingFilenamePrefix), ".json"))
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "error parsing recording time from filename %s", filename)
+	}
+
+	return recordedAt, nil
+}
+
+func rebaseMetrolinkDepartures(metrolinkDepartures *domain.MetrolinkDepartures, age time.Duration) {
+	metrolinkDepartures.LastUpdated = metrolinkDepartures.LastUpdated.Add(age)
+
+	for _, departure := range metrolinkDepartures.Departures {
+		departure.LastUpdated = departure.LastUpdated.Add(age)
+	}
+
+	for _, message := range metrolinkDepartures.Messages {
+		message.LastUpdated = message.LastUpdated.Add(age)
+	}
+}
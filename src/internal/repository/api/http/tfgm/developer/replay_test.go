@@ -0,0 +1,170 @@
+package developer_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/api/http/tfgm/developer"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// givenRecordings writes recordings made 10 seconds apart, with waits of 3, 2 and 1 minutes, and returns their directory
+func givenRecordings(t *testing.T) string {
+	t.Helper()
+
+	directory := t.TempDir()
+
+	recordings := map[string]string{
+		"metrolinks_20210321T153456.000000000Z.json": givenRecordedResponse(t, "3", "2021-03-21T15:34:54Z"),
+		"metrolinks_20210321T153506.000000000Z.json": givenRecordedResponse(t, "2", "2021-03-21T15:35:04Z"),
+		"metrolinks_20210321T153516.000000000Z.json": givenRecordedResponse(t, "1", "2021-03-21T15:35:14Z"),
+	}
+
+	for filename, body := range recordings {
+		if err := ioutil.WriteFile(filepath.Join(directory, filename), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return directory
+}
+
+func TestTfgmDeveloperMetrolinkReplayDataSource_Fetch(t *testing.T) {
+	t.Run(`Given a directory of recorded responses
+When data is fetched from a replay data source with a speed of zero
+Then each fetch returns the next recording in order
+And the replay starts again from the first recording after the last`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, "", "")
+
+		replay := developer.NewTfgmDeveloperMetrolinkReplayDataSource(logger, dataSource, givenRecordings(t), 0, false, time.Now)
+
+		ctx := context.Background()
+
+		var waits []string
+
+		// When
+		for i := 0; i < 4; i++ {
+			result, err := replay.Fetch(ctx)
+			assert.Nil(t, err)
+
+			waits = append(waits, result.Departures[0].Wait)
+		}
+
+		// Then
+		assert.Equal(t, []string{"3", "2", "1", "3"}, waits)
+	})
+
+	t.Run(`Given a directory of recorded responses
+When data is fetched from a replay data source with a speed of two
+Then each fetch returns the latest recording made within twice the time elapsed since the first fetch`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, "", "")
+
+		now := time.Date(2021, time.April, 24, 12, 0, 0, 0, time.UTC)
+
+		currentTimeFunc := func() time.Time {
+			return now
+		}
+
+		replay := developer.NewTfgmDeveloperMetrolinkReplayDataSource(logger, dataSource, givenRecordings(t), 2, false, currentTimeFunc)
+
+		ctx := context.Background()
+
+		var waits []string
+
+		// When
+		for _, elapsed := range []time.Duration{0, 4 * time.Second, 1 * time.Second, 5 * time.Second, 12 * time.Second} {
+			now = now.Add(elapsed)
+
+			result, err := replay.Fetch(ctx)
+			assert.Nil(t, err)
+
+			waits = append(waits, result.Departures[0].Wait)
+		}
+
+		// Then
+		assert.Equal(t, []string{"3", "3", "2", "1", "3"}, waits)
+	})
+
+	t.Run(`Given a directory of recorded responses
+When data is fetched from a replay data source which rebases times
+Then the LastUpdated times are moved forward to the current time`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, "", "")
+
+		now := time.Date(2021, time.April, 24, 12, 0, 0, 0, time.UTC)
+
+		currentTimeFunc := func() time.Time {
+			return now
+		}
+
+		replay := developer.NewTfgmDeveloperMetrolinkReplayDataSource(logger, dataSource, givenRecordings(t), 0, true, currentTimeFunc)
+
+		ctx := context.Background()
+
+		// When
+		result, err := replay.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, now, result.LastUpdated)
+		assert.Equal(t, now, result.Departures[0].LastUpdated)
+		assert.Equal(t, now, result.Messages[0].LastUpdated)
+	})
+
+	t.Run(`Given an empty directory
+When data is fetched from a replay data source
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, "", "")
+
+		directory := t.TempDir()
+
+		replay := developer.NewTfgmDeveloperMetrolinkReplayDataSource(logger, dataSource, directory, 0, false, time.Now)
+
+		ctx := context.Background()
+
+		// When
+		result, err := replay.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, result)
+		assert.Equal(t, "no recordings in directory "+directory, err.Error())
+	})
+
+	t.Run(`Given a directory of responses which are not named by the time they were recorded
+When data is fetched from a replay data source with a positive speed
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		dataSource := developer.NewTfgmDeveloperMetrolinkDataSource(logger, &http.Client{}, "", "")
+
+		directory := t.TempDir()
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(directory, "capture.json"), []byte(givenRecordedResponse(t, "3", "2021-03-21T15:34:54Z")), 0644))
+
+		replay := developer.NewTfgmDeveloperMetrolinkReplayDataSource(logger, dataSource, directory, 1, false, time.Now)
+
+		ctx := context.Background()
+
+		// When
+		result, err := replay.Fetch(ctx)
+
+		// Then
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "error parsing recording time from filename capture.json")
+	})
+}
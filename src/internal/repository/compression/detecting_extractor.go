@@ -0,0 +1,41 @@
+package compression
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+)
+
+// DetectingExtractor extracts files from zip, tar, gzipped tar and gzip archives, detecting the format of each archive
+// from its magic bytes rather than from the name of the download
+type DetectingExtractor struct {
+	logger *zap.Logger
+	zip    *ZipFileExtractor
+	tar    *TarFileExtractor
+}
+
+func NewDetectingExtractor(logger *zap.Logger) *DetectingExtractor {
+	return &DetectingExtractor{
+		logger: logger,
+		zip:    NewZipFileExtractor(logger),
+		tar:    NewTarFileExtractor(logger),
+	}
+}
+
+func (c *DetectingExtractor) ExtractFile(archive Archive, filename string) (io.ReadCloser, error) {
+	format, err := detectFormat(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Debug("detected archive format", zap.String("format", format), zap.String("filename", filename))
+
+	switch format {
+	case formatZip:
+		return c.zip.ExtractFile(archive, filename)
+	case formatGzip, formatTar:
+		return c.tar.ExtractFile(archive, filename)
+	default:
+		return nil, fmt.Errorf("unknown archive format: expected a zip, tar or gzip archive containing '%s'", filename)
+	}
+}
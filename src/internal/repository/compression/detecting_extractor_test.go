@@ -0,0 +1,74 @@
+package compression_test
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDetectingExtractor_ExtractFile(t *testing.T) {
+	testCases := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{
+			name: "zip",
+			data: givenZippedData,
+		},
+		{
+			name: "tar",
+			data: givenTarData,
+		},
+		{
+			name: "gzipped tar",
+			data: func(t *testing.T) []byte {
+				return givenGzippedData(t, "", givenTarData(t))
+			},
+		},
+		{
+			name: "gzip",
+			data: func(t *testing.T) []byte {
+				return givenGzippedData(t, "file1.txt", []byte("The quick brown fox jumped over the lazy dog."))
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(`Given a `+testCase.name+` archive containing a file
+When ExtractFile is called with the file name
+Then the format is detected and an io.ReadCloser containing the file contents is returned`, func(t *testing.T) {
+			// Given
+			logger := mockLogger(t)
+
+			archive := givenArchive(t, testCase.data(t))
+
+			extractor := compression.NewDetectingExtractor(logger)
+
+			// When
+			rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+			// Then
+			assert.Nil(t, err)
+			assert.Equal(t, []byte("The quick brown fox jumped over the lazy dog."), readData(t, rc))
+			assert.Nil(t, rc.Close())
+		})
+	}
+
+	t.Run(`Given data which is not an archive
+When ExtractFile is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, []byte("not archive data"))
+
+		extractor := compression.NewDetectingExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+		// Then
+		assert.Nil(t, rc)
+		assert.EqualError(t, err, "unknown archive format: expected a zip, tar or gzip archive containing 'file1.txt'")
+	})
+}
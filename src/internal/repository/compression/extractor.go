@@ -2,7 +2,6 @@ package compression
 
 import (
 	"archive/zip"
-	"bytes"
 	"fmt"
 	"go.uber.org/zap"
 	"io"
@@ -18,8 +17,10 @@ func NewZipFileExtractor(logger *zap.Logger) *ZipFileExtractor {
 	}
 }
 
-func (c *ZipFileExtractor) ExtractFile(zipData []byte, filename string) (io.ReadCloser, error) {
-	zipReader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+// ExtractFile returns a reader which decompresses a file from a zip archive as it is read, so that neither the archive
+// nor the file is held in memory
+func (c *ZipFileExtractor) ExtractFile(archive Archive, filename string) (io.ReadCloser, error) {
+	zipReader, err := zip.NewReader(archive, archive.Size())
 	if err != nil {
 		return nil, err
 	}
@@ -47,6 +47,23 @@ func givenZippedData(t *testing.T) []byte {
 	return zipData
 }
 
+// bytesArchive is a compression.Archive held in memory
+type bytesArchive struct {
+	*bytes.Reader
+}
+
+func (bytesArchive) Close() error {
+	return nil
+}
+
+func givenArchive(t *testing.T, data []byte) compression.Archive {
+	t.Helper()
+
+	return bytesArchive{
+		Reader: bytes.NewReader(data),
+	}
+}
+
 func mockLogger(t *testing.T) *zap.Logger {
 	t.Helper()
 
@@ -72,12 +89,12 @@ Then an io.ReadCloser containing the file contents is returned`, func(t *testing
 		// Given
 		logger := mockLogger(t)
 
-		zipData := givenZippedData(t)
+		archive := givenArchive(t, givenZippedData(t))
 
 		extractor := compression.NewZipFileExtractor(logger)
 
 		// When
-		rc, err := extractor.ExtractFile(zipData, "file1.txt")
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
 
 		// Then
 		assert.NotNil(t, rc)
@@ -91,12 +108,12 @@ Then an error is returned`, func(t *testing.T) {
 		// Given
 		logger := mockLogger(t)
 
-		zipData := givenZippedData(t)
+		archive := givenArchive(t, givenZippedData(t))
 
 		extractor := compression.NewZipFileExtractor(logger)
 
 		// When
-		rc, err := extractor.ExtractFile(zipData, "file3.txt")
+		rc, err := extractor.ExtractFile(archive, "file3.txt")
 
 		// Then
 		assert.Nil(t, rc)
@@ -110,12 +127,12 @@ Then an error is returned`, func(t *testing.T) {
 		// Given
 		logger := mockLogger(t)
 
-		zipData := []byte("not zip data")
+		archive := givenArchive(t, []byte("not zip data"))
 
 		extractor := compression.NewZipFileExtractor(logger)
 
 		// When
-		rc, err := extractor.ExtractFile(zipData, "file1.txt")
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
 
 		// Then
 		assert.Nil(t, rc)
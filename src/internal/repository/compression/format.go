@@ -0,0 +1,70 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+)
+
+// Formats of archive, detected by the magic bytes at the start of the archive
+const (
+	formatGzip    = "gzip"
+	formatTar     = "tar"
+	formatUnknown = "unknown"
+	formatZip     = "zip"
+)
+
+var (
+	// gzipMagic starts every gzip file
+	gzipMagic = []byte{0x1f, 0x8b}
+	// tarMagic is the magic field of a POSIX or GNU tar header, at tarMagicOffset from the start of the archive
+	tarMagic       = []byte("ustar")
+	tarMagicOffset = 257
+	// zipMagic starts the local file header of the first file in a zip archive, or the end of central directory record
+	// of an empty zip archive
+	zipMagic      = []byte("PK\x03\x04")
+	emptyZipMagic = []byte("PK\x05\x06")
+)
+
+// detectFormat returns the format of an archive from its magic bytes
+func detectFormat(archive Archive) (string, error) {
+	return detectFormatOf(io.NewSectionReader(archive, 0, archive.Size()))
+}
+
+func detectFormatOf(reader io.Reader) (string, error) {
+	header := make([]byte, tarMagicOffset+len(tarMagic))
+
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, zipMagic), bytes.HasPrefix(header, emptyZipMagic):
+		return formatZip, nil
+	case bytes.HasPrefix(header, gzipMagic):
+		return formatGzip, nil
+	case len(header) == tarMagicOffset+len(tarMagic) && bytes.Equal(header[tarMagicOffset:], tarMagic):
+		return formatTar, nil
+	default:
+		return formatUnknown, nil
+	}
+}
+
+// isGzippedTar returns whether a gzip archive holds a tar archive (.tar.gz) rather than a single file (.gz)
+func isGzippedTar(archive Archive) (bool, error) {
+	gzipReader, err := gzip.NewReader(io.NewSectionReader(archive, 0, archive.Size()))
+	if err != nil {
+		return false, err
+	}
+	defer gzipReader.Close()
+
+	format, err := detectFormatOf(gzipReader)
+	if err != nil {
+		return false, err
+	}
+
+	return format == formatTar, nil
+}
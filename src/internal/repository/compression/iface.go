@@ -2,6 +2,14 @@ package compression
 
 import "io"
 
+// Archive is a compressed archive which is read at offsets, e.g. from a temporary file, rather than held in memory.
+// Closing the archive releases the storage behind it.
+type Archive interface {
+	io.ReaderAt
+	io.Closer
+	Size() int64
+}
+
 type Extractor interface {
-	ExtractFile(zipData []byte, filename string) (io.ReadCloser, error)
+	ExtractFile(archive Archive, filename string) (io.ReadCloser, error)
 }
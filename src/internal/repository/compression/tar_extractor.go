@@ -0,0 +1,112 @@
+package compression
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"io"
+	"path"
+)
+
+type TarFileExtractor struct {
+	logger *zap.Logger
+}
+
+func NewTarFileExtractor(logger *zap.Logger) *TarFileExtractor {
+	return &TarFileExtractor{
+		logger: logger,
+	}
+}
+
+// ExtractFile returns a reader of a file in a tar archive, which may be compressed with gzip (.tar.gz), or of a single
+// file compressed with gzip (.gz). Files are found by name; a leading ./ in the archive is ignored. The archive is read
+// sequentially up to the file, and the file is decompressed as it is read, so that neither is held in memory.
+func (c *TarFileExtractor) ExtractFile(archive Archive, filename string) (io.ReadCloser, error) {
+	format, err := detectFormat(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case formatTar:
+		return c.getFileFromTarArchive(io.NewSectionReader(archive, 0, archive.Size()), nil, filename)
+	case formatGzip:
+		return c.getFileFromGzipArchive(archive, filename)
+	default:
+		return nil, errors.New("not a valid tar or gzip archive")
+	}
+}
+
+func (c *TarFileExtractor) getFileFromGzipArchive(archive Archive, filename string) (io.ReadCloser, error) {
+	isTar, err := isGzippedTar(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	gzipReader, err := gzip.NewReader(io.NewSectionReader(archive, 0, archive.Size()))
+	if err != nil {
+		return nil, err
+	}
+
+	if isTar {
+		return c.getFileFromTarArchive(gzipReader, gzipReader, filename)
+	}
+
+	// A gzip file which is not a tar archive holds a single file, whose original name may be recorded in its header
+	if gzipReader.Name != "" && path.Base(gzipReader.Name) != path.Base(filename) {
+		c.close(gzipReader)
+		return nil, fmt.Errorf("file '%s' not found in gzip archive", filename)
+	}
+
+	return gzipReader, nil
+}
+
+func (c *TarFileExtractor) getFileFromTarArchive(reader io.Reader, closer io.Closer, filename string) (io.ReadCloser, error) {
+	tarReader := tar.NewReader(reader)
+
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			c.close(closer)
+
+			if err == io.EOF {
+				return nil, fmt.Errorf("file '%s' not found in tar archive", filename)
+			}
+
+			return nil, err
+		}
+
+		if header.Typeflag == tar.TypeReg && path.Clean(header.Name) == path.Clean(filename) {
+			return &tarFileReadCloser{
+				Reader: tarReader,
+				closer: closer,
+			}, nil
+		}
+	}
+}
+
+func (c *TarFileExtractor) close(closer io.Closer) {
+	if closer == nil {
+		return
+	}
+
+	if err := closer.Close(); err != nil {
+		c.logger.Error("error closing gzip reader", zap.Error(err))
+	}
+}
+
+// tarFileReadCloser reads a file from a tar archive, and closes the gzip reader of a compressed archive
+type tarFileReadCloser struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (r *tarFileReadCloser) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+
+	return r.closer.Close()
+}
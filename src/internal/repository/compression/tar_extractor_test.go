@@ -0,0 +1,178 @@
+package compression_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func givenTarData(t *testing.T) []byte {
+	t.Helper()
+
+	tarArchive := new(bytes.Buffer)
+	tarWriter := tar.NewWriter(tarArchive)
+
+	filesToTar := []struct {
+		name string
+		data []byte
+	}{
+		{name: "./file1.txt", data: []byte("The quick brown fox jumped over the lazy dog.")},
+		{name: "./file2.txt", data: []byte("The slow grey cat rode on the fox's back.")},
+	}
+
+	for _, file := range filesToTar {
+		if err := tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.name,
+			Mode:     0644,
+			Size:     int64(len(file.data)),
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := tarWriter.Write(file.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return tarArchive.Bytes()
+}
+
+func givenGzippedData(t *testing.T, name string, data []byte) []byte {
+	t.Helper()
+
+	gzipArchive := new(bytes.Buffer)
+
+	gzipWriter := gzip.NewWriter(gzipArchive)
+	gzipWriter.Name = name
+
+	if _, err := gzipWriter.Write(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return gzipArchive.Bytes()
+}
+
+func TestTarFileExtractor_ExtractFile(t *testing.T) {
+	t.Run(`Given a tar archive containing a file
+When ExtractFile is called with the file name
+Then an io.ReadCloser containing the file contents is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenTarData(t))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file2.txt")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("The slow grey cat rode on the fox's back."), readData(t, rc))
+		assert.Nil(t, rc.Close())
+	})
+
+	t.Run(`Given a gzipped tar archive containing a file
+When ExtractFile is called with the file name
+Then an io.ReadCloser containing the file contents is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenGzippedData(t, "", givenTarData(t)))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("The quick brown fox jumped over the lazy dog."), readData(t, rc))
+		assert.Nil(t, rc.Close())
+	})
+
+	t.Run(`Given a gzipped tar archive does not contain a file
+When ExtractFile is called with the file name
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenGzippedData(t, "", givenTarData(t)))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file3.txt")
+
+		// Then
+		assert.Nil(t, rc)
+		assert.EqualError(t, err, "file 'file3.txt' not found in tar archive")
+	})
+
+	t.Run(`Given a single gzipped file
+When ExtractFile is called with the name of the file
+Then an io.ReadCloser containing the file contents is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenGzippedData(t, "file1.txt", []byte("The quick brown fox jumped over the lazy dog.")))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("The quick brown fox jumped over the lazy dog."), readData(t, rc))
+		assert.Nil(t, rc.Close())
+	})
+
+	t.Run(`Given a single gzipped file with a different name
+When ExtractFile is called with the file name
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenGzippedData(t, "file2.txt", []byte("The slow grey cat rode on the fox's back.")))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+		// Then
+		assert.Nil(t, rc)
+		assert.EqualError(t, err, "file 'file1.txt' not found in gzip archive")
+	})
+
+	t.Run(`Given a zip archive
+When ExtractFile is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		logger := mockLogger(t)
+
+		archive := givenArchive(t, givenZippedData(t))
+
+		extractor := compression.NewTarFileExtractor(logger)
+
+		// When
+		rc, err := extractor.ExtractFile(archive, "file1.txt")
+
+		// Then
+		assert.Nil(t, rc)
+		assert.EqualError(t, err, "not a valid tar or gzip archive")
+	})
+}
@@ -0,0 +1,73 @@
+package compression
+
+import (
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// TempFileArchive is an Archive spooled to a temporary file, so that a large download uses disk rather than memory.
+// Closing it removes the temporary file.
+type TempFileArchive struct {
+	file *os.File
+	size int64
+}
+
+// SpoolToTempFile copies everything from reader to a new temporary file in dir, or in the default directory for
+// temporary files when dir is empty. The temporary file is removed if it cannot be written.
+func SpoolToTempFile(reader io.Reader, dir string) (*TempFileArchive, error) {
+	file, err := ioutil.TempFile(dir, "archive-*")
+	if err != nil {
+		return nil, errors.Wrap(err, "error creating temporary file")
+	}
+
+	archive := &TempFileArchive{
+		file: file,
+	}
+
+	size, err := io.Copy(file, reader)
+	if err != nil {
+		var errs error
+		errs = multierror.Append(errs, errors.Wrap(err, "error writing temporary file"))
+
+		if err := archive.Close(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+
+		return nil, errs
+	}
+
+	archive.size = size
+
+	return archive, nil
+}
+
+func (a *TempFileArchive) ReadAt(p []byte, off int64) (int, error) {
+	return a.file.ReadAt(p, off)
+}
+
+func (a *TempFileArchive) Size() int64 {
+	return a.size
+}
+
+// Name returns the path of the temporary file
+func (a *TempFileArchive) Name() string {
+	return a.file.Name()
+}
+
+// Close closes and removes the temporary file
+func (a *TempFileArchive) Close() error {
+	var errs error
+
+	if err := a.file.Close(); err != nil {
+		errs = multierror.Append(errs, errors.Wrap(err, "error closing temporary file"))
+	}
+
+	if err := os.Remove(a.file.Name()); err != nil {
+		errs = multierror.Append(errs, errors.Wrap(err, "error removing temporary file"))
+	}
+
+	return errs
+}
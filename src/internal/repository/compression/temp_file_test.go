@@ -0,0 +1,271 @@
+package compression_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/compression"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// failingReader returns some data and then an error, like a download which is interrupted
+type failingReader struct {
+	data []byte
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, r.err
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+
+	return n, nil
+}
+
+func givenFilesInDirectory(t *testing.T, dir string) []string {
+	t.Helper()
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filenames := make([]string, 0, len(fileInfos))
+	for _, fileInfo := range fileInfos {
+		filenames = append(filenames, fileInfo.Name())
+	}
+
+	return filenames
+}
+
+func TestSpoolToTempFile(t *testing.T) {
+	t.Run(`Given a reader
+When SpoolToTempFile is called
+Then an archive which reads the data from a temporary file is returned`, func(t *testing.T) {
+		// Given
+		dir := t.TempDir()
+
+		reader := bytes.NewBufferString("The quick brown fox jumped over the lazy dog.")
+
+		// When
+		archive, err := compression.SpoolToTempFile(reader, dir)
+
+		// Then
+		assert.Nil(t, err)
+		defer archive.Close()
+
+		assert.Equal(t, int64(45), archive.Size())
+		assert.FileExists(t, archive.Name())
+		assert.Len(t, givenFilesInDirectory(t, dir), 1)
+
+		p := make([]byte, 9)
+		n, err := archive.ReadAt(p, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 9, n)
+		assert.Equal(t, []byte("brown fox"), p)
+	})
+
+	t.Run(`Given an archive spooled to a temporary file
+When Close is called
+Then the temporary file is removed`, func(t *testing.T) {
+		// Given
+		dir := t.TempDir()
+
+		archive, err := compression.SpoolToTempFile(bytes.NewBufferString("arbitrary data"), dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// When
+		err = archive.Close()
+
+		// Then
+		assert.Nil(t, err)
+		assert.NoFileExists(t, archive.Name())
+		assert.Empty(t, givenFilesInDirectory(t, dir))
+	})
+
+	t.Run(`Given a reader which fails part way through
+When SpoolToTempFile is called
+Then an error is returned
+And the temporary file is removed`, func(t *testing.T) {
+		// Given
+		dir := t.TempDir()
+
+		reader := &failingReader{
+			data: []byte("arbitrary data"),
+			err:  errors.New("FUBAR"),
+		}
+
+		// When
+		archive, err := compression.SpoolToTempFile(reader, dir)
+
+		// Then
+		assert.Nil(t, archive)
+		assert.EqualError(t, err, "1 error occurred:\n\t* error writing temporary file: FUBAR\n\n")
+		assert.Empty(t, givenFilesInDirectory(t, dir))
+	})
+}
+
+// givenLargeZippedCsv returns a zip archive containing a CSV file of about 16MB, made from random data so that it does
+// not compress well, like the national NaPTAN download
+func givenLargeZippedCsv(b *testing.B, filename string) []byte {
+	b.Helper()
+
+	random := rand.New(rand.NewSource(1))
+
+	zipArchive := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(zipArchive)
+
+	fileWriter, err := zipWriter.Create(filename)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if _, err := fmt.Fprintln(fileWriter, "StopAreaCode,AtcoCode,RevisionNumber,Modification"); err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < 300000; i++ {
+		if _, err := fmt.Fprintf(fileWriter, "940G%08X,9400%016X%016X,%d,new\n", random.Uint32(), random.Uint64(), random.Uint64(), random.Intn(10)); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	return zipArchive.Bytes()
+}
+
+// readCsvRows streams every row of a CSV file, as the NaPTAN readers do, and returns the number of rows
+func readCsvRows(b *testing.B, reader io.Reader) int {
+	b.Helper()
+
+	csvReader := csv.NewReader(reader)
+	csvReader.ReuseRecord = true
+
+	rows := 0
+
+	for {
+		if _, err := csvReader.Read(); err != nil {
+			if err == io.EOF {
+				return rows
+			}
+
+			b.Fatal(err)
+		}
+
+		rows++
+	}
+}
+
+// liveHeapBytes returns the size of the objects which are still reachable on the heap
+func liveHeapBytes() uint64 {
+	runtime.GC()
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return memStats.HeapAlloc
+}
+
+// BenchmarkExtractFile compares holding a downloaded archive in memory, as the NaPTAN loader used to, with spooling it
+// to a temporary file. The live-heap-bytes metric is the most heap in use while a file is extracted from the archive,
+// which is what the memory of the loader Lambda has to accommodate; it drops from about the size of the archive to tens
+// of KB when the archive is spooled to a temporary file.
+//
+//	go test -run=^$ -bench=BenchmarkExtractFile ./internal/repository/compression
+func BenchmarkExtractFile(b *testing.B) {
+	filename := "StopsInArea.csv"
+	zipData := givenLargeZippedCsv(b, filename)
+
+	fetchers := []struct {
+		name  string
+		fetch func(b *testing.B, body io.Reader) compression.Archive
+	}{
+		{
+			name: "in memory",
+			fetch: func(b *testing.B, body io.Reader) compression.Archive {
+				data, err := ioutil.ReadAll(body)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				return bytesArchive{
+					Reader: bytes.NewReader(data),
+				}
+			},
+		},
+		{
+			name: "temporary file",
+			fetch: func(b *testing.B, body io.Reader) compression.Archive {
+				archive, err := compression.SpoolToTempFile(body, b.TempDir())
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				return archive
+			},
+		},
+	}
+
+	for _, fetcher := range fetchers {
+		b.Run(fetcher.name, func(b *testing.B) {
+			extractor := compression.NewZipFileExtractor(zap.NewNop())
+
+			var peakLiveHeapBytes uint64
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(zipData)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				baseline := liveHeapBytes()
+				// Hide the io.WriterTo of bytes.Reader, so that the archive is read like a HTTP response body
+				body := struct{ io.Reader }{bytes.NewReader(zipData)}
+				b.StartTimer()
+
+				archive := fetcher.fetch(b, body)
+
+				rc, err := extractor.ExtractFile(archive, filename)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				if rows := readCsvRows(b, rc); rows != 300001 {
+					b.Fatalf("read %d rows, expected 300001", rows)
+				}
+
+				b.StopTimer()
+				if liveHeap := liveHeapBytes(); liveHeap > baseline && liveHeap-baseline > peakLiveHeapBytes {
+					peakLiveHeapBytes = liveHeap - baseline
+				}
+				b.StartTimer()
+
+				if err := rc.Close(); err != nil {
+					b.Fatal(err)
+				}
+
+				if err := archive.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(peakLiveHeapBytes), "live-heap-bytes")
+		})
+	}
+}
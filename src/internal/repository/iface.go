@@ -3,11 +3,12 @@ package repository
 import (
 	"context"
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
-	"time"
 )
 
+// AtcoCodeLister finds the StopAreaCode of the stop area an AtcoCode belongs to, and lists every AtcoCode in that stop
+// area, including the given AtcoCode
 type AtcoCodeLister interface {
-	GetAtcoCodesInStopArea(stopAreaCode string) ([]string, error)
+	GetStopAreaForAtcoCode(ctx context.Context, atcoCode string) (string, []string, error)
 }
 
 type EventScheduler interface {
@@ -18,20 +19,76 @@ type MetrolinkDeparturesFetcher interface {
 	Fetch(ctx context.Context) (*domain.MetrolinkDepartures, error)
 }
 
-type MetrolinkDeparturesGetter interface {
-	Get(ctx context.Context, stopAreaCode string) ([]*domain.MetrolinkDeparture, error)
+type MetrolinkDeparturesMultiGetter interface {
+	GetMulti(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkDeparture, error)
 }
 
 type MetrolinkDeparturesStorer interface {
-	Store(ctx context.Context, departures []*domain.MetrolinkDeparture) error
+	Store(ctx context.Context, generation string, departures []*domain.MetrolinkDeparture) error
+}
+
+type MetrolinkMessagesGetter interface {
+	Get(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkMessage, error)
+}
+
+type MetrolinkLineMessagesGetter interface {
+	GetForLine(ctx context.Context, generation string, line string) ([]*domain.MetrolinkMessage, error)
+}
+
+type MetrolinkMessagesStorer interface {
+	Store(ctx context.Context, generation string, messages []*domain.MetrolinkMessage) error
+}
+
+// MetrolinkTlarefGetter resolves a TLAREF, the three letter code which TfGM use for a Metrolink stop, to the
+// StopAreaCode of the stop
+type MetrolinkTlarefGetter interface {
+	GetStopAreaCodeForTlaref(ctx context.Context, tlaref string) (string, error)
+}
+
+// MetrolinkTlarefsStorer stores the StopAreaCode of each TLAREF. TLAREFs which are not given are left in place.
+type MetrolinkTlarefsStorer interface {
+	StoreTlarefs(ctx context.Context, stopAreaCodesByTlaref map[string]string) error
 }
 
 type SystemStatusGetter interface {
-	Get(ctx context.Context) (*time.Time, error)
+	Get(ctx context.Context) (*domain.SystemStatus, error)
 }
 
 type SystemStatusSetter interface {
-	Set(ctx context.Context, lastUpdated time.Time) error
+	Set(ctx context.Context, systemStatus *domain.SystemStatus) error
+}
+
+type MetrolinkStopDirectoryGetter interface {
+	GetStopDirectory(ctx context.Context) ([]*domain.MetrolinkStopArea, error)
+}
+
+type MetrolinkStopDirectoryStorer interface {
+	StoreStopDirectory(ctx context.Context, stopAreas []*domain.MetrolinkStopArea) error
+}
+
+type NaptanStopsFetcher interface {
+	FetchStopsAndStopAreas(ctx context.Context) ([]*domain.NaptanStop, []*domain.NaptanStopArea, error)
+}
+
+type NaptanStopsGetter interface {
+	GetStops(ctx context.Context) ([]*domain.NaptanStop, error)
+}
+
+type NaptanStopsStorer interface {
+	StoreStops(ctx context.Context, stops []*domain.NaptanStop) error
+}
+
+type NaptanStopAreasGetter interface {
+	GetStopAreas(ctx context.Context) ([]*domain.NaptanStopArea, error)
+}
+
+// NaptanStopAreasNearbyGetter finds the stop areas within radiusMetres of a location, nearest first
+type NaptanStopAreasNearbyGetter interface {
+	GetStopAreasNearby(ctx context.Context, latitude float64, longitude float64, radiusMetres float64, limit int) ([]*domain.NearbyStopArea, error)
+}
+
+type NaptanStopAreasStorer interface {
+	StoreStopAreas(ctx context.Context, stopAreas []*domain.NaptanStopArea) error
 }
 
 type PlatformNamer interface {
@@ -42,10 +99,22 @@ type StopsInAreaFetcher interface {
 	FetchStopsInArea(ctx context.Context) (map[string][]string, error)
 }
 
+// StopsInAreaLister returns every stored stop area, with the AtcoCodes of its stops, by StopAreaCode
+type StopsInAreaLister interface {
+	GetAllStopsInArea(ctx context.Context) (map[string][]string, error)
+}
+
+// StopsInAreaChangeReportPublisher publishes the changes made by a load of NaPTAN stops in area
+type StopsInAreaChangeReportPublisher interface {
+	PublishStopsInAreaChangeReport(ctx context.Context, report *domain.StopsInAreaChangeReport) error
+}
+
 type StopsInAreaGetter interface {
 	GetStopsInArea(ctx context.Context, stopAreaCode string) ([]string, error)
 }
 
+// StopsInAreaStorer replaces the stored stops in every stop area, and the StopAreaCode of the stop area each AtcoCode
+// belongs to, together
 type StopsInAreaStorer interface {
-	StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string) error
+	StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string, stopAreaCodesByAtcoCode map[string]string) error
 }
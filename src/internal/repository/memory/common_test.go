@@ -0,0 +1,45 @@
+package memory_test
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"sync"
+	"testing"
+	"time"
+)
+
+func mockLogger(t *testing.T) *zap.Logger {
+	t.Helper()
+
+	zapCore, _ := observer.New(zapcore.DebugLevel)
+	return zap.New(zapCore)
+}
+
+// clock is a current time func which can be moved forward by tests to expire items
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func givenClock(t *testing.T) *clock {
+	t.Helper()
+
+	return &clock{
+		now: time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC),
+	}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"math"
+)
+
+// earthRadiusMetres is the radius used by Redis for geospatial commands, so that distances match the Redis repositories
+const earthRadiusMetres = 6372797.560856
+
+// distanceMetres returns the great-circle distance between two locations with the haversine formula, as Redis does
+func distanceMetres(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	lat1 := latitude1 * math.Pi / 180
+	lat2 := latitude2 * math.Pi / 180
+	u := math.Sin((lat2 - lat1) / 2)
+	v := math.Sin((longitude2 - longitude1) * math.Pi / 180 / 2)
+
+	return 2 * earthRadiusMetres * math.Asin(math.Sqrt(u*u+math.Cos(lat1)*math.Cos(lat2)*v*v))
+}
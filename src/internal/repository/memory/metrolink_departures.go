@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkDeparturesRepository stores departures in memory with the same keys and behaviour as the Redis repository:
+// departures are grouped by AtcoCode under a generation, and GetMulti skips AtcoCodes without departures.
+type MetrolinkDeparturesRepository struct {
+	logger               *zap.Logger
+	store                *Store
+	departuresKeyPrefix  string
+	departuresTimeToLive time.Duration
+}
+
+func NewMetrolinkDeparturesRepository(logger *zap.Logger, store *Store, departuresKeyPrefix string, departuresTimeToLive time.Duration) *MetrolinkDeparturesRepository {
+	return &MetrolinkDeparturesRepository{
+		logger:               logger,
+		store:                store,
+		departuresKeyPrefix:  departuresKeyPrefix,
+		departuresTimeToLive: departuresTimeToLive,
+	}
+}
+
+// GetMulti returns the departures for every AtcoCode. AtcoCodes without departures are skipped.
+func (m *MetrolinkDeparturesRepository) GetMulti(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkDeparture, error) {
+	var departures []*domain.MetrolinkDeparture
+
+	for _, atcoCode := range atcoCodes {
+		departuresJson, ok := m.store.get(m.departuresKey(generation, atcoCode))
+		if !ok {
+			continue
+		}
+
+		var departuresForAtcoCode []*domain.MetrolinkDeparture
+		if err := json.Unmarshal(departuresJson, &departuresForAtcoCode); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling departures for AtcoCode %s", atcoCode)
+		}
+
+		departures = append(departures, departuresForAtcoCode...)
+	}
+
+	return departures, nil
+}
+
+// Store writes departures under a new generation, which only becomes visible to readers once it is published in the
+// system status
+func (m *MetrolinkDeparturesRepository) Store(ctx context.Context, generation string, departures []*domain.MetrolinkDeparture) error {
+	groupedDepartures := make(map[string][]*domain.MetrolinkDeparture)
+
+	for _, departure := range departures {
+		groupedDepartures[departure.AtcoCode] = append(groupedDepartures[departure.AtcoCode], departure)
+	}
+
+	values := make(map[string][]byte)
+
+	for atcoCode, departuresForAtcoCode := range groupedDepartures {
+		departuresJson, err := json.Marshal(departuresForAtcoCode)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding departures as JSON for AtcoCode %s", atcoCode)
+		}
+
+		values[m.departuresKey(generation, atcoCode)] = departuresJson
+	}
+
+	m.store.setMulti(values, m.departuresTimeToLive)
+
+	return nil
+}
+
+func (m *MetrolinkDeparturesRepository) departuresKey(generation string, atcoCode string) string {
+	if generation == "" {
+		return fmt.Sprintf("%s_%s", m.departuresKeyPrefix, atcoCode)
+	}
+
+	return fmt.Sprintf("%s_%s_%s", m.departuresKeyPrefix, generation, atcoCode)
+}
@@ -0,0 +1,154 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func givenGeneration(t *testing.T) string {
+	t.Helper()
+
+	return "1617745039000000000"
+}
+
+func givenDepartures(t *testing.T) []*domain.MetrolinkDeparture {
+	t.Helper()
+
+	platform1 := "1"
+	platform2 := "2"
+
+	lastUpdated := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+
+	return []*domain.MetrolinkDeparture{
+		{
+			AtcoCode:    "9400ZZMASTP1",
+			Destination: "Altrincham",
+			Carriages:   "Single",
+			Status:      "Due",
+			Wait:        "3",
+			Platform:    &platform1,
+			LastUpdated: lastUpdated,
+		},
+		{
+			AtcoCode:    "9400ZZMASTP1",
+			Destination: "Bury",
+			Carriages:   "Double",
+			Status:      "Due",
+			Wait:        "7",
+			Platform:    &platform1,
+			LastUpdated: lastUpdated,
+		},
+		{
+			AtcoCode:    "9400ZZMASTP2",
+			Destination: "Manchester Airport",
+			Carriages:   "Single",
+			Status:      "Due",
+			Wait:        "5",
+			Platform:    &platform2,
+			LastUpdated: lastUpdated,
+		},
+	}
+}
+
+func TestMetrolinkDeparturesRepository_GetMulti(t *testing.T) {
+	t.Run(`Given departures stored in a generation
+When GetMulti is called with AtcoCodes, some of which have no departures
+Then the departures for the AtcoCodes with departures are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures", 15*time.Second)
+
+		departures := givenDepartures(t)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), departures))
+
+		// When
+		result, err := repository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, departures, result)
+	})
+
+	t.Run(`Given departures stored in a generation
+When GetMulti is called
+And the returned departures are modified
+Then the stored departures are not modified`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures", 15*time.Second)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), givenDepartures(t)))
+
+		result, err := repository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
+		assert.Nil(t, err)
+
+		// When
+		result[0].Wait = "0"
+
+		// Then
+		result, err = repository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "3", result[0].Wait)
+	})
+
+	t.Run(`Given departures stored in a generation
+When GetMulti is called with AtcoCodes in a different generation
+Then no departures are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures", 15*time.Second)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), givenDepartures(t)))
+
+		// When
+		result, err := repository.GetMulti(ctx, "1617745029000000000", []string{"9400ZZMASTP1"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Nil(t, result)
+	})
+
+	t.Run(`Given departures stored with a time to live
+When GetMulti is called after the time to live has passed
+Then no departures are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures", 15*time.Second)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), givenDepartures(t)))
+
+		c.Advance(15 * time.Second)
+
+		// When
+		result, err := repository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// MetrolinkMessagesRepository stores messages in memory with the same keys and behaviour as the Redis repository:
+// messages are grouped by both AtcoCode and Line under a generation, and GetForLine returns redis.ErrNil for a line
+// without messages.
+type MetrolinkMessagesRepository struct {
+	logger             *zap.Logger
+	store              *Store
+	messagesKeyPrefix  string
+	messagesTimeToLive time.Duration
+}
+
+func NewMetrolinkMessagesRepository(logger *zap.Logger, store *Store, messagesKeyPrefix string, messagesTimeToLive time.Duration) *MetrolinkMessagesRepository {
+	return &MetrolinkMessagesRepository{
+		logger:             logger,
+		store:              store,
+		messagesKeyPrefix:  messagesKeyPrefix,
+		messagesTimeToLive: messagesTimeToLive,
+	}
+}
+
+// Get returns the messages for every AtcoCode. AtcoCodes without messages are skipped.
+func (m *MetrolinkMessagesRepository) Get(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkMessage, error) {
+	var messages []*domain.MetrolinkMessage
+
+	for _, atcoCode := range atcoCodes {
+		messagesJson, ok := m.store.get(m.messagesKey(generation, atcoCode))
+		if !ok {
+			continue
+		}
+
+		var messagesForAtcoCode []*domain.MetrolinkMessage
+		if err := json.Unmarshal(messagesJson, &messagesForAtcoCode); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling messages for AtcoCode %s", atcoCode)
+		}
+
+		messages = append(messages, messagesForAtcoCode...)
+	}
+
+	return messages, nil
+}
+
+func (m *MetrolinkMessagesRepository) GetForLine(ctx context.Context, generation string, line string) ([]*domain.MetrolinkMessage, error) {
+	messagesJson, ok := m.store.get(m.lineMessagesKey(generation, line))
+	if !ok {
+		return nil, redis.ErrNil
+	}
+
+	var messages []*domain.MetrolinkMessage
+	if err := json.Unmarshal(messagesJson, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Store writes messages under a new generation, which only becomes visible to readers once it is published in the
+// system status
+func (m *MetrolinkMessagesRepository) Store(ctx context.Context, generation string, messages []*domain.MetrolinkMessage) error {
+	groupedMessages := make(map[string][]*domain.MetrolinkMessage)
+
+	for _, message := range messages {
+		atcoCodeKey := m.messagesKey(generation, message.AtcoCode)
+		groupedMessages[atcoCodeKey] = append(groupedMessages[atcoCodeKey], message)
+
+		if message.Line != "" {
+			lineKey := m.lineMessagesKey(generation, message.Line)
+			groupedMessages[lineKey] = append(groupedMessages[lineKey], message)
+		}
+	}
+
+	values := make(map[string][]byte)
+
+	for key, messagesForKey := range groupedMessages {
+		messagesJson, err := json.Marshal(messagesForKey)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding messages as JSON for key %s", key)
+		}
+
+		values[key] = messagesJson
+	}
+
+	m.store.setMulti(values, m.messagesTimeToLive)
+
+	return nil
+}
+
+func (m *MetrolinkMessagesRepository) messagesKey(generation string, atcoCode string) string {
+	return fmt.Sprintf("%s_%s", m.generationKeyPrefix(generation), atcoCode)
+}
+
+func (m *MetrolinkMessagesRepository) lineMessagesKey(generation string, line string) string {
+	return fmt.Sprintf("%s_line_%s", m.generationKeyPrefix(generation), strings.ToLower(line))
+}
+
+func (m *MetrolinkMessagesRepository) generationKeyPrefix(generation string) string {
+	if generation == "" {
+		return m.messagesKeyPrefix
+	}
+
+	return fmt.Sprintf("%s_%s", m.messagesKeyPrefix, generation)
+}
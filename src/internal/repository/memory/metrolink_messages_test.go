@@ -0,0 +1,103 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func givenMessages(t *testing.T) []*domain.MetrolinkMessage {
+	t.Helper()
+
+	return []*domain.MetrolinkMessage{
+		{
+			AtcoCode: "9400ZZMASTP1",
+			Line:     "Airport",
+			Message:  "No trams to Manchester Airport due to engineering works",
+		},
+		{
+			AtcoCode: "9400ZZMASTP2",
+			Message:  "Please take care on the platform",
+		},
+	}
+}
+
+func TestMetrolinkMessagesRepository_Get(t *testing.T) {
+	t.Run(`Given messages stored in a generation
+When Get is called with AtcoCodes, some of which have no messages
+Then the messages for the AtcoCodes with messages are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkMessagesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_messages", 15*time.Second)
+
+		messages := givenMessages(t)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), messages))
+
+		// When
+		result, err := repository.Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, messages, result)
+	})
+}
+
+func TestMetrolinkMessagesRepository_GetForLine(t *testing.T) {
+	t.Run(`Given messages stored in a generation
+When GetForLine is called with a line in any case
+Then the messages for that line are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkMessagesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_messages", 15*time.Second)
+
+		messages := givenMessages(t)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), messages))
+
+		// When
+		result, err := repository.GetForLine(ctx, givenGeneration(t), "AIRPORT")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, messages[:1], result)
+	})
+
+	t.Run(`Given messages stored with a time to live
+When GetForLine is called after the time to live has passed
+Then redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkMessagesRepository(logger, memory.NewStore(logger, c.Now), "metrolink_messages", 15*time.Second)
+
+		assert.Nil(t, repository.Store(ctx, givenGeneration(t), givenMessages(t)))
+
+		c.Advance(time.Minute)
+
+		// When
+		result, err := repository.GetForLine(ctx, givenGeneration(t), "Airport")
+
+		// Then
+		assert.Equal(t, redis.ErrNil, err)
+		assert.Nil(t, result)
+	})
+}
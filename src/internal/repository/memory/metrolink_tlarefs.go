@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkTlarefsRepository stores the StopAreaCode of each TLAREF in memory with the same keys and behaviour as the
+// Redis repository
+type MetrolinkTlarefsRepository struct {
+	logger            *zap.Logger
+	store             *Store
+	tlarefsKeyPrefix  string
+	tlarefsTimeToLive time.Duration
+}
+
+func NewMetrolinkTlarefsRepository(logger *zap.Logger, store *Store, tlarefsKeyPrefix string, tlarefsTimeToLive time.Duration) *MetrolinkTlarefsRepository {
+	return &MetrolinkTlarefsRepository{
+		logger:            logger,
+		store:             store,
+		tlarefsKeyPrefix:  tlarefsKeyPrefix,
+		tlarefsTimeToLive: tlarefsTimeToLive,
+	}
+}
+
+func (m *MetrolinkTlarefsRepository) GetStopAreaCodeForTlaref(ctx context.Context, tlaref string) (string, error) {
+	stopAreaCode, ok := m.store.get(m.tlarefKey(tlaref))
+	if !ok {
+		return "", errors.Wrapf(redis.ErrNil, "error getting StopAreaCode for TLAREF %s", tlaref)
+	}
+
+	return string(stopAreaCode), nil
+}
+
+func (m *MetrolinkTlarefsRepository) StoreTlarefs(ctx context.Context, stopAreaCodesByTlaref map[string]string) error {
+	values := make(map[string][]byte, len(stopAreaCodesByTlaref))
+	for tlaref, stopAreaCode := range stopAreaCodesByTlaref {
+		values[m.tlarefKey(tlaref)] = []byte(stopAreaCode)
+	}
+
+	m.store.setMulti(values, m.tlarefsTimeToLive)
+
+	return nil
+}
+
+func (m *MetrolinkTlarefsRepository) tlarefKey(tlaref string) string {
+	return fmt.Sprintf("%s_%s", m.tlarefsKeyPrefix, tlaref)
+}
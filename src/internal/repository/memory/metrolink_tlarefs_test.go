@@ -0,0 +1,64 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkTlarefsRepository_GetStopAreaCodeForTlaref(t *testing.T) {
+	t.Run(`Given TLAREFs have been stored in separate loads
+When GetStopAreaCodeForTlaref is called for each TLAREF
+Then the StopAreaCode of each TLAREF is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkTlarefsRepository(logger, memory.NewStore(logger, c.Now), "metrolink_tlarefs", 24*time.Hour)
+
+		assert.Nil(t, repository.StoreTlarefs(ctx, map[string]string{"PIC": "940GZZMAPIC"}))
+		assert.Nil(t, repository.StoreTlarefs(ctx, map[string]string{"DCF": "940GZZMAGMX"}))
+
+		// When
+		picStopAreaCode, picErr := repository.GetStopAreaCodeForTlaref(ctx, "PIC")
+		dcfStopAreaCode, dcfErr := repository.GetStopAreaCodeForTlaref(ctx, "DCF")
+
+		// Then
+		assert.Nil(t, picErr)
+		assert.Equal(t, "940GZZMAPIC", picStopAreaCode)
+		assert.Nil(t, dcfErr)
+		assert.Equal(t, "940GZZMAGMX", dcfStopAreaCode)
+	})
+
+	t.Run(`Given a TLAREF has been stored with a time to live
+When GetStopAreaCodeForTlaref is called after the time to live has passed
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkTlarefsRepository(logger, memory.NewStore(logger, c.Now), "metrolink_tlarefs", 24*time.Hour)
+
+		assert.Nil(t, repository.StoreTlarefs(ctx, map[string]string{"PIC": "940GZZMAPIC"}))
+
+		c.Advance(24 * time.Hour)
+
+		// When
+		stopAreaCode, err := repository.GetStopAreaCodeForTlaref(ctx, "PIC")
+
+		// Then
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting StopAreaCode for TLAREF PIC: redigo: nil returned", err.Error())
+		assert.Equal(t, "", stopAreaCode)
+	})
+}
@@ -0,0 +1,191 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+// NaptanMemory stores NaPTAN stops in area in memory with the same keys and behaviour as the Redis repository
+type NaptanMemory struct {
+	logger            *zap.Logger
+	store             *Store
+	keyPrefix         string
+	atcoCodeKeyPrefix string
+	timeToLive        time.Duration
+}
+
+func NewNaptanMemory(logger *zap.Logger, store *Store, keyPrefix string, atcoCodeKeyPrefix string, timeToLive time.Duration) *NaptanMemory {
+	return &NaptanMemory{
+		logger:            logger,
+		store:             store,
+		keyPrefix:         keyPrefix,
+		atcoCodeKeyPrefix: atcoCodeKeyPrefix,
+		timeToLive:        timeToLive,
+	}
+}
+
+func (n *NaptanMemory) GetStopsInArea(ctx context.Context, stopAreaCode string) ([]string, error) {
+	stopsInAreaJson, ok := n.store.get(n.key(stopAreaCode))
+	if !ok {
+		return nil, errors.Wrapf(redis.ErrNil, "error getting stops in area for %s", stopAreaCode)
+	}
+
+	var atcoCodes []string
+	if err := json.Unmarshal(stopsInAreaJson, &atcoCodes); err != nil {
+		return nil, errors.Wrapf(err, "error unmarshalling data for %s", stopAreaCode)
+	}
+
+	return atcoCodes, nil
+}
+
+// GetAllStopsInArea returns every stop area in the index of stored StopAreaCodes. Stop areas which have expired are not
+// returned.
+func (n *NaptanMemory) GetAllStopsInArea(ctx context.Context) (map[string][]string, error) {
+	stopAreaCodes, err := n.getIndex(n.indexKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting stored StopAreaCodes")
+	}
+
+	stopsInArea := make(map[string][]string)
+
+	for _, stopAreaCode := range stopAreaCodes {
+		atcoCodes, err := n.GetStopsInArea(ctx, stopAreaCode)
+		if err != nil {
+			if errors.Cause(err) == redis.ErrNil {
+				continue
+			}
+
+			return nil, err
+		}
+
+		stopsInArea[stopAreaCode] = atcoCodes
+	}
+
+	return stopsInArea, nil
+}
+
+// StoreStopsInArea replaces every stored stop area with stopsInArea, and the StopAreaCode stored for every AtcoCode with
+// stopAreaCodesByAtcoCode, in a single update. As in Redis, the StopAreaCodes and AtcoCodes which are stored are kept in
+// indexes, so that stop areas and AtcoCodes which are no longer present are deleted rather than left to expire.
+func (n *NaptanMemory) StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string, stopAreaCodesByAtcoCode map[string]string) error {
+	storedStopAreaCodes, err := n.getIndex(n.indexKey())
+	if err != nil {
+		return errors.Wrap(err, "error getting stored StopAreaCodes")
+	}
+
+	storedAtcoCodes, err := n.getIndex(n.atcoCodeIndexKey())
+	if err != nil {
+		return errors.Wrap(err, "error getting stored AtcoCodes")
+	}
+
+	values := make(map[string][]byte)
+	stopAreaCodes := make([]string, 0, len(stopsInArea))
+
+	for stopAreaCode, atcoCodes := range stopsInArea {
+		stopsInAreaJson, err := json.Marshal(atcoCodes)
+		if err != nil {
+			return errors.Wrapf(err, "error encoding AtcoCodes for StopAreaCode %s", stopAreaCode)
+		}
+
+		values[n.key(stopAreaCode)] = stopsInAreaJson
+		stopAreaCodes = append(stopAreaCodes, stopAreaCode)
+	}
+
+	atcoCodes := make([]string, 0, len(stopAreaCodesByAtcoCode))
+
+	for atcoCode, stopAreaCode := range stopAreaCodesByAtcoCode {
+		values[n.atcoCodeKey(atcoCode)] = []byte(stopAreaCode)
+		atcoCodes = append(atcoCodes, atcoCode)
+	}
+
+	if err := n.setIndex(values, n.indexKey(), stopAreaCodes); err != nil {
+		return errors.Wrap(err, "error encoding StopAreaCode index")
+	}
+
+	if err := n.setIndex(values, n.atcoCodeIndexKey(), atcoCodes); err != nil {
+		return errors.Wrap(err, "error encoding AtcoCode index")
+	}
+
+	removedKeys := make([]string, 0)
+
+	for _, stopAreaCode := range storedStopAreaCodes {
+		if _, ok := stopsInArea[stopAreaCode]; !ok {
+			removedKeys = append(removedKeys, n.key(stopAreaCode))
+		}
+	}
+
+	for _, atcoCode := range storedAtcoCodes {
+		if _, ok := stopAreaCodesByAtcoCode[atcoCode]; !ok {
+			removedKeys = append(removedKeys, n.atcoCodeKey(atcoCode))
+		}
+	}
+
+	n.store.update(values, removedKeys, n.timeToLive)
+
+	return nil
+}
+
+// getIndex returns the members of the index stored at indexKey, or nil when it has not been stored
+func (n *NaptanMemory) getIndex(indexKey string) ([]string, error) {
+	indexJson, ok := n.store.get(indexKey)
+	if !ok {
+		return nil, nil
+	}
+
+	var members []string
+	if err := json.Unmarshal(indexJson, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// setIndex adds the index at indexKey, with members sorted, to values
+func (n *NaptanMemory) setIndex(values map[string][]byte, indexKey string, members []string) error {
+	sort.Strings(members)
+
+	indexJson, err := json.Marshal(members)
+	if err != nil {
+		return err
+	}
+
+	values[indexKey] = indexJson
+
+	return nil
+}
+
+func (n *NaptanMemory) key(stopAreaCode string) string {
+	return fmt.Sprintf("%s_%s", n.keyPrefix, stopAreaCode)
+}
+
+func (n *NaptanMemory) indexKey() string {
+	return fmt.Sprintf("%s_index", n.keyPrefix)
+}
+
+func (n *NaptanMemory) GetStopAreaForAtcoCode(ctx context.Context, atcoCode string) (string, []string, error) {
+	stopAreaCode, ok := n.store.get(n.atcoCodeKey(atcoCode))
+	if !ok {
+		return "", nil, errors.Wrapf(redis.ErrNil, "error getting StopAreaCode for AtcoCode %s", atcoCode)
+	}
+
+	atcoCodes, err := n.GetStopsInArea(ctx, string(stopAreaCode))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(stopAreaCode), atcoCodes, nil
+}
+
+func (n *NaptanMemory) atcoCodeKey(atcoCode string) string {
+	return fmt.Sprintf("%s_%s", n.atcoCodeKeyPrefix, atcoCode)
+}
+
+func (n *NaptanMemory) atcoCodeIndexKey() string {
+	return fmt.Sprintf("%s_index", n.atcoCodeKeyPrefix)
+}
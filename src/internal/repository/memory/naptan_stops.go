@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+// NaptanStopsMemory stores NaPTAN stops and stop areas in memory with the same keys and behaviour as the Redis
+// repository
+type NaptanStopsMemory struct {
+	logger       *zap.Logger
+	store        *Store
+	stopsKey     string
+	stopAreasKey string
+	timeToLive   time.Duration
+}
+
+func NewNaptanStopsMemory(logger *zap.Logger, store *Store, stopsKey string, stopAreasKey string, timeToLive time.Duration) *NaptanStopsMemory {
+	return &NaptanStopsMemory{
+		logger:       logger,
+		store:        store,
+		stopsKey:     stopsKey,
+		stopAreasKey: stopAreasKey,
+		timeToLive:   timeToLive,
+	}
+}
+
+func (n *NaptanStopsMemory) GetStops(ctx context.Context) ([]*domain.NaptanStop, error) {
+	var stops []*domain.NaptanStop
+	if err := n.get(n.stopsKey, &stops); err != nil {
+		return nil, errors.Wrap(err, "error getting NaPTAN stops")
+	}
+
+	return stops, nil
+}
+
+func (n *NaptanStopsMemory) StoreStops(ctx context.Context, stops []*domain.NaptanStop) error {
+	if err := n.set(n.stopsKey, stops); err != nil {
+		return errors.Wrap(err, "error storing NaPTAN stops")
+	}
+
+	return nil
+}
+
+func (n *NaptanStopsMemory) GetStopAreas(ctx context.Context) ([]*domain.NaptanStopArea, error) {
+	var stopAreas []*domain.NaptanStopArea
+	if err := n.get(n.stopAreasKey, &stopAreas); err != nil {
+		return nil, errors.Wrap(err, "error getting NaPTAN stop areas")
+	}
+
+	return stopAreas, nil
+}
+
+func (n *NaptanStopsMemory) StoreStopAreas(ctx context.Context, stopAreas []*domain.NaptanStopArea) error {
+	if err := n.set(n.stopAreasKey, stopAreas); err != nil {
+		return errors.Wrap(err, "error storing NaPTAN stop areas")
+	}
+
+	return nil
+}
+
+// GetStopAreasNearby returns up to limit stop areas within radiusMetres of a location, nearest first. The stored stop
+// areas are searched in full, which is quick for the few stop areas of the Metrolink network.
+func (n *NaptanStopsMemory) GetStopAreasNearby(ctx context.Context, latitude float64, longitude float64, radiusMetres float64, limit int) ([]*domain.NearbyStopArea, error) {
+	var stopAreas []*domain.NaptanStopArea
+	if err := n.get(n.stopAreasKey, &stopAreas); err != nil {
+		if err == redis.ErrNil {
+			return make([]*domain.NearbyStopArea, 0), nil
+		}
+
+		return nil, errors.Wrap(err, "error searching for NaPTAN stop areas nearby")
+	}
+
+	nearbyStopAreas := make([]*domain.NearbyStopArea, 0)
+
+	for _, stopArea := range stopAreas {
+		distance := distanceMetres(latitude, longitude, stopArea.Latitude, stopArea.Longitude)
+		if distance > radiusMetres {
+			continue
+		}
+
+		nearbyStopAreas = append(nearbyStopAreas, &domain.NearbyStopArea{
+			StopAreaCode:   stopArea.StopAreaCode,
+			DistanceMetres: distance,
+		})
+	}
+
+	sort.SliceStable(nearbyStopAreas, func(i, j int) bool {
+		return nearbyStopAreas[i].DistanceMetres < nearbyStopAreas[j].DistanceMetres
+	})
+
+	if limit > 0 && len(nearbyStopAreas) > limit {
+		nearbyStopAreas = nearbyStopAreas[:limit]
+	}
+
+	return nearbyStopAreas, nil
+}
+
+func (n *NaptanStopsMemory) get(key string, v interface{}) error {
+	data, ok := n.store.get(key)
+	if !ok {
+		return redis.ErrNil
+	}
+
+	return errors.Wrap(json.Unmarshal(data, v), "error unmarshalling JSON")
+}
+
+func (n *NaptanStopsMemory) set(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "error encoding JSON")
+	}
+
+	n.store.set(key, data, n.timeToLive)
+
+	return nil
+}
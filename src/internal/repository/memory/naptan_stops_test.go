@@ -0,0 +1,131 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNaptanStopsMemory(t *testing.T) {
+	t.Run(`Given NaPTAN stops and stop areas have been stored
+When GetStops and GetStopAreas are called
+Then the stops and stop areas are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanStopsMemory(logger, memory.NewStore(logger, c.Now), "naptan_stops", "naptan_stop_areas", 25*time.Hour)
+
+		stops := []*domain.NaptanStop{
+			{AtcoCode: "9400ZZMAPIC1", CommonName: "Piccadilly (Manchester Metrolink)", Latitude: 53.4776, Longitude: -2.2301},
+		}
+
+		stopAreas := []*domain.NaptanStopArea{
+			{StopAreaCode: "940GZZMAPIC", Name: "Piccadilly (Manchester Metrolink)", Latitude: 53.4775, Longitude: -2.2302},
+		}
+
+		assert.Nil(t, repository.StoreStops(ctx, stops))
+		assert.Nil(t, repository.StoreStopAreas(ctx, stopAreas))
+
+		// When
+		storedStops, stopsErr := repository.GetStops(ctx)
+		storedStopAreas, stopAreasErr := repository.GetStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, stopsErr)
+		assert.Equal(t, stops, storedStops)
+
+		assert.Nil(t, stopAreasErr)
+		assert.Equal(t, stopAreas, storedStopAreas)
+	})
+
+	t.Run(`Given NaPTAN stops have been stored with a time to live
+When GetStops is called after the time to live has passed
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanStopsMemory(logger, memory.NewStore(logger, c.Now), "naptan_stops", "naptan_stop_areas", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStops(ctx, []*domain.NaptanStop{
+			{AtcoCode: "9400ZZMAPIC1"},
+		}))
+
+		c.Advance(25 * time.Hour)
+
+		// When
+		stops, err := repository.GetStops(ctx)
+
+		// Then
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting NaPTAN stops: redigo: nil returned", err.Error())
+		assert.Nil(t, stops)
+	})
+}
+
+func TestNaptanStopsMemory_GetStopAreasNearby(t *testing.T) {
+	t.Run(`Given NaPTAN stop areas have been stored
+When GetStopAreasNearby is called with a location, radius and limit
+Then the stop areas within the radius are returned with their distances, nearest first, up to the limit`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanStopsMemory(logger, memory.NewStore(logger, c.Now), "naptan_stops", "naptan_stop_areas", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopAreas(ctx, []*domain.NaptanStopArea{
+			{StopAreaCode: "940GZZMAPIC", Latitude: 53.4775, Longitude: -2.2302},
+			{StopAreaCode: "940GZZMASTP", Latitude: 53.4780, Longitude: -2.2436},
+			{StopAreaCode: "940GZZMAVIC", Latitude: 53.4875, Longitude: -2.2423},
+			{StopAreaCode: "940GZZMAALT", Latitude: 53.3874, Longitude: -2.3475},
+		}))
+
+		// When
+		nearbyStopAreas, err := repository.GetStopAreasNearby(ctx, 53.4780, -2.2400, 1500, 2)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(nearbyStopAreas))
+
+		assert.Equal(t, "940GZZMASTP", nearbyStopAreas[0].StopAreaCode)
+		assert.InDelta(t, 239, nearbyStopAreas[0].DistanceMetres, 1)
+
+		assert.Equal(t, "940GZZMAPIC", nearbyStopAreas[1].StopAreaCode)
+		assert.InDelta(t, 651, nearbyStopAreas[1].DistanceMetres, 1)
+	})
+
+	t.Run(`Given no NaPTAN stop areas have been stored
+When GetStopAreasNearby is called
+Then no stop areas are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanStopsMemory(logger, memory.NewStore(logger, c.Now), "naptan_stops", "naptan_stop_areas", 25*time.Hour)
+
+		// When
+		nearbyStopAreas, err := repository.GetStopAreasNearby(ctx, 53.4780, -2.2400, 1500, 2)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(nearbyStopAreas))
+	})
+}
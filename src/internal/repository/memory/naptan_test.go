@@ -0,0 +1,244 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNaptanMemory_GetStopsInArea(t *testing.T) {
+	t.Run(`Given stops in area have been stored
+When GetStopsInArea is called with a StopAreaCode
+Then AtcoCodes for that StopAreaCode are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"},
+			"940GZZMAVIC": {"9400ZZMAVIC1", "9400ZZMAVIC2", "9400ZZMAVIC3", "9400ZZMAVIC4"},
+		}, map[string]string{}))
+
+		// When
+		atcoCodes, err := repository.GetStopsInArea(ctx, "940GZZMASTP")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}, atcoCodes)
+	})
+
+	t.Run(`Given stops in area have been stored with a time to live
+When GetStopsInArea is called after the time to live has passed
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+		}, map[string]string{}))
+
+		c.Advance(25 * time.Hour)
+
+		// When
+		atcoCodes, err := repository.GetStopsInArea(ctx, "940GZZMASTP")
+
+		// Then
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting stops in area for 940GZZMASTP: redigo: nil returned", err.Error())
+		assert.Nil(t, atcoCodes)
+	})
+}
+
+func TestNaptanMemory_StoreStopsInArea(t *testing.T) {
+	t.Run(`Given stops in area have been stored
+When StoreStopsInArea is called without one of the stored stop areas
+Then the stop area which is no longer present is deleted
+And the other stop areas are replaced`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+			"940GZZMAVIC": {"9400ZZMAVIC1"},
+		}, map[string]string{}))
+
+		// When
+		err := repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2"},
+		}, map[string]string{})
+
+		// Then
+		assert.Nil(t, err)
+
+		atcoCodes, err := repository.GetStopsInArea(ctx, "940GZZMASTP")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"9400ZZMASTP1", "9400ZZMASTP2"}, atcoCodes)
+
+		atcoCodes, err = repository.GetStopsInArea(ctx, "940GZZMAVIC")
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Nil(t, atcoCodes)
+	})
+
+	t.Run(`Given stops in area and the StopAreaCodes of AtcoCodes have been stored
+When StoreStopsInArea is called without one of the stored AtcoCodes
+Then the StopAreaCode of the AtcoCode which is no longer present is deleted
+And the StopAreaCodes of the other AtcoCodes are replaced`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2"},
+		}, map[string]string{
+			"9400ZZMASTP1": "940GZZMASTP",
+			"9400ZZMASTP2": "940GZZMASTP",
+		}))
+
+		// When
+		err := repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+		}, map[string]string{
+			"9400ZZMASTP1": "940GZZMASTP",
+		})
+
+		// Then
+		assert.Nil(t, err)
+
+		stopAreaCode, atcoCodes, err := repository.GetStopAreaForAtcoCode(ctx, "9400ZZMASTP1")
+		assert.Nil(t, err)
+		assert.Equal(t, "940GZZMASTP", stopAreaCode)
+		assert.Equal(t, []string{"9400ZZMASTP1"}, atcoCodes)
+
+		stopAreaCode, atcoCodes, err = repository.GetStopAreaForAtcoCode(ctx, "9400ZZMASTP2")
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "", stopAreaCode)
+		assert.Nil(t, atcoCodes)
+	})
+}
+
+func TestNaptanMemory_GetAllStopsInArea(t *testing.T) {
+	t.Run(`Given stops in area have been stored
+When GetAllStopsInArea is called
+Then the AtcoCodes of every stored stop area are returned by StopAreaCode`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		stored := map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1", "9400ZZMASTP2"},
+			"940GZZMAVIC": {"9400ZZMAVIC1"},
+		}
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, stored, map[string]string{}))
+
+		// When
+		stopsInArea, err := repository.GetAllStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, stored, stopsInArea)
+	})
+
+	t.Run(`Given no stops in area have been stored
+When GetAllStopsInArea is called
+Then an empty map is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		// When
+		stopsInArea, err := repository.GetAllStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{}, stopsInArea)
+	})
+}
+
+func TestNaptanMemory_GetStopAreaForAtcoCode(t *testing.T) {
+	t.Run(`Given stops in area and the StopAreaCodes of AtcoCodes have been stored
+When GetStopAreaForAtcoCode is called with an AtcoCode
+Then the StopAreaCode and the AtcoCodes in the stop area are returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopsInArea(ctx, map[string][]string{
+			"940GZZMAPIC": {"9400ZZMAPIC1", "9400ZZMAPIC2"},
+		}, map[string]string{
+			"9400ZZMAPIC1": "940GZZMAPIC",
+			"9400ZZMAPIC2": "940GZZMAPIC",
+		}))
+
+		// When
+		stopAreaCode, atcoCodes, err := repository.GetStopAreaForAtcoCode(ctx, "9400ZZMAPIC2")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, "940GZZMAPIC", stopAreaCode)
+		assert.Equal(t, []string{"9400ZZMAPIC1", "9400ZZMAPIC2"}, atcoCodes)
+	})
+
+	t.Run(`Given the StopAreaCode of an AtcoCode has not been stored
+When GetStopAreaForAtcoCode is called with the AtcoCode
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewNaptanMemory(logger, memory.NewStore(logger, c.Now), "stops_in_area", "stop_area_of_atco_code", 25*time.Hour)
+
+		// When
+		stopAreaCode, atcoCodes, err := repository.GetStopAreaForAtcoCode(ctx, "9400ZZMAXXX1")
+
+		// Then
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting StopAreaCode for AtcoCode 9400ZZMAXXX1: redigo: nil returned", err.Error())
+		assert.Equal(t, "", stopAreaCode)
+		assert.Nil(t, atcoCodes)
+	})
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkStopDirectoryMemory stores the Metrolink stop directory in memory with the same key and behaviour as the
+// Redis repository
+type MetrolinkStopDirectoryMemory struct {
+	logger     *zap.Logger
+	store      *Store
+	key        string
+	timeToLive time.Duration
+}
+
+func NewMetrolinkStopDirectoryMemory(logger *zap.Logger, store *Store, key string, timeToLive time.Duration) *MetrolinkStopDirectoryMemory {
+	return &MetrolinkStopDirectoryMemory{
+		logger:     logger,
+		store:      store,
+		key:        key,
+		timeToLive: timeToLive,
+	}
+}
+
+func (m *MetrolinkStopDirectoryMemory) GetStopDirectory(ctx context.Context) ([]*domain.MetrolinkStopArea, error) {
+	stopDirectoryJson, ok := m.store.get(m.key)
+	if !ok {
+		return nil, errors.Wrap(redis.ErrNil, "error getting Metrolink stop directory")
+	}
+
+	var stopAreas []*domain.MetrolinkStopArea
+	if err := json.Unmarshal(stopDirectoryJson, &stopAreas); err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling Metrolink stop directory")
+	}
+
+	return stopAreas, nil
+}
+
+func (m *MetrolinkStopDirectoryMemory) StoreStopDirectory(ctx context.Context, stopAreas []*domain.MetrolinkStopArea) error {
+	stopDirectoryJson, err := json.Marshal(stopAreas)
+	if err != nil {
+		return errors.Wrap(err, "error encoding Metrolink stop directory as JSON")
+	}
+
+	m.store.set(m.key, stopDirectoryJson, m.timeToLive)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkStopDirectoryMemory_GetStopDirectory(t *testing.T) {
+	t.Run(`Given a stop directory has been stored
+When GetStopDirectory is called
+Then the stop directory is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkStopDirectoryMemory(logger, memory.NewStore(logger, c.Now), "metrolink_stop_directory", 25*time.Hour)
+
+		platformA := "A"
+
+		stopAreas := []*domain.MetrolinkStopArea{
+			{
+				StopAreaCode: "940GZZMAPIC",
+				Stops: []*domain.MetrolinkStop{
+					{AtcoCode: "9400ZZMAPIC1", Platform: &platformA},
+					{AtcoCode: "9400ZZMAPIC2"},
+				},
+			},
+		}
+
+		assert.Nil(t, repository.StoreStopDirectory(ctx, stopAreas))
+
+		// When
+		stopDirectory, err := repository.GetStopDirectory(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, stopAreas, stopDirectory)
+	})
+
+	t.Run(`Given a stop directory has been stored with a time to live
+When GetStopDirectory is called after the time to live has passed
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkStopDirectoryMemory(logger, memory.NewStore(logger, c.Now), "metrolink_stop_directory", 25*time.Hour)
+
+		assert.Nil(t, repository.StoreStopDirectory(ctx, []*domain.MetrolinkStopArea{
+			{StopAreaCode: "940GZZMAPIC"},
+		}))
+
+		c.Advance(25 * time.Hour)
+
+		// When
+		stopDirectory, err := repository.GetStopDirectory(ctx)
+
+		// Then
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting Metrolink stop directory: redigo: nil returned", err.Error())
+		assert.Nil(t, stopDirectory)
+	})
+}
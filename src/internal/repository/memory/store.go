@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired items are removed from a Store. Items are also treated as missing as soon as they
+// expire, so this only bounds the memory used by items which are never read again, e.g. departures for an old
+// generation.
+const sweepInterval = time.Minute
+
+// Store is a thread-safe key-value store with per-item time to live, shared by the in-memory repositories in the same
+// way as the Redis repositories share a Redis server. Values are stored as encoded bytes so that callers never share
+// data with the store. Time is read from the injected currentTimeFunc, so expiry can be controlled in tests.
+type Store struct {
+	logger          *zap.Logger
+	currentTimeFunc func() time.Time
+	mu              sync.RWMutex
+	items           map[string]*item
+	lastSweep       time.Time
+}
+
+type item struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func NewStore(logger *zap.Logger, currentTimeFunc func() time.Time) *Store {
+	return &Store{
+		logger:          logger,
+		currentTimeFunc: currentTimeFunc,
+		items:           make(map[string]*item),
+		lastSweep:       currentTimeFunc(),
+	}
+}
+
+// get returns the value for a key, or false if the key does not exist or has expired
+func (s *Store) get(key string) ([]byte, bool) {
+	now := s.currentTimeFunc()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	i, ok := s.items[key]
+	if !ok || i.expired(now) {
+		return nil, false
+	}
+
+	return i.value, true
+}
+
+// set stores the value for a key. A time to live of zero or less means the key never expires.
+func (s *Store) set(key string, value []byte, timeToLive time.Duration) {
+	s.setMulti(map[string][]byte{key: value}, timeToLive)
+}
+
+// setMulti stores several values with the same time to live under a single lock, so that readers see all of them or
+// none of them
+func (s *Store) setMulti(values map[string][]byte, timeToLive time.Duration) {
+	s.update(values, nil, timeToLive)
+}
+
+// update stores several values with the same time to live and deletes several keys under a single lock, so that
+// readers see either the whole update or none of it
+func (s *Store) update(values map[string][]byte, deletedKeys []string, timeToLive time.Duration) {
+	now := s.currentTimeFunc()
+
+	var expiresAt time.Time
+	if timeToLive > 0 {
+		expiresAt = now.Add(timeToLive)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, value := range values {
+		s.items[key] = &item{
+			value:     value,
+			expiresAt: expiresAt,
+		}
+	}
+
+	for _, key := range deletedKeys {
+		delete(s.items, key)
+	}
+
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+}
+
+// sweep removes expired items. The caller must hold the write lock.
+func (s *Store) sweep(now time.Time) {
+	removed := 0
+
+	for key, i := range s.items {
+		if i.expired(now) {
+			delete(s.items, key)
+			removed++
+		}
+	}
+
+	s.lastSweep = now
+
+	s.logger.Debug("removed expired items from in-memory store", zap.Int("removed", removed), zap.Int("remaining", len(s.items)))
+}
+
+func (i *item) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && !now.Before(i.expiresAt)
+}
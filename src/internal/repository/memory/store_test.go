@@ -0,0 +1,93 @@
+package memory_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStore(t *testing.T) {
+	t.Run(`Given items stored with a time to live
+When the sweep interval has passed and more items are stored
+Then the expired items are removed from the store`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.DebugLevel)
+		logger := zap.New(zapCore)
+
+		c := givenClock(t)
+
+		store := memory.NewStore(logger, c.Now)
+
+		expiring := memory.NewMetrolinkStopDirectoryMemory(logger, store, "metrolink_stop_directory_a", 15*time.Second)
+		repository := memory.NewMetrolinkStopDirectoryMemory(logger, store, "metrolink_stop_directory_b", 15*time.Second)
+
+		assert.Nil(t, expiring.StoreStopDirectory(ctx, []*domain.MetrolinkStopArea{{StopAreaCode: "940GZZMASTP"}}))
+
+		c.Advance(time.Minute)
+
+		// When
+		err := repository.StoreStopDirectory(ctx, []*domain.MetrolinkStopArea{{StopAreaCode: "940GZZMAVIC"}})
+
+		// Then
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, int64(1), observedLogs.All()[0].ContextMap()["removed"])
+		assert.Equal(t, int64(1), observedLogs.All()[0].ContextMap()["remaining"])
+	})
+
+	t.Run(`Given a store shared by concurrent readers and writers
+When departures are stored and read concurrently
+Then every read sees a complete set of departures`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		store := memory.NewStore(logger, time.Now)
+
+		repository := memory.NewMetrolinkDeparturesRepository(logger, store, "metrolink_departures", time.Minute)
+
+		atcoCodes := []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP3", "9400ZZMASTP4"}
+
+		var departures []*domain.MetrolinkDeparture
+		for _, atcoCode := range atcoCodes {
+			departures = append(departures, &domain.MetrolinkDeparture{AtcoCode: atcoCode})
+		}
+
+		var wg sync.WaitGroup
+
+		// When
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+
+			generation := fmt.Sprintf("%d", i)
+
+			go func() {
+				defer wg.Done()
+				assert.Nil(t, repository.Store(ctx, generation, departures))
+			}()
+
+			go func() {
+				defer wg.Done()
+
+				result, err := repository.GetMulti(ctx, generation, atcoCodes)
+				assert.Nil(t, err)
+
+				// Then
+				assert.Contains(t, []int{0, len(atcoCodes)}, len(result))
+			}()
+		}
+
+		wg.Wait()
+	})
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// MetrolinkDeparturesSystemStatusRepository stores the system status in memory. Get returns redis.ErrNil until a status
+// has been set, as the Redis repository does.
+type MetrolinkDeparturesSystemStatusRepository struct {
+	logger          *zap.Logger
+	store           *Store
+	systemStatusKey string
+}
+
+func NewMetrolinkDeparturesSystemStatusRepository(logger *zap.Logger, store *Store, systemStatusKey string) *MetrolinkDeparturesSystemStatusRepository {
+	return &MetrolinkDeparturesSystemStatusRepository{
+		logger:          logger,
+		store:           store,
+		systemStatusKey: systemStatusKey,
+	}
+}
+
+func (m *MetrolinkDeparturesSystemStatusRepository) Get(ctx context.Context) (*domain.SystemStatus, error) {
+	systemStatusJson, ok := m.store.get(m.systemStatusKey)
+	if !ok {
+		return nil, redis.ErrNil
+	}
+
+	var systemStatus domain.SystemStatus
+	if err := json.Unmarshal(systemStatusJson, &systemStatus); err != nil {
+		return nil, err
+	}
+
+	return &systemStatus, nil
+}
+
+// Set publishes the system status, which makes the generation it holds visible to readers
+func (m *MetrolinkDeparturesSystemStatusRepository) Set(ctx context.Context, systemStatus *domain.SystemStatus) error {
+	systemStatusJson, err := json.Marshal(systemStatus)
+	if err != nil {
+		return errors.Wrap(err, "error encoding system status as JSON")
+	}
+
+	m.store.set(m.systemStatusKey, systemStatusJson, 0)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package memory_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/memory"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkDeparturesSystemStatusRepository(t *testing.T) {
+	t.Run(`Given a system status has been set
+When Get is called long after it was set
+Then the system status is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesSystemStatusRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures_service_status")
+
+		lastUpdated := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+
+		systemStatus := &domain.SystemStatus{
+			Generation:                   givenGeneration(t),
+			LastUpdated:                  lastUpdated,
+			LastAttempt:                  lastUpdated.Add(2 * time.Second),
+			LastSuccess:                  lastUpdated.Add(2 * time.Second),
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}
+
+		assert.Nil(t, repository.Set(ctx, systemStatus))
+
+		c.Advance(24 * time.Hour)
+
+		// When
+		result, err := repository.Get(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, systemStatus, result)
+	})
+
+	t.Run(`Given a system status has never been set
+When Get is called
+Then redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		c := givenClock(t)
+
+		repository := memory.NewMetrolinkDeparturesSystemStatusRepository(logger, memory.NewStore(logger, c.Now), "metrolink_departures_service_status")
+
+		// When
+		result, err := repository.Get(ctx)
+
+		// Then
+		assert.Equal(t, redis.ErrNil, err)
+		assert.Nil(t, result)
+	})
+}
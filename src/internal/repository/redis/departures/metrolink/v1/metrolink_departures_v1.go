@@ -30,7 +30,13 @@ func NewMetrolinkDeparturesRepository(logger *zap.Logger, pool redis2.Pooler, de
 	}
 }
 
-func (m *MetrolinkDeparturesRepository) Get(ctx context.Context, atcoCode string) ([]*domain.MetrolinkDeparture, error) {
+// GetMulti returns the departures for every AtcoCode using a single MGET command on one connection. AtcoCodes without
+// departures are skipped.
+func (m *MetrolinkDeparturesRepository) GetMulti(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkDeparture, error) {
+	if len(atcoCodes) == 0 {
+		return nil, nil
+	}
+
 	conn, err := m.pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
@@ -41,21 +47,38 @@ func (m *MetrolinkDeparturesRepository) Get(ctx context.Context, atcoCode string
 		}
 	}()
 
-	departuresJson, err := redis.Bytes(conn.Do("GET", m.departuresKey(atcoCode)))
+	keys := make([]interface{}, len(atcoCodes))
+	for i, atcoCode := range atcoCodes {
+		keys[i] = m.departuresKey(generation, atcoCode)
+	}
+
+	departuresJsons, err := redis.ByteSlices(conn.Do("MGET", keys...))
 	if err != nil {
 		return nil, err
 	}
 
 	var departures []*domain.MetrolinkDeparture
 
-	if err := json.Unmarshal(departuresJson, &departures); err != nil {
-		return nil, err
+	for i, departuresJson := range departuresJsons {
+		// MGET returns nil for keys which do not exist
+		if departuresJson == nil {
+			continue
+		}
+
+		var departuresForAtcoCode []*domain.MetrolinkDeparture
+		if err := json.Unmarshal(departuresJson, &departuresForAtcoCode); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling departures for AtcoCode %s", atcoCodes[i])
+		}
+
+		departures = append(departures, departuresForAtcoCode...)
 	}
 
 	return departures, nil
 }
 
-func (m *MetrolinkDeparturesRepository) Store(ctx context.Context, departures []*domain.MetrolinkDeparture) error {
+// Store writes departures under a new generation. Keys for a generation are never overwritten by a later load, so the
+// departures only become visible to readers once the generation is published in the system status.
+func (m *MetrolinkDeparturesRepository) Store(ctx context.Context, generation string, departures []*domain.MetrolinkDeparture) error {
 	groupedDeparturesByAtcoCode := m.groupDeparturesByAtcoCode(departures)
 
 	conn, err := m.pool.GetContext(ctx)
@@ -68,7 +91,7 @@ func (m *MetrolinkDeparturesRepository) Store(ctx context.Context, departures []
 		}
 	}()
 
-	chReceive, chErr := m.send(conn, groupedDeparturesByAtcoCode)
+	chReceive, chErr := m.send(conn, generation, groupedDeparturesByAtcoCode)
 	if err := m.receive(conn, chReceive); err != nil {
 		return errors.Wrap(err, "error receiving on Redis connection")
 	}
@@ -90,7 +113,7 @@ func (*MetrolinkDeparturesRepository) groupDeparturesByAtcoCode(departures []*do
 	return groupedDepartures
 }
 
-func (m *MetrolinkDeparturesRepository) send(conn redis.Conn, groupedDeparturesByAtcoCode map[string][]*domain.MetrolinkDeparture) (chan int, chan error) {
+func (m *MetrolinkDeparturesRepository) send(conn redis.Conn, generation string, groupedDeparturesByAtcoCode map[string][]*domain.MetrolinkDeparture) (chan int, chan error) {
 	chReceive := make(chan int)
 	chErr := make(chan error, 1)
 
@@ -113,7 +136,7 @@ func (m *MetrolinkDeparturesRepository) send(conn redis.Conn, groupedDeparturesB
 				continue
 			}
 
-			if err := conn.Send("SET", m.departuresKey(atcoCode), buf.String(), "PX", m.departuresTimeToLive.Milliseconds()); err != nil {
+			if err := conn.Send("SET", m.departuresKey(generation, atcoCode), buf.String(), "PX", m.departuresTimeToLive.Milliseconds()); err != nil {
 				errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for AtcoCode %s", atcoCode))
 				continue
 			}
@@ -145,6 +168,12 @@ func (*MetrolinkDeparturesRepository) receive(conn redis.Conn, chReceive <-chan
 	return errs
 }
 
-func (m *MetrolinkDeparturesRepository) departuresKey(atcoCode string) string {
-	return fmt.Sprintf("%s_%s", m.departuresKeyPrefix, atcoCode)
+// departuresKey returns the key for an AtcoCode's departures in a generation. Departures stored before generations were
+// introduced have no generation in their key.
+func (m *MetrolinkDeparturesRepository) departuresKey(generation string, atcoCode string) string {
+	if generation == "" {
+		return fmt.Sprintf("%s_%s", m.departuresKeyPrefix, atcoCode)
+	}
+
+	return fmt.Sprintf("%s_%s_%s", m.departuresKeyPrefix, generation, atcoCode)
 }
@@ -4,17 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/departures/metrolink/v1"
 	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -26,7 +27,13 @@ func mockLogger(t *testing.T) *zap.Logger {
 	return zap.New(zapCore)
 }
 
-func givenDeparturesForAtcoCode9400ZZMASTP1(t *testing.T) []*domain.MetrolinkDeparture {
+func givenGeneration(t testing.TB) string {
+	t.Helper()
+
+	return "1617745039000000000"
+}
+
+func givenDeparturesForAtcoCode9400ZZMASTP1(t testing.TB) []*domain.MetrolinkDeparture {
 	t.Helper()
 
 	return []*domain.MetrolinkDeparture{
@@ -167,10 +174,10 @@ func givenDeparturesToStore(t *testing.T) []*domain.MetrolinkDeparture {
 	}
 }
 
-func TestMetrolinkDeparturesRepository_Get(t *testing.T) {
+func TestMetrolinkDeparturesRepository_GetMulti(t *testing.T) {
 	t.Run(`Given a populated Redis departures repository
-When Get is called with an AtcoCode
-Then departures for that AtcoCode are returned`, func(t *testing.T) {
+When GetMulti is called with AtcoCodes
+Then departures for those AtcoCodes are returned from a single MGET command`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -179,42 +186,43 @@ Then departures for that AtcoCode are returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		departuresKeyPrefix := "departures"
-
-		departuresTimeToLive := time.Second * 15
-
-		conn := mock_redis.NewMockConn(ctrl)
-
-		atcoCode := "9400ZZMASTP1"
-
-		expDepartures := givenDeparturesForAtcoCode9400ZZMASTP1(t)
+		var departuresFor9400ZZMASTP1 bytes.Buffer
+		if err := json.NewEncoder(&departuresFor9400ZZMASTP1).Encode(givenDeparturesForAtcoCode9400ZZMASTP1(t)); err != nil {
+			t.Fatal(err)
+		}
 
-		var departuresFromRedis bytes.Buffer
-		if err := json.NewEncoder(&departuresFromRedis).Encode(expDepartures); err != nil {
+		var departuresFor9400ZZMASTP2 bytes.Buffer
+		if err := json.NewEncoder(&departuresFor9400ZZMASTP2).Encode(givenDeparturesForAtcoCode9400ZZMASTP2(t)); err != nil {
 			t.Fatal(err)
 		}
 
+		conn := mock_redis.NewMockConn(ctrl)
+
 		gomock.InOrder(
-			conn.EXPECT().Do("GET", fmt.Sprintf("%s_%s", "departures", atcoCode)).Return(departuresFromRedis.Bytes(), nil),
+			conn.EXPECT().Do("MGET", "departures_1617745039000000000_9400ZZMASTP", "departures_1617745039000000000_9400ZZMASTP1", "departures_1617745039000000000_9400ZZMASTP2").Return([]interface{}{
+				nil,
+				departuresFor9400ZZMASTP1.Bytes(),
+				departuresFor9400ZZMASTP2.Bytes(),
+			}, nil),
 			conn.EXPECT().Close().Return(nil),
 		)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
+		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, "departures", time.Second*15)
 
 		// When
-		departures, err := metrolinkDeparturesRepository.Get(ctx, atcoCode)
+		departures, err := metrolinkDeparturesRepository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP", "9400ZZMASTP1", "9400ZZMASTP2"})
 
 		// Then
 		assert.Nil(t, err)
-		assert.Equal(t, expDepartures, departures)
+		assert.Equal(t, append(givenDeparturesForAtcoCode9400ZZMASTP1(t), givenDeparturesForAtcoCode9400ZZMASTP2(t)...), departures)
 	})
 
-	t.Run(`Given an error occurs getting a Redis connection from the connection pool
-When Get is called
-Then an error is returned`, func(t *testing.T) {
+	t.Run(`Given no AtcoCodes
+When GetMulti is called
+Then Redis is not queried`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -223,29 +231,20 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		departuresKeyPrefix := "departures"
-
-		departuresTimeToLive := time.Second * 15
-
-		atcoCode := "9400ZZMASTP1"
-
 		pool := mock_redis.NewMockPooler(ctrl)
-		poolErr := errors.New("FUBAR")
-		pool.EXPECT().GetContext(ctx).Return(nil, poolErr)
 
-		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
+		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, "departures", time.Second*15)
 
 		// When
-		departures, err := metrolinkDeparturesRepository.Get(ctx, atcoCode)
+		departures, err := metrolinkDeparturesRepository.GetMulti(ctx, givenGeneration(t), nil)
 
 		// Then
+		assert.Nil(t, err)
 		assert.Nil(t, departures)
-		assert.NotNil(t, err)
-		assert.Equal(t, poolErr, err)
 	})
 
-	t.Run(`Given an error occurs getting data from the Redis repository
-When Get is called
+	t.Run(`Given an error occurs executing the MGET command
+When GetMulti is called
 Then an error is returned`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
@@ -255,125 +254,112 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		departuresKeyPrefix := "departures"
-
-		atcoCode := "9400ZZMASTP1"
-
-		departuresTimeToLive := time.Second * 15
-
 		conn := mock_redis.NewMockConn(ctrl)
 		connErr := errors.New("FUBAR")
 
 		gomock.InOrder(
-			conn.EXPECT().Do("GET", fmt.Sprintf("%s_%s", "departures", atcoCode)).Return(nil, connErr),
+			conn.EXPECT().Do("MGET", "departures_1617745039000000000_9400ZZMASTP1").Return(nil, connErr),
 			conn.EXPECT().Close().Return(nil),
 		)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
+		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, "departures", time.Second*15)
 
 		// When
-		departures, err := metrolinkDeparturesRepository.Get(ctx, atcoCode)
+		departures, err := metrolinkDeparturesRepository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
 
 		// Then
 		assert.Nil(t, departures)
-		assert.NotNil(t, err)
 		assert.Equal(t, connErr, err)
 	})
 
-	t.Run(`Given an error occurs returning the Redis connection to the pool
-When Get is called
-Then an error is logged`, func(t *testing.T) {
+	t.Run(`Given Redis returns invalid data for an AtcoCode
+When GetMulti is called
+Then an error is returned`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
 		ctx := context.Background()
 
-		zapCore, observedLogs := observer.New(zapcore.DebugLevel)
-		logger := zap.New(zapCore)
-
-		departuresKeyPrefix := "departures"
-
-		departuresTimeToLive := time.Second * 15
-
-		atcoCode := "9400ZZMASTP1"
+		logger := mockLogger(t)
 
 		conn := mock_redis.NewMockConn(ctrl)
-		connCloseErr := errors.New("FUBAR")
-
-		expDepartures := givenDeparturesForAtcoCode9400ZZMASTP1(t)
-
-		var departuresFromRedis bytes.Buffer
-		if err := json.NewEncoder(&departuresFromRedis).Encode(expDepartures); err != nil {
-			t.Fatal(err)
-		}
 
 		gomock.InOrder(
-			conn.EXPECT().Do("GET", fmt.Sprintf("%s_%s", "departures", atcoCode)).Return(departuresFromRedis.Bytes(), nil),
-			conn.EXPECT().Close().Return(connCloseErr),
+			conn.EXPECT().Do("MGET", "departures_1617745039000000000_9400ZZMASTP1").Return([]interface{}{[]byte("x")}, nil),
+			conn.EXPECT().Close().Return(nil),
 		)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
+		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, "departures", time.Second*15)
 
 		// When
-		departures, err := metrolinkDeparturesRepository.Get(ctx, atcoCode)
+		departures, err := metrolinkDeparturesRepository.GetMulti(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
 
 		// Then
-		assert.NotNil(t, departures)
-		assert.Nil(t, err)
+		assert.Nil(t, departures)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "error unmarshalling departures for AtcoCode 9400ZZMASTP1")
+	})
+}
 
-		assert.Equal(t, 1, observedLogs.Len())
+// benchmarkAtcoCodes are the platforms at a large interchange such as Piccadilly
+var benchmarkAtcoCodes = []string{"9400ZZMAPIC1", "9400ZZMAPIC2", "9400ZZMAPIC3", "9400ZZMAPIC4", "9400ZZMAPGD1", "9400ZZMAPGD2"}
 
-		loggedItems := observedLogs.TakeAll()
-		assert.Equal(t, zapcore.ErrorLevel, loggedItems[0].Level)
-		assert.Equal(t, "error returning Redis connection to pool", loggedItems[0].Message)
-		assert.Equal(t, "error", loggedItems[0].Context[0].Key)
-		assert.Equal(t, connCloseErr, loggedItems[0].Context[0].Interface)
-	})
+func givenBenchmarkDeparturesJson(b *testing.B) []byte {
+	b.Helper()
 
-	t.Run(`Given Redis returns invalid data
-When Get is called
-Then an error is logged`, func(t *testing.T) {
-		// Given
-		ctrl := gomock.NewController(t)
-		defer ctrl.Finish()
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(givenDeparturesForAtcoCode9400ZZMASTP1(b)); err != nil {
+		b.Fatal(err)
+	}
 
-		ctx := context.Background()
+	return buf.Bytes()
+}
 
-		logger := mockLogger(t)
+// BenchmarkMetrolinkDeparturesRepository_GetMulti fetches departures for every AtcoCode with a single MGET command
+func BenchmarkMetrolinkDeparturesRepository_GetMulti(b *testing.B) {
+	ctrl := gomock.NewController(b)
+	defer ctrl.Finish()
 
-		departuresKeyPrefix := "departures"
+	ctx := context.Background()
 
-		departuresTimeToLive := time.Second * 15
+	departuresJson := givenBenchmarkDeparturesJson(b)
 
-		atcoCode := "9400ZZMASTP1"
+	mgetReply := make([]interface{}, len(benchmarkAtcoCodes))
+	for i := range mgetReply {
+		mgetReply[i] = departuresJson
+	}
+
+	var connections int64
 
+	pool := mock_redis.NewMockPooler(ctrl)
+	pool.EXPECT().GetContext(ctx).DoAndReturn(func(ctx context.Context) (redis.Conn, error) {
 		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Do("MGET", gomock.Any()).Return(mgetReply, nil)
+		conn.EXPECT().Close().Return(nil)
 
-		gomock.InOrder(
-			conn.EXPECT().Do("GET", fmt.Sprintf("%s_%s", "departures", atcoCode)).Return([]byte("x"), nil),
-			conn.EXPECT().Close().Return(nil),
-		)
+		atomic.AddInt64(&connections, 1)
 
-		pool := mock_redis.NewMockPooler(ctrl)
-		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+		return conn, nil
+	}).AnyTimes()
 
-		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
+	metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(zap.NewNop(), pool, "departures", time.Second*15)
 
-		// When
-		departures, err := metrolinkDeparturesRepository.Get(ctx, atcoCode)
+	b.ResetTimer()
 
-		// Then
-		assert.Nil(t, departures)
-		assert.NotNil(t, err)
-		assert.EqualError(t, err, "invalid character 'x' looking for beginning of value")
-	})
+	for i := 0; i < b.N; i++ {
+		if _, err := metrolinkDeparturesRepository.GetMulti(ctx, givenGeneration(b), benchmarkAtcoCodes); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.ReportMetric(float64(connections)/float64(b.N), "conns/op")
 }
 
 func TestMetrolinkDeparturesRepository_Store(t *testing.T) {
@@ -406,8 +392,8 @@ Then the departures are stored in Redis grouped by AtcoCode`, func(t *testing.T)
 			t.Fatal(err)
 		}
 
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
 		conn.EXPECT().Flush().Return(nil)
 		conn.EXPECT().Receive().Return("OK", nil).Times(2)
 		conn.EXPECT().Close().Return(nil)
@@ -418,7 +404,7 @@ Then the departures are stored in Redis grouped by AtcoCode`, func(t *testing.T)
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.Nil(t, err)
@@ -448,7 +434,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.NotNil(t, err)
@@ -485,8 +471,8 @@ Then an error is returned`, func(t *testing.T) {
 		}
 
 		connErr := errors.New("FUBAR")
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(connErr)
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(connErr)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
 		conn.EXPECT().Flush().Return(nil)
 		conn.EXPECT().Receive().Return("OK", nil).Times(1)
 		conn.EXPECT().Close().Return(nil)
@@ -497,7 +483,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.NotNil(t, err)
@@ -533,8 +519,8 @@ Then an error is returned`, func(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
 		connErr := errors.New("FUBAR")
 		conn.EXPECT().Flush().Return(connErr)
 		conn.EXPECT().Receive().Return("OK", nil).Times(2)
@@ -546,7 +532,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.NotNil(t, err)
@@ -582,8 +568,8 @@ Then an error is returned`, func(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
 		conn.EXPECT().Flush().Return(nil)
 		conn.EXPECT().Receive().Return("OK", nil)
 		connErr := errors.New("FUBAR")
@@ -596,7 +582,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.NotNil(t, err)
@@ -633,8 +619,8 @@ Then an error message is logged`, func(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "departures_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP1", departuresFor9400ZZMASTP1.String(), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "departures_1617745039000000000_9400ZZMASTP2", departuresFor9400ZZMASTP2.String(), "PX", int64(15000)).Return(nil)
 		conn.EXPECT().Flush().Return(nil)
 		conn.EXPECT().Receive().Return("OK", nil).Times(2)
 		connErr := errors.New("FUBAR")
@@ -646,7 +632,7 @@ Then an error message is logged`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesRepository(logger, pool, departuresKeyPrefix, departuresTimeToLive)
 
 		// When
-		err := metrolinkDeparturesRepository.Store(ctx, departuresToStore)
+		err := metrolinkDeparturesRepository.Store(ctx, givenGeneration(t), departuresToStore)
 
 		// Then
 		assert.Nil(t, err)
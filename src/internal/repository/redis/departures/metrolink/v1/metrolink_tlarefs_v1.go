@@ -0,0 +1,92 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	redis2 "github.com/Marchie/tf-experiment/lambda/pkg/redis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkTlarefsRepository stores the StopAreaCode of each TLAREF in the departures feed under its own key, so that
+// TLAREFs are refreshed by every load and only expire once they have been missing from the feed for the time to live
+type MetrolinkTlarefsRepository struct {
+	logger            *zap.Logger
+	pool              redis2.Pooler
+	tlarefsKeyPrefix  string
+	tlarefsTimeToLive time.Duration
+}
+
+func NewMetrolinkTlarefsRepository(logger *zap.Logger, pool redis2.Pooler, tlarefsKeyPrefix string, tlarefsTimeToLive time.Duration) *MetrolinkTlarefsRepository {
+	return &MetrolinkTlarefsRepository{
+		logger:            logger,
+		pool:              pool,
+		tlarefsKeyPrefix:  tlarefsKeyPrefix,
+		tlarefsTimeToLive: tlarefsTimeToLive,
+	}
+}
+
+func (m *MetrolinkTlarefsRepository) GetStopAreaCodeForTlaref(ctx context.Context, tlaref string) (string, error) {
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	stopAreaCode, err := redis.String(conn.Do("GET", m.tlarefKey(tlaref)))
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting StopAreaCode for TLAREF %s", tlaref)
+	}
+
+	return stopAreaCode, nil
+}
+
+// StoreTlarefs sets every TLAREF in a single transaction
+func (m *MetrolinkTlarefsRepository) StoreTlarefs(ctx context.Context, stopAreaCodesByTlaref map[string]string) error {
+	if len(stopAreaCodesByTlaref) == 0 {
+		return nil
+	}
+
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	var errs error
+
+	if err := conn.Send("MULTI"); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	for tlaref, stopAreaCode := range stopAreaCodesByTlaref {
+		if err := conn.Send("SET", m.tlarefKey(tlaref), stopAreaCode, "PX", m.tlarefsTimeToLive.Milliseconds()); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for TLAREF %s", tlaref))
+		}
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "error sending Redis commands to store TLAREFs")
+	}
+
+	if _, err := conn.Do("EXEC"); err != nil {
+		return errors.Wrap(err, "error storing TLAREFs")
+	}
+
+	return nil
+}
+
+func (m *MetrolinkTlarefsRepository) tlarefKey(tlaref string) string {
+	return fmt.Sprintf("%s_%s", m.tlarefsKeyPrefix, tlaref)
+}
@@ -0,0 +1,150 @@
+package v1_test
+
+import (
+	"context"
+	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/departures/metrolink/v1"
+	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkTlarefsRepository_GetStopAreaCodeForTlaref(t *testing.T) {
+	t.Run(`Given a TLAREF is stored in Redis
+When GetStopAreaCodeForTlaref is called
+Then the StopAreaCode of the TLAREF is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "metrolink_tlarefs_PIC").Return([]byte("940GZZMAPIC"), nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		repository := v1.NewMetrolinkTlarefsRepository(mockLogger(t), pool, "metrolink_tlarefs", 24*time.Hour)
+
+		// When
+		stopAreaCode, err := repository.GetStopAreaCodeForTlaref(ctx, "PIC")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, "940GZZMAPIC", stopAreaCode)
+	})
+
+	t.Run(`Given a TLAREF is not stored in Redis
+When GetStopAreaCodeForTlaref is called
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "metrolink_tlarefs_XYZ").Return(nil, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		repository := v1.NewMetrolinkTlarefsRepository(mockLogger(t), pool, "metrolink_tlarefs", 24*time.Hour)
+
+		// When
+		stopAreaCode, err := repository.GetStopAreaCodeForTlaref(ctx, "XYZ")
+
+		// Then
+		assert.Equal(t, "", stopAreaCode)
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting StopAreaCode for TLAREF XYZ: redigo: nil returned", err.Error())
+	})
+}
+
+func TestMetrolinkTlarefsRepository_StoreTlarefs(t *testing.T) {
+	t.Run(`Given TLAREFs and their StopAreaCodes
+When StoreTlarefs is called
+Then each TLAREF is set with the time to live in a single transaction`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Send("MULTI").Return(nil),
+			conn.EXPECT().Send("SET", "metrolink_tlarefs_PIC", "940GZZMAPIC", "PX", int64(86400000)).Return(nil),
+			conn.EXPECT().Do("EXEC").Return([]interface{}{"OK"}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		repository := v1.NewMetrolinkTlarefsRepository(mockLogger(t), pool, "metrolink_tlarefs", 24*time.Hour)
+
+		// When
+		err := repository.StoreTlarefs(ctx, map[string]string{"PIC": "940GZZMAPIC"})
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given no TLAREFs
+When StoreTlarefs is called
+Then nothing is sent to Redis`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		repository := v1.NewMetrolinkTlarefsRepository(mockLogger(t), mock_redis.NewMockPooler(ctrl), "metrolink_tlarefs", 24*time.Hour)
+
+		// When
+		err := repository.StoreTlarefs(ctx, map[string]string{})
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given the Redis transaction fails
+When StoreTlarefs is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return(nil, errors.New("FUBAR"))
+		conn.EXPECT().Close().Return(nil)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		repository := v1.NewMetrolinkTlarefsRepository(mockLogger(t), pool, "metrolink_tlarefs", 24*time.Hour)
+
+		// When
+		err := repository.StoreTlarefs(ctx, map[string]string{"PIC": "940GZZMAPIC"})
+
+		// Then
+		assert.EqualError(t, err, "error storing TLAREFs: FUBAR")
+	})
+}
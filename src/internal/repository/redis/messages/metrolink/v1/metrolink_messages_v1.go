@@ -0,0 +1,219 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	redis2 "github.com/Marchie/tf-experiment/lambda/pkg/redis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type MetrolinkMessagesRepository struct {
+	logger             *zap.Logger
+	pool               redis2.Pooler
+	messagesKeyPrefix  string
+	messagesTimeToLive time.Duration
+}
+
+func NewMetrolinkMessagesRepository(logger *zap.Logger, pool redis2.Pooler, messagesKeyPrefix string, messagesTimeToLive time.Duration) *MetrolinkMessagesRepository {
+	return &MetrolinkMessagesRepository{
+		logger:             logger,
+		pool:               pool,
+		messagesKeyPrefix:  messagesKeyPrefix,
+		messagesTimeToLive: messagesTimeToLive,
+	}
+}
+
+func (m *MetrolinkMessagesRepository) Get(ctx context.Context, generation string, atcoCodes []string) ([]*domain.MetrolinkMessage, error) {
+	if len(atcoCodes) == 0 {
+		return nil, nil
+	}
+
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	keys := make([]interface{}, len(atcoCodes))
+	for i, atcoCode := range atcoCodes {
+		keys[i] = m.messagesKey(generation, atcoCode)
+	}
+
+	messagesJsons, err := redis.ByteSlices(conn.Do("MGET", keys...))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*domain.MetrolinkMessage
+
+	for i, messagesJson := range messagesJsons {
+		// MGET returns nil for keys which do not exist
+		if messagesJson == nil {
+			continue
+		}
+
+		var messagesForAtcoCode []*domain.MetrolinkMessage
+		if err := json.Unmarshal(messagesJson, &messagesForAtcoCode); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling messages for AtcoCode %s", atcoCodes[i])
+		}
+
+		messages = append(messages, messagesForAtcoCode...)
+	}
+
+	return messages, nil
+}
+
+func (m *MetrolinkMessagesRepository) GetForLine(ctx context.Context, generation string, line string) ([]*domain.MetrolinkMessage, error) {
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	messagesJson, err := redis.Bytes(conn.Do("GET", m.lineMessagesKey(generation, line)))
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*domain.MetrolinkMessage
+
+	if err := json.Unmarshal(messagesJson, &messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// Store writes messages under a new generation, which only becomes visible to readers once it is published in the
+// system status
+func (m *MetrolinkMessagesRepository) Store(ctx context.Context, generation string, messages []*domain.MetrolinkMessage) error {
+	groupedMessagesByKey := m.groupMessagesByKey(generation, messages)
+
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	chReceive, chErr := m.send(conn, groupedMessagesByKey)
+	if err := m.receive(conn, chReceive); err != nil {
+		return errors.Wrap(err, "error receiving on Redis connection")
+	}
+
+	if err := <-chErr; err != nil {
+		return errors.Wrap(err, "error sending/flushing Redis connection")
+	}
+
+	return nil
+}
+
+// groupMessagesByKey groups messages by both AtcoCode and Line, so that messages can be retrieved for a stop or for a
+// whole line with a single Redis command
+func (m *MetrolinkMessagesRepository) groupMessagesByKey(generation string, messages []*domain.MetrolinkMessage) map[string][]*domain.MetrolinkMessage {
+	groupedMessages := make(map[string][]*domain.MetrolinkMessage)
+
+	for _, message := range messages {
+		atcoCodeKey := m.messagesKey(generation, message.AtcoCode)
+		groupedMessages[atcoCodeKey] = append(groupedMessages[atcoCodeKey], message)
+
+		if message.Line != "" {
+			lineKey := m.lineMessagesKey(generation, message.Line)
+			groupedMessages[lineKey] = append(groupedMessages[lineKey], message)
+		}
+	}
+
+	return groupedMessages
+}
+
+func (m *MetrolinkMessagesRepository) send(conn redis.Conn, groupedMessagesByKey map[string][]*domain.MetrolinkMessage) (chan int, chan error) {
+	chReceive := make(chan int)
+	chErr := make(chan error, 1)
+
+	go func() {
+		defer close(chErr)
+		defer close(chReceive)
+
+		var errs error
+
+		defer func() {
+			chErr <- errs
+		}()
+
+		i := 0
+
+		for key, messages := range groupedMessagesByKey {
+			var buf bytes.Buffer
+			if err := json.NewEncoder(&buf).Encode(messages); err != nil {
+				errs = multierror.Append(errs, errors.Wrapf(err, "error encoding messages as JSON for key %s", key))
+				continue
+			}
+
+			if err := conn.Send("SET", key, buf.String(), "PX", m.messagesTimeToLive.Milliseconds()); err != nil {
+				errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for key %s", key))
+				continue
+			}
+
+			i++
+		}
+
+		if err := conn.Flush(); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "error flushing Redis connection"))
+		}
+
+		chReceive <- i
+	}()
+
+	return chReceive, chErr
+}
+
+func (*MetrolinkMessagesRepository) receive(conn redis.Conn, chReceive <-chan int) error {
+	var errs error
+
+	limit := <-chReceive
+
+	for i := 0; i < limit; i++ {
+		if _, err := conn.Receive(); err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (m *MetrolinkMessagesRepository) messagesKey(generation string, atcoCode string) string {
+	return fmt.Sprintf("%s_%s", m.generationKeyPrefix(generation), atcoCode)
+}
+
+func (m *MetrolinkMessagesRepository) lineMessagesKey(generation string, line string) string {
+	return fmt.Sprintf("%s_line_%s", m.generationKeyPrefix(generation), strings.ToLower(line))
+}
+
+// generationKeyPrefix returns the key prefix for messages in a generation. Messages stored before generations were
+// introduced have no generation in their keys.
+func (m *MetrolinkMessagesRepository) generationKeyPrefix(generation string) string {
+	if generation == "" {
+		return m.messagesKeyPrefix
+	}
+
+	return fmt.Sprintf("%s_%s", m.messagesKeyPrefix, generation)
+}
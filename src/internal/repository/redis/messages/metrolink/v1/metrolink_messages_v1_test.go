@@ -0,0 +1,347 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/messages/metrolink/v1"
+	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func mockLogger(t *testing.T) *zap.Logger {
+	t.Helper()
+
+	zapCore, _ := observer.New(zapcore.DebugLevel)
+	return zap.New(zapCore)
+}
+
+func givenGeneration(t *testing.T) string {
+	t.Helper()
+
+	return "1617745039000000000"
+}
+
+func givenMessagesForAtcoCode9400ZZMASTP1(t *testing.T) []*domain.MetrolinkMessage {
+	t.Helper()
+
+	return []*domain.MetrolinkMessage{
+		{
+			AtcoCode:    "9400ZZMASTP1",
+			Line:        "Eccles",
+			Message:     "PLANNED IMPROVEMENT WORKS - No service between Eccles and MediaCityUK.",
+			LastUpdated: time.Date(2021, time.March, 29, 11, 3, 40, 0, time.UTC),
+		},
+	}
+}
+
+func givenMessagesForAtcoCode9400ZZMASTP4(t *testing.T) []*domain.MetrolinkMessage {
+	t.Helper()
+
+	return []*domain.MetrolinkMessage{
+		{
+			AtcoCode:    "9400ZZMASTP4",
+			Line:        "Altrincham",
+			Message:     "Please see printed posters for first and last tram times.",
+			LastUpdated: time.Date(2021, time.March, 29, 11, 3, 40, 0, time.UTC),
+		},
+	}
+}
+
+func encodeJson(t *testing.T, v interface{}) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestMetrolinkMessagesRepository_Get(t *testing.T) {
+	t.Run(`Given a populated Redis messages repository
+When Get is called with AtcoCodes
+Then messages for those AtcoCodes are returned with a single command`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("MGET", "messages_1617745039000000000_9400ZZMASTP1", "messages_1617745039000000000_9400ZZMASTP2", "messages_1617745039000000000_9400ZZMASTP4").Return([]interface{}{
+				encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t)),
+				nil,
+				encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP4(t)),
+			}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1", "9400ZZMASTP2", "9400ZZMASTP4"})
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, append(givenMessagesForAtcoCode9400ZZMASTP1(t), givenMessagesForAtcoCode9400ZZMASTP4(t)...), messages)
+	})
+
+	t.Run(`Given no AtcoCodes
+When Get is called
+Then Redis is not queried`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.Get(ctx, givenGeneration(t), nil)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Nil(t, messages)
+	})
+
+	t.Run(`Given an error occurs getting data from the Redis repository
+When Get is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		connErr := errors.New("FUBAR")
+
+		gomock.InOrder(
+			conn.EXPECT().Do("MGET", "messages_1617745039000000000_9400ZZMASTP1").Return(nil, connErr),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
+
+		// Then
+		assert.Nil(t, messages)
+		assert.Equal(t, connErr, err)
+	})
+
+	t.Run(`Given Redis returns invalid data
+When Get is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("MGET", "messages_1617745039000000000_9400ZZMASTP1").Return([]interface{}{[]byte("x")}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.Get(ctx, givenGeneration(t), []string{"9400ZZMASTP1"})
+
+		// Then
+		assert.Nil(t, messages)
+		assert.EqualError(t, err, "error unmarshalling messages for AtcoCode 9400ZZMASTP1: invalid character 'x' looking for beginning of value")
+	})
+}
+
+func TestMetrolinkMessagesRepository_GetForLine(t *testing.T) {
+	t.Run(`Given a populated Redis messages repository
+When GetForLine is called with a Line
+Then messages for that Line are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "messages_1617745039000000000_line_eccles").Return(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t)), nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.GetForLine(ctx, givenGeneration(t), "Eccles")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, givenMessagesForAtcoCode9400ZZMASTP1(t), messages)
+	})
+
+	t.Run(`Given there are no messages for a Line
+When GetForLine is called
+Then redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "messages_1617745039000000000_line_bury").Return(nil, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		messages, err := metrolinkMessagesRepository.GetForLine(ctx, givenGeneration(t), "Bury")
+
+		// Then
+		assert.Nil(t, messages)
+		assert.Equal(t, redis.ErrNil, err)
+	})
+}
+
+func TestMetrolinkMessagesRepository_Store(t *testing.T) {
+	t.Run(`Given a slice of Metrolink messages
+When Store is called
+Then the messages are stored in Redis grouped by AtcoCode and by Line`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		messagesToStore := append(givenMessagesForAtcoCode9400ZZMASTP1(t), givenMessagesForAtcoCode9400ZZMASTP4(t)...)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_9400ZZMASTP1", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_line_eccles", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_9400ZZMASTP4", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP4(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_line_altrincham", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP4(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Flush().Return(nil)
+		conn.EXPECT().Receive().Return("OK", nil).Times(4)
+		conn.EXPECT().Close().Return(nil)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		err := metrolinkMessagesRepository.Store(ctx, givenGeneration(t), messagesToStore)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given an error occurs getting a Redis connection from the connection pool
+When Store is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		poolErr := errors.New("FUBAR")
+		pool.EXPECT().GetContext(ctx).Return(nil, poolErr)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		err := metrolinkMessagesRepository.Store(ctx, givenGeneration(t), givenMessagesForAtcoCode9400ZZMASTP1(t))
+
+		// Then
+		assert.Equal(t, poolErr, err)
+	})
+
+	t.Run(`Given an error occurs receiving the response from the Redis connection
+When Store is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_9400ZZMASTP1", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Send("SET", "messages_1617745039000000000_line_eccles", string(encodeJson(t, givenMessagesForAtcoCode9400ZZMASTP1(t))), "PX", int64(15000)).Return(nil)
+		conn.EXPECT().Flush().Return(nil)
+		conn.EXPECT().Receive().Return("OK", nil)
+		conn.EXPECT().Receive().Return(nil, errors.New("FUBAR"))
+		conn.EXPECT().Close().Return(nil)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkMessagesRepository := v1.NewMetrolinkMessagesRepository(logger, pool, "messages", time.Second*15)
+
+		// When
+		err := metrolinkMessagesRepository.Store(ctx, givenGeneration(t), givenMessagesForAtcoCode9400ZZMASTP1(t))
+
+		// Then
+		assert.EqualError(t, err, "error receiving on Redis connection: 1 error occurred:\n\t* FUBAR\n\n")
+	})
+}
@@ -0,0 +1,50 @@
+package naptan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// GetStopAreaForAtcoCode returns the StopAreaCode of the stop area an AtcoCode belongs to, and the AtcoCodes in that
+// stop area. redis.ErrNil is returned when the AtcoCode is not in a stop area.
+func (n *NaptanRedis) GetStopAreaForAtcoCode(ctx context.Context, atcoCode string) (string, []string, error) {
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	stopAreaCode, err := redis.String(conn.Do("GET", n.atcoCodeKey(atcoCode)))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "error getting StopAreaCode for AtcoCode %s", atcoCode)
+	}
+
+	stopsInAreaJson, err := redis.Bytes(conn.Do("GET", n.key(stopAreaCode)))
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "error getting stops in area for %s", stopAreaCode)
+	}
+
+	var atcoCodes []string
+	if err := json.Unmarshal(stopsInAreaJson, &atcoCodes); err != nil {
+		return "", nil, errors.Wrapf(err, "error unmarshalling data for %s", stopAreaCode)
+	}
+
+	return stopAreaCode, atcoCodes, nil
+}
+
+func (n *NaptanRedis) atcoCodeKey(atcoCode string) string {
+	return fmt.Sprintf("%s_%s", n.atcoCodeKeyPrefix, atcoCode)
+}
+
+// atcoCodeIndexKey is the key of the set of AtcoCodes whose StopAreaCodes are stored
+func (n *NaptanRedis) atcoCodeIndexKey() string {
+	return fmt.Sprintf("%s_index", n.atcoCodeKeyPrefix)
+}
@@ -0,0 +1,77 @@
+package naptan_test
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
+	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNaptanRedis_GetStopAreaForAtcoCode(t *testing.T) {
+	t.Run(`Given the StopAreaCode of an AtcoCode and the stops in that area are stored in Redis
+When GetStopAreaForAtcoCode is called
+Then the StopAreaCode and the AtcoCodes in the stop area are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "stop_area_of_atco_code_9400ZZMAPIC1").Return([]byte("940GZZMAPIC"), nil),
+			conn.EXPECT().Do("GET", "stopsinarea_940GZZMAPIC").Return([]byte(`["9400ZZMAPIC1","9400ZZMAPIC2"]`), nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(mockLogger(t), pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		stopAreaCode, atcoCodes, err := naptanRepository.GetStopAreaForAtcoCode(ctx, "9400ZZMAPIC1")
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, "940GZZMAPIC", stopAreaCode)
+		assert.Equal(t, []string{"9400ZZMAPIC1", "9400ZZMAPIC2"}, atcoCodes)
+	})
+
+	t.Run(`Given the StopAreaCode of an AtcoCode is not stored in Redis
+When GetStopAreaForAtcoCode is called
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "stop_area_of_atco_code_9400ZZMAXXX1").Return(nil, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(mockLogger(t), pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		stopAreaCode, atcoCodes, err := naptanRepository.GetStopAreaForAtcoCode(ctx, "9400ZZMAXXX1")
+
+		// Then
+		assert.Equal(t, "", stopAreaCode)
+		assert.Nil(t, atcoCodes)
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.EqualError(t, err, "error getting StopAreaCode for AtcoCode 9400ZZMAXXX1: redigo: nil returned")
+	})
+}
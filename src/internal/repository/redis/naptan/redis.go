@@ -10,22 +10,25 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"sort"
 	"time"
 )
 
 type NaptanRedis struct {
-	logger     *zap.Logger
-	pool       redis2.Pooler
-	keyPrefix  string
-	timeToLive time.Duration
+	logger            *zap.Logger
+	pool              redis2.Pooler
+	keyPrefix         string
+	atcoCodeKeyPrefix string
+	timeToLive        time.Duration
 }
 
-func NewNaptanRedis(logger *zap.Logger, pool redis2.Pooler, keyPrefix string, timeToLive time.Duration) *NaptanRedis {
+func NewNaptanRedis(logger *zap.Logger, pool redis2.Pooler, keyPrefix string, atcoCodeKeyPrefix string, timeToLive time.Duration) *NaptanRedis {
 	return &NaptanRedis{
-		logger:     logger,
-		pool:       pool,
-		keyPrefix:  keyPrefix,
-		timeToLive: timeToLive,
+		logger:            logger,
+		pool:              pool,
+		keyPrefix:         keyPrefix,
+		atcoCodeKeyPrefix: atcoCodeKeyPrefix,
+		timeToLive:        timeToLive,
 	}
 }
 
@@ -53,10 +56,12 @@ func (n *NaptanRedis) GetStopsInArea(ctx context.Context, stopAreaCode string) (
 	return atcoCodes, nil
 }
 
-func (n *NaptanRedis) StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string) error {
+// GetAllStopsInArea returns every stop area in the index of stored StopAreaCodes. Stop areas which have expired are not
+// returned.
+func (n *NaptanRedis) GetAllStopsInArea(ctx context.Context) (map[string][]string, error) {
 	conn, err := n.pool.GetContext(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -64,73 +69,226 @@ func (n *NaptanRedis) StoreStopsInArea(ctx context.Context, stopsInArea map[stri
 		}
 	}()
 
-	chReceive, chErr := n.send(conn, stopsInArea)
-	if err := n.receive(conn, chReceive); err != nil {
-		return errors.Wrap(err, "error receiving on Redis connection")
+	stopAreaCodes, err := redis.Strings(conn.Do("SMEMBERS", n.indexKey()))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting stored StopAreaCodes")
 	}
 
-	if err := <-chErr; err != nil {
-		return errors.Wrap(err, "error sending/flushing Redis connection")
+	stopsInArea := make(map[string][]string)
+
+	if len(stopAreaCodes) == 0 {
+		return stopsInArea, nil
 	}
 
-	return nil
-}
+	keys := make([]interface{}, 0, len(stopAreaCodes))
 
-func (n *NaptanRedis) send(conn redis.Conn, stopsInArea map[string][]string) (chan int, chan error) {
-	chReceive := make(chan int)
-	chErr := make(chan error, 1)
+	for _, stopAreaCode := range stopAreaCodes {
+		keys = append(keys, n.key(stopAreaCode))
+	}
 
-	go func() {
-		defer close(chErr)
-		defer close(chReceive)
+	values, err := redis.ByteSlices(conn.Do("MGET", keys...))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting stored stops in area")
+	}
 
-		var errs error
+	for i, stopsInAreaJson := range values {
+		if stopsInAreaJson == nil {
+			continue
+		}
 
-		defer func() {
-			chErr <- errs
-		}()
+		var atcoCodes []string
+		if err := json.Unmarshal(stopsInAreaJson, &atcoCodes); err != nil {
+			return nil, errors.Wrapf(err, "error unmarshalling data for %s", stopAreaCodes[i])
+		}
 
-		i := 0
+		stopsInArea[stopAreaCodes[i]] = atcoCodes
+	}
 
-		for stopAreaCode, atcoCodes := range stopsInArea {
-			var stopsInAreaJson bytes.Buffer
-			if err := json.NewEncoder(&stopsInAreaJson).Encode(atcoCodes); err != nil {
-				errs = multierror.Append(errs, errors.Wrapf(err, "error encoding AtcoCodes for StopAreaCode %s", stopAreaCode))
-				continue
-			}
+	return stopsInArea, nil
+}
+
+// maxStoreStopsInAreaAttempts is the number of times the stops in area transaction is attempted when the indexes of
+// stored StopAreaCodes and AtcoCodes are changed by another load while the transaction is prepared
+const maxStoreStopsInAreaAttempts = 3
 
-			if err := conn.Send("SET", n.key(stopAreaCode), stopsInAreaJson.String(), "PX", n.timeToLive.Milliseconds()); err != nil {
-				errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for StopAreaCode %s", stopAreaCode))
-				continue
-			}
+// errStopsInAreaTransactionAborted is returned by storeStopsInArea when a watched index changed before the transaction
+// was executed, so the transaction was not applied
+var errStopsInAreaTransactionAborted = errors.New("stops in area transaction aborted because the stored StopAreaCodes or AtcoCodes changed")
 
-			i++
+// StoreStopsInArea replaces every stored stop area with stopsInArea, and the StopAreaCode stored for every AtcoCode with
+// stopAreaCodesByAtcoCode, in a single transaction. The StopAreaCodes and AtcoCodes which are stored are kept in
+// indexes, so that stop areas and AtcoCodes which are no longer present are deleted rather than left to expire. The
+// indexes are watched while the transaction is prepared, and the transaction is retried if another load changes them
+// first, so that keys stored by the other load are not left out of the indexes.
+func (n *NaptanRedis) StoreStopsInArea(ctx context.Context, stopsInArea map[string][]string, stopAreaCodesByAtcoCode map[string]string) error {
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
 		}
+	}()
 
-		if err := conn.Flush(); err != nil {
-			errs = multierror.Append(errs, errors.Wrap(err, "error flushing Redis connection"))
+	for attempt := 1; ; attempt++ {
+		err := n.storeStopsInArea(conn, stopsInArea, stopAreaCodesByAtcoCode)
+		if err != errStopsInAreaTransactionAborted {
+			return err
 		}
 
-		chReceive <- i
-	}()
+		if attempt == maxStoreStopsInAreaAttempts {
+			return errors.Wrapf(err, "error storing stops in area after %d attempts", attempt)
+		}
 
-	return chReceive, chErr
+		n.logger.Warn("retrying stops in area transaction", zap.Int("attempt", attempt), zap.Error(err))
+	}
 }
 
-func (n *NaptanRedis) receive(conn redis.Conn, chReceive <-chan int) error {
+// storeStopsInArea attempts the stops in area transaction once, returning errStopsInAreaTransactionAborted when it was
+// not applied because a watched index changed
+func (n *NaptanRedis) storeStopsInArea(conn redis.Conn, stopsInArea map[string][]string, stopAreaCodesByAtcoCode map[string]string) error {
+	if _, err := conn.Do("WATCH", n.indexKey(), n.atcoCodeIndexKey()); err != nil {
+		return errors.Wrap(err, "error watching the StopAreaCode and AtcoCode indexes")
+	}
+
+	storedStopAreaCodes, err := redis.Strings(conn.Do("SMEMBERS", n.indexKey()))
+	if err != nil {
+		return errors.Wrap(err, "error getting stored StopAreaCodes")
+	}
+
+	storedAtcoCodes, err := redis.Strings(conn.Do("SMEMBERS", n.atcoCodeIndexKey()))
+	if err != nil {
+		return errors.Wrap(err, "error getting stored AtcoCodes")
+	}
+
 	var errs error
 
-	limit := <-chReceive
+	if err := conn.Send("MULTI"); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	stopAreaCodes := make([]string, 0, len(stopsInArea))
+
+	for stopAreaCode, atcoCodes := range stopsInArea {
+		var stopsInAreaJson bytes.Buffer
+		if err := json.NewEncoder(&stopsInAreaJson).Encode(atcoCodes); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "error encoding AtcoCodes for StopAreaCode %s", stopAreaCode))
+			continue
+		}
 
-	for i := 0; i < limit; i++ {
-		if _, err := conn.Receive(); err != nil {
-			errs = multierror.Append(errs, err)
+		if err := conn.Send("SET", n.key(stopAreaCode), stopsInAreaJson.String(), "PX", n.timeToLive.Milliseconds()); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for StopAreaCode %s", stopAreaCode))
+			continue
 		}
+
+		stopAreaCodes = append(stopAreaCodes, stopAreaCode)
 	}
 
-	return errs
+	sort.Strings(stopAreaCodes)
+
+	removedKeys := make([]interface{}, 0)
+
+	for _, stopAreaCode := range storedStopAreaCodes {
+		if _, ok := stopsInArea[stopAreaCode]; !ok {
+			removedKeys = append(removedKeys, n.key(stopAreaCode))
+		}
+	}
+
+	atcoCodes := make([]string, 0, len(stopAreaCodesByAtcoCode))
+
+	for atcoCode := range stopAreaCodesByAtcoCode {
+		atcoCodes = append(atcoCodes, atcoCode)
+	}
+
+	sort.Strings(atcoCodes)
+
+	for _, atcoCode := range atcoCodes {
+		if err := conn.Send("SET", n.atcoCodeKey(atcoCode), stopAreaCodesByAtcoCode[atcoCode], "PX", n.timeToLive.Milliseconds()); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "error sending Redis command for AtcoCode %s", atcoCode))
+		}
+	}
+
+	for _, atcoCode := range storedAtcoCodes {
+		if _, ok := stopAreaCodesByAtcoCode[atcoCode]; !ok {
+			removedKeys = append(removedKeys, n.atcoCodeKey(atcoCode))
+		}
+	}
+
+	if len(removedKeys) > 0 {
+		if err := conn.Send("DEL", removedKeys...); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "error sending Redis command to delete removed stop areas and AtcoCodes"))
+		}
+	}
+
+	if err := n.sendReplaceIndex(conn, n.indexKey(), "StopAreaCode", stopAreaCodes); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if err := n.sendReplaceIndex(conn, n.atcoCodeIndexKey(), "AtcoCode", atcoCodes); err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "error sending Redis commands to store stops in area")
+	}
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		if err == redis.ErrNil {
+			return errStopsInAreaTransactionAborted
+		}
+
+		return errors.Wrap(err, "error storing stops in area")
+	}
+
+	for _, reply := range replies {
+		if replyErr, ok := reply.(redis.Error); ok {
+			errs = multierror.Append(errs, replyErr)
+		}
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "error storing stops in area")
+	}
+
+	return nil
+}
+
+// sendReplaceIndex sends the commands to replace the index at indexKey, which is described by name in errors, with
+// members
+func (n *NaptanRedis) sendReplaceIndex(conn redis.Conn, indexKey string, name string, members []string) error {
+	if err := conn.Send("DEL", indexKey); err != nil {
+		return errors.Wrapf(err, "error sending Redis command to delete the %s index", name)
+	}
+
+	if len(members) == 0 {
+		return nil
+	}
+
+	args := make([]interface{}, 0, len(members)+1)
+	args = append(args, indexKey)
+
+	for _, member := range members {
+		args = append(args, member)
+	}
+
+	if err := conn.Send("SADD", args...); err != nil {
+		return errors.Wrapf(err, "error sending Redis command to add to the %s index", name)
+	}
+
+	if err := conn.Send("PEXPIRE", indexKey, n.timeToLive.Milliseconds()); err != nil {
+		return errors.Wrapf(err, "error sending Redis command to expire the %s index", name)
+	}
+
+	return nil
 }
 
 func (n NaptanRedis) key(stopAreaCode string) string {
 	return fmt.Sprintf("%s_%s", n.keyPrefix, stopAreaCode)
 }
+
+// indexKey is the key of the set of stored StopAreaCodes
+func (n NaptanRedis) indexKey() string {
+	return fmt.Sprintf("%s_index", n.keyPrefix)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
 	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
 	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -65,6 +66,15 @@ func givenAtcoCodesToStore(t *testing.T) map[string][]string {
 	}
 }
 
+func givenStopAreaCodesByAtcoCodeToStore(t *testing.T) map[string]string {
+	t.Helper()
+
+	return map[string]string{
+		"9400ZZMASTP1": "940GZZMASTP",
+		"9400ZZMAVIC1": "940GZZMAVIC",
+	}
+}
+
 func TestNaptanRedis_GetStopsInArea(t *testing.T) {
 	t.Run(`Given a populated Redis stops in area repository
 When GetStopsInArea is called with a StopAreaCode
@@ -100,7 +110,7 @@ Then AtcoCodes for that StopAreaCode are returned`, func(t *testing.T) {
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, "stop_area_of_atco_code", timeToLive)
 
 		// When
 		stopsInArea, err := naptanRepository.GetStopsInArea(ctx, stopAreaCode)
@@ -131,7 +141,7 @@ Then an error is returned`, func(t *testing.T) {
 		poolErr := errors.New("FUBAR")
 		pool.EXPECT().GetContext(ctx).Return(nil, poolErr)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, "stop_area_of_atco_code", timeToLive)
 
 		// When
 		stopsInArea, err := naptanRepository.GetStopsInArea(ctx, stopAreaCode)
@@ -170,7 +180,7 @@ Then an error is returned`, func(t *testing.T) {
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, "stop_area_of_atco_code", timeToLive)
 
 		// When
 		stopsInArea, err := naptanRepository.GetStopsInArea(ctx, stopAreaCode)
@@ -217,7 +227,7 @@ Then an error is logged`, func(t *testing.T) {
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, "stop_area_of_atco_code", timeToLive)
 
 		// When
 		stopsInArea, err := naptanRepository.GetStopsInArea(ctx, stopAreaCode)
@@ -262,7 +272,7 @@ Then an error is logged`, func(t *testing.T) {
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, "stop_area_of_atco_code", timeToLive)
 
 		// When
 		stopsInArea, err := naptanRepository.GetStopsInArea(ctx, stopAreaCode)
@@ -274,10 +284,122 @@ Then an error is logged`, func(t *testing.T) {
 	})
 }
 
+func TestNaptanRedis_GetAllStopsInArea(t *testing.T) {
+	t.Run(`Given stops in area are stored in Redis
+And one of the StopAreaCodes in the index has expired
+When GetAllStopsInArea is called
+Then the AtcoCodes of every stop area which has not expired are returned by StopAreaCode`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{[]byte("940GZZMASTP"), []byte("940GZZMAVIC"), []byte("940GZZMAOLD")}, nil),
+			conn.EXPECT().Do("MGET", "stopsinarea_940GZZMASTP", "stopsinarea_940GZZMAVIC", "stopsinarea_940GZZMAOLD").Return([]interface{}{[]byte(`["9400ZZMASTP1"]`), []byte(`["9400ZZMAVIC1","9400ZZMAVIC2"]`), nil}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(mockLogger(t), pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		stopsInArea, err := naptanRepository.GetAllStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{
+			"940GZZMASTP": {"9400ZZMASTP1"},
+			"940GZZMAVIC": {"9400ZZMAVIC1", "9400ZZMAVIC2"},
+		}, stopsInArea)
+	})
+
+	t.Run(`Given no stops in area are stored in Redis
+When GetAllStopsInArea is called
+Then an empty map is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(mockLogger(t), pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		stopsInArea, err := naptanRepository.GetAllStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, map[string][]string{}, stopsInArea)
+	})
+
+	t.Run(`Given an error occurs getting the stored stops in area
+When GetAllStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{[]byte("940GZZMASTP")}, nil),
+			conn.EXPECT().Do("MGET", "stopsinarea_940GZZMASTP").Return(nil, errors.New("FUBAR")),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(mockLogger(t), pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		stopsInArea, err := naptanRepository.GetAllStopsInArea(ctx)
+
+		// Then
+		assert.Nil(t, stopsInArea)
+		assert.EqualError(t, err, "error getting stored stops in area: FUBAR")
+	})
+}
+
+func givenEncodedStopsInArea(t *testing.T, atcoCodes []string) string {
+	t.Helper()
+
+	var stopsInArea bytes.Buffer
+	if err := json.NewEncoder(&stopsInArea).Encode(atcoCodes); err != nil {
+		t.Fatal(err)
+	}
+
+	return stopsInArea.String()
+}
+
 func TestMetrolinkDeparturesRepository_Store(t *testing.T) {
 	t.Run(`Given a map of StopAreaCodes to AtcoCodes
+And a stop area which is not in the map has been stored
+And an AtcoCode which is not in the map of StopAreaCodes of AtcoCodes has been stored
 When StoreStopsInArea is called
-Then the AtcoCodes are stored in Redis grouped by StopAreaCode`, func(t *testing.T) {
+Then the indexes of stored StopAreaCodes and AtcoCodes are watched
+And the AtcoCodes are stored in Redis grouped by StopAreaCode in a single transaction
+And the StopAreaCode of each AtcoCode is stored in the same transaction
+And the stop area and the AtcoCode which are not in the maps are deleted
+And the indexes of stored StopAreaCodes and AtcoCodes are replaced`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -286,45 +408,50 @@ Then the AtcoCodes are stored in Redis grouped by StopAreaCode`, func(t *testing
 
 		logger := mockLogger(t)
 
-		keyPrefix := "stopsinarea"
-
-		timeToLive := time.Second * 15
-
-		stopAreasToStore := givenAtcoCodesToStore(t)
-
 		conn := mock_redis.NewMockConn(ctrl)
 
-		var stopsInAreaFor940GZZMASTP bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMASTP).Encode(givenAtcoCodesFor940GZZMASTP(t)); err != nil {
-			t.Fatal(err)
-		}
+		gomock.InOrder(
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{[]byte("940GZZMASTP"), []byte("940GZZMAOLD")}, nil),
+			conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{[]byte("9400ZZMASTP1"), []byte("9400ZZMAOLD1")}, nil),
+			conn.EXPECT().Send("MULTI").Return(nil),
+		)
 
-		var stopsInAreaFor940GZZMAVIC bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMAVIC).Encode(givenAtcoCodesFor940GZZMAVIC(t)); err != nil {
-			t.Fatal(err)
+		storeStopAreas := []*gomock.Call{
+			conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", givenEncodedStopsInArea(t, givenAtcoCodesFor940GZZMASTP(t)), "PX", int64(15000)).Return(nil),
+			conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", givenEncodedStopsInArea(t, givenAtcoCodesFor940GZZMAVIC(t)), "PX", int64(15000)).Return(nil),
 		}
 
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", stopsInAreaFor940GZZMASTP.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", stopsInAreaFor940GZZMAVIC.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Flush().Return(nil)
-		conn.EXPECT().Receive().Return("OK", nil).Times(2)
-		conn.EXPECT().Close().Return(nil)
+		gomock.InOrder(
+			conn.EXPECT().Send("SET", "stop_area_of_atco_code_9400ZZMASTP1", "940GZZMASTP", "PX", int64(15000)).Return(nil).After(storeStopAreas[0]).After(storeStopAreas[1]),
+			conn.EXPECT().Send("SET", "stop_area_of_atco_code_9400ZZMAVIC1", "940GZZMAVIC", "PX", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_940GZZMAOLD", "stop_area_of_atco_code_9400ZZMAOLD1").Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_index").Return(nil),
+			conn.EXPECT().Send("SADD", "stopsinarea_index", "940GZZMASTP", "940GZZMAVIC").Return(nil),
+			conn.EXPECT().Send("PEXPIRE", "stopsinarea_index", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stop_area_of_atco_code_index").Return(nil),
+			conn.EXPECT().Send("SADD", "stop_area_of_atco_code_index", "9400ZZMASTP1", "9400ZZMAVIC1").Return(nil),
+			conn.EXPECT().Send("PEXPIRE", "stop_area_of_atco_code_index", int64(15000)).Return(nil),
+			conn.EXPECT().Do("EXEC").Return([]interface{}{"OK", "OK", "OK", "OK", int64(2), int64(1), int64(2), int64(1), int64(1), int64(2), int64(1)}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
-		// Thens
+		// Then
 		assert.Nil(t, err)
 	})
 
-	t.Run(`Given an error occurs getting a Redis connection from the connection pool
+	t.Run(`Given empty maps of StopAreaCodes to AtcoCodes and AtcoCodes to StopAreaCodes
+And a stop area and the StopAreaCode of an AtcoCode have been stored
 When StoreStopsInArea is called
-Then an error is returned`, func(t *testing.T) {
+Then the stored stop area, the stored StopAreaCode of the AtcoCode and the indexes are deleted`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -333,27 +460,57 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		keyPrefix := "stopsinarea"
+		conn := mock_redis.NewMockConn(ctrl)
 
-		timeToLive := time.Second * 15
+		gomock.InOrder(
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{[]byte("940GZZMASTP")}, nil),
+			conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{[]byte("9400ZZMASTP1")}, nil),
+			conn.EXPECT().Send("MULTI").Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_940GZZMASTP", "stop_area_of_atco_code_9400ZZMASTP1").Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_index").Return(nil),
+			conn.EXPECT().Send("DEL", "stop_area_of_atco_code_index").Return(nil),
+			conn.EXPECT().Do("EXEC").Return([]interface{}{int64(2), int64(1), int64(1)}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		err := naptanRepository.StoreStopsInArea(ctx, map[string][]string{}, map[string]string{})
 
-		stopAreasToStore := givenAtcoCodesToStore(t)
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given an error occurs getting a Redis connection from the connection pool
+When StoreStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		poolErr := errors.New("FUBAR")
 		pool.EXPECT().GetContext(ctx).Return(nil, poolErr)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
 		// Then
-		assert.NotNil(t, err)
 		assert.Equal(t, poolErr, err)
 	})
 
-	t.Run(`Given an error occurs sending data to Redis
+	t.Run(`Given an error occurs getting the stored StopAreaCodes
 When StoreStopsInArea is called
 Then an error is returned`, func(t *testing.T) {
 		// Given
@@ -364,45 +521,92 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		keyPrefix := "stopsinarea"
+		conn := mock_redis.NewMockConn(ctrl)
 
-		timeToLive := time.Second * 15
+		gomock.InOrder(
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return(nil, errors.New("FUBAR")),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
-		stopAreasToStore := givenAtcoCodesToStore(t)
+		// Then
+		assert.EqualError(t, err, "error getting stored StopAreaCodes: FUBAR")
+	})
+
+	t.Run(`Given an error occurs getting the stored AtcoCodes
+When StoreStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
 
 		conn := mock_redis.NewMockConn(ctrl)
 
-		var stopsInAreaFor940GZZMASTP bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMASTP).Encode(givenAtcoCodesFor940GZZMASTP(t)); err != nil {
-			t.Fatal(err)
-		}
+		gomock.InOrder(
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil),
+			conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return(nil, errors.New("FUBAR")),
+			conn.EXPECT().Close().Return(nil),
+		)
 
-		var stopsInAreaFor940GZZMAVIC bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMAVIC).Encode(givenAtcoCodesFor940GZZMAVIC(t)); err != nil {
-			t.Fatal(err)
-		}
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		connErr := errors.New("FUBAR")
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", stopsInAreaFor940GZZMASTP.String(), "PX", int64(15000)).Return(connErr)
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", stopsInAreaFor940GZZMAVIC.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Flush().Return(nil)
-		conn.EXPECT().Receive().Return("OK", nil).Times(1)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
+
+		// When
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
+
+		// Then
+		assert.EqualError(t, err, "error getting stored AtcoCodes: FUBAR")
+	})
+
+	t.Run(`Given an error occurs sending data to Redis
+When StoreStopsInArea is called
+Then an error is returned
+And the transaction is not executed`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil)
+		conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Send("MULTI").Return(nil)
+		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", givenEncodedStopsInArea(t, givenAtcoCodesFor940GZZMASTP(t)), "PX", int64(15000)).Return(errors.New("FUBAR"))
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 		conn.EXPECT().Close().Return(nil)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
 		// Then
-		assert.NotNil(t, err)
-		assert.EqualError(t, err, "error sending/flushing Redis connection: 1 error occurred:\n\t* error sending Redis command for StopAreaCode 940GZZMASTP: FUBAR\n\n")
+		assert.EqualError(t, err, "error sending Redis commands to store stops in area: 1 error occurred:\n\t* error sending Redis command for StopAreaCode 940GZZMASTP: FUBAR\n\n")
 	})
 
-	t.Run(`Given an error occurs flushing the Redis connection
+	t.Run(`Given an error occurs executing the Redis transaction
 When StoreStopsInArea is called
 Then an error is returned`, func(t *testing.T) {
 		// Given
@@ -413,47 +617,79 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		keyPrefix := "stopsinarea"
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil)
+		conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return(nil, errors.New("FUBAR"))
+		conn.EXPECT().Close().Return(nil)
 
-		timeToLive := time.Second * 15
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		stopAreasToStore := givenAtcoCodesToStore(t)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
-		conn := mock_redis.NewMockConn(ctrl)
+		// When
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
-		var stopsInAreaFor940GZZMASTP bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMASTP).Encode(givenAtcoCodesFor940GZZMASTP(t)); err != nil {
-			t.Fatal(err)
-		}
+		// Then
+		assert.EqualError(t, err, "error storing stops in area: FUBAR")
+	})
 
-		var stopsInAreaFor940GZZMAVIC bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMAVIC).Encode(givenAtcoCodesFor940GZZMAVIC(t)); err != nil {
-			t.Fatal(err)
-		}
+	t.Run(`Given the indexes of stored StopAreaCodes and AtcoCodes are changed by another load before the transaction is executed
+When StoreStopsInArea is called
+Then the aborted transaction is retried with the changed indexes`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
 
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", stopsInAreaFor940GZZMASTP.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", stopsInAreaFor940GZZMAVIC.String(), "PX", int64(15000)).Return(nil)
-		connErr := errors.New("FUBAR")
-		conn.EXPECT().Flush().Return(connErr)
-		conn.EXPECT().Receive().Return("OK", nil).Times(2)
-		conn.EXPECT().Close().Return(nil)
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil),
+			conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil),
+			conn.EXPECT().Send("MULTI").Return(nil),
+			conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", givenEncodedStopsInArea(t, givenAtcoCodesFor940GZZMASTP(t)), "PX", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_index").Return(nil),
+			conn.EXPECT().Send("SADD", "stopsinarea_index", "940GZZMASTP").Return(nil),
+			conn.EXPECT().Send("PEXPIRE", "stopsinarea_index", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stop_area_of_atco_code_index").Return(nil),
+			conn.EXPECT().Do("EXEC").Return(nil, nil),
+			conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil),
+			conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{[]byte("940GZZMAOLD")}, nil),
+			conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil),
+			conn.EXPECT().Send("MULTI").Return(nil),
+			conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", givenEncodedStopsInArea(t, givenAtcoCodesFor940GZZMASTP(t)), "PX", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_940GZZMAOLD").Return(nil),
+			conn.EXPECT().Send("DEL", "stopsinarea_index").Return(nil),
+			conn.EXPECT().Send("SADD", "stopsinarea_index", "940GZZMASTP").Return(nil),
+			conn.EXPECT().Send("PEXPIRE", "stopsinarea_index", int64(15000)).Return(nil),
+			conn.EXPECT().Send("DEL", "stop_area_of_atco_code_index").Return(nil),
+			conn.EXPECT().Do("EXEC").Return([]interface{}{"OK", int64(1), int64(1), int64(1), int64(1), int64(0)}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, map[string][]string{"940GZZMASTP": givenAtcoCodesFor940GZZMASTP(t)}, map[string]string{})
 
 		// Then
-		assert.NotNil(t, err)
-		assert.EqualError(t, err, "error sending/flushing Redis connection: 1 error occurred:\n\t* error flushing Redis connection: FUBAR\n\n")
+		assert.Nil(t, err)
 	})
 
-	t.Run(`Given an error occurs receiving the response from the Redis connection
+	t.Run(`Given the indexes of stored StopAreaCodes and AtcoCodes are changed by another load before every attempt is executed
 When StoreStopsInArea is called
-Then an error is returned`, func(t *testing.T) {
+Then an error is returned after the last attempt`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -462,43 +698,55 @@ Then an error is returned`, func(t *testing.T) {
 
 		logger := mockLogger(t)
 
-		keyPrefix := "stopsinarea"
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil).Times(3)
+		conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil).Times(3)
+		conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil).Times(3)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return(nil, nil).Times(3)
+		conn.EXPECT().Close().Return(nil)
 
-		timeToLive := time.Second * 15
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		stopAreasToStore := givenAtcoCodesToStore(t)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
-		conn := mock_redis.NewMockConn(ctrl)
+		// When
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
-		var stopsInAreaFor940GZZMASTP bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMASTP).Encode(givenAtcoCodesFor940GZZMASTP(t)); err != nil {
-			t.Fatal(err)
-		}
+		// Then
+		assert.EqualError(t, err, "error storing stops in area after 3 attempts: stops in area transaction aborted because the stored StopAreaCodes or AtcoCodes changed")
+	})
 
-		var stopsInAreaFor940GZZMAVIC bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMAVIC).Encode(givenAtcoCodesFor940GZZMAVIC(t)); err != nil {
-			t.Fatal(err)
-		}
+	t.Run(`Given a command in the Redis transaction fails
+When StoreStopsInArea is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
 
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", stopsInAreaFor940GZZMASTP.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", stopsInAreaFor940GZZMAVIC.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Flush().Return(nil)
-		conn.EXPECT().Receive().Return("OK", nil)
-		connErr := errors.New("FUBAR")
-		conn.EXPECT().Receive().Return(nil, connErr)
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil)
+		conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return([]interface{}{"OK", redis.Error("OOM command not allowed when used memory > 'maxmemory'"), int64(0), int64(2), int64(1)}, nil)
 		conn.EXPECT().Close().Return(nil)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
 		// Then
-		assert.NotNil(t, err)
-		assert.EqualError(t, err, "error receiving on Redis connection: 1 error occurred:\n\t* FUBAR\n\n")
+		assert.EqualError(t, err, "error storing stops in area: 1 error occurred:\n\t* OOM command not allowed when used memory > 'maxmemory'\n\n")
 	})
 
 	t.Run(`Given an error occurs returning the Redis connection to the pool
@@ -513,38 +761,22 @@ Then an error message is logged`, func(t *testing.T) {
 		zapCore, observedLogs := observer.New(zapcore.DebugLevel)
 		logger := zap.New(zapCore)
 
-		keyPrefix := "stopsinarea"
-
-		timeToLive := time.Second * 15
-
-		stopAreasToStore := givenAtcoCodesToStore(t)
-
 		conn := mock_redis.NewMockConn(ctrl)
-
-		var stopsInAreaFor940GZZMASTP bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMASTP).Encode(givenAtcoCodesFor940GZZMASTP(t)); err != nil {
-			t.Fatal(err)
-		}
-
-		var stopsInAreaFor940GZZMAVIC bytes.Buffer
-		if err := json.NewEncoder(&stopsInAreaFor940GZZMAVIC).Encode(givenAtcoCodesFor940GZZMAVIC(t)); err != nil {
-			t.Fatal(err)
-		}
-
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMASTP", stopsInAreaFor940GZZMASTP.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Send("SET", "stopsinarea_940GZZMAVIC", stopsInAreaFor940GZZMAVIC.String(), "PX", int64(15000)).Return(nil)
-		conn.EXPECT().Flush().Return(nil)
-		conn.EXPECT().Receive().Return("OK", nil).Times(2)
+		conn.EXPECT().Do("WATCH", "stopsinarea_index", "stop_area_of_atco_code_index").Return("OK", nil)
+		conn.EXPECT().Do("SMEMBERS", "stopsinarea_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Do("SMEMBERS", "stop_area_of_atco_code_index").Return([]interface{}{}, nil)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return([]interface{}{}, nil)
 		connErr := errors.New("FUBAR")
 		conn.EXPECT().Close().Return(connErr)
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		pool.EXPECT().GetContext(ctx).Return(conn, nil)
 
-		naptanRepository := naptan.NewNaptanRedis(logger, pool, keyPrefix, timeToLive)
+		naptanRepository := naptan.NewNaptanRedis(logger, pool, "stopsinarea", "stop_area_of_atco_code", 15*time.Second)
 
 		// When
-		err := naptanRepository.StoreStopsInArea(ctx, stopAreasToStore)
+		err := naptanRepository.StoreStopsInArea(ctx, givenAtcoCodesToStore(t), givenStopAreaCodesByAtcoCodeToStore(t))
 
 		// Then
 		assert.Nil(t, err)
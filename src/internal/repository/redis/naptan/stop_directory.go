@@ -0,0 +1,77 @@
+package naptan
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	redis2 "github.com/Marchie/tf-experiment/lambda/pkg/redis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkStopDirectoryRedis stores the directory of every Metrolink stop area as a single value, which is replaced
+// whenever NaPTAN data is loaded
+type MetrolinkStopDirectoryRedis struct {
+	logger     *zap.Logger
+	pool       redis2.Pooler
+	key        string
+	timeToLive time.Duration
+}
+
+func NewMetrolinkStopDirectoryRedis(logger *zap.Logger, pool redis2.Pooler, key string, timeToLive time.Duration) *MetrolinkStopDirectoryRedis {
+	return &MetrolinkStopDirectoryRedis{
+		logger:     logger,
+		pool:       pool,
+		key:        key,
+		timeToLive: timeToLive,
+	}
+}
+
+func (m *MetrolinkStopDirectoryRedis) GetStopDirectory(ctx context.Context) ([]*domain.MetrolinkStopArea, error) {
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	stopDirectoryJson, err := redis.Bytes(conn.Do("GET", m.key))
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting Metrolink stop directory")
+	}
+
+	var stopAreas []*domain.MetrolinkStopArea
+	if err := json.Unmarshal(stopDirectoryJson, &stopAreas); err != nil {
+		return nil, errors.Wrap(err, "error unmarshalling Metrolink stop directory")
+	}
+
+	return stopAreas, nil
+}
+
+func (m *MetrolinkStopDirectoryRedis) StoreStopDirectory(ctx context.Context, stopAreas []*domain.MetrolinkStopArea) error {
+	stopDirectoryJson, err := json.Marshal(stopAreas)
+	if err != nil {
+		return errors.Wrap(err, "error encoding Metrolink stop directory as JSON")
+	}
+
+	conn, err := m.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	if _, err := conn.Do("SET", m.key, string(stopDirectoryJson), "PX", m.timeToLive.Milliseconds()); err != nil {
+		return errors.Wrap(err, "error storing Metrolink stop directory")
+	}
+
+	return nil
+}
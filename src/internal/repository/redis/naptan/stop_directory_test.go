@@ -0,0 +1,205 @@
+package naptan_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
+	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func givenMetrolinkStopDirectory(t *testing.T) []*domain.MetrolinkStopArea {
+	t.Helper()
+
+	platformA := "A"
+	platformB := "B"
+
+	return []*domain.MetrolinkStopArea{
+		{
+			StopAreaCode: "940GZZMAPIC",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMAPIC1", Platform: &platformA},
+				{AtcoCode: "9400ZZMAPIC2", Platform: &platformB},
+			},
+		},
+		{
+			StopAreaCode: "940GZZMASTP",
+			Stops: []*domain.MetrolinkStop{
+				{AtcoCode: "9400ZZMASTP1"},
+			},
+		},
+	}
+}
+
+func TestMetrolinkStopDirectoryRedis_GetStopDirectory(t *testing.T) {
+	t.Run(`Given a stop directory is stored in Redis
+When GetStopDirectory is called
+Then the stop directory is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		expStopDirectory := givenMetrolinkStopDirectory(t)
+
+		stopDirectoryJson, err := json.Marshal(expStopDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "metrolink_stop_directory").Return(stopDirectoryJson, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		stopDirectoryRepository := naptan.NewMetrolinkStopDirectoryRedis(logger, pool, "metrolink_stop_directory", time.Hour)
+
+		// When
+		stopDirectory, err := stopDirectoryRepository.GetStopDirectory(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expStopDirectory, stopDirectory)
+	})
+
+	t.Run(`Given no stop directory is stored in Redis
+When GetStopDirectory is called
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "metrolink_stop_directory").Return(nil, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		stopDirectoryRepository := naptan.NewMetrolinkStopDirectoryRedis(logger, pool, "metrolink_stop_directory", time.Hour)
+
+		// When
+		stopDirectory, err := stopDirectoryRepository.GetStopDirectory(ctx)
+
+		// Then
+		assert.Nil(t, stopDirectory)
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting Metrolink stop directory: redigo: nil returned", err.Error())
+	})
+
+	t.Run(`Given an error occurs getting a Redis connection from the connection pool
+When GetStopDirectory is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		poolErr := errors.New("FUBAR")
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(nil, poolErr)
+
+		stopDirectoryRepository := naptan.NewMetrolinkStopDirectoryRedis(logger, pool, "metrolink_stop_directory", time.Hour)
+
+		// When
+		stopDirectory, err := stopDirectoryRepository.GetStopDirectory(ctx)
+
+		// Then
+		assert.Nil(t, stopDirectory)
+		assert.Equal(t, poolErr, err)
+	})
+}
+
+func TestMetrolinkStopDirectoryRedis_StoreStopDirectory(t *testing.T) {
+	t.Run(`Given a stop directory
+When StoreStopDirectory is called
+Then the stop directory is stored as JSON with the time to live`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopDirectory := givenMetrolinkStopDirectory(t)
+
+		stopDirectoryJson, err := json.Marshal(stopDirectory)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SET", "metrolink_stop_directory", string(stopDirectoryJson), "PX", int64(3600000)).Return("OK", nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		stopDirectoryRepository := naptan.NewMetrolinkStopDirectoryRedis(logger, pool, "metrolink_stop_directory", time.Hour)
+
+		// When
+		err = stopDirectoryRepository.StoreStopDirectory(ctx, stopDirectory)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given an error occurs storing the stop directory in Redis
+When StoreStopDirectory is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SET", "metrolink_stop_directory", gomock.Any(), "PX", int64(3600000)).Return(nil, errors.New("FUBAR")),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		stopDirectoryRepository := naptan.NewMetrolinkStopDirectoryRedis(logger, pool, "metrolink_stop_directory", time.Hour)
+
+		// When
+		err := stopDirectoryRepository.StoreStopDirectory(ctx, givenMetrolinkStopDirectory(t))
+
+		// Then
+		assert.Equal(t, "error storing Metrolink stop directory: FUBAR", err.Error())
+	})
+}
@@ -0,0 +1,214 @@
+package naptan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	redis2 "github.com/Marchie/tf-experiment/lambda/pkg/redis"
+	"github.com/gomodule/redigo/redis"
+	"github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// NaptanStopsRedis stores the NaPTAN stops and stop areas of the Metrolink network. There are few enough that each is
+// stored as a single value, which is replaced whenever NaPTAN data is loaded. The locations of stop areas are also
+// stored in a geospatial index, so that stop areas near a location can be found with GEORADIUS.
+type NaptanStopsRedis struct {
+	logger       *zap.Logger
+	pool         redis2.Pooler
+	stopsKey     string
+	stopAreasKey string
+	timeToLive   time.Duration
+}
+
+func NewNaptanStopsRedis(logger *zap.Logger, pool redis2.Pooler, stopsKey string, stopAreasKey string, timeToLive time.Duration) *NaptanStopsRedis {
+	return &NaptanStopsRedis{
+		logger:       logger,
+		pool:         pool,
+		stopsKey:     stopsKey,
+		stopAreasKey: stopAreasKey,
+		timeToLive:   timeToLive,
+	}
+}
+
+func (n *NaptanStopsRedis) GetStops(ctx context.Context) ([]*domain.NaptanStop, error) {
+	var stops []*domain.NaptanStop
+	if err := n.get(ctx, n.stopsKey, &stops); err != nil {
+		return nil, errors.Wrap(err, "error getting NaPTAN stops")
+	}
+
+	return stops, nil
+}
+
+func (n *NaptanStopsRedis) StoreStops(ctx context.Context, stops []*domain.NaptanStop) error {
+	if err := n.set(ctx, n.stopsKey, stops); err != nil {
+		return errors.Wrap(err, "error storing NaPTAN stops")
+	}
+
+	return nil
+}
+
+func (n *NaptanStopsRedis) GetStopAreas(ctx context.Context) ([]*domain.NaptanStopArea, error) {
+	var stopAreas []*domain.NaptanStopArea
+	if err := n.get(ctx, n.stopAreasKey, &stopAreas); err != nil {
+		return nil, errors.Wrap(err, "error getting NaPTAN stop areas")
+	}
+
+	return stopAreas, nil
+}
+
+// StoreStopAreas replaces the stop areas and their geospatial index in a single transaction, so that a search never
+// sees a partly built index
+func (n *NaptanStopsRedis) StoreStopAreas(ctx context.Context, stopAreas []*domain.NaptanStopArea) error {
+	stopAreasJson, err := json.Marshal(stopAreas)
+	if err != nil {
+		return errors.Wrap(err, "error encoding NaPTAN stop areas as JSON")
+	}
+
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error storing NaPTAN stop areas")
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	var errs error
+
+	sendErr := func(err error) {
+		if err != nil {
+			errs = multierror.Append(errs, err)
+		}
+	}
+
+	sendErr(conn.Send("MULTI"))
+	sendErr(conn.Send("SET", n.stopAreasKey, string(stopAreasJson), "PX", n.timeToLive.Milliseconds()))
+	sendErr(conn.Send("DEL", n.stopAreasGeoKey()))
+
+	if len(stopAreas) > 0 {
+		args := redis.Args{}.Add(n.stopAreasGeoKey())
+
+		for _, stopArea := range stopAreas {
+			args = args.Add(stopArea.Longitude, stopArea.Latitude, stopArea.StopAreaCode)
+		}
+
+		sendErr(conn.Send("GEOADD", args...))
+		sendErr(conn.Send("PEXPIRE", n.stopAreasGeoKey(), n.timeToLive.Milliseconds()))
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "error sending Redis commands to store NaPTAN stop areas")
+	}
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return errors.Wrap(err, "error storing NaPTAN stop areas")
+	}
+
+	// A command which fails inside the transaction, e.g. a GEOADD with invalid coordinates, does not fail EXEC, but leaves
+	// an error in its reply
+	for _, reply := range replies {
+		if replyErr, ok := reply.(redis.Error); ok {
+			errs = multierror.Append(errs, replyErr)
+		}
+	}
+
+	if errs != nil {
+		return errors.Wrap(errs, "error storing NaPTAN stop areas")
+	}
+
+	return nil
+}
+
+// GetStopAreasNearby returns up to limit stop areas within radiusMetres of a location, nearest first
+func (n *NaptanStopsRedis) GetStopAreasNearby(ctx context.Context, latitude float64, longitude float64, radiusMetres float64, limit int) ([]*domain.NearbyStopArea, error) {
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	results, err := redis.Values(conn.Do("GEORADIUS", n.stopAreasGeoKey(), longitude, latitude, radiusMetres, "m", "WITHDIST", "ASC", "COUNT", limit))
+	if err != nil {
+		return nil, errors.Wrap(err, "error searching for NaPTAN stop areas nearby")
+	}
+
+	nearbyStopAreas := make([]*domain.NearbyStopArea, 0, len(results))
+
+	for _, result := range results {
+		values, err := redis.Values(result, nil)
+		if err != nil || len(values) != 2 {
+			return nil, errors.Errorf("unexpected GEORADIUS result %v", result)
+		}
+
+		stopAreaCode, err := redis.String(values[0], nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading stop area code from GEORADIUS result")
+		}
+
+		distanceMetres, err := redis.Float64(values[1], nil)
+		if err != nil {
+			return nil, errors.Wrap(err, "error reading distance from GEORADIUS result")
+		}
+
+		nearbyStopAreas = append(nearbyStopAreas, &domain.NearbyStopArea{
+			StopAreaCode:   stopAreaCode,
+			DistanceMetres: distanceMetres,
+		})
+	}
+
+	return nearbyStopAreas, nil
+}
+
+func (n *NaptanStopsRedis) stopAreasGeoKey() string {
+	return fmt.Sprintf("%s_geo", n.stopAreasKey)
+}
+
+func (n *NaptanStopsRedis) get(ctx context.Context, key string, v interface{}) error {
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	data, err := redis.Bytes(conn.Do("GET", key))
+	if err != nil {
+		return err
+	}
+
+	return errors.Wrap(json.Unmarshal(data, v), "error unmarshalling JSON")
+}
+
+func (n *NaptanStopsRedis) set(ctx context.Context, key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, "error encoding JSON")
+	}
+
+	conn, err := n.pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			n.logger.Error("error returning Redis connection to pool", zap.Error(err))
+		}
+	}()
+
+	_, err = conn.Do("SET", key, string(data), "PX", n.timeToLive.Milliseconds())
+
+	return err
+}
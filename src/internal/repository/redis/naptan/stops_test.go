@@ -0,0 +1,392 @@
+package naptan_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/Marchie/tf-experiment/lambda/internal/repository/redis/naptan"
+	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func givenNaptanStops(t *testing.T) []*domain.NaptanStop {
+	t.Helper()
+
+	return []*domain.NaptanStop{
+		{
+			AtcoCode:   "9400ZZMAPIC1",
+			CommonName: "Piccadilly (Manchester Metrolink)",
+			Indicator:  "Platform A",
+			Street:     "London Road",
+			Latitude:   53.4776,
+			Longitude:  -2.2301,
+			Status:     "act",
+		},
+	}
+}
+
+func givenNaptanStopAreas(t *testing.T) []*domain.NaptanStopArea {
+	t.Helper()
+
+	return []*domain.NaptanStopArea{
+		{
+			StopAreaCode: "940GZZMAPIC",
+			Name:         "Piccadilly (Manchester Metrolink)",
+			Latitude:     53.4775,
+			Longitude:    -2.2302,
+			Status:       "act",
+		},
+	}
+}
+
+func TestNaptanStopsRedis_GetStops(t *testing.T) {
+	t.Run(`Given NaPTAN stops are stored in Redis
+When GetStops is called
+Then the stops are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		expStops := givenNaptanStops(t)
+
+		stopsJson, err := json.Marshal(expStops)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "naptan_stops").Return(stopsJson, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		stops, err := naptanStopsRepository.GetStops(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expStops, stops)
+	})
+
+	t.Run(`Given no NaPTAN stops are stored in Redis
+When GetStops is called
+Then an error wrapping redis.ErrNil is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "naptan_stops").Return(nil, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		stops, err := naptanStopsRepository.GetStops(ctx)
+
+		// Then
+		assert.Nil(t, stops)
+		assert.Equal(t, redis.ErrNil, errors.Cause(err))
+		assert.Equal(t, "error getting NaPTAN stops: redigo: nil returned", err.Error())
+	})
+}
+
+func TestNaptanStopsRedis_GetStopAreas(t *testing.T) {
+	t.Run(`Given NaPTAN stop areas are stored in Redis
+When GetStopAreas is called
+Then the stop areas are returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		expStopAreas := givenNaptanStopAreas(t)
+
+		stopAreasJson, err := json.Marshal(expStopAreas)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", "naptan_stop_areas").Return(stopAreasJson, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		stopAreas, err := naptanStopsRepository.GetStopAreas(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, expStopAreas, stopAreas)
+	})
+}
+
+func TestNaptanStopsRedis_StoreStops(t *testing.T) {
+	t.Run(`Given NaPTAN stops
+When StoreStops is called
+Then the stops are stored as JSON with the time to live`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stops := givenNaptanStops(t)
+
+		stopsJson, err := json.Marshal(stops)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("SET", "naptan_stops", string(stopsJson), "PX", int64(3600000)).Return("OK", nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		err = naptanStopsRepository.StoreStops(ctx, stops)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given an error occurs getting a Redis connection from the connection pool
+When StoreStops is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(nil, errors.New("FUBAR"))
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		err := naptanStopsRepository.StoreStops(ctx, givenNaptanStops(t))
+
+		// Then
+		assert.Equal(t, "error storing NaPTAN stops: FUBAR", err.Error())
+	})
+}
+
+func TestNaptanStopsRedis_StoreStopAreas(t *testing.T) {
+	t.Run(`Given NaPTAN stop areas
+When StoreStopAreas is called
+Then the stop areas are stored as JSON with the time to live
+And the geospatial index of stop areas is replaced in the same transaction`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		stopAreas := givenNaptanStopAreas(t)
+
+		stopAreasJson, err := json.Marshal(stopAreas)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Send("MULTI").Return(nil),
+			conn.EXPECT().Send("SET", "naptan_stop_areas", string(stopAreasJson), "PX", int64(3600000)).Return(nil),
+			conn.EXPECT().Send("DEL", "naptan_stop_areas_geo").Return(nil),
+			conn.EXPECT().Send("GEOADD", "naptan_stop_areas_geo", -2.2302, 53.4775, "940GZZMAPIC").Return(nil),
+			conn.EXPECT().Send("PEXPIRE", "naptan_stop_areas_geo", int64(3600000)).Return(nil),
+			conn.EXPECT().Do("EXEC").Return([]interface{}{"OK", int64(1), int64(1), int64(1)}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		err = naptanStopsRepository.StoreStopAreas(ctx, stopAreas)
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given NaPTAN stop areas
+When StoreStopAreas is called
+And the transaction fails
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return(nil, errors.New("FUBAR"))
+		conn.EXPECT().Close().Return(nil)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		err := naptanStopsRepository.StoreStopAreas(ctx, givenNaptanStopAreas(t))
+
+		// Then
+		assert.EqualError(t, err, "error storing NaPTAN stop areas: FUBAR")
+	})
+
+	t.Run(`Given NaPTAN stop areas
+When StoreStopAreas is called
+And a command in the transaction fails
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+		conn.EXPECT().Send(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		conn.EXPECT().Do("EXEC").Return([]interface{}{"OK", int64(1), redis.Error("ERR invalid longitude,latitude pair 200.000000,53.477500"), int64(1)}, nil)
+		conn.EXPECT().Close().Return(nil)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		err := naptanStopsRepository.StoreStopAreas(ctx, givenNaptanStopAreas(t))
+
+		// Then
+		assert.EqualError(t, err, "error storing NaPTAN stop areas: 1 error occurred:\n\t* ERR invalid longitude,latitude pair 200.000000,53.477500\n\n")
+	})
+}
+
+func TestNaptanStopsRedis_GetStopAreasNearby(t *testing.T) {
+	t.Run(`Given NaPTAN stop areas are stored in Redis
+When GetStopAreasNearby is called with a location, radius and limit
+Then the stop areas found by GEORADIUS are returned with their distances, nearest first`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GEORADIUS", "naptan_stop_areas_geo", -2.2400, 53.4780, float64(1000), "m", "WITHDIST", "ASC", "COUNT", 5).Return([]interface{}{
+				[]interface{}{[]byte("940GZZMASTP"), []byte("23.9512")},
+				[]interface{}{[]byte("940GZZMAPIC"), []byte("655.1207")},
+			}, nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		nearbyStopAreas, err := naptanStopsRepository.GetStopAreasNearby(ctx, 53.4780, -2.2400, 1000, 5)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, []*domain.NearbyStopArea{
+			{StopAreaCode: "940GZZMASTP", DistanceMetres: 23.9512},
+			{StopAreaCode: "940GZZMAPIC", DistanceMetres: 655.1207},
+		}, nearbyStopAreas)
+	})
+
+	t.Run(`Given an error occurs searching Redis
+When GetStopAreasNearby is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GEORADIUS", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("FUBAR")),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		naptanStopsRepository := naptan.NewNaptanStopsRedis(logger, pool, "naptan_stops", "naptan_stop_areas", time.Hour)
+
+		// When
+		nearbyStopAreas, err := naptanStopsRepository.GetStopAreasNearby(ctx, 53.4780, -2.2400, 1000, 5)
+
+		// Then
+		assert.Nil(t, nearbyStopAreas)
+		assert.EqualError(t, err, "error searching for NaPTAN stop areas nearby: FUBAR")
+	})
+}
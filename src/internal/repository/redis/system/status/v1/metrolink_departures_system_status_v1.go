@@ -1,9 +1,13 @@
 package v1
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	redis2 "github.com/Marchie/tf-experiment/lambda/pkg/redis"
 	"github.com/gomodule/redigo/redis"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"time"
 )
@@ -22,7 +26,9 @@ func NewMetrolinkDeparturesSystemStatusRepository(logger *zap.Logger, pool redis
 	}
 }
 
-func (m *MetrolinkDeparturesSystemStatusRepository) Get(ctx context.Context) (*time.Time, error) {
+// Get returns the most recently published system status. A status stored before generations were introduced is a
+// plain RFC3339 timestamp, which is returned with an empty Generation.
+func (m *MetrolinkDeparturesSystemStatusRepository) Get(ctx context.Context) (*domain.SystemStatus, error) {
 	conn, err := m.pool.GetContext(ctx)
 	if err != nil {
 		return nil, err
@@ -33,20 +39,39 @@ func (m *MetrolinkDeparturesSystemStatusRepository) Get(ctx context.Context) (*t
 		}
 	}()
 
-	lastUpdatedStr, err := redis.String(conn.Do("GET", m.systemStatusKey))
+	systemStatusJson, err := redis.Bytes(conn.Do("GET", m.systemStatusKey))
 	if err != nil {
 		return nil, err
 	}
 
-	lastUpdated, err := time.Parse(time.RFC3339, lastUpdatedStr)
-	if err != nil {
+	if !bytes.HasPrefix(systemStatusJson, []byte("{")) {
+		lastUpdated, err := time.Parse(time.RFC3339, string(systemStatusJson))
+		if err != nil {
+			return nil, err
+		}
+
+		return &domain.SystemStatus{
+			LastUpdated: lastUpdated,
+		}, nil
+	}
+
+	var systemStatus domain.SystemStatus
+
+	if err := json.Unmarshal(systemStatusJson, &systemStatus); err != nil {
 		return nil, err
 	}
 
-	return &lastUpdated, nil
+	return &systemStatus, nil
 }
 
-func (m *MetrolinkDeparturesSystemStatusRepository) Set(ctx context.Context, lastUpdated time.Time) error {
+// Set publishes the system status. Since the status holds the generation of the current snapshot, this single SET is
+// the atomic switch which makes a newly stored snapshot visible to readers.
+func (m *MetrolinkDeparturesSystemStatusRepository) Set(ctx context.Context, systemStatus *domain.SystemStatus) error {
+	systemStatusJson, err := json.Marshal(systemStatus)
+	if err != nil {
+		return errors.Wrap(err, "error encoding system status as JSON")
+	}
+
 	conn, err := m.pool.GetContext(ctx)
 	if err != nil {
 		return err
@@ -57,6 +82,6 @@ func (m *MetrolinkDeparturesSystemStatusRepository) Set(ctx context.Context, las
 		}
 	}()
 
-	_, err = conn.Do("SET", m.systemStatusKey, lastUpdated.Format(time.RFC3339))
+	_, err = conn.Do("SET", m.systemStatusKey, string(systemStatusJson))
 	return err
 }
@@ -2,6 +2,7 @@ package v1_test
 
 import (
 	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
 	v1 "github.com/Marchie/tf-experiment/lambda/internal/repository/redis/system/status/v1"
 	mock_redis "github.com/Marchie/tf-experiment/lambda/pkg/mocks/redis"
 	"github.com/golang/mock/gomock"
@@ -24,7 +25,50 @@ func mockLogger(t *testing.T) *zap.Logger {
 func TestMetrolinkDeparturesSystemStatusRepository_Get(t *testing.T) {
 	t.Run(`Given a populated Redis status in the repository
 When Get is called
-Then the last updated time is returned`, func(t *testing.T) {
+Then the system status is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		logger := mockLogger(t)
+
+		systemStatusKey := "status"
+
+		conn := mock_redis.NewMockConn(ctrl)
+
+		gomock.InOrder(
+			conn.EXPECT().Do("GET", systemStatusKey).Return([]byte(`{"Generation":"1619231415000000000","LastUpdated":"2021-04-24T02:30:15Z","LastAttempt":"2021-04-24T02:30:22Z","LastSuccess":"2021-04-24T02:30:17Z","ConsecutiveFailures":1,"LastErrorCategory":"source","PassengerInformationDisplays":296,"Departures":412,"Messages":87}`), nil),
+			conn.EXPECT().Close().Return(nil),
+		)
+
+		pool := mock_redis.NewMockPooler(ctrl)
+		pool.EXPECT().GetContext(ctx).Return(conn, nil)
+
+		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
+
+		// When
+		systemStatus, err := metrolinkDeparturesRepository.Get(ctx)
+
+		// Then
+		assert.Nil(t, err)
+		assert.Equal(t, &domain.SystemStatus{
+			Generation:                   "1619231415000000000",
+			LastUpdated:                  time.Date(2021, time.April, 24, 2, 30, 15, 0, time.UTC),
+			LastAttempt:                  time.Date(2021, time.April, 24, 2, 30, 22, 0, time.UTC),
+			LastSuccess:                  time.Date(2021, time.April, 24, 2, 30, 17, 0, time.UTC),
+			ConsecutiveFailures:          1,
+			LastErrorCategory:            domain.SystemStatusErrorCategorySource,
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}, systemStatus)
+	})
+
+	t.Run(`Given a Redis status stored before generations were introduced
+When Get is called
+Then the last updated time is returned without a generation`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -50,11 +94,11 @@ Then the last updated time is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
 
 		// When
-		lastUpdatedTime, err := metrolinkDeparturesRepository.Get(ctx)
+		systemStatus, err := metrolinkDeparturesRepository.Get(ctx)
 
 		// Then
 		assert.Nil(t, err)
-		assert.Equal(t, &expLastUpdatedTime, lastUpdatedTime)
+		assert.Equal(t, &domain.SystemStatus{LastUpdated: expLastUpdatedTime}, systemStatus)
 	})
 
 	t.Run(`Given an error occurs getting a Redis connection from the connection pool
@@ -201,9 +245,9 @@ Then an error is logged`, func(t *testing.T) {
 }
 
 func TestMetrolinkDeparturesRepository_SetStatus(t *testing.T) {
-	t.Run(`Given a last updated time value
+	t.Run(`Given a system status
 When Set is called
-Then the last updated time is stored in the repository`, func(t *testing.T) {
+Then the system status is stored in the repository`, func(t *testing.T) {
 		// Given
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -214,11 +258,19 @@ Then the last updated time is stored in the repository`, func(t *testing.T) {
 
 		systemStatusKey := "status"
 
-		lastUpdatedTime := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+		systemStatus := &domain.SystemStatus{
+			Generation:                   "1619231084000000000",
+			LastUpdated:                  time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC),
+			LastAttempt:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			LastSuccess:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}
 
 		conn := mock_redis.NewMockConn(ctrl)
 
-		conn.EXPECT().Do("SET", systemStatusKey, lastUpdatedTime.Format(time.RFC3339)).Return(nil, nil)
+		conn.EXPECT().Do("SET", systemStatusKey, `{"Generation":"1619231084000000000","LastUpdated":"2021-04-24T02:24:44Z","LastAttempt":"2021-04-24T02:24:46Z","LastSuccess":"2021-04-24T02:24:46Z","ConsecutiveFailures":0,"LastErrorCategory":"","PassengerInformationDisplays":296,"Departures":412,"Messages":87}`).Return(nil, nil)
 		conn.EXPECT().Close().Return(nil)
 
 		pool := mock_redis.NewMockPooler(ctrl)
@@ -227,7 +279,7 @@ Then the last updated time is stored in the repository`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
 
 		// When
-		err := metrolinkDeparturesRepository.Set(ctx, lastUpdatedTime)
+		err := metrolinkDeparturesRepository.Set(ctx, systemStatus)
 
 		// Then
 		assert.Nil(t, err)
@@ -246,7 +298,15 @@ Then an error is returned`, func(t *testing.T) {
 
 		systemStatusKey := "status"
 
-		lastUpdatedTime := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+		systemStatus := &domain.SystemStatus{
+			Generation:                   "1619231084000000000",
+			LastUpdated:                  time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC),
+			LastAttempt:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			LastSuccess:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}
 
 		pool := mock_redis.NewMockPooler(ctrl)
 		poolErr := errors.New("FUBAR")
@@ -255,7 +315,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
 
 		// When
-		err := metrolinkDeparturesRepository.Set(ctx, lastUpdatedTime)
+		err := metrolinkDeparturesRepository.Set(ctx, systemStatus)
 
 		// Then
 		assert.NotNil(t, err)
@@ -275,12 +335,20 @@ Then an error is returned`, func(t *testing.T) {
 
 		systemStatusKey := "status"
 
-		lastUpdatedTime := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+		systemStatus := &domain.SystemStatus{
+			Generation:                   "1619231084000000000",
+			LastUpdated:                  time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC),
+			LastAttempt:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			LastSuccess:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}
 
 		conn := mock_redis.NewMockConn(ctrl)
 
 		connErr := errors.New("FUBAR")
-		conn.EXPECT().Do("SET", systemStatusKey, lastUpdatedTime.Format(time.RFC3339)).Return(nil, connErr)
+		conn.EXPECT().Do("SET", systemStatusKey, `{"Generation":"1619231084000000000","LastUpdated":"2021-04-24T02:24:44Z","LastAttempt":"2021-04-24T02:24:46Z","LastSuccess":"2021-04-24T02:24:46Z","ConsecutiveFailures":0,"LastErrorCategory":"","PassengerInformationDisplays":296,"Departures":412,"Messages":87}`).Return(nil, connErr)
 		conn.EXPECT().Close().Return(nil)
 
 		pool := mock_redis.NewMockPooler(ctrl)
@@ -289,7 +357,7 @@ Then an error is returned`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
 
 		// When
-		err := metrolinkDeparturesRepository.Set(ctx, lastUpdatedTime)
+		err := metrolinkDeparturesRepository.Set(ctx, systemStatus)
 
 		// Then
 		assert.NotNil(t, err)
@@ -310,11 +378,19 @@ Then an error message is logged`, func(t *testing.T) {
 
 		systemStatusKey := "status"
 
-		lastUpdatedTime := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+		systemStatus := &domain.SystemStatus{
+			Generation:                   "1619231084000000000",
+			LastUpdated:                  time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC),
+			LastAttempt:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			LastSuccess:                  time.Date(2021, time.April, 24, 2, 24, 46, 0, time.UTC),
+			PassengerInformationDisplays: 296,
+			Departures:                   412,
+			Messages:                     87,
+		}
 
 		conn := mock_redis.NewMockConn(ctrl)
 
-		conn.EXPECT().Do("SET", systemStatusKey, lastUpdatedTime.Format(time.RFC3339)).Return(nil, nil)
+		conn.EXPECT().Do("SET", systemStatusKey, `{"Generation":"1619231084000000000","LastUpdated":"2021-04-24T02:24:44Z","LastAttempt":"2021-04-24T02:24:46Z","LastSuccess":"2021-04-24T02:24:46Z","ConsecutiveFailures":0,"LastErrorCategory":"","PassengerInformationDisplays":296,"Departures":412,"Messages":87}`).Return(nil, nil)
 		connErr := errors.New("FUBAR")
 		conn.EXPECT().Close().Return(connErr)
 
@@ -324,7 +400,7 @@ Then an error message is logged`, func(t *testing.T) {
 		metrolinkDeparturesRepository := v1.NewMetrolinkDeparturesSystemStatusRepository(logger, pool, systemStatusKey)
 
 		// When
-		err := metrolinkDeparturesRepository.Set(ctx, lastUpdatedTime)
+		err := metrolinkDeparturesRepository.Set(ctx, systemStatus)
 
 		// Then
 		assert.Nil(t, err)
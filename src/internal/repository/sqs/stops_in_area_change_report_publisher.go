@@ -0,0 +1,47 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// StopsInAreaChangeReportPublisher sends the change report of each NaPTAN stops in area load to an SQS queue as JSON
+type StopsInAreaChangeReportPublisher struct {
+	logger      *zap.Logger
+	sqsClient   sqsiface.SQSAPI
+	sqsQueueUrl string
+}
+
+func NewStopsInAreaChangeReportPublisher(logger *zap.Logger, sqsClient sqsiface.SQSAPI, sqsQueueUrl string) *StopsInAreaChangeReportPublisher {
+	return &StopsInAreaChangeReportPublisher{
+		logger:      logger,
+		sqsClient:   sqsClient,
+		sqsQueueUrl: sqsQueueUrl,
+	}
+}
+
+func (p *StopsInAreaChangeReportPublisher) PublishStopsInAreaChangeReport(ctx context.Context, report *domain.StopsInAreaChangeReport) error {
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		return errors.Wrap(err, "error encoding stops in area change report")
+	}
+
+	messageBody := string(reportJson)
+
+	sendMessageOutput, err := p.sqsClient.SendMessageWithContext(ctx, &sqs.SendMessageInput{
+		MessageBody: &messageBody,
+		QueueUrl:    &p.sqsQueueUrl,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error publishing stops in area change report")
+	}
+
+	p.logger.Debug("successfully published stops in area change report", zap.Stringp("messageId", sendMessageOutput.MessageId))
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package sqs
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/domain"
+	mock_sqsiface "github.com/Marchie/tf-experiment/lambda/pkg/mocks/sqs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func givenStopsInAreaChangeReport(t *testing.T) *domain.StopsInAreaChangeReport {
+	t.Helper()
+
+	return &domain.StopsInAreaChangeReport{
+		Accepted:             true,
+		StoredStopAreas:      1,
+		FetchedStopAreas:     1,
+		StoredStops:          1,
+		FetchedStops:         1,
+		AddedStopAreaCodes:   []string{"940GZZMAVIC"},
+		RemovedStopAreaCodes: []string{"940GZZMASTP"},
+		AddedAtcoCodes:       []string{"9400ZZMAVIC1"},
+		RemovedAtcoCodes:     []string{"9400ZZMASTP1"},
+		ChangedStopAreas:     []*domain.StopAreaChange{},
+	}
+}
+
+func TestStopsInAreaChangeReportPublisher_PublishStopsInAreaChangeReport(t *testing.T) {
+	t.Run(`Given a stops in area change report
+When PublishStopsInAreaChangeReport is called
+Then the report is sent to the SQS queue as JSON`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		sqsQueueUrl := "https://sqs.eu-west-2.amazonaws.com/123456789012/naptan-changes"
+
+		sqsClient := mock_sqsiface.NewMockSQSAPI(ctrl)
+		sqsClient.EXPECT().SendMessageWithContext(ctx, &sqs.SendMessageInput{
+			MessageBody: aws.String(`{"accepted":true,"storedStopAreas":1,"fetchedStopAreas":1,"storedStops":1,"fetchedStops":1,"addedStopAreaCodes":["940GZZMAVIC"],"removedStopAreaCodes":["940GZZMASTP"],"addedAtcoCodes":["9400ZZMAVIC1"],"removedAtcoCodes":["9400ZZMASTP1"],"changedStopAreas":[]}`),
+			QueueUrl:    aws.String(sqsQueueUrl),
+		}).Return(&sqs.SendMessageOutput{MessageId: aws.String("abc")}, nil)
+
+		publisher := NewStopsInAreaChangeReportPublisher(mockLogger(t), sqsClient, sqsQueueUrl)
+
+		// When
+		err := publisher.PublishStopsInAreaChangeReport(ctx, givenStopsInAreaChangeReport(t))
+
+		// Then
+		assert.Nil(t, err)
+	})
+
+	t.Run(`Given the SQS queue cannot be reached
+When PublishStopsInAreaChangeReport is called
+Then an error is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		sqsClient := mock_sqsiface.NewMockSQSAPI(ctrl)
+		sqsClient.EXPECT().SendMessageWithContext(ctx, gomock.Any()).Return(nil, errors.New("FUBAR"))
+
+		publisher := NewStopsInAreaChangeReportPublisher(mockLogger(t), sqsClient, "https://sqs.eu-west-2.amazonaws.com/123456789012/naptan-changes")
+
+		// When
+		err := publisher.PublishStopsInAreaChangeReport(ctx, givenStopsInAreaChangeReport(t))
+
+		// Then
+		assert.EqualError(t, err, "error publishing stops in area change report: FUBAR")
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
 type MetrolinkDeparturesAwsApiGateway struct {
@@ -43,7 +44,7 @@ func (h *MetrolinkDeparturesAwsApiGateway) Handler(ctx context.Context, event ev
 		}, nil
 	}
 
-	departures, statusCode, err := h.stopAreaDeparturesJsoner.Json(ctx, stopAreaCodeOrAtcoCode)
+	departures, statusCode, err := h.stopAreaDeparturesJsoner.Json(ctx, stopAreaCodeOrAtcoCode, event.QueryStringParameters)
 	if err != nil {
 		h.logger.Error("error with Metrolink Departures API JSON response", zap.String("stopAreaCode", stopAreaCodeOrAtcoCode), zap.Error(err))
 
@@ -80,9 +81,19 @@ func (h *MetrolinkDeparturesAwsApiGateway) Handler(ctx context.Context, event ev
 		}, nil
 	}
 
+	if staleData, ok := departures.(core.StaleDataJson); ok {
+		headers["Warning"] = staleDataWarning(staleData.DataAge())
+	}
+
 	return &events.APIGatewayProxyResponse{
 		StatusCode: statusCode,
 		Headers:    headers,
 		Body:       buf.String(),
 	}, nil
 }
+
+// staleDataWarning returns a Warning header value, with the 110 "Response is Stale" warning code, for departures or
+// messages which are served while they are stale
+func staleDataWarning(dataAge time.Duration) string {
+	return fmt.Sprintf(`110 - "Response is Stale: Metrolink departures were last updated %s ago"`, dataAge.Round(time.Second))
+}
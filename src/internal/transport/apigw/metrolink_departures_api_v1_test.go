@@ -12,9 +12,11 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest/observer"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"testing"
+	"time"
 )
 
 func thenExpHeaders(t *testing.T) map[string]string {
@@ -26,6 +28,16 @@ func thenExpHeaders(t *testing.T) map[string]string {
 	return headers
 }
 
+// staleDataJson is a JSON response containing stale departures
+type staleDataJson struct {
+	io.ReadCloser
+	dataAge time.Duration
+}
+
+func (s *staleDataJson) DataAge() time.Duration {
+	return s.dataAge
+}
+
 func TestMetrolinkDeparturesAwsApiGateway_Handler(t *testing.T) {
 	t.Run(`Given a configured Metrolink Departures AWS API Gateway
 When Handler is called with a StopAreaCode in the path parameter
@@ -68,7 +80,7 @@ Then Metrolink departures for the StopAreaCode are returned in the response`, fu
 		pathParameters[stopAreaCodePathParameter] = stopAreaCode
 
 		metrolinkDeparturesJsonApi := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
-		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode, gomock.Nil()).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
 
 		metrolinkDeparturesAwsApiGateway := apigw.NewMetrolinkDeparturesAwsApiGateway(logger, metrolinkDeparturesJsonApi, stopAreaCodePathParameter)
 
@@ -93,6 +105,61 @@ Then Metrolink departures for the StopAreaCode are returned in the response`, fu
 		assert.Equal(t, 0, observedLogs.Len())
 	})
 
+	t.Run(`Given a configured Metrolink Departures AWS API Gateway
+When Handler is called with a StopAreaCode in the path parameter and filters in the query string parameters
+Then the query string parameters are passed to the Metrolink Departures API`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		stopAreaCode := "940GZZMASTP"
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"stopAreaCode": "940GZZMASTP",
+	"departures": [],
+	"lastUpdated": "2021-03-24T21:26:52Z"
+}`
+
+		stopAreaCodePathParameter := "stopAreaCode"
+		pathParameters := make(map[string]string)
+		pathParameters[stopAreaCodePathParameter] = stopAreaCode
+
+		queryStringParameters := map[string]string{
+			"direction": "Outgoing",
+			"limit":     "3",
+		}
+
+		metrolinkDeparturesJsonApi := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode, queryStringParameters).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkDeparturesAwsApiGateway := apigw.NewMetrolinkDeparturesAwsApiGateway(logger, metrolinkDeparturesJsonApi, stopAreaCodePathParameter)
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters:        pathParameters,
+			QueryStringParameters: queryStringParameters,
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkDeparturesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
 	t.Run(`Given a configured Metrolink Departures AWS API Gateway
 When Handler is called without a StopAreaCode in the path parameter
 Then an error response is returned`, func(t *testing.T) {
@@ -156,7 +223,7 @@ Then an error response is returned`, func(t *testing.T) {
 
 		metrolinkDeparturesJsonApi := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
 		metrolinkDeparturesJsonApiErr := errors.New("FUBAR")
-		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode).Return(nil, http.StatusInternalServerError, metrolinkDeparturesJsonApiErr)
+		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode, gomock.Nil()).Return(nil, http.StatusInternalServerError, metrolinkDeparturesJsonApiErr)
 
 		metrolinkDeparturesAwsApiGateway := apigw.NewMetrolinkDeparturesAwsApiGateway(logger, metrolinkDeparturesJsonApi, stopAreaCodePathParameter)
 
@@ -207,7 +274,7 @@ Then an error response is returned`, func(t *testing.T) {
 		pathParameters[stopAreaCodePathParameter] = stopAreaCode
 
 		metrolinkDeparturesJsonApi := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
-		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode).Return(ioutil.NopCloser(bytes.NewBufferString("")), http.StatusOK, nil)
+		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode, gomock.Nil()).Return(ioutil.NopCloser(bytes.NewBufferString("")), http.StatusOK, nil)
 
 		metrolinkDeparturesAwsApiGateway := apigw.NewMetrolinkDeparturesAwsApiGateway(logger, metrolinkDeparturesJsonApi, stopAreaCodePathParameter)
 
@@ -231,6 +298,63 @@ Then an error response is returned`, func(t *testing.T) {
 
 		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
 
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+	t.Run(`Given a configured Metrolink Departures AWS API Gateway
+When Handler is called with a StopAreaCode in the path parameter
+And the Metrolink Departures API returns stale departures
+Then the departures are returned in the response with a Warning header`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+		stopAreaCode := "940GZZMASTP"
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocation": "940GZZMASTP",
+	"departures": [],
+	"lastUpdated": "2021-03-24T21:26:52Z",
+	"stale": true,
+	"dataAgeSeconds": 95
+}`
+
+		stopAreaCodePathParameter := "stopAreaCode"
+		pathParameters := make(map[string]string)
+		pathParameters[stopAreaCodePathParameter] = stopAreaCode
+
+		metrolinkDeparturesJsonApi := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+		metrolinkDeparturesJsonApi.EXPECT().Json(ctx, stopAreaCode, gomock.Nil()).Return(&staleDataJson{
+			ReadCloser: ioutil.NopCloser(bytes.NewBufferString(apiData)),
+			dataAge:    time.Second*95 + time.Millisecond*300,
+		}, http.StatusOK, nil)
+
+		metrolinkDeparturesAwsApiGateway := apigw.NewMetrolinkDeparturesAwsApiGateway(logger, metrolinkDeparturesJsonApi, stopAreaCodePathParameter)
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters: pathParameters,
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkDeparturesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expHeaders := thenExpHeaders(t)
+		expHeaders["Warning"] = `110 - "Response is Stale: Metrolink departures were last updated 1m35s ago"`
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    expHeaders,
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
 		assert.Equal(t, 0, observedLogs.Len())
 	})
 }
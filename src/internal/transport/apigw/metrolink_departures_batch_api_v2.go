@@ -0,0 +1,82 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkDeparturesBatchAwsApiGateway struct {
+	logger                  *zap.Logger
+	batchDeparturesJsoner   core.BatchDeparturesJsoner
+	locationsQueryParameter string
+}
+
+func NewMetrolinkDeparturesBatchAwsApiGateway(logger *zap.Logger, batchDeparturesJsoner core.BatchDeparturesJsoner, locationsQueryParameter string) *MetrolinkDeparturesBatchAwsApiGateway {
+	return &MetrolinkDeparturesBatchAwsApiGateway{
+		logger:                  logger,
+		batchDeparturesJsoner:   batchDeparturesJsoner,
+		locationsQueryParameter: locationsQueryParameter,
+	}
+}
+
+// Handler reads a comma separated list of StopAreaCodes and AtcoCodes from the locations query string parameter. Other
+// query string parameters are passed on as departure filters.
+func (h *MetrolinkDeparturesBatchAwsApiGateway) Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	locations := event.QueryStringParameters[h.locationsQueryParameter]
+
+	if locations == "" {
+		h.logger.Error("no StopAreaCodes or AtcoCodes in request query string parameters")
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusBadRequest,
+			Headers:    headers,
+			Body: `{
+	"error": "no StopAreaCodes or AtcoCodes in request query string parameters"
+}`,
+		}, nil
+	}
+
+	departures, statusCode, err := h.batchDeparturesJsoner.BatchJson(ctx, strings.Split(locations, ","), event.QueryStringParameters)
+	if err != nil {
+		h.logger.Error("error with Metrolink Departures Batch API JSON response", zap.String("locations", locations), zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, departures); err != nil {
+		h.logger.Error("error reading Metrolink Departures Batch API JSON response", zap.String("locations", locations), zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	if staleData, ok := departures.(core.StaleDataJson); ok {
+		headers["Warning"] = staleDataWarning(staleData.DataAge())
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
@@ -0,0 +1,107 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestMetrolinkDeparturesBatchAwsApiGateway_Handler(t *testing.T) {
+	t.Run(`Given a configured Metrolink Departures Batch AWS API Gateway
+When Handler is called with a list of locations in the query string parameters
+Then Metrolink departures for the locations are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocations": [
+		"940GZZMASTP",
+		"9400ZZMAPIC1"
+	],
+	"locations": {},
+	"lastUpdated": "2021-03-24T21:26:52Z"
+}`
+
+		queryStringParameters := map[string]string{
+			"locations": "940GZZMASTP,9400ZZMAPIC1",
+			"limit":     "3",
+		}
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(ctx, []string{"940GZZMASTP", "9400ZZMAPIC1"}, queryStringParameters).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkDeparturesBatchAwsApiGateway := apigw.NewMetrolinkDeparturesBatchAwsApiGateway(logger, batchDeparturesJsoner, "locations")
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			QueryStringParameters: queryStringParameters,
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkDeparturesBatchAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Departures Batch AWS API Gateway
+When Handler is called without a list of locations in the query string parameters
+Then an error response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+
+		metrolinkDeparturesBatchAwsApiGateway := apigw.NewMetrolinkDeparturesBatchAwsApiGateway(logger, batchDeparturesJsoner, "locations")
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkDeparturesBatchAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusBadRequest,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "no StopAreaCodes or AtcoCodes in request query string parameters"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+	})
+}
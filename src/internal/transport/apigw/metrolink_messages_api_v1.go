@@ -0,0 +1,89 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkMessagesAwsApiGateway struct {
+	logger                              *zap.Logger
+	stopAreaMessagesJsoner              core.StopAreaMessagesJsoner
+	lineMessagesJsoner                  core.LineMessagesJsoner
+	stopAreaCodeOrAtcoCodePathParameter string
+	linePathParameter                   string
+}
+
+func NewMetrolinkMessagesAwsApiGateway(logger *zap.Logger, stopAreaMessagesJsoner core.StopAreaMessagesJsoner, lineMessagesJsoner core.LineMessagesJsoner, stopAreaCodeOrAtcoCodePathParameter string, linePathParameter string) *MetrolinkMessagesAwsApiGateway {
+	return &MetrolinkMessagesAwsApiGateway{
+		logger:                              logger,
+		stopAreaMessagesJsoner:              stopAreaMessagesJsoner,
+		lineMessagesJsoner:                  lineMessagesJsoner,
+		stopAreaCodeOrAtcoCodePathParameter: stopAreaCodeOrAtcoCodePathParameter,
+		linePathParameter:                   linePathParameter,
+	}
+}
+
+func (h *MetrolinkMessagesAwsApiGateway) Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	var messages io.ReadCloser
+	var statusCode int
+	var err error
+
+	if line := event.PathParameters[h.linePathParameter]; line != "" {
+		messages, statusCode, err = h.lineMessagesJsoner.LineMessagesJson(ctx, line)
+	} else if stopAreaCodeOrAtcoCode := event.PathParameters[h.stopAreaCodeOrAtcoCodePathParameter]; stopAreaCodeOrAtcoCode != "" {
+		messages, statusCode, err = h.stopAreaMessagesJsoner.MessagesJson(ctx, stopAreaCodeOrAtcoCode)
+	} else {
+		h.logger.Error("no StopAreaCode, AtcoCode or Line in request path parameters")
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusBadRequest,
+			Headers:    headers,
+			Body: `{
+	"error": "no StopAreaCode or Line in request path parameters"
+}`,
+		}, nil
+	}
+
+	if err != nil {
+		h.logger.Error("error with Metrolink Messages API JSON response", zap.Any("pathParameters", event.PathParameters), zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, messages); err != nil {
+		h.logger.Error("error reading Metrolink Messages API JSON response", zap.Any("pathParameters", event.PathParameters), zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	if staleData, ok := messages.(core.StaleDataJson); ok {
+		headers["Warning"] = staleDataWarning(staleData.DataAge())
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
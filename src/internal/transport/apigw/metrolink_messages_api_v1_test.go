@@ -0,0 +1,254 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkMessagesAwsApiGateway_Handler(t *testing.T) {
+	t.Run(`Given a configured Metrolink Messages AWS API Gateway
+When Handler is called with a StopAreaCode in the path parameter
+Then Metrolink messages for the StopAreaCode are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocation": "940GZZMASTP",
+	"messages": []
+}`
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+		stopAreaMessagesJsoner.EXPECT().MessagesJson(ctx, "940GZZMASTP").Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCode", "line")
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{
+				"stopAreaCode": "940GZZMASTP",
+			},
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkMessagesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Messages AWS API Gateway
+When Handler is called with a Line in the path parameter
+Then Metrolink messages for the Line are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLine": "Eccles",
+	"messages": []
+}`
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+		lineMessagesJsoner.EXPECT().LineMessagesJson(ctx, "Eccles").Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCode", "line")
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{
+				"line": "Eccles",
+			},
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkMessagesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Messages AWS API Gateway
+When Handler is called without a StopAreaCode or Line in the path parameters
+Then an error response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCode", "line")
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkMessagesAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusBadRequest,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "no StopAreaCode or Line in request path parameters"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+	})
+
+	t.Run(`Given a configured Metrolink Messages AWS API Gateway
+When Handler is called with a Line in the path parameter
+And the Metrolink Messages API returns stale messages
+Then the messages are returned in the response with a Warning header`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLine": "Eccles",
+	"messages": [],
+	"stale": true,
+	"dataAgeSeconds": 95
+}`
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+		lineMessagesJsoner.EXPECT().LineMessagesJson(ctx, "Eccles").Return(&staleDataJson{
+			ReadCloser: ioutil.NopCloser(bytes.NewBufferString(apiData)),
+			dataAge:    time.Second*95 + time.Millisecond*300,
+		}, http.StatusOK, nil)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCode", "line")
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{
+				"line": "Eccles",
+			},
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkMessagesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expHeaders := thenExpHeaders(t)
+		expHeaders["Warning"] = `110 - "Response is Stale: Metrolink departures were last updated 1m35s ago"`
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    expHeaders,
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given an error occurs retrieving Metrolink Messages API data
+When Handler is called with a StopAreaCode in the path parameter
+Then an error response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+		stopAreaMessagesJsonerErr := errors.New("FUBAR")
+		stopAreaMessagesJsoner.EXPECT().MessagesJson(ctx, "940GZZMASTP").Return(nil, http.StatusInternalServerError, stopAreaMessagesJsonerErr)
+
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+
+		metrolinkMessagesAwsApiGateway := apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCode", "line")
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			PathParameters: map[string]string{
+				"stopAreaCode": "940GZZMASTP",
+			},
+		}
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkMessagesAwsApiGateway.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "internal server error"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, "error with Metrolink Messages API JSON response", observedLogs.All()[0].Message)
+	})
+}
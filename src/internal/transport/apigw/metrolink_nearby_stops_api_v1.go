@@ -0,0 +1,61 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkNearbyStopsAwsApiGateway struct {
+	logger            *zap.Logger
+	nearbyStopsJsoner core.NearbyStopsJsoner
+}
+
+func NewMetrolinkNearbyStopsAwsApiGateway(logger *zap.Logger, nearbyStopsJsoner core.NearbyStopsJsoner) *MetrolinkNearbyStopsAwsApiGateway {
+	return &MetrolinkNearbyStopsAwsApiGateway{
+		logger:            logger,
+		nearbyStopsJsoner: nearbyStopsJsoner,
+	}
+}
+
+// Handler passes the query string parameters, which include the location to search around, to the nearby stops API
+func (h *MetrolinkNearbyStopsAwsApiGateway) Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	nearbyStops, statusCode, err := h.nearbyStopsJsoner.NearbyJson(ctx, event.QueryStringParameters)
+	if err != nil {
+		h.logger.Error("error with Metrolink Nearby Stops API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, nearbyStops); err != nil {
+		h.logger.Error("error reading Metrolink Nearby Stops API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
@@ -0,0 +1,112 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestMetrolinkNearbyStopsAwsApiGateway_Handler(t *testing.T) {
+	t.Run(`Given a configured Metrolink Nearby Stops AWS API Gateway
+When Handler is called with a location in the query string parameters
+Then the nearby stop areas and their status code are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		queryStringParameters := map[string]string{
+			"latitude":  "53.4776",
+			"longitude": "-2.2301",
+		}
+
+		apiData := `{
+	"latitude": 53.4776,
+	"longitude": -2.2301,
+	"radiusMetres": 1000,
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"distanceMetres": 11
+		}
+	]
+}`
+
+		nearbyStopsJsoner := mock_core.NewMockNearbyStopsJsoner(ctrl)
+		nearbyStopsJsoner.EXPECT().NearbyJson(ctx, queryStringParameters).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkNearbyStopsAwsApiGateway := apigw.NewMetrolinkNearbyStopsAwsApiGateway(logger, nearbyStopsJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkNearbyStopsAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{
+			QueryStringParameters: queryStringParameters,
+		})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Nearby Stops AWS API Gateway
+When Handler is called
+And an error occurs searching for nearby stop areas
+Then an error response is returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		nearbyStopsJsoner := mock_core.NewMockNearbyStopsJsoner(ctrl)
+		nearbyStopsJsoner.EXPECT().NearbyJson(ctx, gomock.Any()).Return(nil, http.StatusInternalServerError, errors.New("FUBAR"))
+
+		metrolinkNearbyStopsAwsApiGateway := apigw.NewMetrolinkNearbyStopsAwsApiGateway(logger, nearbyStopsJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkNearbyStopsAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "internal server error"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+	})
+}
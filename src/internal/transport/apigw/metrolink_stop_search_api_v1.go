@@ -0,0 +1,61 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkStopSearchAwsApiGateway struct {
+	logger           *zap.Logger
+	stopSearchJsoner core.StopSearchJsoner
+}
+
+func NewMetrolinkStopSearchAwsApiGateway(logger *zap.Logger, stopSearchJsoner core.StopSearchJsoner) *MetrolinkStopSearchAwsApiGateway {
+	return &MetrolinkStopSearchAwsApiGateway{
+		logger:           logger,
+		stopSearchJsoner: stopSearchJsoner,
+	}
+}
+
+// Handler passes the query string parameters, which include the search query, to the stop search API
+func (h *MetrolinkStopSearchAwsApiGateway) Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	stopSearchResults, statusCode, err := h.stopSearchJsoner.SearchJson(ctx, event.QueryStringParameters)
+	if err != nil {
+		h.logger.Error("error with Metrolink Stop Search API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, stopSearchResults); err != nil {
+		h.logger.Error("error reading Metrolink Stop Search API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestMetrolinkStopSearchAwsApiGateway_Handler(t *testing.T) {
+	t.Run(`Given a configured Metrolink Stop Search AWS API Gateway
+When Handler is called with a search query in the query string parameters
+Then the matching stop areas and their status code are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		queryStringParameters := map[string]string{
+			"q": "picadilly",
+		}
+
+		apiData := `{
+	"query": "picadilly",
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"score": 60
+		}
+	]
+}`
+
+		stopSearchJsoner := mock_core.NewMockStopSearchJsoner(ctrl)
+		stopSearchJsoner.EXPECT().SearchJson(ctx, queryStringParameters).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkStopSearchAwsApiGateway := apigw.NewMetrolinkStopSearchAwsApiGateway(logger, stopSearchJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkStopSearchAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{
+			QueryStringParameters: queryStringParameters,
+		})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Stop Search AWS API Gateway
+When Handler is called
+And an error occurs searching for stop areas
+Then an error response is returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopSearchJsoner := mock_core.NewMockStopSearchJsoner(ctrl)
+		stopSearchJsoner.EXPECT().SearchJson(ctx, gomock.Any()).Return(nil, http.StatusInternalServerError, errors.New("FUBAR"))
+
+		metrolinkStopSearchAwsApiGateway := apigw.NewMetrolinkStopSearchAwsApiGateway(logger, stopSearchJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkStopSearchAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "internal server error"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+	})
+}
@@ -0,0 +1,60 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkStopsAwsApiGateway struct {
+	logger              *zap.Logger
+	stopDirectoryJsoner core.StopDirectoryJsoner
+}
+
+func NewMetrolinkStopsAwsApiGateway(logger *zap.Logger, stopDirectoryJsoner core.StopDirectoryJsoner) *MetrolinkStopsAwsApiGateway {
+	return &MetrolinkStopsAwsApiGateway{
+		logger:              logger,
+		stopDirectoryJsoner: stopDirectoryJsoner,
+	}
+}
+
+func (h *MetrolinkStopsAwsApiGateway) Handler(ctx context.Context, _ events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	stops, statusCode, err := h.stopDirectoryJsoner.StopsJson(ctx)
+	if err != nil {
+		h.logger.Error("error with Metrolink Stops API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, stops); err != nil {
+		h.logger.Error("error reading Metrolink Stops API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
@@ -0,0 +1,109 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestMetrolinkStopsAwsApiGateway_Handler(t *testing.T) {
+	t.Run(`Given a configured Metrolink Stops AWS API Gateway
+When Handler is called
+Then the stop directory and its status code are returned in the response`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"stopAreas": [
+		{
+			"stopAreaCode": "940GZZMAPIC",
+			"stationLocation": "Piccadilly",
+			"tlaref": "PIC",
+			"stops": [
+				{
+					"atcoCode": "9400ZZMAPIC1",
+					"platform": "A"
+				}
+			]
+		}
+	]
+}`
+
+		stopDirectoryJsoner := mock_core.NewMockStopDirectoryJsoner(ctrl)
+		stopDirectoryJsoner.EXPECT().StopsJson(ctx).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		metrolinkStopsAwsApiGateway := apigw.NewMetrolinkStopsAwsApiGateway(logger, stopDirectoryJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkStopsAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a configured Metrolink Stops AWS API Gateway
+When Handler is called
+And an error occurs generating the stop directory
+Then an error response is returned
+And an error is logged`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopDirectoryJsoner := mock_core.NewMockStopDirectoryJsoner(ctrl)
+		stopDirectoryJsoner.EXPECT().StopsJson(ctx).Return(nil, http.StatusInternalServerError, errors.New("FUBAR"))
+
+		metrolinkStopsAwsApiGateway := apigw.NewMetrolinkStopsAwsApiGateway(logger, stopDirectoryJsoner)
+
+		// When
+		apiGatewayProxyResponse, err := metrolinkStopsAwsApiGateway.Handler(ctx, events.APIGatewayProxyRequest{})
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "internal server error"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+	})
+}
@@ -0,0 +1,60 @@
+package apigw
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type MetrolinkSystemStatusAwsApiGateway struct {
+	logger             *zap.Logger
+	systemStatusJsoner core.SystemStatusJsoner
+}
+
+func NewMetrolinkSystemStatusAwsApiGateway(logger *zap.Logger, systemStatusJsoner core.SystemStatusJsoner) *MetrolinkSystemStatusAwsApiGateway {
+	return &MetrolinkSystemStatusAwsApiGateway{
+		logger:             logger,
+		systemStatusJsoner: systemStatusJsoner,
+	}
+}
+
+func (h *MetrolinkSystemStatusAwsApiGateway) Handler(ctx context.Context, _ events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	headers := make(map[string]string)
+	headers["Content-type"] = "application/json"
+
+	systemStatus, statusCode, err := h.systemStatusJsoner.StatusJson(ctx)
+	if err != nil {
+		h.logger.Error("error with Metrolink System Status API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: statusCode,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	buf := new(strings.Builder)
+	if _, err := io.Copy(buf, systemStatus); err != nil {
+		h.logger.Error("error reading Metrolink System Status API JSON response", zap.Error(err))
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusInternalServerError,
+			Headers:    headers,
+			Body: `{
+	"error": "internal server error"
+}`,
+		}, nil
+	}
+
+	return &events.APIGatewayProxyResponse{
+		StatusCode: statusCode,
+		Headers:    headers,
+		Body:       buf.String(),
+	}, nil
+}
@@ -0,0 +1,47 @@
+package apigw
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type AwsApiGatewayHandler interface {
+	Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error)
+}
+
+// Router dispatches API Gateway proxy requests to a handler based on the API Gateway resource path, which allows a
+// single Lambda function to serve several routes
+type Router struct {
+	logger *zap.Logger
+	routes map[string]AwsApiGatewayHandler
+}
+
+func NewRouter(logger *zap.Logger, routes map[string]AwsApiGatewayHandler) *Router {
+	return &Router{
+		logger: logger,
+		routes: routes,
+	}
+}
+
+func (r *Router) Handler(ctx context.Context, event events.APIGatewayProxyRequest) (*events.APIGatewayProxyResponse, error) {
+	handler, ok := r.routes[event.Resource]
+	if !ok {
+		r.logger.Error("no handler for API Gateway resource", zap.String("resource", event.Resource))
+
+		headers := make(map[string]string)
+		headers["Content-type"] = "application/json"
+
+		return &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusNotFound,
+			Headers:    headers,
+			Body: fmt.Sprintf(`{
+	"error": "no route for resource %s"
+}`, event.Resource),
+		}, nil
+	}
+
+	return handler.Handler(ctx, event)
+}
@@ -0,0 +1,108 @@
+package apigw_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestRouter_Handler(t *testing.T) {
+	t.Run(`Given a Router with a route for a resource
+When Handler is called with a request for that resource
+Then the request is handled by the handler for that resource`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocation": "940GZZMASTP",
+	"messages": []
+}`
+
+		stopAreaMessagesJsoner := mock_core.NewMockStopAreaMessagesJsoner(ctrl)
+		stopAreaMessagesJsoner.EXPECT().MessagesJson(ctx, "940GZZMASTP").Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		lineMessagesJsoner := mock_core.NewMockLineMessagesJsoner(ctrl)
+
+		stopAreaDeparturesJsoner := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+
+		router := apigw.NewRouter(logger, map[string]apigw.AwsApiGatewayHandler{
+			"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}":          apigw.NewMetrolinkDeparturesAwsApiGateway(logger, stopAreaDeparturesJsoner, "stopAreaCodeOrAtcoCode"),
+			"/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}/messages": apigw.NewMetrolinkMessagesAwsApiGateway(logger, stopAreaMessagesJsoner, lineMessagesJsoner, "stopAreaCodeOrAtcoCode", "line"),
+		})
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			Resource: "/departures/metrolink/v1/{stopAreaCodeOrAtcoCode}/messages",
+			PathParameters: map[string]string{
+				"stopAreaCodeOrAtcoCode": "940GZZMASTP",
+			},
+		}
+
+		// When
+		apiGatewayProxyResponse, err := router.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusOK,
+			Headers:    thenExpHeaders(t),
+			Body:       apiData,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a Router without a route for a resource
+When Handler is called with a request for that resource
+Then a not found response is returned`, func(t *testing.T) {
+		// Given
+		ctx := context.Background()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		router := apigw.NewRouter(logger, map[string]apigw.AwsApiGatewayHandler{})
+
+		apiGatewayProxyRequest := events.APIGatewayProxyRequest{
+			Resource: "/unknown",
+		}
+
+		// When
+		apiGatewayProxyResponse, err := router.Handler(ctx, apiGatewayProxyRequest)
+
+		// Then
+		assert.Nil(t, err)
+
+		expApiGatewayProxyResponse := &events.APIGatewayProxyResponse{
+			StatusCode: http.StatusNotFound,
+			Headers:    thenExpHeaders(t),
+			Body: `{
+	"error": "no route for resource /unknown"
+}`,
+		}
+
+		assert.EqualValues(t, expApiGatewayProxyResponse, apiGatewayProxyResponse)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+		assert.Equal(t, "no handler for API Gateway resource", observedLogs.All()[0].Message)
+	})
+}
@@ -0,0 +1,70 @@
+package httpserver
+
+import (
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// RequestLogger logs the method, path, status code, response size and duration of every request handled by the next
+// handler
+type RequestLogger struct {
+	logger          *zap.Logger
+	next            http.Handler
+	currentTimeFunc func() time.Time
+}
+
+func NewRequestLogger(logger *zap.Logger, next http.Handler, currentTimeFunc func() time.Time) *RequestLogger {
+	return &RequestLogger{
+		logger:          logger,
+		next:            next,
+		currentTimeFunc: currentTimeFunc,
+	}
+}
+
+func (l *RequestLogger) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	start := l.currentTimeFunc()
+
+	rw := &responseRecorder{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+
+	l.next.ServeHTTP(rw, req)
+
+	l.logger.Info("handled request",
+		zap.String("method", req.Method),
+		zap.String("path", req.URL.Path),
+		zap.String("query", req.URL.RawQuery),
+		zap.String("remoteAddr", req.RemoteAddr),
+		zap.Int("statusCode", rw.statusCode),
+		zap.Int("bytes", rw.bytes),
+		zap.Duration("duration", l.currentTimeFunc().Sub(start)),
+	)
+}
+
+// responseRecorder records the status code and number of bytes written to a http.ResponseWriter
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+
+	return n, err
+}
@@ -0,0 +1,91 @@
+package httpserver_test
+
+import (
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/httpserver"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRequestLogger_ServeHTTP(t *testing.T) {
+	t.Run(`Given a RequestLogger
+When ServeHTTP is called
+Then the request is handled by the next handler
+And the request is logged with its status code, response size and duration`, func(t *testing.T) {
+		// Given
+		zapCore, observedLogs := observer.New(zap.InfoLevel)
+		logger := zap.New(zapCore)
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+			_, _ = w.Write([]byte("short and stout"))
+		})
+
+		start := time.Date(2021, time.April, 24, 2, 24, 44, 0, time.UTC)
+		times := []time.Time{start, start.Add(25 * time.Millisecond)}
+
+		currentTimeFunc := func() time.Time {
+			t := times[0]
+			times = times[1:]
+			return t
+		}
+
+		requestLogger := httpserver.NewRequestLogger(logger, next, currentTimeFunc)
+
+		req := httptest.NewRequest(http.MethodGet, "/status?verbose=true", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		requestLogger.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, "short and stout", rec.Body.String())
+
+		assert.Equal(t, 1, observedLogs.Len())
+
+		log := observedLogs.All()[0]
+		assert.Equal(t, zapcore.InfoLevel, log.Level)
+		assert.Equal(t, "handled request", log.Message)
+
+		fields := log.ContextMap()
+		assert.Equal(t, http.MethodGet, fields["method"])
+		assert.Equal(t, "/status", fields["path"])
+		assert.Equal(t, "verbose=true", fields["query"])
+		assert.Equal(t, int64(http.StatusTeapot), fields["statusCode"])
+		assert.Equal(t, int64(15), fields["bytes"])
+		assert.Equal(t, 25*time.Millisecond, fields["duration"])
+	})
+
+	t.Run(`Given a RequestLogger
+When ServeHTTP is called
+And the next handler writes a body without a status code
+Then the request is logged with a 200 status code`, func(t *testing.T) {
+		// Given
+		zapCore, observedLogs := observer.New(zap.InfoLevel)
+		logger := zap.New(zapCore)
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			_, _ = w.Write([]byte("{}"))
+		})
+
+		requestLogger := httpserver.NewRequestLogger(logger, next, time.Now)
+
+		req := httptest.NewRequest(http.MethodGet, "/status", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		requestLogger.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, int64(http.StatusOK), observedLogs.All()[0].ContextMap()["statusCode"])
+	})
+}
@@ -0,0 +1,170 @@
+package httpserver
+
+import (
+	"fmt"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/aws/aws-lambda-go/events"
+	"go.uber.org/zap"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Router serves HTTP requests with the API Gateway handlers used by the API Lambda function. Routes are keyed by the
+// same API Gateway resource paths, e.g. /departures/metrolink/v1/{stopAreaCodeOrAtcoCode}, so that the request path is
+// matched to a resource and its path parameters in the same way as API Gateway.
+type Router struct {
+	logger *zap.Logger
+	routes []*route
+}
+
+type route struct {
+	resource string
+	segments []string
+	handler  apigw.AwsApiGatewayHandler
+}
+
+func NewRouter(logger *zap.Logger, routes map[string]apigw.AwsApiGatewayHandler) *Router {
+	r := &Router{
+		logger: logger,
+	}
+
+	for resource, handler := range routes {
+		r.routes = append(r.routes, &route{
+			resource: resource,
+			segments: pathSegments(resource),
+			handler:  handler,
+		})
+	}
+
+	// Like API Gateway, a resource with a literal path segment is preferred over a path parameter in the same position,
+	// so that e.g. /departures/metrolink/v2/batch is not routed to /departures/metrolink/v2/{stopAreaCodeOrAtcoCode}
+	sort.Slice(r.routes, func(i, j int) bool {
+		return r.routes[i].less(r.routes[j])
+	})
+
+	return r
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodHead}, ", "))
+		writeJsonError(w, http.StatusMethodNotAllowed, fmt.Sprintf("method %s not allowed", req.Method))
+		return
+	}
+
+	segments := pathSegments(req.URL.Path)
+
+	for _, rt := range r.routes {
+		pathParameters, ok := rt.match(segments)
+		if !ok {
+			continue
+		}
+
+		apiGatewayProxyResponse, err := rt.handler.Handler(req.Context(), events.APIGatewayProxyRequest{
+			Resource:              rt.resource,
+			Path:                  req.URL.Path,
+			HTTPMethod:            req.Method,
+			PathParameters:        pathParameters,
+			QueryStringParameters: queryStringParameters(req),
+		})
+		if err != nil {
+			r.logger.Error("error handling request", zap.String("resource", rt.resource), zap.String("path", req.URL.Path), zap.Error(err))
+			writeJsonError(w, http.StatusInternalServerError, "internal server error")
+			return
+		}
+
+		for k, v := range apiGatewayProxyResponse.Headers {
+			w.Header().Set(k, v)
+		}
+
+		w.WriteHeader(apiGatewayProxyResponse.StatusCode)
+
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		if _, err := w.Write([]byte(apiGatewayProxyResponse.Body)); err != nil {
+			r.logger.Error("error writing response", zap.String("resource", rt.resource), zap.String("path", req.URL.Path), zap.Error(err))
+		}
+
+		return
+	}
+
+	writeJsonError(w, http.StatusNotFound, fmt.Sprintf("no route for path %s", req.URL.Path))
+}
+
+// match returns the path parameters from the segments of a request path if the request path matches the route
+func (rt *route) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(rt.segments) {
+		return nil, false
+	}
+
+	pathParameters := make(map[string]string)
+
+	for i, segment := range rt.segments {
+		if name, ok := pathParameterName(segment); ok {
+			if segments[i] == "" {
+				return nil, false
+			}
+
+			pathParameters[name] = segments[i]
+			continue
+		}
+
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return pathParameters, true
+}
+
+func (rt *route) less(other *route) bool {
+	for i := 0; i < len(rt.segments) && i < len(other.segments); i++ {
+		_, isParameter := pathParameterName(rt.segments[i])
+		_, otherIsParameter := pathParameterName(other.segments[i])
+
+		if isParameter != otherIsParameter {
+			return otherIsParameter
+		}
+	}
+
+	return rt.resource < other.resource
+}
+
+func pathSegments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func pathParameterName(segment string) (string, bool) {
+	if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") {
+		return "", false
+	}
+
+	return strings.TrimSuffix(strings.TrimPrefix(segment, "{"), "}"), true
+}
+
+// queryStringParameters returns the first value of each query string parameter, as API Gateway does for
+// QueryStringParameters
+func queryStringParameters(req *http.Request) map[string]string {
+	query := req.URL.Query()
+	if len(query) == 0 {
+		return nil
+	}
+
+	queryStringParameters := make(map[string]string)
+	for k, v := range query {
+		queryStringParameters[k] = v[0]
+	}
+
+	return queryStringParameters
+}
+
+func writeJsonError(w http.ResponseWriter, statusCode int, errorMsg string) {
+	w.Header().Set("Content-type", "application/json")
+	w.WriteHeader(statusCode)
+	_, _ = fmt.Fprintf(w, `{
+	"error": %q
+}`, errorMsg)
+}
@@ -0,0 +1,179 @@
+package httpserver_test
+
+import (
+	"bytes"
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/apigw"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/httpserver"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func givenRouter(t *testing.T, logger *zap.Logger, stopAreaDeparturesJsoner *mock_core.MockStopAreaDeparturesJsoner, batchDeparturesJsoner *mock_core.MockBatchDeparturesJsoner) *httpserver.Router {
+	t.Helper()
+
+	return httpserver.NewRouter(logger, map[string]apigw.AwsApiGatewayHandler{
+		"/departures/metrolink/v2/{stopAreaCodeOrAtcoCode}": apigw.NewMetrolinkDeparturesAwsApiGateway(logger, stopAreaDeparturesJsoner, "stopAreaCodeOrAtcoCode"),
+		"/departures/metrolink/v2/batch":                    apigw.NewMetrolinkDeparturesBatchAwsApiGateway(logger, batchDeparturesJsoner, "locations"),
+	})
+}
+
+func TestRouter_ServeHTTP(t *testing.T) {
+	t.Run(`Given a Router with a route for a resource with a path parameter
+When ServeHTTP is called with a request path matching that resource
+Then the request is handled by the handler for that resource with the path and query string parameters`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocation": "940GZZMASTP",
+	"departures": []
+}`
+
+		stopAreaDeparturesJsoner := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+		stopAreaDeparturesJsoner.EXPECT().Json(gomock.Any(), "940GZZMASTP", map[string]string{"line": "Airport", "limit": "3"}).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+
+		router := givenRouter(t, logger, stopAreaDeparturesJsoner, batchDeparturesJsoner)
+
+		req := httptest.NewRequest(http.MethodGet, "/departures/metrolink/v2/940GZZMASTP?line=Airport&limit=3&limit=4", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		router.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-type"))
+		assert.Equal(t, apiData, rec.Body.String())
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a Router with a route for a resource with a literal path segment
+And a route for a resource with a path parameter in the same position
+When ServeHTTP is called with a request path matching the literal path segment
+Then the request is handled by the handler for the resource with the literal path segment`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		apiData := `{
+	"requestedLocations": ["940GZZMASTP", "9400ZZMAPIC1"]
+}`
+
+		stopAreaDeparturesJsoner := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+
+		batchDeparturesJsoner := mock_core.NewMockBatchDeparturesJsoner(ctrl)
+		batchDeparturesJsoner.EXPECT().BatchJson(gomock.Any(), []string{"940GZZMASTP", "9400ZZMAPIC1"}, map[string]string{"locations": "940GZZMASTP,9400ZZMAPIC1"}).Return(ioutil.NopCloser(bytes.NewBufferString(apiData)), http.StatusOK, nil)
+
+		router := givenRouter(t, logger, stopAreaDeparturesJsoner, batchDeparturesJsoner)
+
+		req := httptest.NewRequest(http.MethodGet, "/departures/metrolink/v2/batch?locations=940GZZMASTP,9400ZZMAPIC1", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		router.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, apiData, rec.Body.String())
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a Router
+When ServeHTTP is called with a request path which does not match any resource
+Then a not found response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zapCore, _ := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		router := givenRouter(t, logger, mock_core.NewMockStopAreaDeparturesJsoner(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		req := httptest.NewRequest(http.MethodGet, "/departures/metrolink/v2/940GZZMASTP/unknown", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		router.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-type"))
+		assert.Equal(t, `{
+	"error": "no route for path /departures/metrolink/v2/940GZZMASTP/unknown"
+}`, rec.Body.String())
+	})
+
+	t.Run(`Given a Router
+When ServeHTTP is called with a POST request
+Then a method not allowed response is returned`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zapCore, _ := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		router := givenRouter(t, logger, mock_core.NewMockStopAreaDeparturesJsoner(ctrl), mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		req := httptest.NewRequest(http.MethodPost, "/departures/metrolink/v2/940GZZMASTP", nil)
+		rec := httptest.NewRecorder()
+
+		// When
+		router.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, "GET, HEAD", rec.Header().Get("Allow"))
+		assert.Equal(t, `{
+	"error": "method POST not allowed"
+}`, rec.Body.String())
+	})
+
+	t.Run(`Given a Router
+When ServeHTTP is called with a request whose context is cancelled
+Then the cancelled context is passed to the handler`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		zapCore, _ := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		stopAreaDeparturesJsoner := mock_core.NewMockStopAreaDeparturesJsoner(ctrl)
+		stopAreaDeparturesJsoner.EXPECT().Json(ctx, "940GZZMASTP", nil).Return(ioutil.NopCloser(bytes.NewBufferString("{}")), http.StatusOK, nil)
+
+		router := givenRouter(t, logger, stopAreaDeparturesJsoner, mock_core.NewMockBatchDeparturesJsoner(ctrl))
+
+		req := httptest.NewRequest(http.MethodGet, "/departures/metrolink/v2/940GZZMASTP", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		// When
+		router.ServeHTTP(rec, req)
+
+		// Then
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
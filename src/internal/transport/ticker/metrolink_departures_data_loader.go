@@ -0,0 +1,44 @@
+package ticker
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"go.uber.org/zap"
+	"time"
+)
+
+// MetrolinkDeparturesDataLoader loads Metrolink departures in process at a fixed interval, in place of the scheduler and
+// SQS data loader Lambda functions
+type MetrolinkDeparturesDataLoader struct {
+	logger                    *zap.Logger
+	metrolinkDeparturesLoader core.MetrolinkDeparturesLoader
+	interval                  time.Duration
+}
+
+func NewMetrolinkDeparturesDataLoader(logger *zap.Logger, metrolinkDeparturesLoader core.MetrolinkDeparturesLoader, interval time.Duration) *MetrolinkDeparturesDataLoader {
+	return &MetrolinkDeparturesDataLoader{
+		logger:                    logger,
+		metrolinkDeparturesLoader: metrolinkDeparturesLoader,
+		interval:                  interval,
+	}
+}
+
+// Run loads Metrolink departures immediately and then once per interval until the context is done. A failed load is
+// logged and retried at the next interval. Loads never overlap: if a load takes longer than the interval, the next
+// load starts when it finishes.
+func (m *MetrolinkDeparturesDataLoader) Run(ctx context.Context) {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+
+	for {
+		if err := m.metrolinkDeparturesLoader.Load(ctx); err != nil {
+			m.logger.Error("error loading Metrolink departures", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
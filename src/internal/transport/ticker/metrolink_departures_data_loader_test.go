@@ -0,0 +1,88 @@
+package ticker_test
+
+import (
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/ticker"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func TestMetrolinkDeparturesDataLoader_Run(t *testing.T) {
+	t.Run(`Given a Metrolink departures data loader
+When Run is called
+Then Metrolink departures are loaded at each interval until the context is done`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		loads := 0
+
+		metrolinkDeparturesLoader := mock_core.NewMockMetrolinkDeparturesLoader(ctrl)
+		metrolinkDeparturesLoader.EXPECT().Load(ctx).DoAndReturn(func(ctx context.Context) error {
+			loads++
+			if loads == 3 {
+				cancel()
+			}
+
+			return nil
+		}).Times(3)
+
+		metrolinkDeparturesDataLoader := ticker.NewMetrolinkDeparturesDataLoader(logger, metrolinkDeparturesLoader, time.Millisecond)
+
+		// When
+		metrolinkDeparturesDataLoader.Run(ctx)
+
+		// Then
+		assert.Equal(t, 3, loads)
+
+		assert.Equal(t, 0, observedLogs.Len())
+	})
+
+	t.Run(`Given a Metrolink departures data loader
+When Run is called
+And an error occurs loading Metrolink departures
+Then the error is logged
+And Metrolink departures are loaded again at the next interval`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		metrolinkDeparturesLoader := mock_core.NewMockMetrolinkDeparturesLoader(ctrl)
+		gomock.InOrder(
+			metrolinkDeparturesLoader.EXPECT().Load(ctx).Return(errors.New("FUBAR")),
+			metrolinkDeparturesLoader.EXPECT().Load(ctx).DoAndReturn(func(ctx context.Context) error {
+				cancel()
+				return nil
+			}),
+		)
+
+		metrolinkDeparturesDataLoader := ticker.NewMetrolinkDeparturesDataLoader(logger, metrolinkDeparturesLoader, time.Millisecond)
+
+		// When
+		metrolinkDeparturesDataLoader.Run(ctx)
+
+		// Then
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+		assert.Equal(t, "error loading Metrolink departures", observedLogs.All()[0].Message)
+	})
+}
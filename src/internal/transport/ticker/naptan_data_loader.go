@@ -0,0 +1,43 @@
+package ticker
+
+import (
+	"context"
+	"github.com/Marchie/tf-experiment/lambda/internal/core"
+	"go.uber.org/zap"
+	"time"
+)
+
+// NaptanDataLoader loads NaPTAN stops in area in process at a fixed interval, in place of the NaPTAN data loader Lambda
+// function
+type NaptanDataLoader struct {
+	logger            *zap.Logger
+	stopsInAreaLoader core.NaptanStopsInAreaLoader
+	interval          time.Duration
+}
+
+func NewNaptanDataLoader(logger *zap.Logger, stopsInAreaLoader core.NaptanStopsInAreaLoader, interval time.Duration) *NaptanDataLoader {
+	return &NaptanDataLoader{
+		logger:            logger,
+		stopsInAreaLoader: stopsInAreaLoader,
+		interval:          interval,
+	}
+}
+
+// Run loads NaPTAN stops in area immediately and then once per interval until the context is done. A failed load is
+// logged and retried at the next interval.
+func (n *NaptanDataLoader) Run(ctx context.Context) {
+	t := time.NewTicker(n.interval)
+	defer t.Stop()
+
+	for {
+		if err := n.stopsInAreaLoader.LoadStopsInArea(ctx); err != nil {
+			n.logger.Error("error loading NaPTAN stops in area", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package ticker_test
+
+import (
+	"context"
+	mock_core "github.com/Marchie/tf-experiment/lambda/internal/mocks/core"
+	"github.com/Marchie/tf-experiment/lambda/internal/transport/ticker"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func TestNaptanDataLoader_Run(t *testing.T) {
+	t.Run(`Given a NaPTAN data loader
+When Run is called
+And an error occurs loading NaPTAN stops in area
+Then the error is logged
+And NaPTAN stops in area are loaded again at the next interval until the context is done`, func(t *testing.T) {
+		// Given
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		zapCore, observedLogs := observer.New(zap.ErrorLevel)
+		logger := zap.New(zapCore)
+
+		stopsInAreaLoader := mock_core.NewMockNaptanStopsInAreaLoader(ctrl)
+		gomock.InOrder(
+			stopsInAreaLoader.EXPECT().LoadStopsInArea(ctx).Return(errors.New("FUBAR")),
+			stopsInAreaLoader.EXPECT().LoadStopsInArea(ctx).DoAndReturn(func(ctx context.Context) error {
+				cancel()
+				return nil
+			}),
+		)
+
+		naptanDataLoader := ticker.NewNaptanDataLoader(logger, stopsInAreaLoader, time.Millisecond)
+
+		// When
+		naptanDataLoader.Run(ctx)
+
+		// Then
+		assert.Equal(t, 1, observedLogs.Len())
+		assert.Equal(t, zapcore.ErrorLevel, observedLogs.All()[0].Level)
+		assert.Equal(t, "error loading NaPTAN stops in area", observedLogs.All()[0].Message)
+	})
+}
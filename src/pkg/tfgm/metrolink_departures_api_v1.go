@@ -2,19 +2,33 @@ package tfgm
 
 import "time"
 
+// MetrolinkDepartures are the departures from a stop area or stop. When a stop is requested, StopAreaCode is its stop
+// area and OtherPlatforms are the AtcoCodes of the other stops in the stop area. Stale is true, and DataAgeSeconds is
+// the age of the departures, when the last known departures are served because departures could not be updated from the
+// source.
 type MetrolinkDepartures struct {
 	RequestedLocation string                `json:"requestedLocation"`
+	StopAreaCode      string                `json:"stopAreaCode,omitempty"`
+	OtherPlatforms    []string              `json:"otherPlatforms,omitempty"`
 	Departures        []*MetrolinkDeparture `json:"departures"`
+	Messages          []*MetrolinkMessage   `json:"messages,omitempty"`
 	LastUpdated       time.Time             `json:"lastUpdated"`
+	Stale             bool                  `json:"stale,omitempty"`
+	DataAgeSeconds    int                   `json:"dataAgeSeconds,omitempty"`
 }
 
+// MetrolinkDeparture is a departure from a stop. ExpectedDepartureTime is the time at which the tram is expected to
+// depart, based on the wait when the departure was last updated, and is omitted when the tram is delayed. WaitAdjusted
+// is true when the wait has been reduced from the wait shown on the display to allow for the age of the data.
 type MetrolinkDeparture struct {
-	AtcoCode    string    `json:"atcoCode"`
-	Sequence    int       `json:"sequence"`
-	Destination string    `json:"destination"`
-	Status      string    `json:"status"`
-	Wait        string    `json:"wait"`
-	Carriages   string    `json:"carriages"`
-	Platform    *string   `json:"platform,omitempty"`
-	LastUpdated time.Time `json:"lastUpdated"`
+	AtcoCode              string     `json:"atcoCode"`
+	Sequence              int        `json:"sequence"`
+	Destination           string     `json:"destination"`
+	Status                string     `json:"status"`
+	Wait                  string     `json:"wait"`
+	WaitAdjusted          bool       `json:"waitAdjusted,omitempty"`
+	ExpectedDepartureTime *time.Time `json:"expectedDepartureTime,omitempty"`
+	Carriages             string     `json:"carriages"`
+	Platform              *string    `json:"platform,omitempty"`
+	LastUpdated           time.Time  `json:"lastUpdated"`
 }
@@ -0,0 +1,41 @@
+package tfgm
+
+import "time"
+
+// MetrolinkDeparturesV2 are the departures from a stop area or stop. When a stop is requested, StopAreaCode is its stop
+// area and OtherPlatforms are the AtcoCodes of the other stops in the stop area. Stale is true, and DataAgeSeconds is
+// the age of the departures, when the last known departures are served because departures could not be updated from the
+// source.
+type MetrolinkDeparturesV2 struct {
+	RequestedLocation string                  `json:"requestedLocation"`
+	StopAreaCode      string                  `json:"stopAreaCode,omitempty"`
+	OtherPlatforms    []string                `json:"otherPlatforms,omitempty"`
+	StationLocation   string                  `json:"stationLocation,omitempty"`
+	Tlaref            string                  `json:"tlaref,omitempty"`
+	Departures        []*MetrolinkDepartureV2 `json:"departures"`
+	Messages          []*MetrolinkMessage     `json:"messages"`
+	LastUpdated       time.Time               `json:"lastUpdated"`
+	Stale             bool                    `json:"stale,omitempty"`
+	DataAgeSeconds    int                     `json:"dataAgeSeconds,omitempty"`
+}
+
+// MetrolinkDepartureV2 is a departure from a stop. ExpectedDepartureTime is the time at which the tram is expected to
+// depart, based on the wait when the departure was last updated, and is omitted when the tram is delayed. WaitAdjusted
+// is true when the wait has been reduced from the wait shown on the display to allow for the age of the data.
+type MetrolinkDepartureV2 struct {
+	AtcoCode              string     `json:"atcoCode"`
+	Sequence              int        `json:"sequence"`
+	Line                  string     `json:"line"`
+	Direction             string     `json:"direction"`
+	Destination           string     `json:"destination"`
+	Status                string     `json:"status"`
+	Wait                  string     `json:"wait"`
+	WaitAdjusted          bool       `json:"waitAdjusted,omitempty"`
+	ExpectedDepartureTime *time.Time `json:"expectedDepartureTime,omitempty"`
+	Carriages             string     `json:"carriages"`
+	Platform              *string    `json:"platform,omitempty"`
+	StationLocation       string     `json:"stationLocation"`
+	Tlaref                string     `json:"tlaref"`
+	Pidref                string     `json:"pidref"`
+	LastUpdated           time.Time  `json:"lastUpdated"`
+}
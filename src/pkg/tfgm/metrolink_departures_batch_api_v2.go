@@ -0,0 +1,18 @@
+package tfgm
+
+import "time"
+
+type MetrolinkDeparturesBatchV2 struct {
+	RequestedLocations []string                                       `json:"requestedLocations"`
+	Locations          map[string]*MetrolinkDeparturesBatchLocationV2 `json:"locations"`
+	LastUpdated        time.Time                                      `json:"lastUpdated"`
+	Stale              bool                                           `json:"stale,omitempty"`
+	DataAgeSeconds     int                                            `json:"dataAgeSeconds,omitempty"`
+}
+
+// MetrolinkDeparturesBatchLocationV2 contains either the departures for a requested location or the error which
+// prevented them from being returned
+type MetrolinkDeparturesBatchLocationV2 struct {
+	*MetrolinkDeparturesV2
+	Error string `json:"error,omitempty"`
+}
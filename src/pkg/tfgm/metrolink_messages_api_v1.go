@@ -0,0 +1,21 @@
+package tfgm
+
+import "time"
+
+// MetrolinkMessages are the service messages for a stop area, stop or line. Stale is true, and DataAgeSeconds is the age
+// of the messages, when the last known messages are served because they could not be updated from the source.
+type MetrolinkMessages struct {
+	RequestedLocation string              `json:"requestedLocation,omitempty"`
+	RequestedLine     string              `json:"requestedLine,omitempty"`
+	Messages          []*MetrolinkMessage `json:"messages"`
+	LastUpdated       time.Time           `json:"lastUpdated"`
+	Stale             bool                `json:"stale,omitempty"`
+	DataAgeSeconds    int                 `json:"dataAgeSeconds,omitempty"`
+}
+
+type MetrolinkMessage struct {
+	Message     string    `json:"message"`
+	AtcoCodes   []string  `json:"atcoCodes"`
+	Lines       []string  `json:"lines"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
@@ -0,0 +1,66 @@
+package tfgm
+
+import "encoding/json"
+
+type MetrolinkStopAreas struct {
+	StopAreas []*MetrolinkStopArea `json:"stopAreas"`
+}
+
+// MetrolinkStopArea is a Metrolink stop and its platforms. The StopAreaCode, or the AtcoCode of any of its stops, can be
+// passed to the departures API.
+type MetrolinkStopArea struct {
+	StopAreaCode    string           `json:"stopAreaCode"`
+	Name            string           `json:"name,omitempty"`
+	StationLocation string           `json:"stationLocation,omitempty"`
+	Tlaref          string           `json:"tlaref,omitempty"`
+	Latitude        *float64         `json:"latitude,omitempty"`
+	Longitude       *float64         `json:"longitude,omitempty"`
+	Stops           []*MetrolinkStop `json:"stops"`
+}
+
+type MetrolinkStop struct {
+	AtcoCode   string   `json:"atcoCode"`
+	Platform   *string  `json:"platform,omitempty"`
+	CommonName string   `json:"commonName,omitempty"`
+	Indicator  string   `json:"indicator,omitempty"`
+	Street     string   `json:"street,omitempty"`
+	Latitude   *float64 `json:"latitude,omitempty"`
+	Longitude  *float64 `json:"longitude,omitempty"`
+}
+
+// MetrolinkNearbyStopAreas are the Metrolink stop areas closest to a location, nearest first. Departures is the batch
+// departures API response for the stop areas, when departures are requested, and DeparturesStale is set when those
+// departures are stale. DeparturesError describes why departures were requested but could not be served.
+type MetrolinkNearbyStopAreas struct {
+	Latitude        float64                    `json:"latitude"`
+	Longitude       float64                    `json:"longitude"`
+	RadiusMetres    float64                    `json:"radiusMetres"`
+	StopAreas       []*MetrolinkNearbyStopArea `json:"stopAreas"`
+	Departures      json.RawMessage            `json:"departures,omitempty"`
+	DeparturesStale bool                       `json:"departuresStale,omitempty"`
+	DeparturesError string                     `json:"departuresError,omitempty"`
+}
+
+type MetrolinkNearbyStopArea struct {
+	StopAreaCode   string   `json:"stopAreaCode"`
+	Name           string   `json:"name,omitempty"`
+	Latitude       *float64 `json:"latitude,omitempty"`
+	Longitude      *float64 `json:"longitude,omitempty"`
+	DistanceMetres int      `json:"distanceMetres"`
+}
+
+// MetrolinkStopSearchResults are the Metrolink stop areas which match a search query, best match first
+type MetrolinkStopSearchResults struct {
+	Query     string                       `json:"query"`
+	StopAreas []*MetrolinkStopSearchResult `json:"stopAreas"`
+}
+
+// MetrolinkStopSearchResult is a stop area which matches a search query. The Score is out of 100, for an exact match of
+// the name, StationLocation or TLAREF of the stop area.
+type MetrolinkStopSearchResult struct {
+	StopAreaCode    string `json:"stopAreaCode"`
+	Name            string `json:"name,omitempty"`
+	StationLocation string `json:"stationLocation,omitempty"`
+	Tlaref          string `json:"tlaref,omitempty"`
+	Score           int    `json:"score"`
+}
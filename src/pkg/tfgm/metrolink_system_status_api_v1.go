@@ -0,0 +1,24 @@
+package tfgm
+
+import "time"
+
+const (
+	MetrolinkSystemStatusOk          = "OK"
+	MetrolinkSystemStatusDegraded    = "DEGRADED"
+	MetrolinkSystemStatusOutdated    = "OUTDATED"
+	MetrolinkSystemStatusUnavailable = "UNAVAILABLE"
+)
+
+type MetrolinkSystemStatus struct {
+	Status                       string     `json:"status"`
+	Error                        string     `json:"error,omitempty"`
+	LastUpdated                  *time.Time `json:"lastUpdated,omitempty"`
+	DataAgeSeconds               *int       `json:"dataAgeSeconds,omitempty"`
+	LastAttempt                  *time.Time `json:"lastAttempt,omitempty"`
+	LastSuccess                  *time.Time `json:"lastSuccess,omitempty"`
+	ConsecutiveFailures          int        `json:"consecutiveFailures"`
+	LastErrorCategory            string     `json:"lastErrorCategory,omitempty"`
+	PassengerInformationDisplays int        `json:"passengerInformationDisplays"`
+	Departures                   int        `json:"departures"`
+	Messages                     int        `json:"messages"`
+}
@@ -24,6 +24,155 @@ resource "aws_api_gateway_integration" "api_departures_metrolink_v1_lambda_api_g
 }
 
 
+# /departures/metrolink/v1/{stopAreaCodeOrAtcoCode}/messages
+
+resource "aws_api_gateway_resource" "api_departures_metrolink_v1_messages_lambda_api_gateway_resource" {
+  rest_api_id = aws_api_gateway_resource.api_departures_metrolink_v1_lambda_api_gateway_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.api_departures_metrolink_v1_lambda_api_gateway_resource.id
+  path_part   = "messages"
+}
+
+resource "aws_api_gateway_method" "api_departures_metrolink_v1_messages_lambda_api_gateway_method" {
+  authorization = "NONE"
+  http_method   = "GET"
+  resource_id   = aws_api_gateway_resource.api_departures_metrolink_v1_messages_lambda_api_gateway_resource.id
+  rest_api_id   = aws_api_gateway_resource.api_departures_metrolink_v1_messages_lambda_api_gateway_resource.rest_api_id
+}
+
+resource "aws_api_gateway_integration" "api_departures_metrolink_v1_messages_lambda_api_gateway_integration" {
+  http_method             = aws_api_gateway_method.api_departures_metrolink_v1_messages_lambda_api_gateway_method.http_method
+  resource_id             = aws_api_gateway_method.api_departures_metrolink_v1_messages_lambda_api_gateway_method.resource_id
+  rest_api_id             = aws_api_gateway_method.api_departures_metrolink_v1_messages_lambda_api_gateway_method.rest_api_id
+  type                    = "AWS_PROXY"
+  integration_http_method = "POST"
+  uri                     = aws_lambda_function.api_departures_metrolink_v1_lambda_function.invoke_arn
+  passthrough_behavior    = "WHEN_NO_MATCH"
+}
+
+# /departures/metrolink/v1/lines
+
+resource "aws_api_gateway_resource" "departures_metrolink_v1_lines_resource" {
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_v1_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.departures_metrolink_v1_resource.id
+  path_part   = "lines"
+}
+
+# /departures/metrolink/v1/lines/{line}
+
+resource "aws_api_gateway_resource" "departures_metrolink_v1_line_resource" {
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_v1_lines_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.departures_metrolink_v1_lines_resource.id
+  path_part   = "{line}"
+}
+
+# /departures/metrolink/v1/lines/{line}/messages
+
+resource "aws_api_gateway_resource" "api_departures_metrolink_v1_line_messages_lambda_api_gateway_resource" {
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_v1_line_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.departures_metrolink_v1_line_resource.id
+  path_part   = "messages"
+}
+
+resource "aws_api_gateway_method" "api_departures_metrolink_v1_line_messages_lambda_api_gateway_method" {
+  authorization = "NONE"
+  http_method   = "GET"
+  resource_id   = aws_api_gateway_resource.api_departures_metrolink_v1_line_messages_lambda_api_gateway_resource.id
+  rest_api_id   = aws_api_gateway_resource.api_departures_metrolink_v1_line_messages_lambda_api_gateway_resource.rest_api_id
+}
+
+resource "aws_api_gateway_integration" "api_departures_metrolink_v1_line_messages_lambda_api_gateway_integration" {
+  http_method             = aws_api_gateway_method.api_departures_metrolink_v1_line_messages_lambda_api_gateway_method.http_method
+  resource_id             = aws_api_gateway_method.api_departures_metrolink_v1_line_messages_lambda_api_gateway_method.resource_id
+  rest_api_id             = aws_api_gateway_method.api_departures_metrolink_v1_line_messages_lambda_api_gateway_method.rest_api_id
+  type                    = "AWS_PROXY"
+  integration_http_method = "POST"
+  uri                     = aws_lambda_function.api_departures_metrolink_v1_lambda_function.invoke_arn
+  passthrough_behavior    = "WHEN_NO_MATCH"
+}
+
+# /departures/metrolink/v2
+
+resource "aws_api_gateway_resource" "departures_metrolink_v2_resource" {
+  parent_id   = aws_api_gateway_resource.departures_metrolink_resource.id
+  path_part   = "v2"
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_resource.rest_api_id
+}
+
+# /departures/metrolink/v2/{stopAreaCodeOrAtcoCode}
+
+resource "aws_api_gateway_resource" "api_departures_metrolink_v2_lambda_api_gateway_resource" {
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_v2_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.departures_metrolink_v2_resource.id
+  path_part   = "{stopAreaCodeOrAtcoCode}"
+}
+
+resource "aws_api_gateway_method" "api_departures_metrolink_v2_lambda_api_gateway_method" {
+  authorization = "NONE"
+  http_method   = "GET"
+  resource_id   = aws_api_gateway_resource.api_departures_metrolink_v2_lambda_api_gateway_resource.id
+  rest_api_id   = aws_api_gateway_resource.api_departures_metrolink_v2_lambda_api_gateway_resource.rest_api_id
+}
+
+resource "aws_api_gateway_integration" "api_departures_metrolink_v2_lambda_api_gateway_integration" {
+  http_method             = aws_api_gateway_method.api_departures_metrolink_v2_lambda_api_gateway_method.http_method
+  resource_id             = aws_api_gateway_method.api_departures_metrolink_v2_lambda_api_gateway_method.resource_id
+  rest_api_id             = aws_api_gateway_method.api_departures_metrolink_v2_lambda_api_gateway_method.rest_api_id
+  type                    = "AWS_PROXY"
+  integration_http_method = "POST"
+  uri                     = aws_lambda_function.api_departures_metrolink_v1_lambda_function.invoke_arn
+  passthrough_behavior    = "WHEN_NO_MATCH"
+}
+
+# /departures/metrolink/v2/batch
+
+resource "aws_api_gateway_resource" "api_departures_metrolink_v2_batch_lambda_api_gateway_resource" {
+  rest_api_id = aws_api_gateway_resource.departures_metrolink_v2_resource.rest_api_id
+  parent_id   = aws_api_gateway_resource.departures_metrolink_v2_resource.id
+  path_part   = "batch"
+}
+
+resource "aws_api_gateway_method" "api_departures_metrolink_v2_batch_lambda_api_gateway_method" {
+  authorization = "NONE"
+  http_method   = "GET"
+  resource_id   = aws_api_gateway_resource.api_departures_metrolink_v2_batch_lambda_api_gateway_resource.id
+  rest_api_id   = aws_api_gateway_resource.api_departures_metrolink_v2_batch_lambda_api_gateway_resource.rest_api_id
+}
+
+resource "aws_api_gateway_integration" "api_departures_metrolink_v2_batch_lambda_api_gateway_integration" {
+  http_method             = aws_api_gateway_method.api_departures_metrolink_v2_batch_lambda_api_gateway_method.http_method
+  resource_id             = aws_api_gateway_method.api_departures_metrolink_v2_batch_lambda_api_gateway_method.resource_id
+  rest_api_id             = aws_api_gateway_method.api_departures_metrolink_v2_batch_lambda_api_gateway_method.rest_api_id
+  type                    = "AWS_PROXY"
+  integration_http_method = "POST"
+  uri                     = aws_lambda_function.api_departures_metrolink_v1_lambda_function.invoke_arn
+  passthrough_behavior    = "WHEN_NO_MATCH"
+}
+
+# /status
+
+resource "aws_api_gateway_resource" "api_status_lambda_api_gateway_resource" {
+  rest_api_id = aws_api_gateway_rest_api.tfgm_com.id
+  parent_id   = aws_api_gateway_rest_api.tfgm_com.root_resource_id
+  path_part   = "status"
+}
+
+resource "aws_api_gateway_method" "api_status_lambda_api_gateway_method" {
+  authorization = "NONE"
+  http_method   = "GET"
+  resource_id   = aws_api_gateway_resource.api_status_lambda_api_gateway_resource.id
+  rest_api_id   = aws_api_gateway_resource.api_status_lambda_api_gateway_resource.rest_api_id
+}
+
+resource "aws_api_gateway_integration" "api_status_lambda_api_gateway_integration" {
+  http_method             = aws_api_gateway_method.api_status_lambda_api_gateway_method.http_method
+  resource_id             = aws_api_gateway_method.api_status_lambda_api_gateway_method.resource_id
+  rest_api_id             = aws_api_gateway_method.api_status_lambda_api_gateway_method.rest_api_id
+  type                    = "AWS_PROXY"
+  integration_http_method = "POST"
+  uri                     = aws_lambda_function.api_departures_metrolink_v1_lambda_function.invoke_arn
+  passthrough_behavior    = "WHEN_NO_MATCH"
+}
+
 resource "aws_lambda_function" "api_departures_metrolink_v1_lambda_function" {
   depends_on = [
     aws_iam_role_policy_attachment.api_departures_metrolink_v1_iam_role_policy_attachment,
@@ -62,6 +211,7 @@ resource "aws_lambda_function" "api_departures_metrolink_v1_lambda_function" {
       REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS = "${aws_elasticache_cluster.tfgm_com.cache_nodes.0.address}:${aws_elasticache_cluster.tfgm_com.cache_nodes.0.port}"
       REDIS_STOPS_IN_AREA_SERVER_ADDRESS                       = "${aws_elasticache_cluster.tfgm_com.cache_nodes.0.address}:${aws_elasticache_cluster.tfgm_com.cache_nodes.0.port}"
       STOP_AREA_CODE_OR_ATCO_CODE_API_GATEWAY_PATH_PARAMETER   = "stopAreaCodeOrAtcoCode"
+      LINE_API_GATEWAY_PATH_PARAMETER                          = "line"
     }
   }
 }
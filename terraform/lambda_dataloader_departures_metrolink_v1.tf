@@ -38,7 +38,7 @@ resource "aws_lambda_function" "dataloader_departures_metrolink_v1_lambda_functi
       METROLINK_DEPARTURES_STALE_DATA_THRESHOLD                = "30s"
       REDIS_METROLINK_DEPARTURES_SERVER_ADDRESS                = "${aws_elasticache_cluster.tfgm_com.cache_nodes.0.address}:${aws_elasticache_cluster.tfgm_com.cache_nodes.0.port}"
       REDIS_METROLINK_DEPARTURES_SERVICE_STATUS_SERVER_ADDRESS = "${aws_elasticache_cluster.tfgm_com.cache_nodes.0.address}:${aws_elasticache_cluster.tfgm_com.cache_nodes.0.port}"
-      REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE                  = "15s"
+      REDIS_METROLINK_DEPARTURES_TIME_TO_LIVE                  = "1m"
       TFGM_METROLINKS_API_KEY                                  = var.departures_metrolink_v1_tfgm_developer_api_key
       TFGM_METROLINKS_API_URL                                  = var.departures_metrolink_v1_tfgm_developer_metrolinks_url
     }